@@ -22,9 +22,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/httpVersion": {
+        "/api-keys": {
             "get": {
-                "description": "Checks and logs the HTTP version of the incoming request to the server console.",
+                "description": "List the API keys (without the keys) in order of their creation time, including the revoked ones.\nThe caller, if not an admin, sees its own keys only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -32,18 +32,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[System] Utility"
+                    "[Auth] Identity and API keys"
+                ],
+                "summary": "List the API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name of the principal (admin only)",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Check HTTP version of incoming request",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ApiKey"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden (e.g., the authentication is disabled)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -55,11 +78,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/readyz": {
-            "get": {
-                "description": "Check mc-terrarium server is ready",
+            },
+            "post": {
+                "description": "Create an API key authenticating a principal (i.e., Authorization: Bearer \u003ckey\u003e, or x-api-key: \u003ckey\u003e).\nThe key is returned only once, and only its hash is stored.\nThe principal is the caller, bound to the credential holder of the caller, by default.\nThe caller, if not an admin, creates a non-admin key of its own (and of its credential holder) only.\nThe keys are not managed if the authentication is disabled.",
                 "consumes": [
                     "application/json"
                 ],
@@ -67,18 +88,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[System] Utility"
+                    "[Auth] Identity and API keys"
+                ],
+                "summary": "Create an API key",
+                "parameters": [
+                    {
+                        "description": "Principal of the API key",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateApiKeyRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Check mc-terrarium server is ready",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateApiKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "403": {
+                        "description": "Forbidden (e.g., a key of another principal, or the authentication is disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -86,9 +142,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tofuVersion": {
-            "get": {
-                "description": "Check Tofu version",
+        "/api-keys/{keyId}": {
+            "delete": {
+                "description": "Revoke an API key, which is kept (with revokedAt) for the audit. Revoking a revoked key does nothing.\nThe caller, if not an admin, revokes its own keys only. The keys are not managed if the authentication is disabled.",
                 "consumes": [
                     "application/json"
                 ],
@@ -96,18 +152,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[System] Utility"
+                    "[Auth] Identity and API keys"
+                ],
+                "summary": "Revoke an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "keyId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Check Tofu version",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ApiKey"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (e.g., a key of another principal, or the authentication is disabled)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -115,9 +204,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr": {
+        "/credential-profiles": {
             "get": {
-                "description": "Read all terrarium",
+                "description": "List the credential profiles (i.e., credential holders) in the secrets store with the providers whose credentials are configured.\nThe admin profile is always listed first.",
                 "consumes": [
                     "application/json"
                 ],
@@ -125,9 +214,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
+                    "[Credential] Profile management"
                 ],
-                "summary": "Read all terrarium",
+                "summary": "List the credential profiles",
                 "parameters": [
                     {
                         "type": "string",
@@ -148,18 +237,24 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.TerrariumInfo"
+                                "$ref": "#/definitions/model.CredentialProfile"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden (e.g., not held by the caller)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
                     "503": {
-                        "description": "Service Unavailable",
+                        "description": "Service Unavailable (e.g., the secrets store is not configured)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -167,7 +262,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Issue/create a terrarium",
+                "description": "Create a credential profile with the credentials by provider (e.g., {\"aws\": {\"AWS_ACCESS_KEY_ID\": \"...\", \"AWS_SECRET_ACCESS_KEY\": \"...\"}}).\nThe credentials are stored in the secrets store (i.e., users/{name}/csp/{provider}), and they are not returned by any API.",
                 "consumes": [
                     "application/json"
                 ],
@@ -175,17 +270,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
+                    "[Credential] Profile management"
                 ],
-                "summary": "Issue/create a terrarium",
+                "summary": "Create a credential profile",
                 "parameters": [
                     {
-                        "description": "Information for a new terrarium",
+                        "description": "Name and credentials of the credential profile",
                         "name": "RequestBody",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.TerrariumCreationRequest"
+                            "$ref": "#/definitions/model.CreateCredentialProfileRequest"
                         }
                     },
                     {
@@ -202,10 +297,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.CredentialProfile"
                         }
                     },
                     "400": {
@@ -214,8 +309,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (e.g., not held by the caller)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., already exists)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "503": {
-                        "description": "Service Unavailable",
+                        "description": "Service Unavailable (e.g., the secrets store is not configured)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -223,9 +336,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}": {
+        "/credential-profiles/{name}": {
             "get": {
-                "description": "Read a terrarium",
+                "description": "Get a credential profile with the providers whose credentials are configured.",
                 "consumes": [
                     "application/json"
                 ],
@@ -233,15 +346,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
+                    "[Credential] Profile management"
                 ],
-                "summary": "Read a terrarium",
+                "summary": "Get a credential profile",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
+                        "default": "admin",
+                        "description": "Credential profile name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
@@ -262,7 +375,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.TerrariumInfo"
+                            "$ref": "#/definitions/model.CredentialProfile"
                         }
                     },
                     "400": {
@@ -271,16 +384,34 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (e.g., not held by the caller)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "503": {
-                        "description": "Service Unavailable",
+                        "description": "Service Unavailable (e.g., the secrets store is not configured)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Erase the entire terrarium including directories and configuration files",
+            "put": {
+                "description": "Update the credentials of the providers in a credential profile.\nThe credential of a provider is replaced, or deleted if empty (e.g., {\"tencent\": {}}). The other providers are kept.",
                 "consumes": [
                     "application/json"
                 ],
@@ -288,18 +419,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
+                    "[Credential] Profile management"
                 ],
-                "summary": "Erase the entire terrarium including directories and configuration files",
+                "summary": "Update a credential profile",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
+                        "default": "admin",
+                        "description": "Credential profile name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Credentials to replace (or delete if empty) by provider",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateCredentialProfileRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -317,7 +457,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.CredentialProfile"
                         }
                     },
                     "400": {
@@ -326,18 +466,34 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (e.g., not held by the caller)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "503": {
-                        "description": "Service Unavailable",
+                        "description": "Service Unavailable (e.g., the secrets store is not configured)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/message-broker": {
-            "get": {
-                "description": "Get resource info of Message Broker",
+            },
+            "delete": {
+                "description": "Delete a credential profile with all its credentials.\nA credential profile used by any terrarium is not deleted, and the admin profile cannot be deleted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -345,32 +501,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Credential] Profile management"
                 ],
-                "summary": "Get resource info of Message Broker",
+                "summary": "Delete a credential profile",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
+                        "description": "Credential profile name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "string",
-                        "default": "refined",
-                        "description": "Resource info by detail (refined, raw)",
-                        "name": "detail",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": true,
-                        "description": "Refresh the state before getting the info",
-                        "name": "refresh",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -397,6 +538,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (e.g., not held by the caller)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., used by terrariums)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -404,15 +563,17 @@ const docTemplate = `{
                         }
                     },
                     "503": {
-                        "description": "Service Unavailable",
+                        "description": "Service Unavailable (e.g., the secrets store is not configured)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/credential-profiles/{name}/verify": {
             "post": {
-                "description": "Create Message Broker",
+                "description": "Verify the credential of a provider in a credential profile before running the enrichments,\nby running a tiny, read-only tofu configuration (e.g., aws_caller_identity) in a scratch directory.\nNo resource is created. It takes a while (i.e., tofu init and plan) but much less than a failed apply.\nThe result is passed, or failed at a stage (credential, init or plan) with the diagnostic of tofu (e.g., an expired or mis-scoped key).",
                 "consumes": [
                     "application/json"
                 ],
@@ -420,21 +581,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Credential] Profile management"
                 ],
-                "summary": "Create Message Broker",
+                "summary": "Verify the credential of a provider in a credential profile",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
+                        "default": "admin",
+                        "description": "Credential profile name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
+                        "enum": [
+                            "aws",
+                            "azure",
+                            "gcp",
+                            "alibaba",
+                            "tencent",
+                            "ibm",
+                            "ncp",
+                            "dcs"
+                        ],
                         "type": "string",
-                        "description": "Custom request ID",
+                        "default": "aws",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
                         "name": "x-request-id",
                         "in": "header"
                     },
@@ -447,9 +626,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK (passed or failed)",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.CredentialVerification"
                         }
                     },
                     "400": {
@@ -458,6 +637,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (e.g., not held by the caller)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -471,9 +662,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Destroy Message Broker",
+            }
+        },
+        "/enrichments": {
+            "get": {
+                "description": "List the enrichments (i.e., kinds of resources) served by /tr/{trId}/enrichments/{kind}",
                 "consumes": [
                     "application/json"
                 ],
@@ -481,31 +674,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Destroy Message Broker",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Custom request ID",
-                        "name": "x-request-id",
-                        "in": "header"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Credential holder (profile) name",
-                        "name": "x-credential-holder",
-                        "in": "header"
+                "summary": "List the enrichments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
                     }
+                }
+            }
+        },
+        "/httpVersion": {
+            "get": {
+                "description": "Checks and logs the HTTP version of the incoming request to the server console.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[System] Utility"
                 ],
+                "summary": "Check HTTP version of incoming request",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -513,8 +707,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -524,6 +718,29 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check mc-terrarium server is ready",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[System] Utility"
+                ],
+                "summary": "Check mc-terrarium server is ready",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
                     },
                     "503": {
                         "description": "Service Unavailable",
@@ -534,9 +751,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/message-broker/env": {
-            "post": {
-                "description": "Initialize a multi-cloud terrarium for Message Broker (e.g., AWS MQ Broker (ActiveMQ))",
+        "/recovery": {
+            "get": {
+                "description": "Get the report of the recovery at startup, which reconciles the requests and terrariums left running by a previous server process.\nThe requests whose process no longer exists are marked Interrupted and the execution locks of their terrariums are released.\nThe state locks left by the requests are released if force unlock is configured (` + "`" + `terrarium.recovery.force_unlock` + "`" + `).\nThe caller, if not an admin, gets the requests and terrariums of its own credential holder only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -544,56 +761,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
-                ],
-                "summary": "Initialize a multi-cloud terrarium for Message Broker (e.g., AWS MQ Broker (ActiveMQ))",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "enum": [
-                            "aws"
-                        ],
-                        "type": "string",
-                        "default": "aws",
-                        "description": "Provider",
-                        "name": "provider",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Custom request ID",
-                        "name": "x-request-id",
-                        "in": "header"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Credential holder (profile) name",
-                        "name": "x-credential-holder",
-                        "in": "header"
-                    }
+                    "[System] Utility"
                 ],
+                "summary": "Get the report of the recovery at startup",
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Report of the recovery",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.RecoveryReport"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                    }
+                }
+            }
+        },
+        "/tofuVersion": {
+            "get": {
+                "description": "Check Tofu version",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[System] Utility"
+                ],
+                "summary": "Check Tofu version",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -605,9 +807,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Clear the entire directory and configuration files",
+            }
+        },
+        "/tr": {
+            "get": {
+                "description": "Read all terrarium, which are selected by labels, filtered, sorted and paginated.\nThe label selector is a comma-separated list of the requirements, e.g., \"env=staging,team!=network,region in (us-east-1,us-west-2),!deprecated\".\nThe filters (enrichment, provider, phase and holder) match any of the comma-separated values.\nIf more terrariums remain, the cursor to the next page is returned by the x-next-cursor header.\nIn strict mode (terrarium.api.auth.strict_holder), a caller other than an admin lists the terrariums of its own credential holder only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -615,26 +819,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Clear the entire directory and configuration files",
+                "summary": "Read all terrarium",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
-                        "in": "path",
-                        "required": true
+                        "description": "Label selector (e.g., env=staging,team!=network)",
+                        "name": "selector",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Enrichments (comma-separated, e.g., testbed,sql-db)",
+                        "name": "enrichment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Providers (comma-separated, e.g., aws,gcp)",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Phases (comma-separated, e.g., Ready,Failed)",
+                        "name": "phase",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holders (comma-separated)",
+                        "name": "holder",
+                        "in": "query"
                     },
                     {
                         "enum": [
-                            "force"
+                            "id",
+                            "-id",
+                            "name",
+                            "-name",
+                            "phase",
+                            "-phase",
+                            "createdAt",
+                            "-createdAt"
                         ],
                         "type": "string",
-                        "default": "",
-                        "description": "Action",
-                        "name": "action",
+                        "default": "id",
+                        "description": "Field to sort by, prefixed with '-' in descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Maximum number of terrariums in a page (0: all)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor to the next page (i.e., x-next-cursor of the previous page)",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
@@ -654,7 +900,16 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TerrariumInfo"
+                            }
+                        },
+                        "headers": {
+                            "x-next-cursor": {
+                                "type": "string",
+                                "description": "Cursor to the next page (if more terrariums remain)"
+                            }
                         }
                     },
                     "400": {
@@ -663,12 +918,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
                     "503": {
                         "description": "Service Unavailable",
                         "schema": {
@@ -676,11 +925,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/message-broker/infracode": {
+            },
             "post": {
-                "description": "Create the infracode for Message Broker",
+                "description": "Issue/create a terrarium\nThe terrarium is held by the credential holder of the request (x-credential-holder),\nwhich is bound to the authenticated caller in strict mode (terrarium.api.auth.strict_holder), where only admins may name another holder.",
                 "consumes": [
                     "application/json"
                 ],
@@ -688,25 +935,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Create the infracode for Message Broker",
+                "summary": "Issue/create a terrarium",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Parameters of infracode for Message Broker",
-                        "name": "ParamsForInfracode",
+                        "description": "Information for a new terrarium",
+                        "name": "RequestBody",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateInfracodeOfMessageBrokerRequest"
+                            "$ref": "#/definitions/model.TerrariumCreationRequest"
                         }
                     },
                     {
@@ -723,8 +962,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -735,12 +974,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
                     "503": {
                         "description": "Service Unavailable",
                         "schema": {
@@ -750,27 +983,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/message-broker/plan": {
+        "/tr/import": {
             "post": {
-                "description": "Check and show changes by the current infracode",
+                "description": "Import a terrarium from an archive (tar.gz) exported by another server.\nThe terrarium is held by the credential holder of the request, and imported as trId if given (otherwise, as it was exported).\nThe providers of the enrichments are installed again (i.e., init), and the saved plans must be created again.\nAn encrypted archive requires the passphrase given on export by x-archive-passphrase.\nAn archive with the redacted sensitive values (i.e., exported without a passphrase) is refused.",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Check and show changes by the current infracode",
+                "summary": "Import a terrarium from an archive",
                 "parameters": [
+                    {
+                        "type": "file",
+                        "description": "The archive (tar.gz) exported from a terrarium",
+                        "name": "archive",
+                        "in": "formData",
+                        "required": true
+                    },
                     {
                         "type": "string",
-                        "default": "tr01",
-                        "description": "Terrarium ID",
+                        "description": "Terrarium ID to import as (if omitted, the ID in the archive)",
                         "name": "trId",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Passphrase to decrypt the sensitive values (required if encrypted)",
+                        "name": "x-archive-passphrase",
+                        "in": "header"
                     },
                     {
                         "type": "string",
@@ -786,10 +1030,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.TerrariumInfo"
                         }
                     },
                     "400": {
@@ -798,6 +1042,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., the terrarium already exists)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -813,9 +1063,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/message-broker/request/{requestId}": {
+        "/tr/{trId}": {
             "get": {
-                "description": "Check the status of a specific request by its ID",
+                "description": "Read a terrarium",
                 "consumes": [
                     "application/json"
                 ],
@@ -823,9 +1073,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Message Broker] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Check the status of a specific request by its ID",
+                "summary": "Read a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -835,13 +1085,6 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "string",
-                        "description": "Request ID",
-                        "name": "requestId",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -859,7 +1102,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.TerrariumInfo"
                         }
                     },
                     "400": {
@@ -868,12 +1111,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
                     "503": {
                         "description": "Service Unavailable",
                         "schema": {
@@ -881,11 +1118,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/object-storage": {
-            "get": {
-                "description": "Get resource info of Object Storage",
+            },
+            "delete": {
+                "description": "Erase the entire terrarium including directories and configuration files\nThe state of each enrichment is inspected (i.e., ` + "`" + `tofu state list` + "`" + `) before erasing,\nand it is refused with the list of the resources if any exist, which would be orphaned.\nWith destroy=true, the resources are destroyed first (the enrichments referencing the outputs of another enrichment before it),\nand the terrarium is erased only if it succeeds.\nWith force=true, the terrarium is erased without inspecting the state (i.e., the resources are orphaned).\nThe terrarium is not erased while a request is running in any enrichment, even with force=true.",
                 "consumes": [
                     "application/json"
                 ],
@@ -893,9 +1128,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Get resource info of Object Storage",
+                "summary": "Erase the entire terrarium including directories and configuration files",
                 "parameters": [
                     {
                         "type": "string",
@@ -906,17 +1141,23 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "default": "refined",
-                        "description": "Resource info by detail (refined, raw)",
-                        "name": "detail",
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Destroy the resources before erasing",
+                        "name": "destroy",
                         "in": "query"
                     },
                     {
                         "type": "boolean",
-                        "default": true,
-                        "description": "Refresh the state before getting the info",
-                        "name": "refresh",
+                        "default": false,
+                        "description": "Erase without inspecting the state (orphan the resources)",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
                         "in": "query"
                     },
                     {
@@ -945,6 +1186,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., resources still exist, a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -958,9 +1205,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/tr/{trId}/clone": {
             "post": {
-                "description": "Create Object Storage",
+                "description": "Clone a terrarium as a new terrarium (e.g., to stand up the same testbed or VPN in another region).\nThe enrichments are copied with their templates and tfvars, but with a fresh, empty state,\nand the providers of the enrichments are installed (i.e., init).\nThe tfvars of each enrichment (key: enrichment) are overridden by tfVars as a JSON merge patch,\ne.g., {\"testbed\": {\"aws_region\": \"ap-northeast-1\"}}, and the terrarium_id is set to the new terrarium.\nThe new terrarium is validated (e.g., undeclared variables, required tfvars) before it is saved.",
                 "consumes": [
                     "application/json"
                 ],
@@ -968,9 +1217,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Create Object Storage",
+                "summary": "Clone a terrarium as a new terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -980,6 +1229,15 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "The new terrarium and the tfvars to override",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CloneTerrariumRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -994,10 +1252,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.TerrariumInfo"
                         }
                     },
                     "400": {
@@ -1006,6 +1264,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., the terrarium already exists)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1019,9 +1283,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Destroy Object Storage",
+            }
+        },
+        "/tr/{trId}/drift": {
+            "get": {
+                "description": "Get the drift reports of the applied enrichments of a terrarium, i.e., the resources changed outside mc-terrarium.\nThe drift is detected periodically by ` + "`" + `tofu plan -refresh-only` + "`" + ` if the drift detector is enabled (terrarium.drift.interval_min).\nA report shows the drifted resources and their changed attributes (the sensitive values are not shown) at the last detection.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1029,9 +1295,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Destroy Object Storage",
+                "summary": "Get the drift reports of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -1041,6 +1307,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Enrichment (e.g., vpn/aws-to-site, sql-db)",
+                        "name": "enrichment",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1058,7 +1330,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DriftReport"
+                            }
                         }
                     },
                     "400": {
@@ -1082,9 +1357,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/object-storage/env": {
-            "post": {
-                "description": "Initialize a multi-cloud terrarium for Object Storage (e.g., AWS S3 Bucket, Azure Blob Storage)",
+        "/tr/{trId}/enrichments": {
+            "get": {
+                "description": "List the enrichments of a terrarium (e.g., testbed, vpn/site-to-site, sql-db) in order of their names.\nEach enrichment has its own working directory, state, execution status and providers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1092,9 +1367,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Initialize a multi-cloud terrarium for Object Storage (e.g., AWS S3 Bucket, Azure Blob Storage)",
+                "summary": "List the enrichments of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -1104,17 +1379,6 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "enum": [
-                            "aws",
-                            "azure"
-                        ],
-                        "type": "string",
-                        "default": "aws",
-                        "description": "Provider",
-                        "name": "provider",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1129,10 +1393,13 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.EnrichmentInfo"
+                            }
                         }
                     },
                     "400": {
@@ -1141,22 +1408,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Clear the entire directory and configuration files",
+            }
+        },
+        "/tr/{trId}/enrichments/{kind}": {
+            "get": {
+                "description": "Get resource info of an enrichment",
                 "consumes": [
                     "application/json"
                 ],
@@ -1164,9 +1427,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Clear the entire directory and configuration files",
+                "summary": "Get resource info of an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1178,12 +1441,39 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "force"
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
                         ],
                         "type": "string",
-                        "default": "",
-                        "description": "Action",
-                        "name": "action",
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refined",
+                            "raw"
+                        ],
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
                         "in": "query"
                     },
                     {
@@ -1212,6 +1502,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1225,11 +1527,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/object-storage/infracode": {
+            },
             "post": {
-                "description": "Create the infracode for Object Storage",
+                "description": "Create the resources of an enrichment, and return the refined resource info",
                 "consumes": [
                     "application/json"
                 ],
@@ -1237,9 +1537,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Create the infracode for Object Storage",
+                "summary": "Create the resources of an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1250,13 +1550,29 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Parameters of infracode for Object Storage",
-                        "name": "ParamsForInfracode",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateInfracodeOfObjectStorageRequest"
-                        }
+                        "enum": [
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
+                        ],
+                        "type": "string",
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -1272,8 +1588,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -1284,6 +1600,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., stale plan, not initialized)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1297,11 +1625,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/object-storage/plan": {
-            "post": {
-                "description": "Check and show changes by the current infracode",
+            },
+            "delete": {
+                "description": "Destroy the resources of an enrichment",
                 "consumes": [
                     "application/json"
                 ],
@@ -1309,9 +1635,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Check and show changes by the current infracode",
+                "summary": "Destroy the resources of an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1321,6 +1647,25 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
+                        ],
+                        "type": "string",
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1347,6 +1692,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1362,9 +1719,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/object-storage/request/{requestId}": {
-            "get": {
-                "description": "Check the status of a specific request by its ID",
+        "/tr/{trId}/enrichments/{kind}/env": {
+            "post": {
+                "description": "Initialize a multi-cloud terrarium for an enrichment\nThe template files of the enrichment for the provider are copied to the working directory, and then initialized.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1372,9 +1729,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Object Storage] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Check the status of a specific request by its ID",
+                "summary": "Initialize a multi-cloud terrarium for an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1385,12 +1742,32 @@ const docTemplate = `{
                         "required": true
                     },
                     {
+                        "enum": [
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
+                        ],
                         "type": "string",
-                        "description": "Request ID",
-                        "name": "requestId",
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "aws",
+                            "azure",
+                            "gcp",
+                            "ncp"
+                        ],
+                        "type": "string",
+                        "default": "aws",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1405,8 +1782,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -1417,24 +1794,34 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/sql-db": {
-            "get": {
-                "description": "Get resource info of SQL database",
+            },
+            "delete": {
+                "description": "Clear the entire directory and configuration files of an enrichment\nThe enrichment is removed from the terrarium after it is cleared.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1442,9 +1829,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Get resource info of SQL database",
+                "summary": "Clear the entire directory and configuration files of an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1455,18 +1842,17 @@ const docTemplate = `{
                         "required": true
                     },
                     {
+                        "enum": [
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
+                        ],
                         "type": "string",
-                        "default": "refined",
-                        "description": "Resource info by detail (refined, raw)",
-                        "name": "detail",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": true,
-                        "description": "Refresh the state before getting the info",
-                        "name": "refresh",
-                        "in": "query"
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -1494,6 +1880,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, resources not destroyed)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1507,9 +1905,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/tr/{trId}/enrichments/{kind}/infracode": {
             "post": {
-                "description": "Create SQL database",
+                "description": "Create the infracode (i.e., tfvars) for an enrichment\nThe tfVars depend on the kind: model.TfVarsSqlDb (sql-db), model.TfVarsObjectStorage (object-storage) and model.TfVarsMessageBroker (message-broker).\nThe terrarium_id is set to the terrarium ID if omitted.\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1517,9 +1917,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Create SQL database",
+                "summary": "Create the infracode for an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1529,6 +1929,28 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
+                        ],
+                        "type": "string",
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters of infracode for the enrichment",
+                        "name": "ParamsForInfracode",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateInfracodeOfEnrichmentRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1543,8 +1965,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -1555,6 +1977,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1568,9 +2002,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Destroy SQL database",
+            }
+        },
+        "/tr/{trId}/enrichments/{kind}/plan": {
+            "post": {
+                "description": "Check and show changes by the current infracode of an enrichment\nThe plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1578,9 +2014,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Destroy SQL database",
+                "summary": "Check and show changes by the current infracode of an enrichment",
                 "parameters": [
                     {
                         "type": "string",
@@ -1590,6 +2026,25 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
+                        ],
+                        "type": "string",
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1616,6 +2071,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1631,9 +2098,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/sql-db/env": {
-            "post": {
-                "description": "Initialize a multi-cloud terrarium for SQL database",
+        "/tr/{trId}/enrichments/{kind}/request/{requestId}": {
+            "get": {
+                "description": "Check the status of a specific request by its ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1641,9 +2108,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Enrichment] Operations"
                 ],
-                "summary": "Initialize a multi-cloud terrarium for SQL database",
+                "summary": "Check the status of a specific request by its ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -1655,16 +2122,23 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
-                            "aws",
-                            "azure",
-                            "gcp",
-                            "ncp"
+                            "sql-db",
+                            "object-storage",
+                            "message-broker"
                         ],
                         "type": "string",
-                        "default": "aws",
-                        "description": "Provider",
-                        "name": "provider",
-                        "in": "query"
+                        "default": "sql-db",
+                        "description": "Kind of the enrichment",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "requestId",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -1680,8 +2154,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -1692,6 +2166,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1705,9 +2185,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Clear the entire directory and configuration files",
+            }
+        },
+        "/tr/{trId}/events": {
+            "get": {
+                "description": "List the events of a terrarium in order of time,\ne.g., expiry_warning, lease_extended, expired, reaped and reap_failed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1715,9 +2197,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Clear the entire directory and configuration files",
+                "summary": "List the events of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -1728,13 +2210,9 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "enum": [
-                            "force"
-                        ],
                         "type": "string",
-                        "default": "",
-                        "description": "Action",
-                        "name": "action",
+                        "description": "Event types to filter (comma-separated, e.g., expiry_warning,reaped)",
+                        "name": "type",
                         "in": "query"
                     },
                     {
@@ -1754,7 +2232,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TerrariumEvent"
+                            }
                         }
                     },
                     "400": {
@@ -1778,19 +2259,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/sql-db/infracode": {
-            "post": {
-                "description": "Create the infracode for SQL database",
+        "/tr/{trId}/export": {
+            "get": {
+                "description": "Export a terrarium as an archive (tar.gz) to be imported into another server,\nwhich includes the terrarium info, the working directories (i.e., rendered templates, tfvars, state) and the requests.\nThe sensitive values in the state and tfvars are redacted, or encrypted if a passphrase is given by x-archive-passphrase.\nAn archive with the redacted values cannot be imported, so give a passphrase to move a terrarium.\nThe archive carries a manifest version, by which an archive of an older version is migrated on import.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/gzip"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Create the infracode for SQL database",
+                "summary": "Export a terrarium as an archive",
                 "parameters": [
                     {
                         "type": "string",
@@ -1801,13 +2282,10 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Parameters of infracode for SQL database",
-                        "name": "ParamsForInfracode",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateInfracodeOfSqlDbRequest"
-                        }
+                        "type": "string",
+                        "description": "Passphrase to encrypt the sensitive values (redacted if omitted, which cannot be imported)",
+                        "name": "x-archive-passphrase",
+                        "in": "header"
                     },
                     {
                         "type": "string",
@@ -1823,10 +2301,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "The archive (tar.gz)",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -1835,6 +2313,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1850,9 +2334,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/sql-db/plan": {
+        "/tr/{trId}/extend": {
             "post": {
-                "description": "Check and show changes by the current infracode",
+                "description": "Extend the lease of a terrarium, after which its resources are destroyed and its environments are emptied out.\nWith ttl, the lease is extended by the duration from the current expiry (or now if expired or not set).\nWith expiresAt, the lease is extended (or shortened) to the time.\nThe expiry is warned again by an event (expiry_warning) within the grace period before the new expiry.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1860,9 +2344,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Check and show changes by the current infracode",
+                "summary": "Extend the lease of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -1872,6 +2356,15 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Duration to extend the lease by (ttl), or the new expiry (expiresAt)",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ExtendLeaseRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -1889,7 +2382,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.TerrariumInfo"
                         }
                     },
                     "400": {
@@ -1898,6 +2391,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., expired and being reaped)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1913,9 +2412,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/sql-db/request/{requestId}": {
-            "get": {
-                "description": "Check the status of a specific request by its ID",
+        "/tr/{trId}/labels": {
+            "put": {
+                "description": "Update (replace) the labels of a terrarium, which are used to select terrariums (e.g., GET /tr?selector=env=staging).\nA key is 1-63 alphanumeric characters, '-', '_', '.' or '/', and a value is 0-63 alphanumeric characters, '-', '_' or '.',\nbeginning and ending with an alphanumeric. Empty labels remove all the labels.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1923,9 +2422,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[SQL Database] Operations (PoC - Not officially supported)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Check the status of a specific request by its ID",
+                "summary": "Update the labels of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -1936,11 +2435,13 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Request ID",
-                        "name": "requestId",
-                        "in": "path",
-                        "required": true
+                        "description": "Labels of the terrarium",
+                        "name": "RequestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.UpdateLabelsRequest"
+                        }
                     },
                     {
                         "type": "string",
@@ -1959,7 +2460,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.TerrariumInfo"
                         }
                     },
                     "400": {
@@ -1983,9 +2484,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/testbed": {
+        "/tr/{trId}/message-broker": {
             "get": {
-                "description": "Get the testbed",
+                "description": "Deprecated: use GET /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1993,13 +2494,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] Resource Operations"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Get the testbed",
+                "summary": "Get resource info of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
@@ -2023,6 +2525,12 @@ const docTemplate = `{
                         "name": "refresh",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2049,6 +2557,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2064,7 +2578,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create the testbed",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2072,26 +2586,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] Resource Operations"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Create the testbed",
+                "summary": "Create the resources of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Parameters requied to create a testbed",
-                        "name": "ReqBody",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateTestbedRequest"
-                        }
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -2107,8 +2625,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2119,6 +2637,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2134,7 +2664,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete the testbed",
+                "description": "Deprecated: use DELETE /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2142,18 +2672,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] Resource Operations"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Delete the testbed",
+                "summary": "Destroy the resources of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2180,7 +2717,19 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
@@ -2195,9 +2744,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/testbed/actions/apply": {
+        "/tr/{trId}/message-broker/env": {
             "post": {
-                "description": "Apply the testbed",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2205,18 +2754,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Apply the testbed",
+                "summary": "Initialize a multi-cloud terrarium for an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "aws",
+                            "azure",
+                            "gcp",
+                            "ncp"
+                        ],
+                        "type": "string",
+                        "default": "aws",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2243,65 +2807,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    },
-                    "503": {
-                        "description": "Service Unavailable",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
-                    }
-                }
-            }
-        },
-        "/tr/{trId}/testbed/actions/destroy": {
-            "delete": {
-                "description": "Destroy the testbed",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "[Testbed] OpenTofu Actions (for fine-grained control)"
-                ],
-                "summary": "Destroy the testbed",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "default": "testbed01",
-                        "description": "Terrarium ID",
-                        "name": "trId",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Custom request ID",
-                        "name": "x-request-id",
-                        "in": "header"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Credential holder (profile) name",
-                        "name": "x-credential-holder",
-                        "in": "header"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2319,11 +2832,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/testbed/actions/emptyout": {
+            },
             "delete": {
-                "description": "EmptyOut the testbed",
+                "description": "Deprecated: use DELETE /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2331,13 +2842,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "EmptyOut the testbed",
+                "summary": "Clear the entire directory and configuration files of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
@@ -2369,14 +2881,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2384,9 +2902,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/testbed/actions/init": {
+        "/tr/{trId}/message-broker/infracode": {
             "post": {
-                "description": "Init testbed",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/infracode, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2394,25 +2912,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Init testbed",
+                "summary": "Create the infracode for an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Parameters requied to create a testbed",
-                        "name": "ReqBody",
+                        "description": "Parameters of infracode for the enrichment",
+                        "name": "ParamsForInfracode",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateTestbedRequest"
+                            "$ref": "#/definitions/model.CreateInfracodeOfEnrichmentRequest"
                         }
                     },
                     {
@@ -2441,6 +2960,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2456,9 +2981,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/testbed/actions/output": {
-            "get": {
-                "description": "Output the testbed",
+        "/tr/{trId}/message-broker/plan": {
+            "post": {
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/plan, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2466,34 +2991,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Output the testbed",
+                "summary": "Check and show changes by the current infracode of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "refined",
-                            "raw"
-                        ],
                         "type": "string",
-                        "default": "refined",
-                        "description": "Resource info by detail (refined, raw)",
-                        "name": "detail",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": true,
-                        "description": "Refresh the state before getting the info",
-                        "name": "refresh",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
                         "in": "query"
                     },
                     {
@@ -2522,6 +3036,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2537,9 +3057,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/testbed/actions/plan": {
-            "post": {
-                "description": "Plan the testbed",
+        "/tr/{trId}/message-broker/request/{requestId}": {
+            "get": {
+                "description": "Deprecated: use GET /tr/{trId}/enrichments/{kind}/request/{requestId}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2547,18 +3067,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Testbed] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Plan the testbed",
+                "summary": "Check the status of a specific request by its ID (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "testbed01",
+                        "default": "tr01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "requestId",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2585,14 +3113,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2600,9 +3128,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/aws-to-site": {
+        "/tr/{trId}/object-storage": {
             "get": {
-                "description": "Get AWS to site VPN",
+                "description": "Deprecated: use GET /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2610,9 +3138,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] Resource Operations"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Get AWS to site VPN",
+                "summary": "Get resource info of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -2640,6 +3169,12 @@ const docTemplate = `{
                         "name": "refresh",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2666,6 +3201,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2681,7 +3222,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create AWS to site VPN",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2689,9 +3230,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] Resource Operations"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Create AWS to site VPN",
+                "summary": "Create the resources of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -2702,13 +3244,16 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Parameters requied to create the AWS to site VPN",
-                        "name": "ReqBody",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateAwsToSiteVpnRequest"
-                        }
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -2724,8 +3269,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2736,6 +3281,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2751,7 +3308,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete AWS to site VPN",
+                "description": "Deprecated: use DELETE /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2759,9 +3316,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] Resource Operations"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Delete AWS to site VPN",
+                "summary": "Destroy the resources of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -2771,6 +3329,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2797,6 +3361,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2812,9 +3388,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/aws-to-site/actions/apply": {
+        "/tr/{trId}/object-storage/env": {
             "post": {
-                "description": "Apply AWS to site VPN",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2822,9 +3398,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Apply AWS to site VPN",
+                "summary": "Initialize a multi-cloud terrarium for an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -2834,6 +3411,20 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "aws",
+                            "azure",
+                            "gcp",
+                            "ncp"
+                        ],
+                        "type": "string",
+                        "default": "aws",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2860,6 +3451,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2873,11 +3476,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tr/{trId}/vpn/aws-to-site/actions/destroy": {
+            },
             "delete": {
-                "description": "Destroy AWS to site VPN",
+                "description": "Deprecated: use DELETE /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2885,9 +3486,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Destroy AWS to site VPN",
+                "summary": "Clear the entire directory and configuration files of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -2923,14 +3525,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2938,9 +3546,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/aws-to-site/actions/emptyout": {
-            "delete": {
-                "description": "EmptyOut AWS to site VPN",
+        "/tr/{trId}/object-storage/infracode": {
+            "post": {
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/infracode, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2948,9 +3556,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "EmptyOut AWS to site VPN",
+                "summary": "Create the infracode for an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -2960,6 +3569,15 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "description": "Parameters of infracode for the enrichment",
+                        "name": "ParamsForInfracode",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateInfracodeOfEnrichmentRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -2974,8 +3592,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -2986,6 +3604,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -3001,9 +3625,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/aws-to-site/actions/init": {
+        "/tr/{trId}/object-storage/plan": {
             "post": {
-                "description": "Init AWS to site VPN",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/plan, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3011,9 +3635,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Init AWS to site VPN",
+                "summary": "Check and show changes by the current infracode of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -3024,13 +3649,10 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Parameters requied to create the AWS to site VPN",
-                        "name": "ReqBody",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateAwsToSiteVpnRequest"
-                        }
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
                     },
                     {
                         "type": "string",
@@ -3046,8 +3668,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3058,6 +3680,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -3073,9 +3701,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/aws-to-site/actions/output": {
+        "/tr/{trId}/object-storage/request/{requestId}": {
             "get": {
-                "description": "Output AWS to site VPN",
+                "description": "Deprecated: use GET /tr/{trId}/enrichments/{kind}/request/{requestId}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3083,9 +3711,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Output AWS to site VPN",
+                "summary": "Check the status of a specific request by its ID (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -3096,22 +3725,11 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "enum": [
-                            "refined",
-                            "raw"
-                        ],
                         "type": "string",
-                        "default": "refined",
-                        "description": "Resource info by detail (refined, raw)",
-                        "name": "detail",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": true,
-                        "description": "Refresh the state before getting the info",
-                        "name": "refresh",
-                        "in": "query"
+                        "description": "Request ID",
+                        "name": "requestId",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -3139,14 +3757,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3154,9 +3772,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/aws-to-site/actions/plan": {
-            "post": {
-                "description": "Plan AWS to site VPN",
+        "/tr/{trId}/outputs": {
+            "get": {
+                "description": "Get the output values of the enrichments stored after they are applied or refreshed (sensitive values are not stored).\nAn enrichment can reference the outputs of another (e.g., a VPN using the VPC IDs of a testbed).\nThe outputs of all the enrichments are returned by enrichment if enrichment is omitted.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3164,9 +3782,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                    "[Terrarium] An environment to enrich the multi-cloud infrastructure"
                 ],
-                "summary": "Plan AWS to site VPN",
+                "summary": "Get the outputs of the enrichments in a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -3176,6 +3794,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Enrichment name (e.g., testbed, vpn/site-to-site, sql-db)",
+                        "name": "enrichment",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3193,7 +3817,8 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "400": {
@@ -3202,14 +3827,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3217,9 +3842,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-aws": {
+        "/tr/{trId}/plans": {
             "get": {
-                "description": "Get resource info to configure GCP to AWS VPN tunnels",
+                "description": "List the saved plans of a terrarium in order of their creation time.\nEach plan has the summary of the resource changes parsed from ` + "`" + `tofu show -json` + "`" + `.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3227,9 +3852,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Plan management"
                 ],
-                "summary": "Get resource info to configure GCP to AWS VPN tunnels",
+                "summary": "List the saved plans of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -3239,20 +3864,6 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "string",
-                        "default": "refined",
-                        "description": "Resource info by detail (refined, raw)",
-                        "name": "detail",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "default": true,
-                        "description": "Refresh the state before getting the info",
-                        "name": "refresh",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3270,7 +3881,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Plan"
+                            }
                         }
                     },
                     "400": {
@@ -3284,17 +3898,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
-                    },
-                    "503": {
-                        "description": "Service Unavailable",
-                        "schema": {
-                            "$ref": "#/definitions/model.Response"
-                        }
                     }
                 }
-            },
-            "post": {
-                "description": "Create network resources for VPN tunnel in GCP and AWS",
+            }
+        },
+        "/tr/{trId}/plans/{planId}": {
+            "get": {
+                "description": "Get a saved plan of a terrarium with the summary of the resource changes",
                 "consumes": [
                     "application/json"
                 ],
@@ -3302,9 +3912,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Plan management"
                 ],
-                "summary": "Create network resources for VPN tunnel in GCP and AWS",
+                "summary": "Get a saved plan of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -3314,6 +3924,13 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "planId",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3328,10 +3945,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.Plan"
                         }
                     },
                     "400": {
@@ -3340,22 +3957,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Destroy network resources that were used to configure GCP as an AWS VPN tunnel",
+            }
+        },
+        "/tr/{trId}/plans/{planId}/approve": {
+            "post": {
+                "description": "Approve a saved plan pending approval, so that it can be applied by its plan ID (planId).\nThe approver is the authenticated principal, who must be different from the creator of the plan.\nApproving a plan is refused if the authentication is disabled.\nThe approval expires after the configured time (TERRARIUM_APPROVAL_EXPIRY_MIN) if the plan is not applied.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3363,9 +3982,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Plan management"
                 ],
-                "summary": "Destroy network resources that were used to configure GCP as an AWS VPN tunnel",
+                "summary": "Approve a saved plan of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -3375,6 +3994,21 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "planId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "ReqBody",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.PlanReviewRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3392,7 +4026,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.Plan"
                         }
                     },
                     "400": {
@@ -3401,14 +4035,26 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden (e.g., self-approval, or the authentication is disabled)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., not pending approval, reviewed by another request in the meantime)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3416,9 +4062,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-aws/env": {
+        "/tr/{trId}/plans/{planId}/reject": {
             "post": {
-                "description": "Initialize a multi-cloud terrarium for GCP to AWS VPN tunnel",
+                "description": "Reject a saved plan pending approval, so that it cannot be applied.\nThe rejecter is the authenticated principal, and rejecting a plan is refused if the authentication is disabled.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3426,9 +4072,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Plan management"
                 ],
-                "summary": "Initialize a multi-cloud terrarium for GCP to AWS VPN tunnel",
+                "summary": "Reject a saved plan of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -3438,6 +4084,21 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Plan ID",
+                        "name": "planId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Comment",
+                        "name": "ReqBody",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/model.PlanReviewRequest"
+                        }
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3452,10 +4113,10 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "$ref": "#/definitions/model.Plan"
                         }
                     },
                     "400": {
@@ -3464,22 +4125,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden (e.g., the authentication is disabled)",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., not pending approval, reviewed by another request in the meantime)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Clear the entire directory and configuration files",
+            }
+        },
+        "/tr/{trId}/requests": {
+            "get": {
+                "description": "List the requests (jobs) processed in a terrarium in order of their start time.\nEach request records the OpenTofu commands, the enrichment, the start/end time, the exit code, the status and the credential holder.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3487,9 +4162,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Request management"
                 ],
-                "summary": "Clear the entire directory and configuration files",
+                "summary": "List the requests of a terrarium",
                 "parameters": [
                     {
                         "type": "string",
@@ -3516,7 +4191,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Job"
+                            }
                         }
                     },
                     "400": {
@@ -3530,9 +4208,73 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/requests/{reqId}": {
+            "get": {
+                "description": "Get a request (job) processed in a terrarium",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Terrarium] Request management"
+                ],
+                "summary": "Get a request of a terrarium",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "reqId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3540,9 +4282,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-aws/infracode": {
+        "/tr/{trId}/requests/{reqId}/cancel": {
             "post": {
-                "description": "Create the infracode to configure GCP to AWS VPN tunnels",
+                "description": "Cancel the running OpenTofu command of a request.\nThe command receives SIGINT first to stop gracefully (e.g., release the state lock),\nand it is killed if it does not stop within the grace period.\nA request running on another host sharing the store is cancelled by the host only (409 Conflict).",
                 "consumes": [
                     "application/json"
                 ],
@@ -3550,9 +4292,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Request management"
                 ],
-                "summary": "Create the infracode to configure GCP to AWS VPN tunnels",
+                "summary": "Cancel a running request",
                 "parameters": [
                     {
                         "type": "string",
@@ -3563,13 +4305,11 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Parameters requied to create the infracode to configure GCP to AWS VPN tunnels",
-                        "name": "ParamsForInfracode",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/model.CreateInfracodeOfGcpAwsVpnRequest"
-                        }
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "reqId",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -3585,8 +4325,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3597,14 +4337,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict (e.g., processed by another host)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3612,9 +4358,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-aws/plan": {
-            "post": {
-                "description": "Check and show changes by the current infracode to configure GCP to AWS VPN tunnels",
+        "/tr/{trId}/requests/{reqId}/events": {
+            "get": {
+                "description": "Get the progress events of a request, which are decoded from the machine-readable UI of OpenTofu\n(i.e., plan, apply, destroy and refresh with -json).\nThe event types include version, log, diagnostic, planned_change, resource_drift, change_summary, outputs,\napply_start, apply_progress, apply_complete, apply_errored, refresh_start and refresh_complete.\nThe per-resource progress and the change summary are available in the progress of the request.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3622,9 +4368,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Request management"
                 ],
-                "summary": "Check and show changes by the current infracode to configure GCP to AWS VPN tunnels",
+                "summary": "Get the progress events of a request",
                 "parameters": [
                     {
                         "type": "string",
@@ -3634,6 +4380,19 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "reqId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event types to filter (comma-separated, e.g., apply_start,apply_complete)",
+                        "name": "type",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3651,7 +4410,10 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/uistream.Event"
+                            }
                         }
                     },
                     "400": {
@@ -3660,14 +4422,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict (e.g., processed by another host)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3675,19 +4443,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-aws/request/{requestId}": {
+        "/tr/{trId}/requests/{reqId}/logs": {
             "get": {
-                "description": "Check the status of a specific request by its ID",
+                "description": "Get the running logs (OpenTofu output) of a request.\nWith follow=true, the logs are streamed while the request is running, and the stream is closed when the request finishes.\nThe logs are streamed as Server-Sent Events if the Accept header includes \"text/event-stream\", otherwise as chunked plain text.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/plain",
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Terrarium] Request management"
                 ],
-                "summary": "Check the status of a specific request by its ID",
+                "summary": "Get the running logs of a request",
                 "parameters": [
                     {
                         "type": "string",
@@ -3700,10 +4469,17 @@ const docTemplate = `{
                     {
                         "type": "string",
                         "description": "Request ID",
-                        "name": "requestId",
+                        "name": "reqId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Stream the logs while the request is running",
+                        "name": "follow",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3721,7 +4497,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/model.Response"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -3730,14 +4506,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict (e.g., processed by another host)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3745,9 +4527,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-azure": {
+        "/tr/{trId}/sql-db": {
             "get": {
-                "description": "Get resource info to configure GCP to Azure VPN tunnels",
+                "description": "Deprecated: use GET /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3755,9 +4537,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Get resource info to configure GCP to Azure VPN tunnels",
+                "summary": "Get resource info of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -3768,6 +4551,10 @@ const docTemplate = `{
                         "required": true
                     },
                     {
+                        "enum": [
+                            "refined",
+                            "raw"
+                        ],
                         "type": "string",
                         "default": "refined",
                         "description": "Resource info by detail (refined, raw)",
@@ -3781,6 +4568,12 @@ const docTemplate = `{
                         "name": "refresh",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3807,6 +4600,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -3822,7 +4621,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create network resources for VPN tunnel in GCP and Azure",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3830,9 +4629,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Create network resources for VPN tunnel in GCP and Azure",
+                "summary": "Create the resources of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -3842,6 +4642,18 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3856,8 +4668,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -3868,6 +4680,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -3883,7 +4707,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Destroy network resources that were used to configure GCP as an Azure VPN tunnel",
+                "description": "Deprecated: use DELETE /tr/{trId}/enrichments/{kind}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3891,9 +4715,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Destroy network resources that were used to configure GCP as an Azure VPN tunnel",
+                "summary": "Destroy the resources of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -3903,6 +4728,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3929,6 +4760,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -3944,9 +4787,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-azure/env": {
+        "/tr/{trId}/sql-db/env": {
             "post": {
-                "description": "Initialize a multi-cloud terrarium for GCP to Azure VPN tunnel",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3954,9 +4797,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Initialize a multi-cloud terrarium for GCP to Azure VPN tunnel",
+                "summary": "Initialize a multi-cloud terrarium for an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -3966,6 +4810,20 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "enum": [
+                            "aws",
+                            "azure",
+                            "gcp",
+                            "ncp"
+                        ],
+                        "type": "string",
+                        "default": "aws",
+                        "description": "Provider",
+                        "name": "provider",
+                        "in": "query",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -3992,6 +4850,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4007,7 +4877,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Clear the entire directory and configuration files",
+                "description": "Deprecated: use DELETE /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4015,9 +4885,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Clear the entire directory and configuration files",
+                "summary": "Clear the entire directory and configuration files of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -4053,24 +4924,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
-                    }
-                }
-            }
-        },
-        "/tr/{trId}/vpn/gcp-azure/infracode": {
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/sql-db/infracode": {
             "post": {
-                "description": "Create the infracode to configure GCP to Azure VPN tunnels",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/infracode, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4078,9 +4955,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Create the infracode to configure GCP to Azure VPN tunnels",
+                "summary": "Create the infracode for an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -4091,12 +4969,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Parameters requied to create the infracode to configure GCP to Azure VPN tunnels",
+                        "description": "Parameters of infracode for the enrichment",
                         "name": "ParamsForInfracode",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateInfracodeOfGcpAzureVpnRequest"
+                            "$ref": "#/definitions/model.CreateInfracodeOfEnrichmentRequest"
                         }
                     },
                     {
@@ -4125,6 +5003,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4140,9 +5024,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-azure/plan": {
+        "/tr/{trId}/sql-db/plan": {
             "post": {
-                "description": "Check and show changes by the current infracode to configure GCP to Azure VPN tunnels",
+                "description": "Deprecated: use POST /tr/{trId}/enrichments/{kind}/plan, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4150,9 +5034,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Check and show changes by the current infracode to configure GCP to Azure VPN tunnels",
+                "summary": "Check and show changes by the current infracode of an enrichment (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -4162,6 +5047,12 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4188,6 +5079,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4203,9 +5100,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/gcp-azure/request/{requestId}": {
+        "/tr/{trId}/sql-db/request/{requestId}": {
             "get": {
-                "description": "Check the status of a specific request by its ID",
+                "description": "Deprecated: use GET /tr/{trId}/enrichments/{kind}/request/{requestId}, which this API is an alias of.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4213,9 +5110,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                    "[Enrichment] Legacy operations (deprecated)"
                 ],
-                "summary": "Check the status of a specific request by its ID",
+                "summary": "Check the status of a specific request by its ID (deprecated)",
+                "deprecated": true,
                 "parameters": [
                     {
                         "type": "string",
@@ -4258,14 +5156,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
@@ -4273,9 +5171,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site": {
+        "/tr/{trId}/testbed": {
             "get": {
-                "description": "Get Site-to-Site VPN information",
+                "description": "Get the testbed",
                 "consumes": [
                     "application/json"
                 ],
@@ -4283,13 +5181,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] Resource Operations (Under development - Paused)"
+                    "[Testbed] Resource Operations"
                 ],
-                "summary": "Get Site-to-Site VPN",
+                "summary": "Get the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
@@ -4313,6 +5211,12 @@ const docTemplate = `{
                         "name": "refresh",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4354,7 +5258,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create Site-to-Site VPN between two cloud sites",
+                "description": "Create the testbed\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4362,27 +5266,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] Resource Operations (Under development - Paused)"
+                    "[Testbed] Resource Operations"
                 ],
-                "summary": "Create Site-to-Site VPN",
+                "summary": "Create the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Parameters required to create the Site-to-Site VPN",
+                        "description": "Parameters requied to create a testbed",
                         "name": "ReqBody",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateSiteToSiteVpnRequest"
+                            "$ref": "#/definitions/model.CreateTestbedRequest"
                         }
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4403,12 +5313,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "202": {
+                        "description": "Accepted (the plan is pending approval, apply it by its plan ID after approval)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4424,7 +5346,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Delete Site-to-Site VPN",
+                "description": "Delete the testbed",
                 "consumes": [
                     "application/json"
                 ],
@@ -4432,18 +5354,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] Resource Operations (Under development - Paused)"
+                    "[Testbed] Resource Operations"
                 ],
-                "summary": "Delete Site-to-Site VPN",
+                "summary": "Delete the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4470,6 +5398,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4485,9 +5419,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site/actions/apply": {
+        "/tr/{trId}/testbed/actions/apply": {
             "post": {
-                "description": "Apply Site-to-Site VPN",
+                "description": "Apply the testbed",
                 "consumes": [
                     "application/json"
                 ],
@@ -4495,18 +5429,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                    "[Testbed] OpenTofu Actions (for fine-grained control)"
                 ],
-                "summary": "Apply Site-to-Site VPN",
+                "summary": "Apply the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4533,6 +5479,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., stale plan, not initialized)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4548,9 +5506,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site/actions/destroy": {
+        "/tr/{trId}/testbed/actions/destroy": {
             "delete": {
-                "description": "Destroy Site-to-Site VPN",
+                "description": "Destroy the testbed",
                 "consumes": [
                     "application/json"
                 ],
@@ -4558,18 +5516,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                    "[Testbed] OpenTofu Actions (for fine-grained control)"
                 ],
-                "summary": "Destroy Site-to-Site VPN",
+                "summary": "Destroy the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4596,6 +5560,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4611,9 +5581,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site/actions/emptyout": {
+        "/tr/{trId}/testbed/actions/emptyout": {
             "delete": {
-                "description": "EmptyOut Site-to-Site VPN",
+                "description": "EmptyOut the testbed",
                 "consumes": [
                     "application/json"
                 ],
@@ -4621,13 +5591,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                    "[Testbed] OpenTofu Actions (for fine-grained control)"
                 ],
-                "summary": "EmptyOut Site-to-Site VPN",
+                "summary": "EmptyOut the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
@@ -4659,6 +5629,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4674,9 +5650,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site/actions/init": {
+        "/tr/{trId}/testbed/actions/init": {
             "post": {
-                "description": "Init Site-to-Site VPN",
+                "description": "Init testbed\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4684,25 +5660,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                    "[Testbed] OpenTofu Actions (for fine-grained control)"
                 ],
-                "summary": "Init Site-to-Site VPN",
+                "summary": "Init testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Parameters required to create the Site-to-Site VPN",
+                        "description": "Parameters requied to create a testbed",
                         "name": "ReqBody",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.CreateSiteToSiteVpnRequest"
+                            "$ref": "#/definitions/model.CreateTestbedRequest"
                         }
                     },
                     {
@@ -4731,6 +5707,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4746,9 +5728,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site/actions/output": {
+        "/tr/{trId}/testbed/actions/output": {
             "get": {
-                "description": "Output Site-to-Site VPN",
+                "description": "Output the testbed",
                 "consumes": [
                     "application/json"
                 ],
@@ -4756,13 +5738,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                    "[Testbed] OpenTofu Actions (for fine-grained control)"
                 ],
-                "summary": "Output Site-to-Site VPN",
+                "summary": "Output the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
@@ -4786,6 +5768,12 @@ const docTemplate = `{
                         "name": "refresh",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4827,9 +5815,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/tr/{trId}/vpn/site-to-site/actions/plan": {
+        "/tr/{trId}/testbed/actions/plan": {
             "post": {
-                "description": "Plan Site-to-Site VPN",
+                "description": "Plan the testbed\nThe plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.\nUse the plan ID (planId) to apply exactly the saved plan.",
                 "consumes": [
                     "application/json"
                 ],
@@ -4837,18 +5825,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                    "[Testbed] OpenTofu Actions (for fine-grained control)"
                 ],
-                "summary": "Plan Site-to-Site VPN",
+                "summary": "Plan the testbed",
                 "parameters": [
                     {
                         "type": "string",
-                        "default": "tr01",
+                        "default": "testbed01",
                         "description": "Terrarium ID",
                         "name": "trId",
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Custom request ID",
@@ -4875,6 +5869,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.Response"
                         }
                     },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -4889,292 +5889,3713 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "model.AlibabaConfig": {
-            "type": "object",
-            "properties": {
-                "bgp_asn": {
-                    "type": "string",
-                    "default": "65532",
-                    "example": "65532"
-                },
-                "region": {
-                    "type": "string",
-                    "default": "ap-northeast-2",
-                    "example": "ap-northeast-2"
-                },
-                "vpc_id": {
-                    "type": "string",
-                    "example": "vpc-bp1abcdefg123456789"
-                },
-                "vswitch_id_1": {
-                    "type": "string",
-                    "example": "vsw-bp1abcdefg123456789"
-                },
-                "vswitch_id_2": {
-                    "type": "string",
-                    "example": "vsw-bp2abcdefg123456789"
-                }
-            }
         },
-        "model.AwsConfig": {
-            "type": "object",
-            "properties": {
-                "bgp_asn": {
-                    "type": "string",
-                    "default": "64512",
-                    "example": "64512"
-                },
-                "region": {
-                    "type": "string",
-                    "default": "ap-northeast-2",
-                    "example": "ap-northeast-2"
-                },
-                "subnet_id": {
-                    "type": "string",
-                    "example": "subnet-12345678"
-                },
-                "vpc_id": {
+        "/tr/{trId}/vpn/aws-to-site": {
+            "get": {
+                "description": "Get AWS to site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] Resource Operations"
+                ],
+                "summary": "Get AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refined",
+                            "raw"
+                        ],
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create AWS to site VPN\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] Resource Operations"
+                ],
+                "summary": "Create AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters requied to create the AWS to site VPN",
+                        "name": "ReqBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateAwsToSiteVpnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted (the plan is pending approval, apply it by its plan ID after approval)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete AWS to site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] Resource Operations"
+                ],
+                "summary": "Delete AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/aws-to-site/actions/apply": {
+            "post": {
+                "description": "Apply AWS to site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                ],
+                "summary": "Apply AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., stale plan, not initialized)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/aws-to-site/actions/destroy": {
+            "delete": {
+                "description": "Destroy AWS to site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                ],
+                "summary": "Destroy AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/aws-to-site/actions/emptyout": {
+            "delete": {
+                "description": "EmptyOut AWS to site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                ],
+                "summary": "EmptyOut AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/aws-to-site/actions/init": {
+            "post": {
+                "description": "Init AWS to site VPN\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                ],
+                "summary": "Init AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters requied to create the AWS to site VPN",
+                        "name": "ReqBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateAwsToSiteVpnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/aws-to-site/actions/output": {
+            "get": {
+                "description": "Output AWS to site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                ],
+                "summary": "Output AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refined",
+                            "raw"
+                        ],
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/aws-to-site/actions/plan": {
+            "post": {
+                "description": "Plan AWS to site VPN\nThe plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.\nUse the plan ID (planId) to apply exactly the saved plan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[AWS to site VPN] OpenTofu Actions (for fine-grained control)"
+                ],
+                "summary": "Plan AWS to site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-aws": {
+            "get": {
+                "description": "Get resource info to configure GCP to AWS VPN tunnels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Get resource info to configure GCP to AWS VPN tunnels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create network resources for VPN tunnel in GCP and AWS",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Create network resources for VPN tunnel in GCP and AWS",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., stale plan, not initialized)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy network resources that were used to configure GCP as an AWS VPN tunnel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Destroy network resources that were used to configure GCP as an AWS VPN tunnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-aws/env": {
+            "post": {
+                "description": "Initialize a multi-cloud terrarium for GCP to AWS VPN tunnel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Initialize a multi-cloud terrarium for GCP to AWS VPN tunnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Clear the entire directory and configuration files",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Clear the entire directory and configuration files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., resources not destroyed)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-aws/infracode": {
+            "post": {
+                "description": "Create the infracode to configure GCP to AWS VPN tunnels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Create the infracode to configure GCP to AWS VPN tunnels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters requied to create the infracode to configure GCP to AWS VPN tunnels",
+                        "name": "ParamsForInfracode",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateInfracodeOfGcpAwsVpnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-aws/plan": {
+            "post": {
+                "description": "Check and show changes by the current infracode to configure GCP to AWS VPN tunnels\nThe plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.\nUse the plan ID (planId) to apply exactly the saved plan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Check and show changes by the current infracode to configure GCP to AWS VPN tunnels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-aws/request/{requestId}": {
+            "get": {
+                "description": "Check the status of a specific request by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Check the status of a specific request by its ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "requestId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-azure": {
+            "get": {
+                "description": "Get resource info to configure GCP to Azure VPN tunnels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Get resource info to configure GCP to Azure VPN tunnels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create network resources for VPN tunnel in GCP and Azure",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Create network resources for VPN tunnel in GCP and Azure",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy network resources that were used to configure GCP as an Azure VPN tunnel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Destroy network resources that were used to configure GCP as an Azure VPN tunnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-azure/env": {
+            "post": {
+                "description": "Initialize a multi-cloud terrarium for GCP to Azure VPN tunnel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Initialize a multi-cloud terrarium for GCP to Azure VPN tunnel",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Clear the entire directory and configuration files",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Clear the entire directory and configuration files",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., resources not destroyed)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-azure/infracode": {
+            "post": {
+                "description": "Create the infracode to configure GCP to Azure VPN tunnels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Create the infracode to configure GCP to Azure VPN tunnels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters requied to create the infracode to configure GCP to Azure VPN tunnels",
+                        "name": "ParamsForInfracode",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateInfracodeOfGcpAzureVpnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-azure/plan": {
+            "post": {
+                "description": "Check and show changes by the current infracode to configure GCP to Azure VPN tunnels",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Check and show changes by the current infracode to configure GCP to Azure VPN tunnels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/gcp-azure/request/{requestId}": {
+            "get": {
+                "description": "Check the status of a specific request by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
+                ],
+                "summary": "Check the status of a specific request by its ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "requestId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site": {
+            "get": {
+                "description": "Get Site-to-Site VPN information",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] Resource Operations (Under development - Paused)"
+                ],
+                "summary": "Get Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refined",
+                            "raw"
+                        ],
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create Site-to-Site VPN between two cloud sites\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] Resource Operations (Under development - Paused)"
+                ],
+                "summary": "Create Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters required to create the Site-to-Site VPN",
+                        "name": "ReqBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateSiteToSiteVpnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted (the plan is pending approval, apply it by its plan ID after approval)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete Site-to-Site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] Resource Operations (Under development - Paused)"
+                ],
+                "summary": "Delete Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site/actions/apply": {
+            "post": {
+                "description": "Apply Site-to-Site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                ],
+                "summary": "Apply Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)",
+                        "name": "planId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., stale plan, not initialized)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site/actions/destroy": {
+            "delete": {
+                "description": "Destroy Site-to-Site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                ],
+                "summary": "Destroy Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site/actions/emptyout": {
+            "delete": {
+                "description": "EmptyOut Site-to-Site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                ],
+                "summary": "EmptyOut Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site/actions/init": {
+            "post": {
+                "description": "Init Site-to-Site VPN\nA field can reference an output of another enrichment in the terrarium by {\"$ref\": \"{enrichment}.{output}[.{path}]\"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                ],
+                "summary": "Init Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameters required to create the Site-to-Site VPN",
+                        "name": "ReqBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.CreateSiteToSiteVpnRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site/actions/output": {
+            "get": {
+                "description": "Output Site-to-Site VPN",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                ],
+                "summary": "Output Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refined",
+                            "raw"
+                        ],
+                        "type": "string",
+                        "default": "refined",
+                        "description": "Resource info by detail (refined, raw)",
+                        "name": "detail",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Refresh the state before getting the info",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tr/{trId}/vpn/site-to-site/actions/plan": {
+            "post": {
+                "description": "Plan Site-to-Site VPN\nThe plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.\nUse the plan ID (planId) to apply exactly the saved plan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)"
+                ],
+                "summary": "Plan Site-to-Site VPN",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "tr01",
+                        "description": "Terrarium ID",
+                        "name": "trId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min",
+                        "name": "timeout",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict (e.g., a request in progress, invalid phase)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/whoami": {
+            "get": {
+                "description": "Get the authenticated principal of the request (e.g., to check the claims mapped from a JWT),\nwith the credential holder resolved for the request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "[Auth] Identity and API keys"
+                ],
+                "summary": "Get the authenticated principal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Custom request ID",
+                        "name": "x-request-id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Credential holder (profile) name",
+                        "name": "x-credential-holder",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Principal"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (e.g., the authentication is disabled)",
+                        "schema": {
+                            "$ref": "#/definitions/model.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "model.AlibabaConfig": {
+            "type": "object",
+            "properties": {
+                "bgp_asn": {
+                    "type": "string",
+                    "default": "65532",
+                    "example": "65532"
+                },
+                "region": {
+                    "type": "string",
+                    "default": "ap-northeast-2",
+                    "example": "ap-northeast-2"
+                },
+                "vpc_id": {
+                    "type": "string",
+                    "example": "vpc-bp1abcdefg123456789"
+                },
+                "vswitch_id_1": {
+                    "type": "string",
+                    "example": "vsw-bp1abcdefg123456789"
+                },
+                "vswitch_id_2": {
+                    "type": "string",
+                    "example": "vsw-bp2abcdefg123456789"
+                }
+            }
+        },
+        "model.ApiKey": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "description": "Admin is true if the principal may act as another credential holder",
+                    "type": "boolean",
+                    "example": false
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "credentialHolder": {
+                    "description": "CredentialHolder is the credential holder (profile) bound to the principal",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CI pipeline of team A"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f9c2a7d51e0b84c"
+                },
+                "name": {
+                    "description": "Name is the name of the principal authenticated by the key (e.g., a team member)",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "revokedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AttributeDrift": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "type": "string",
+                    "example": "bob"
+                },
+                "before": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "tags.Owner"
+                }
+            }
+        },
+        "model.AwsConfig": {
+            "type": "object",
+            "properties": {
+                "bgp_asn": {
+                    "type": "string",
+                    "default": "64512",
+                    "example": "64512"
+                },
+                "region": {
+                    "type": "string",
+                    "default": "ap-northeast-2",
+                    "example": "ap-northeast-2"
+                },
+                "subnet_id": {
+                    "type": "string",
+                    "example": "subnet-12345678"
+                },
+                "vpc_id": {
+                    "type": "string",
+                    "example": "vpc-12345678"
+                }
+            }
+        },
+        "model.AwsToSiteVpnConfig": {
+            "type": "object",
+            "properties": {
+                "aws": {
+                    "$ref": "#/definitions/model.AwsConfig"
+                },
+                "target_csp": {
+                    "$ref": "#/definitions/model.TargetCspConfig"
+                },
+                "terrarium_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.AzureBgpPeeringCidrs": {
+            "type": "object",
+            "properties": {
+                "to_alibaba": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "169.254.21.16/30",
+                        "169.254.21.20/30",
+                        "169.254.22.16/30",
+                        "169.254.22.20/30"
+                    ]
+                },
+                "to_aws": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "169.254.21.0/30",
+                        "169.254.21.4/30",
+                        "169.254.22.0/30",
+                        "169.254.22.4/30"
+                    ]
+                },
+                "to_gcp": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "169.254.21.8/30",
+                        "169.254.21.12/30",
+                        "169.254.22.8/30",
+                        "169.254.22.12/30"
+                    ]
+                },
+                "to_ibm": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "169.254.21.32/30",
+                        "169.254.21.36/30",
+                        "169.254.22.32/30",
+                        "169.254.22.36/30"
+                    ]
+                },
+                "to_tencent": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "169.254.21.24/30",
+                        "169.254.21.28/30",
+                        "169.254.22.24/30",
+                        "169.254.22.28/30"
+                    ]
+                }
+            }
+        },
+        "model.AzureConfig": {
+            "type": "object",
+            "properties": {
+                "bgp_asn": {
+                    "type": "string",
+                    "default": "65531",
+                    "example": "65531"
+                },
+                "bgp_peering_cidrs": {
+                    "$ref": "#/definitions/model.AzureBgpPeeringCidrs"
+                },
+                "gateway_subnet_cidr": {
+                    "type": "string",
+                    "example": "10.0.1.0/27"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "koreacentral"
+                },
+                "resource_group_name": {
+                    "type": "string",
+                    "example": "my-resource-group"
+                },
+                "virtual_network_name": {
+                    "type": "string",
+                    "example": "my-virtual-network"
+                },
+                "vpn_sku": {
+                    "type": "string",
+                    "default": "VpnGw1AZ",
+                    "example": "VpnGw1AZ"
+                }
+            }
+        },
+        "model.ChangeSummary": {
+            "type": "object",
+            "properties": {
+                "add": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "change": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "destroy": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "import": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "apply"
+                }
+            }
+        },
+        "model.CloneTerrariumRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "This terrarium enriches ..."
+                },
+                "name": {
+                    "description": "Name is the name (ID) of the new terrarium",
+                    "type": "string",
+                    "default": "tr02",
+                    "example": "tr02"
+                },
+                "tfVars": {
+                    "description": "TfVars override the tfvars of each enrichment (key: enrichment) as a JSON merge patch (i.e., null removes a tfvar)",
+                    "type": "object"
+                }
+            }
+        },
+        "model.CreateApiKeyRequest": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "credentialHolder": {
+                    "description": "CredentialHolder is the credential holder bound to the principal (default: the credential holder bound to the caller)",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CI pipeline of team A"
+                },
+                "name": {
+                    "description": "Name is the name of the principal (default: the caller)",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "ttlHours": {
+                    "description": "TtlHours is the hours until the key expires (0: never)",
+                    "type": "integer",
+                    "example": 720
+                }
+            }
+        },
+        "model.CreateApiKeyResponse": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "description": "Admin is true if the principal may act as another credential holder",
+                    "type": "boolean",
+                    "example": false
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "credentialHolder": {
+                    "description": "CredentialHolder is the credential holder (profile) bound to the principal",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "CI pipeline of team A"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f9c2a7d51e0b84c"
+                },
+                "key": {
+                    "description": "Key is the bearer token (i.e., Authorization: Bearer \u003ckey\u003e, or x-api-key: \u003ckey\u003e)",
+                    "type": "string",
+                    "example": "trk_3f9c2a7d51e0b84c_5b0e..."
+                },
+                "name": {
+                    "description": "Name is the name of the principal authenticated by the key (e.g., a team member)",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "revokedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CreateAwsToSiteVpnRequest": {
+            "type": "object",
+            "properties": {
+                "vpn_config": {
+                    "$ref": "#/definitions/model.AwsToSiteVpnConfig"
+                }
+            }
+        },
+        "model.CreateCredentialProfileRequest": {
+            "type": "object",
+            "required": [
+                "credentials",
+                "name"
+            ],
+            "properties": {
+                "credentials": {
+                    "description": "Credentials are the credentials by provider (e.g., {\"aws\": {\"AWS_ACCESS_KEY_ID\": \"...\", \"AWS_SECRET_ACCESS_KEY\": \"...\"}})",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "alice"
+                }
+            }
+        },
+        "model.CreateInfracodeOfEnrichmentRequest": {
+            "type": "object",
+            "properties": {
+                "tfVars": {
+                    "type": "object"
+                }
+            }
+        },
+        "model.CreateInfracodeOfGcpAwsVpnRequest": {
+            "type": "object",
+            "properties": {
+                "tfVars": {
+                    "$ref": "#/definitions/model.TfVarsGcpAwsVpnTunnel"
+                }
+            }
+        },
+        "model.CreateInfracodeOfGcpAzureVpnRequest": {
+            "type": "object",
+            "properties": {
+                "tfVars": {
+                    "$ref": "#/definitions/model.TfVarsGcpAzureVpnTunnel"
+                }
+            }
+        },
+        "model.CreateSiteToSiteVpnRequest": {
+            "type": "object",
+            "properties": {
+                "vpn_config": {
+                    "$ref": "#/definitions/model.SiteToSiteVpnConfig"
+                }
+            }
+        },
+        "model.CreateTestbedRequest": {
+            "type": "object",
+            "properties": {
+                "testbed_config": {
+                    "$ref": "#/definitions/model.TestbedConfigDetail"
+                }
+            }
+        },
+        "model.CredentialProfile": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "path": {
+                    "description": "Path is the path of the credentials in the secrets store",
+                    "type": "string",
+                    "example": "secret/users/alice/csp/"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "aws",
+                        "azure"
+                    ]
+                }
+            }
+        },
+        "model.CredentialVerification": {
+            "type": "object",
+            "properties": {
+                "diagnostic": {
+                    "description": "Diagnostic is the diagnostic of tofu (or the reason) if failed",
+                    "type": "string",
+                    "example": "Error: Retrieving AWS account details: validating provider credentials: ... InvalidClientTokenId"
+                },
+                "elapsedSec": {
+                    "description": "ElapsedSec is the seconds taken to verify",
+                    "type": "number",
+                    "example": 12.3
+                },
+                "passed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "profile": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "aws"
+                },
+                "stage": {
+                    "description": "Stage is the stage failed (i.e., credential, init, plan)",
+                    "type": "string",
+                    "example": "plan"
+                },
+                "verifiedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.DcsConfig": {
+            "type": "object",
+            "properties": {
+                "bgp_asn": {
+                    "type": "string",
+                    "default": "65000",
+                    "example": "65000"
+                },
+                "region": {
+                    "type": "string",
+                    "example": "RegionOne"
+                },
+                "subnet_id": {
+                    "type": "string",
+                    "example": "subnet-12345678"
+                }
+            }
+        },
+        "model.DriftReport": {
+            "type": "object",
+            "properties": {
+                "checkedAt": {
+                    "type": "string"
+                },
+                "detectedAt": {
+                    "description": "DetectedAt is the time when the current drift was first detected",
+                    "type": "string"
+                },
+                "drifted": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "enrichment": {
+                    "type": "string",
+                    "example": "vpn/aws-to-site"
+                },
+                "reqId": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "resources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ResourceDrift"
+                    }
+                },
+                "trId": {
+                    "type": "string",
+                    "example": "tr01"
+                }
+            }
+        },
+        "model.EnrichmentInfo": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "testbed"
+                },
+                "phase": {
+                    "description": "Phase is the phase of the enrichment\n(i.e., Empty, Initialized, Planned, Provisioning, Ready, Destroying, Destroyed, Failed)",
+                    "type": "string",
+                    "example": "Ready"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "aws",
+                        "gcp"
+                    ]
+                },
+                "status": {
+                    "description": "Status is the execution status of the last command in the enrichment (e.g., Running, Success, Failed)",
+                    "type": "string",
+                    "example": "Success"
+                }
+            }
+        },
+        "model.Enrichments": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/model.EnrichmentInfo"
+            }
+        },
+        "model.ExtendLeaseRequest": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "ExpiresAt is the new expiry of the lease",
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "TTL is the duration to extend the lease by from the current expiry (or now if expired or not set)",
+                    "type": "string",
+                    "example": "24h"
+                }
+            }
+        },
+        "model.GcpConfig": {
+            "type": "object",
+            "properties": {
+                "bgp_asn": {
+                    "type": "string",
+                    "default": "65530",
+                    "example": "65530"
+                },
+                "region": {
+                    "type": "string",
+                    "default": "asia-northeast3",
+                    "example": "asia-northeast3"
+                },
+                "vpc_network_name": {
+                    "type": "string",
+                    "example": "my-vpc-network"
+                }
+            }
+        },
+        "model.IbmConfig": {
+            "type": "object",
+            "properties": {
+                "region": {
+                    "type": "string",
+                    "default": "au-syd",
+                    "example": "au-syd"
+                },
+                "subnet_id": {
                     "type": "string",
-                    "example": "vpc-12345678"
+                    "example": "0717-abc12345-6789-abcd-ef01-234567890abc"
+                },
+                "vpc_cidr": {
+                    "type": "string",
+                    "example": "10.0.0.0/16"
+                },
+                "vpc_id": {
+                    "type": "string",
+                    "example": "r006-abc12345-6789-abcd-ef01-234567890abc"
                 }
             }
         },
-        "model.AwsToSiteVpnConfig": {
+        "model.Job": {
             "type": "object",
             "properties": {
-                "aws": {
-                    "$ref": "#/definitions/model.AwsConfig"
+                "approvedBy": {
+                    "type": "string",
+                    "example": "alice"
                 },
-                "target_csp": {
-                    "$ref": "#/definitions/model.TargetCspConfig"
+                "command": {
+                    "type": "string",
+                    "example": "tofu apply -auto-approve"
                 },
-                "terrarium_id": {
+                "credentialHolder": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "endedAt": {
+                    "type": "string"
+                },
+                "enrichment": {
+                    "type": "string",
+                    "example": "vpn/aws-to-site"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "exitCode": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "host": {
+                    "description": "Host and Pid identify the process of the running command, which is checked by the recovery at startup",
+                    "type": "string",
+                    "example": "mc-terrarium-0"
+                },
+                "pid": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "planId": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "progress": {
+                    "$ref": "#/definitions/model.JobProgress"
+                },
+                "reqId": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "startedAt": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Success"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.JobStep"
+                    }
+                },
+                "system": {
+                    "description": "System marks a request of the background loops (e.g., drift detection, reaping), which are pruned to the latest ones",
+                    "type": "boolean",
+                    "example": false
+                },
+                "trId": {
+                    "type": "string",
+                    "example": "tr01"
                 }
             }
         },
-        "model.AzureBgpPeeringCidrs": {
+        "model.JobDiagnostic": {
             "type": "object",
             "properties": {
-                "to_alibaba": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "169.254.21.16/30",
-                        "169.254.21.20/30",
-                        "169.254.22.16/30",
-                        "169.254.22.20/30"
-                    ]
+                "address": {
+                    "type": "string"
                 },
-                "to_aws": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "169.254.21.0/30",
-                        "169.254.21.4/30",
-                        "169.254.22.0/30",
-                        "169.254.22.4/30"
-                    ]
+                "detail": {
+                    "type": "string"
                 },
-                "to_gcp": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "169.254.21.8/30",
-                        "169.254.21.12/30",
-                        "169.254.22.8/30",
-                        "169.254.22.12/30"
-                    ]
+                "severity": {
+                    "type": "string",
+                    "example": "error"
                 },
-                "to_ibm": {
+                "summary": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.JobProgress": {
+            "type": "object",
+            "properties": {
+                "diagnostics": {
+                    "description": "Diagnostics are the warnings and errors reported by OpenTofu.",
                     "type": "array",
                     "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "169.254.21.32/30",
-                        "169.254.21.36/30",
-                        "169.254.22.32/30",
-                        "169.254.22.36/30"
-                    ]
+                        "$ref": "#/definitions/model.JobDiagnostic"
+                    }
                 },
-                "to_tencent": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "169.254.21.24/30",
-                        "169.254.21.28/30",
-                        "169.254.22.24/30",
-                        "169.254.22.28/30"
+                "resources": {
+                    "description": "Resources is the progress of each resource (key: resource address).",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/model.ResourceProgress"
+                    }
+                },
+                "summary": {
+                    "description": "Summary is the number of changes of the last plan or apply.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ChangeSummary"
+                        }
                     ]
                 }
             }
         },
-        "model.AzureConfig": {
+        "model.JobStep": {
             "type": "object",
             "properties": {
-                "bgp_asn": {
+                "command": {
                     "type": "string",
-                    "default": "65531",
-                    "example": "65531"
-                },
-                "bgp_peering_cidrs": {
-                    "$ref": "#/definitions/model.AzureBgpPeeringCidrs"
+                    "example": "tofu apply -auto-approve"
                 },
-                "gateway_subnet_cidr": {
-                    "type": "string",
-                    "example": "10.0.1.0/27"
+                "endedAt": {
+                    "type": "string"
                 },
-                "region": {
-                    "type": "string",
-                    "example": "koreacentral"
+                "error": {
+                    "type": "string"
                 },
-                "resource_group_name": {
-                    "type": "string",
-                    "example": "my-resource-group"
+                "exitCode": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "virtual_network_name": {
-                    "type": "string",
-                    "example": "my-virtual-network"
+                "startedAt": {
+                    "type": "string"
                 },
-                "vpn_sku": {
+                "status": {
                     "type": "string",
-                    "default": "VpnGw1AZ",
-                    "example": "VpnGw1AZ"
-                }
-            }
-        },
-        "model.CreateAwsToSiteVpnRequest": {
-            "type": "object",
-            "properties": {
-                "vpn_config": {
-                    "$ref": "#/definitions/model.AwsToSiteVpnConfig"
+                    "example": "Success"
                 }
             }
         },
-        "model.CreateInfracodeOfGcpAwsVpnRequest": {
+        "model.Plan": {
             "type": "object",
             "properties": {
-                "tfVars": {
-                    "$ref": "#/definitions/model.TfVarsGcpAwsVpnTunnel"
+                "appliedAt": {
+                    "type": "string"
+                },
+                "appliedReqId": {
+                    "type": "string",
+                    "example": "1718000000000000001"
+                },
+                "approvedBy": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "comment": {
+                    "type": "string",
+                    "example": "Reviewed the VPN gateway changes"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "string",
+                    "example": "bob"
+                },
+                "enrichment": {
+                    "type": "string",
+                    "example": "vpn/aws-to-site"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "rejectedBy": {
+                    "type": "string",
+                    "example": "bob"
+                },
+                "reqId": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "reviewedAt": {
+                    "type": "string"
+                },
+                "stateLineage": {
+                    "type": "string",
+                    "example": "a1b2c3d4-e5f6-7890-abcd-ef1234567890"
+                },
+                "stateSerial": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "type": "string",
+                    "example": "Planned"
+                },
+                "summary": {
+                    "$ref": "#/definitions/model.PlanSummary"
+                },
+                "trId": {
+                    "type": "string",
+                    "example": "tr01"
                 }
             }
         },
-        "model.CreateInfracodeOfGcpAzureVpnRequest": {
+        "model.PlanReviewRequest": {
             "type": "object",
             "properties": {
-                "tfVars": {
-                    "$ref": "#/definitions/model.TfVarsGcpAzureVpnTunnel"
+                "comment": {
+                    "type": "string",
+                    "example": "Reviewed the VPN gateway changes"
                 }
             }
         },
-        "model.CreateInfracodeOfMessageBrokerRequest": {
+        "model.PlanSummary": {
             "type": "object",
             "properties": {
-                "tfVars": {
-                    "$ref": "#/definitions/model.TfVarsMessageBroker"
+                "add": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "change": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "destroy": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "replace": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "resourceChanges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PlannedResourceChange"
+                    }
                 }
             }
         },
-        "model.CreateInfracodeOfObjectStorageRequest": {
+        "model.PlannedResourceChange": {
             "type": "object",
             "properties": {
-                "tfVars": {
-                    "$ref": "#/definitions/model.TfVarsObjectStorage"
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "create"
+                    ]
+                },
+                "address": {
+                    "type": "string",
+                    "example": "aws_vpc.main"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "main"
+                },
+                "providerName": {
+                    "type": "string",
+                    "example": "registry.opentofu.org/hashicorp/aws"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "aws_vpc"
                 }
             }
         },
-        "model.CreateInfracodeOfSqlDbRequest": {
+        "model.Principal": {
             "type": "object",
             "properties": {
-                "tfVars": {
-                    "$ref": "#/definitions/model.TfVarsSqlDb"
+                "admin": {
+                    "description": "Admin is true if the caller may act as another credential holder",
+                    "type": "boolean",
+                    "example": true
+                },
+                "credentialHolder": {
+                    "description": "CredentialHolder is the credential holder (profile) bound to the caller",
+                    "type": "string",
+                    "example": "admin"
+                },
+                "method": {
+                    "description": "Method is the authentication method (e.g., basic)",
+                    "type": "string",
+                    "example": "basic"
+                },
+                "name": {
+                    "description": "Name is the name of the caller (e.g., the username of BasicAuth)",
+                    "type": "string",
+                    "example": "default"
                 }
             }
         },
-        "model.CreateSiteToSiteVpnRequest": {
+        "model.RecoveredRequest": {
             "type": "object",
             "properties": {
-                "vpn_config": {
-                    "$ref": "#/definitions/model.SiteToSiteVpnConfig"
+                "action": {
+                    "description": "Action is Interrupted (the process no longer exists) or Skipped (the process still exists)",
+                    "type": "string",
+                    "example": "Interrupted"
+                },
+                "command": {
+                    "type": "string",
+                    "example": "tofu apply -auto-approve"
+                },
+                "enrichment": {
+                    "type": "string",
+                    "example": "vpn/aws-to-site"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "pid": {
+                    "type": "integer",
+                    "example": 12345
+                },
+                "reqId": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "stateLock": {
+                    "$ref": "#/definitions/model.StateLock"
+                },
+                "trId": {
+                    "type": "string",
+                    "example": "tr01"
                 }
             }
         },
-        "model.CreateTestbedRequest": {
+        "model.RecoveredTerrarium": {
             "type": "object",
             "properties": {
-                "testbed_config": {
-                    "$ref": "#/definitions/model.TestbedConfigDetail"
+                "action": {
+                    "description": "Action is Interrupted (the execution lock is released) or Skipped (the request is still running)",
+                    "type": "string",
+                    "example": "Interrupted"
+                },
+                "enrichment": {
+                    "description": "Enrichment whose execution status was Running (empty for the terrarium itself)",
+                    "type": "string",
+                    "example": "vpn/aws-to-site"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "reqId": {
+                    "type": "string",
+                    "example": "1718000000000000000"
+                },
+                "trId": {
+                    "type": "string",
+                    "example": "tr01"
                 }
             }
         },
-        "model.DcsConfig": {
+        "model.RecoveryReport": {
             "type": "object",
             "properties": {
-                "bgp_asn": {
-                    "type": "string",
-                    "default": "65000",
-                    "example": "65000"
+                "finishedAt": {
+                    "type": "string"
                 },
-                "region": {
-                    "type": "string",
-                    "example": "RegionOne"
+                "forceUnlock": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "subnet_id": {
+                "host": {
                     "type": "string",
-                    "example": "subnet-12345678"
+                    "example": "mc-terrarium-0"
+                },
+                "requests": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RecoveredRequest"
+                    }
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "terrariums": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RecoveredTerrarium"
+                    }
                 }
             }
         },
-        "model.GcpConfig": {
+        "model.ResourceDrift": {
             "type": "object",
             "properties": {
-                "bgp_asn": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "update"
+                    ]
+                },
+                "address": {
                     "type": "string",
-                    "default": "65530",
-                    "example": "65530"
+                    "example": "aws_vpn_connection.main"
                 },
-                "region": {
+                "attributes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AttributeDrift"
+                    }
+                },
+                "name": {
                     "type": "string",
-                    "default": "asia-northeast3",
-                    "example": "asia-northeast3"
+                    "example": "main"
                 },
-                "vpc_network_name": {
+                "providerName": {
                     "type": "string",
-                    "example": "my-vpc-network"
+                    "example": "registry.opentofu.org/hashicorp/aws"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "aws_vpn_connection"
                 }
             }
         },
-        "model.IbmConfig": {
+        "model.ResourceProgress": {
             "type": "object",
             "properties": {
-                "region": {
+                "action": {
                     "type": "string",
-                    "default": "au-syd",
-                    "example": "au-syd"
+                    "example": "create"
                 },
-                "subnet_id": {
+                "addr": {
                     "type": "string",
-                    "example": "0717-abc12345-6789-abcd-ef01-234567890abc"
+                    "example": "aws_vpc.main"
                 },
-                "vpc_cidr": {
+                "elapsedSeconds": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "idValue": {
                     "type": "string",
-                    "example": "10.0.0.0/16"
+                    "example": "vpc-0123456789abcdef0"
                 },
-                "vpc_id": {
+                "status": {
                     "type": "string",
-                    "example": "r006-abc12345-6789-abcd-ef01-234567890abc"
+                    "example": "InProgress"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
@@ -5237,6 +9658,34 @@ const docTemplate = `{
                 }
             }
         },
+        "model.StateLock": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string",
+                    "example": "2024-06-10T00:00:00Z"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "b6a1f6d4-2a0c-4e4f-8f2b-5c1d2e3f4a5b"
+                },
+                "operation": {
+                    "type": "string",
+                    "example": "OperationTypeApply"
+                },
+                "unlocked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "who": {
+                    "type": "string",
+                    "example": "root@mc-terrarium-0"
+                }
+            }
+        },
         "model.TargetCspConfig": {
             "type": "object",
             "properties": {
@@ -5287,15 +9736,53 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "approvalRequired": {
+                    "description": "ApprovalRequired requires a second person to approve a plan before it is applied",
+                    "type": "boolean",
+                    "default": false,
+                    "example": false
+                },
                 "description": {
                     "type": "string",
                     "default": "This terrarium enriches ...",
                     "example": "This terrarium enriches ..."
                 },
+                "expiresAt": {
+                    "description": "ExpiresAt is the time after which the resources of the terrarium are destroyed (exclusive with ttl)",
+                    "type": "string"
+                },
+                "labels": {
+                    "description": "Labels are the labels to organize and select terrariums (e.g., env=staging, team=network)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "name": {
                     "type": "string",
                     "default": "tr01",
                     "example": "tr01"
+                },
+                "ttl": {
+                    "description": "TTL is the time to live of the terrarium (e.g., 72h), after which its resources are destroyed (exclusive with expiresAt)",
+                    "type": "string",
+                    "example": "72h"
+                }
+            }
+        },
+        "model.TerrariumEvent": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "the terrarium (trId: tr01) expires in 1h0m0s"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "expiry_warning"
                 }
             }
         },
@@ -5306,6 +9793,13 @@ const docTemplate = `{
                 "name"
             ],
             "properties": {
+                "approvalRequired": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "createdAt": {
+                    "type": "string"
+                },
                 "credentialProfile": {
                     "description": "The name of the credential profile (holder) used for this terrarium",
                     "type": "string"
@@ -5315,21 +9809,51 @@ const docTemplate = `{
                     "default": "This terrarium enriches ...",
                     "example": "This terrarium enriches ..."
                 },
+                "enrichmentDetails": {
+                    "description": "Enrichments are the enrichments of the terrarium by name (e.g., testbed, vpn/site-to-site, sql-db)",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Enrichments"
+                        }
+                    ]
+                },
                 "enrichments": {
+                    "description": "EnrichmentNames are the names of the enrichments, separated by commas (e.g., testbed,vpn/site-to-site).\nDeprecated: it is kept in the shape of the former single enrichment (i.e., a string), see enrichmentDetails instead.",
                     "type": "string",
                     "example": "vpn/aws-to-site"
                 },
+                "expiresAt": {
+                    "description": "ExpiresAt is the time after which the resources are destroyed and the environments are emptied out (i.e., auto-destroy)",
+                    "type": "string"
+                },
+                "expiryWarnedAt": {
+                    "description": "ExpiryWarnedAt is the time when the expiry was warned (i.e., within the grace period)",
+                    "type": "string"
+                },
                 "id": {
                     "type": "string",
                     "default": "tr01",
                     "example": "tr01"
                 },
+                "labels": {
+                    "description": "Labels are the labels to organize and select terrariums (e.g., env=staging, team=network)",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "name": {
                     "type": "string",
                     "default": "tr01",
                     "example": "tr01"
                 },
+                "phase": {
+                    "description": "Phase is the least progressed phase of the enrichments (e.g., Empty, Initialized, Planned, Ready, Failed)",
+                    "type": "string",
+                    "example": "Ready"
+                },
                 "providers": {
+                    "description": "Providers are the providers engaged in the enrichments",
                     "type": "array",
                     "items": {
                         "type": "string"
@@ -5339,6 +9863,10 @@ const docTemplate = `{
                         "azure",
                         "gcp"
                     ]
+                },
+                "reapingSince": {
+                    "description": "ReapingSince is the time when the reaper claimed the expired terrarium to destroy its resources,\nduring which the lease cannot be extended",
+                    "type": "string"
                 }
             }
         },
@@ -5437,94 +9965,254 @@ const docTemplate = `{
                 }
             }
         },
-        "model.TfVarsMessageBroker": {
+        "model.UpdateCredentialProfileRequest": {
+            "type": "object",
+            "required": [
+                "credentials"
+            ],
+            "properties": {
+                "credentials": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "model.UpdateLabelsRequest": {
+            "type": "object",
+            "properties": {
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "uistream.Change": {
             "type": "object",
             "properties": {
-                "csp_region": {
+                "action": {
                     "type": "string",
-                    "example": "ap-northeast-2"
+                    "example": "create"
                 },
-                "csp_vnet_id": {
-                    "type": "string",
-                    "example": "vpc-12345678"
+                "reason": {
+                    "type": "string"
                 },
-                "terrarium_id": {
-                    "type": "string",
-                    "example": ""
+                "resource": {
+                    "$ref": "#/definitions/uistream.Resource"
                 }
             }
         },
-        "model.TfVarsObjectStorage": {
+        "uistream.ChangeSummary": {
             "type": "object",
             "properties": {
-                "csp_region": {
-                    "type": "string",
-                    "example": "ap-northeast-2"
+                "add": {
+                    "type": "integer",
+                    "example": 3
                 },
-                "csp_resource_group": {
-                    "type": "string",
-                    "example": "koreacentral"
+                "change": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "terrarium_id": {
+                "import": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "operation": {
                     "type": "string",
-                    "example": ""
+                    "example": "apply"
+                },
+                "remove": {
+                    "type": "integer",
+                    "example": 0
                 }
             }
         },
-        "model.TfVarsSqlDb": {
+        "uistream.Diagnostic": {
             "type": "object",
             "properties": {
-                "csp_region": {
+                "address": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "range": {
+                    "$ref": "#/definitions/uistream.Range"
+                },
+                "severity": {
                     "type": "string",
-                    "example": "ap-northeast-2"
+                    "example": "error"
                 },
-                "csp_resource_group": {
+                "summary": {
+                    "type": "string"
+                }
+            }
+        },
+        "uistream.Event": {
+            "type": "object",
+            "properties": {
+                "@level": {
                     "type": "string",
-                    "example": "rg-12345678"
+                    "example": "info"
                 },
-                "csp_subnet1_id": {
+                "@message": {
                     "type": "string",
-                    "example": "subnet-1234abcd"
+                    "example": "aws_vpc.main: Creating..."
                 },
-                "csp_subnet2_id": {
+                "@module": {
                     "type": "string",
-                    "example": "subnet-abcd1234"
+                    "example": "tofu.ui"
+                },
+                "@timestamp": {
+                    "type": "string"
+                },
+                "change": {
+                    "description": "Change is set for planned_change and resource_drift messages.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/uistream.Change"
+                        }
+                    ]
+                },
+                "changes": {
+                    "description": "Changes is set for change_summary messages.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/uistream.ChangeSummary"
+                        }
+                    ]
+                },
+                "diagnostic": {
+                    "description": "Diagnostic is set for diagnostic messages.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/uistream.Diagnostic"
+                        }
+                    ]
+                },
+                "hook": {
+                    "description": "Hook is set for apply_*, provision_* and refresh_* messages.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/uistream.Hook"
+                        }
+                    ]
                 },
-                "csp_vnet_id": {
+                "outputs": {
+                    "description": "Outputs is set for outputs messages.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/uistream.Output"
+                    }
+                },
+                "type": {
                     "type": "string",
-                    "example": "vpc-12345678"
+                    "example": "apply_start"
+                }
+            }
+        },
+        "uistream.Hook": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "create"
+                },
+                "elapsed_seconds": {
+                    "type": "integer",
+                    "example": 10
                 },
-                "db_admin_password": {
+                "id_key": {
                     "type": "string",
-                    "example": "Password1234!"
+                    "example": "id"
                 },
-                "db_admin_username": {
+                "id_value": {
                     "type": "string",
-                    "example": "mydbadmin"
+                    "example": "vpc-0123456789abcdef0"
                 },
-                "db_engine_port": {
-                    "type": "integer",
-                    "example": 3306
+                "resource": {
+                    "$ref": "#/definitions/uistream.Resource"
+                }
+            }
+        },
+        "uistream.Output": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "sensitive": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "object"
+                },
+                "value": {
+                    "type": "object"
+                }
+            }
+        },
+        "uistream.Pos": {
+            "type": "object",
+            "properties": {
+                "byte": {
+                    "type": "integer"
+                },
+                "column": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "uistream.Range": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "$ref": "#/definitions/uistream.Pos"
                 },
-                "db_engine_version": {
+                "filename": {
                     "type": "string",
-                    "example": "8.0.39"
+                    "example": "main.tf"
                 },
-                "db_instance_spec": {
+                "start": {
+                    "$ref": "#/definitions/uistream.Pos"
+                }
+            }
+        },
+        "uistream.Resource": {
+            "type": "object",
+            "properties": {
+                "addr": {
                     "type": "string",
-                    "example": "db.t3.micro"
+                    "example": "aws_vpc.main"
                 },
-                "egress_cidr_block": {
+                "implied_provider": {
                     "type": "string",
-                    "example": "0.0.0.0/0"
+                    "example": "aws"
+                },
+                "module": {
+                    "type": "string"
                 },
-                "ingress_cidr_block": {
+                "resource": {
                     "type": "string",
-                    "example": "0.0.0.0/0"
+                    "example": "aws_vpc.main"
                 },
-                "terrarium_id": {
+                "resource_key": {},
+                "resource_name": {
                     "type": "string",
-                    "example": ""
+                    "example": "main"
+                },
+                "resource_type": {
+                    "type": "string",
+                    "example": "aws_vpc"
                 }
             }
         }
@@ -5532,6 +10220,12 @@ const docTemplate = `{
     "securityDefinitions": {
         "BasicAuth": {
             "type": "basic"
+        },
+        "BearerAuth": {
+            "description": "API key or JWT as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     },
     "tags": [
@@ -5543,6 +10237,22 @@ const docTemplate = `{
             "description": "Terrarium workspace creation, management, and lifecycle operations",
             "name": "[Terrarium] An environment to enrich the multi-cloud infrastructure"
         },
+        {
+            "description": "Status and control of the requests (OpenTofu commands) running in a terrarium",
+            "name": "[Terrarium] Request management"
+        },
+        {
+            "description": "Saved plans to review the changes before they are applied",
+            "name": "[Terrarium] Plan management"
+        },
+        {
+            "description": "Credential profiles (i.e., credential holders) and their CSP credentials in the secrets store",
+            "name": "[Credential] Profile management"
+        },
+        {
+            "description": "Authenticated principal and the API keys of the users (besides BasicAuth and JWTs)",
+            "name": "[Auth] Identity and API keys"
+        },
         {
             "description": "Multi-cloud testbed infrastructure provisioning and management",
             "name": "[Testbed] Resource Operations"
@@ -5576,16 +10286,8 @@ const docTemplate = `{
             "name": "[VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)"
         },
         {
-            "description": "Message broker infrastructure operations (Proof of Concept)",
-            "name": "[Message Broker] Operations (PoC - Not officially supported)"
-        },
-        {
-            "description": "Object storage infrastructure operations (Proof of Concept)",
-            "name": "[Object Storage] Operations (PoC - Not officially supported)"
-        },
-        {
-            "description": "SQL database infrastructure operations (Proof of Concept)",
-            "name": "[SQL Database] Operations (PoC - Not officially supported)"
+            "description": "Operations of the registered enrichments (e.g., sql-db, object-storage, message-broker), served by the same APIs for each kind",
+            "name": "[Enrichment] Operations"
         }
     ]
 }`
@@ -15,16 +15,22 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	// Black import (_) is for running a package's init() function without using its other contents.
+	"github.com/cloud-barista/mc-terrarium/pkg/auth"
 	"github.com/cloud-barista/mc-terrarium/pkg/config"
 	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
 	"github.com/cloud-barista/mc-terrarium/pkg/logger"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
 	"github.com/fsnotify/fsnotify"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
@@ -76,9 +82,58 @@ func init() {
 		}
 	}
 
-	lkvstore.Init(lkvstore.Config{
-		DbFilePath: dbFilePath,
+	var etcdEndpoints []string
+	for _, endpoint := range strings.Split(config.Terrarium.LKVStore.Etcd.Endpoints, ",") {
+		if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+			etcdEndpoints = append(etcdEndpoints, endpoint)
+		}
+	}
+
+	err := lkvstore.Init(lkvstore.Config{
+		Backend:             config.Terrarium.LKVStore.Backend,
+		DbFilePath:          dbFilePath,
+		CompactionThreshold: config.Terrarium.LKVStore.CompactionThreshold,
+		CompactionInterval:  time.Duration(config.Terrarium.LKVStore.CompactionIntervalSec) * time.Second,
+		Etcd: lkvstore.EtcdConfig{
+			Endpoints:   etcdEndpoints,
+			Username:    config.Terrarium.LKVStore.Etcd.Username,
+			Password:    config.Terrarium.LKVStore.Etcd.Password,
+			Prefix:      config.Terrarium.LKVStore.Etcd.Prefix,
+			DialTimeout: time.Duration(config.Terrarium.LKVStore.Etcd.DialTimeoutSec) * time.Second,
+		},
 	})
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to initialize the lkvstore (backend: %s)", config.Terrarium.LKVStore.Backend)
+	}
+
+	// Initialize the secrets client, which logs in to OpenBao by AppRole (if the address is configured)
+	err = secrets.Init(secrets.Config{
+		Address:      config.Terrarium.Secrets.Address,
+		Mount:        config.Terrarium.Secrets.Mount,
+		RoleId:       config.Terrarium.Secrets.AppRole.RoleId,
+		SecretId:     config.Terrarium.Secrets.AppRole.SecretId,
+		SecretIdFile: config.Terrarium.Secrets.AppRole.SecretIdFile,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Failed to initialize the secrets client (address: %s)", config.Terrarium.Secrets.Address)
+	}
+
+	// Initialize the authentication by JWTs (if an issuer or a JWKS is configured), besides BasicAuth and API keys
+	err = auth.Init(auth.Config{
+		Jwt: auth.JwtConfig{
+			Issuer:      config.Terrarium.API.Auth.Jwt.Issuer,
+			Audience:    config.Terrarium.API.Auth.Jwt.Audience,
+			JwksUrl:     config.Terrarium.API.Auth.Jwt.JwksUrl,
+			JwksFile:    config.Terrarium.API.Auth.Jwt.JwksFile,
+			NameClaim:   config.Terrarium.API.Auth.Jwt.NameClaim,
+			HolderClaim: config.Terrarium.API.Auth.Jwt.HolderClaim,
+			AdminClaim:  config.Terrarium.API.Auth.Jwt.AdminClaim,
+			AdminValue:  config.Terrarium.API.Auth.Jwt.AdminValue,
+		},
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize the authentication by JWTs")
+	}
 
 }
 
@@ -97,12 +152,29 @@ func init() {
 
 // @securityDefinitions.basic BasicAuth
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API key or JWT as "Bearer <token>"
+
 // @tag.name [System] Utility
 // @tag.description System utility and health check operations
 
 // @tag.name [Terrarium] An environment to enrich the multi-cloud infrastructure
 // @tag.description Terrarium workspace creation, management, and lifecycle operations
 
+// @tag.name [Terrarium] Request management
+// @tag.description Status and control of the requests (OpenTofu commands) running in a terrarium
+
+// @tag.name [Terrarium] Plan management
+// @tag.description Saved plans to review the changes before they are applied
+
+// @tag.name [Credential] Profile management
+// @tag.description Credential profiles (i.e., credential holders) and their CSP credentials in the secrets store
+
+// @tag.name [Auth] Identity and API keys
+// @tag.description Authenticated principal and the API keys of the users (besides BasicAuth and JWTs)
+
 // @tag.name [Testbed] Resource Operations
 // @tag.description Multi-cloud testbed infrastructure provisioning and management
 
@@ -127,29 +199,45 @@ func init() {
 // @tag.name [VPN] GCP to Azure VPN tunnel configuration (PoC - Not officially supported)
 // @tag.description GCP to Azure VPN tunnel setup and management (Proof of Concept)
 
-// @tag.name [Message Broker] Operations (PoC - Not officially supported)
-// @tag.description Message broker infrastructure operations (Proof of Concept)
-
-// @tag.name [Object Storage] Operations (PoC - Not officially supported)
-// @tag.description Object storage infrastructure operations (Proof of Concept)
-
-// @tag.name [SQL Database] Operations (PoC - Not officially supported)
-// @tag.description SQL database infrastructure operations (Proof of Concept)
+// @tag.name [Enrichment] Operations
+// @tag.description Operations of the registered enrichments (e.g., sql-db, object-storage, message-broker), served by the same APIs for each kind
 
 func main() {
 
 	log.Info().Msg("preparing to run mc-terrarium server...")
 
 	// Load the state from the file back into the key-value store
+	// (the write-ahead log is replayed on top of the db file)
 	if err := lkvstore.LoadLkvStore(); err != nil {
-		log.Warn().Msg("The db file may not exist when first run.")
+		log.Warn().Msgf("The db file may not exist when first run: %v", err)
 	} else {
 		log.Info().Msg("Successfully loaded the lkvstore from file.")
 	}
 
+	// Recover the requests and terrariums left running by a previous server process
+	if report, err := terrarium.Recover(context.Background()); err != nil {
+		log.Error().Err(err).Msg("Failed to recover the interrupted requests.")
+	} else {
+		log.Info().Msgf("Recovered the interrupted requests (requests: %d, terrariums: %d).", len(report.Requests), len(report.Terrariums))
+	}
+
+	// Elect the leader among the instances sharing the lkvstore, which runs the background loops below
+	terrarium.StartLeaderElection(context.Background())
+
+	// Start the reaper, which destroys the resources of the expired terrariums
+	terrarium.StartReaper(context.Background())
+
+	// Start the drift detector, which detects the resources changed outside mc-terrarium (if enabled)
+	terrarium.StartDriftDetector(context.Background())
+
 	defer func() {
-		// Save the current state of the key-value store to file
-		if err := lkvstore.SaveLkvStore(); err != nil {
+		// Revoke the token of the secrets client
+		if err := secrets.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close the secrets client.")
+		}
+
+		// Compact the write-ahead log into the db file and close the key-value store
+		if err := lkvstore.Close(); err != nil {
 			log.Error().Msgf("Error saving: %v\n", err)
 		} else {
 			log.Info().Msg("Successfully saved the lkvstore to file.")
@@ -0,0 +1,134 @@
+#!/usr/bin/env bash
+# ==============================================================================
+# # openbao-approle.sh — Set up the AppRole of mc-terrarium in OpenBao
+# ==============================================================================
+#
+# This script enables the AppRole auth method, writes the policy of mc-terrarium,
+# and creates its role, so that mc-terrarium logs in by AppRole
+# instead of holding the root token (VAULT_TOKEN) in its environment.
+#
+# The policy permits:
+#   - reading and managing the CSP credentials (secret/csp/*, secret/users/*/csp/*)
+#   - issuing the short-lived child tokens for the runs of tofu
+#
+# Prerequisites:
+#   - OpenBao is initialized and unsealed (or running in dev mode)
+#   - VAULT_TOKEN is a token permitted to manage auth methods and policies (e.g., the root token)
+#
+# Output:
+#   - .env updated with TERRARIUM_SECRETS_APPROLE_ROLE_ID and TERRARIUM_SECRETS_APPROLE_SECRET_ID
+#
+# Usage:
+#   ./openbao-approle.sh [ENV_FILE]
+#
+# Test against a local dev server:
+#   bao server -dev -dev-root-token-id=root &
+#   VAULT_ADDR=http://127.0.0.1:8200 VAULT_TOKEN=root ./openbao-approle.sh /tmp/test.env
+#
+# ==============================================================================
+
+if [[ "${1:-}" == "-h" ]] || [[ "${1:-}" == "--help" ]]; then
+    echo "OpenBao AppRole Setup Script"
+    echo ""
+    echo "Usage: openbao-approle.sh [ENV_FILE]"
+    echo ""
+    echo "Enables the AppRole auth method, writes the policy of mc-terrarium,"
+    echo "and creates its role. The role_id and secret_id are written to ENV_FILE."
+    echo ""
+    echo "Arguments:"
+    echo "  ENV_FILE        Path to .env file to update (default: ../.env)"
+    exit 0
+fi
+
+set -euo pipefail
+
+VAULT_ADDR="${VAULT_ADDR:-http://localhost:8200}"
+ROLE_NAME="${ROLE_NAME:-mc-terrarium}"
+KV_MOUNT="${KV_MOUNT:-secret}"
+
+# Colors for output
+RED='\033[0;31m'
+GREEN='\033[0;32m'
+YELLOW='\033[1;33m'
+NC='\033[0m' # No Color
+
+SCRIPT_DIR=$(cd "$(dirname "$0")" && pwd)
+ENV_FILE="${1:-${ENV_FILE:-${SCRIPT_DIR}/../.env}}"
+
+if [ -z "${VAULT_TOKEN:-}" ] && [ -f "${ENV_FILE}" ]; then
+    VAULT_TOKEN=$(grep '^VAULT_TOKEN=' "${ENV_FILE}" | cut -d= -f2- || true)
+fi
+if [ -z "${VAULT_TOKEN:-}" ]; then
+    echo -e "${RED}Error: VAULT_TOKEN is required to set up the AppRole.${NC}" >&2
+    exit 1
+fi
+
+echo -e "${YELLOW}[openbao-approle]${NC} VAULT_ADDR=${VAULT_ADDR}"
+
+bao_api() {
+    local method="$1" path="$2" data="${3:-}"
+    if [ -n "${data}" ]; then
+        curl -sf -X "${method}" -H "X-Vault-Token: ${VAULT_TOKEN}" -H "Content-Type: application/json" \
+            -d "${data}" "${VAULT_ADDR}/v1/${path}"
+    else
+        curl -sf -X "${method}" -H "X-Vault-Token: ${VAULT_TOKEN}" "${VAULT_ADDR}/v1/${path}"
+    fi
+}
+
+# ── Enable the AppRole auth method ───────────────────────────────────
+if bao_api GET sys/auth | grep -q '"approle/"'; then
+    echo -e "${YELLOW}[openbao-approle]${NC} AppRole auth method is already enabled."
+else
+    echo -e "${YELLOW}[openbao-approle]${NC} Enabling AppRole auth method..."
+    bao_api POST sys/auth/approle '{"type":"approle"}' > /dev/null
+fi
+
+# ── Write the policy ─────────────────────────────────────────────────
+echo -e "${YELLOW}[openbao-approle]${NC} Writing the policy (${ROLE_NAME})..."
+POLICY=$(cat <<EOF
+path "${KV_MOUNT}/data/csp/*" {
+  capabilities = ["create", "read", "update", "delete"]
+}
+path "${KV_MOUNT}/metadata/csp/*" {
+  capabilities = ["read", "list", "delete"]
+}
+path "${KV_MOUNT}/data/users/*" {
+  capabilities = ["create", "read", "update", "delete"]
+}
+path "${KV_MOUNT}/metadata/users/*" {
+  capabilities = ["read", "list", "delete"]
+}
+path "${KV_MOUNT}/metadata/users" {
+  capabilities = ["list"]
+}
+path "auth/token/create" {
+  capabilities = ["update"]
+}
+EOF
+)
+POLICY_JSON=$(printf '%s' "${POLICY}" | python3 -c 'import json,sys; print(json.dumps({"policy": sys.stdin.read()}))')
+bao_api PUT "sys/policies/acl/${ROLE_NAME}" "${POLICY_JSON}" > /dev/null
+
+# ── Create the role ──────────────────────────────────────────────────
+# The token is renewed by mc-terrarium before it expires (token_ttl),
+# and mc-terrarium logs in again when it reaches token_max_ttl.
+echo -e "${YELLOW}[openbao-approle]${NC} Creating the role (${ROLE_NAME})..."
+bao_api POST "auth/approle/role/${ROLE_NAME}" \
+    "{\"token_policies\":[\"${ROLE_NAME}\"],\"token_ttl\":\"1h\",\"token_max_ttl\":\"24h\",\"secret_id_ttl\":\"0\"}" > /dev/null
+
+ROLE_ID=$(bao_api GET "auth/approle/role/${ROLE_NAME}/role-id" | python3 -c 'import json,sys; print(json.load(sys.stdin)["data"]["role_id"])')
+SECRET_ID=$(bao_api POST "auth/approle/role/${ROLE_NAME}/secret-id" '{}' | python3 -c 'import json,sys; print(json.load(sys.stdin)["data"]["secret_id"])')
+
+# ── Update .env ──────────────────────────────────────────────────────
+touch "${ENV_FILE}"
+for kv in "TERRARIUM_SECRETS_APPROLE_ROLE_ID=${ROLE_ID}" "TERRARIUM_SECRETS_APPROLE_SECRET_ID=${SECRET_ID}"; do
+    key="${kv%%=*}"
+    if grep -q "^${key}=" "${ENV_FILE}"; then
+        sed -i "s|^${key}=.*|${kv}|" "${ENV_FILE}"
+    else
+        echo "${kv}" >> "${ENV_FILE}"
+    fi
+done
+
+echo -e "${GREEN}[openbao-approle]${NC} AppRole is ready. role_id and secret_id are written to ${ENV_FILE}"
+echo "  Set TERRARIUM_SECRETS_ADDRESS (e.g., http://openbao:8200) to let mc-terrarium log in by the AppRole."
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/middlewares"
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/auth"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [API - Auth] Identity and API keys
+ */
+
+// isKeyAdmin reports whether the caller may manage the API keys of any principal, i.e., an admin principal.
+// Unlike middlewares.IsAdmin, it does not depend on the strict mode, so that a user cannot issue an admin key.
+func isKeyAdmin(c echo.Context) bool {
+	principal, authenticated := middlewares.PrincipalFrom(c.Request().Context())
+	return authenticated && principal.Admin
+}
+
+// keyPrincipalOf returns the authenticated principal managing the API keys.
+// The keys are not managed without a principal (i.e., the authentication is disabled),
+// since a key created by anyone would remain valid once the authentication is enabled.
+func keyPrincipalOf(c echo.Context) (model.Principal, error) {
+	principal, authenticated := middlewares.PrincipalFrom(c.Request().Context())
+	if !authenticated {
+		return model.Principal{}, fmt.Errorf("%w, API keys are managed by an authenticated principal only (the authentication is disabled)", errForbidden)
+	}
+	return principal, nil
+}
+
+// WhoAmI godoc
+// @Summary Get the authenticated principal
+// @Description Get the authenticated principal of the request (e.g., to check the claims mapped from a JWT),
+// @Description with the credential holder resolved for the request.
+// @Tags [Auth] Identity and API keys
+// @Accept  json
+// @Produce  json
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Principal "OK"
+// @Failure 401 {object} model.Response "Unauthorized (e.g., the authentication is disabled)"
+// @Router /whoami [get]
+func WhoAmI(c echo.Context) error {
+
+	principal, authenticated := middlewares.PrincipalFrom(c.Request().Context())
+	if !authenticated {
+		res := model.Response{Success: false, Message: "not authenticated (the authentication is disabled)"}
+		return c.JSON(http.StatusUnauthorized, res)
+	}
+	principal.CredentialHolder = job.CredentialHolderFrom(c.Request().Context())
+
+	return c.JSON(http.StatusOK, principal)
+}
+
+// ListApiKeys godoc
+// @Summary List the API keys
+// @Description List the API keys (without the keys) in order of their creation time, including the revoked ones.
+// @Description The caller, if not an admin, sees its own keys only.
+// @Tags [Auth] Identity and API keys
+// @Accept  json
+// @Produce  json
+// @Param name query string false "Name of the principal (admin only)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.ApiKey "OK"
+// @Failure 403 {object} model.Response "Forbidden (e.g., the authentication is disabled)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /api-keys [get]
+func ListApiKeys(c echo.Context) error {
+
+	principal, err := keyPrincipalOf(c)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	name := c.QueryParam("name")
+	if !isKeyAdmin(c) {
+		name = principal.Name
+	}
+
+	keys, err := auth.ListApiKeys(name)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list the API keys")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// CreateApiKey godoc
+// @Summary Create an API key
+// @Description Create an API key authenticating a principal (i.e., Authorization: Bearer <key>, or x-api-key: <key>).
+// @Description The key is returned only once, and only its hash is stored.
+// @Description The principal is the caller, bound to the credential holder of the caller, by default.
+// @Description The caller, if not an admin, creates a non-admin key of its own (and of its credential holder) only.
+// @Description The keys are not managed if the authentication is disabled.
+// @Tags [Auth] Identity and API keys
+// @Accept  json
+// @Produce  json
+// @Param RequestBody body model.CreateApiKeyRequest true "Principal of the API key"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.CreateApiKeyResponse "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 403 {object} model.Response "Forbidden (e.g., a key of another principal, or the authentication is disabled)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /api-keys [post]
+func CreateApiKey(c echo.Context) error {
+
+	req := new(model.CreateApiKeyRequest)
+	if err := c.Bind(req); err != nil {
+		res := model.Response{Success: false, Message: "failed to bind the request"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	principal, err := keyPrincipalOf(c)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	// The holder bound to the principal, not the one of the request (e.g., x-credential-holder in legacy mode)
+	if req.Name == "" {
+		req.Name = principal.Name
+	}
+	if req.CredentialHolder == "" {
+		req.CredentialHolder = principal.CredentialHolder
+	}
+	if !isKeyAdmin(c) && (req.Name != principal.Name || req.CredentialHolder != principal.CredentialHolder || req.Admin) {
+		err := fmt.Errorf("%w, only admins may create a key of another principal, holder or an admin key", errForbidden)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	key, err := auth.CreateApiKey(*req, principal.Name)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to create the API key (name: %s)", req.Name)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+// RevokeApiKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key, which is kept (with revokedAt) for the audit. Revoking a revoked key does nothing.
+// @Description The caller, if not an admin, revokes its own keys only. The keys are not managed if the authentication is disabled.
+// @Tags [Auth] Identity and API keys
+// @Accept  json
+// @Produce  json
+// @Param keyId path string true "API key ID"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.ApiKey "OK"
+// @Failure 403 {object} model.Response "Forbidden (e.g., a key of another principal, or the authentication is disabled)"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /api-keys/{keyId} [delete]
+func RevokeApiKey(c echo.Context) error {
+
+	principal, err := keyPrincipalOf(c)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	keyId := c.Param("keyId")
+	key, err := auth.GetApiKey(keyId)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	if !isKeyAdmin(c) && key.Name != principal.Name {
+		err := fmt.Errorf("%w, the API key (%s) is not of the caller", errForbidden, keyId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	key, err = auth.RevokeApiKey(keyId)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to revoke the API key (id: %s)", keyId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
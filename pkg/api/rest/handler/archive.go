@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// ExportTerrarium godoc
+// @Summary Export a terrarium as an archive
+// @Description Export a terrarium as an archive (tar.gz) to be imported into another server,
+// @Description which includes the terrarium info, the working directories (i.e., rendered templates, tfvars, state) and the requests.
+// @Description The sensitive values in the state and tfvars are redacted, or encrypted if a passphrase is given by x-archive-passphrase.
+// @Description An archive with the redacted values cannot be imported, so give a passphrase to move a terrarium.
+// @Description The archive carries a manifest version, by which an archive of an older version is migrated on import.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  application/gzip
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param x-archive-passphrase header string false "Passphrase to encrypt the sensitive values (redacted if omitted, which cannot be imported)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {file} file "The archive (tar.gz)"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/export [get]
+func ExportTerrarium(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	passphrase := c.Request().Header.Get(model.HeaderXArchivePassphrase)
+
+	archive, err := terrarium.Export(requestContext(c), trId, passphrase)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to export the terrarium (trId: %s)", trId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", trId+".tar.gz"))
+	return c.Blob(http.StatusOK, "application/gzip", archive)
+}
+
+// ImportTerrarium godoc
+// @Summary Import a terrarium from an archive
+// @Description Import a terrarium from an archive (tar.gz) exported by another server.
+// @Description The terrarium is held by the credential holder of the request, and imported as trId if given (otherwise, as it was exported).
+// @Description The providers of the enrichments are installed again (i.e., init), and the saved plans must be created again.
+// @Description An encrypted archive requires the passphrase given on export by x-archive-passphrase.
+// @Description An archive with the redacted sensitive values (i.e., exported without a passphrase) is refused.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param archive formData file true "The archive (tar.gz) exported from a terrarium"
+// @Param trId query string false "Terrarium ID to import as (if omitted, the ID in the archive)"
+// @Param x-archive-passphrase header string false "Passphrase to decrypt the sensitive values (required if encrypted)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.TerrariumInfo "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., the terrarium already exists)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/import [post]
+func ImportTerrarium(c echo.Context) error {
+
+	file, err := c.FormFile("archive")
+	if err != nil {
+		err2 := fmt.Errorf("invalid request, an archive (archive) is required: %w", err)
+		log.Warn().Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	src, err := file.Open()
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	defer src.Close()
+
+	archive, err := io.ReadAll(src)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// * Info: The imported terrarium is held by the credential holder of the request
+	credentialHolder := job.CredentialHolderFrom(c.Request().Context())
+
+	passphrase := c.Request().Header.Get(model.HeaderXArchivePassphrase)
+
+	trInfo, err := terrarium.Import(requestContext(c), archive, c.QueryParam("trId"), passphrase, credentialHolder, reqId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to import the terrarium")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	log.Debug().Msgf("%+v", trInfo) // debug
+
+	return c.JSON(http.StatusCreated, trInfo)
+}
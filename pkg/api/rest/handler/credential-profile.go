@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/middlewares"
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [API - Credential] Profile management
+ */
+
+// errForbidden is returned if the caller is not permitted to access a resource.
+var errForbidden = errors.New("forbidden")
+
+// authorizeProfile checks if the caller may access a credential profile,
+// i.e., an admin or the holder of the profile (see middlewares.IsAdmin).
+func authorizeProfile(c echo.Context, name string) error {
+	if middlewares.IsAdmin(c) || job.CredentialHolderFrom(c.Request().Context()) == name {
+		return nil
+	}
+	return fmt.Errorf("%w, the credential profile (%s) is not held by the caller", errForbidden, name)
+}
+
+// ListCredentialProfiles godoc
+// @Summary List the credential profiles
+// @Description List the credential profiles (i.e., credential holders) in the secrets store with the providers whose credentials are configured.
+// @Description The admin profile is always listed first.
+// @Tags [Credential] Profile management
+// @Accept  json
+// @Produce  json
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.CredentialProfile "OK"
+// @Failure 403 {object} model.Response "Forbidden (e.g., not held by the caller)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable (e.g., the secrets store is not configured)"
+// @Router /credential-profiles [get]
+func ListCredentialProfiles(c echo.Context) error {
+
+	profiles, err := terrarium.ListCredentialProfiles(requestContext(c))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list the credential profiles")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// The caller, if not an admin, sees its own profile only
+	if !middlewares.IsAdmin(c) {
+		owned := []model.CredentialProfile{}
+		for _, profile := range profiles {
+			if authorizeProfile(c, profile.Name) == nil {
+				owned = append(owned, profile)
+			}
+		}
+		profiles = owned
+	}
+
+	return c.JSON(http.StatusOK, profiles)
+}
+
+// CreateCredentialProfile godoc
+// @Summary Create a credential profile
+// @Description Create a credential profile with the credentials by provider (e.g., {"aws": {"AWS_ACCESS_KEY_ID": "...", "AWS_SECRET_ACCESS_KEY": "..."}}).
+// @Description The credentials are stored in the secrets store (i.e., users/{name}/csp/{provider}), and they are not returned by any API.
+// @Tags [Credential] Profile management
+// @Accept  json
+// @Produce  json
+// @Param RequestBody body model.CreateCredentialProfileRequest true "Name and credentials of the credential profile"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.CredentialProfile "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., already exists)"
+// @Failure 403 {object} model.Response "Forbidden (e.g., not held by the caller)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable (e.g., the secrets store is not configured)"
+// @Router /credential-profiles [post]
+func CreateCredentialProfile(c echo.Context) error {
+
+	req := new(model.CreateCredentialProfileRequest)
+	if err := c.Bind(req); err != nil {
+		res := model.Response{Success: false, Message: "failed to bind the request"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	if err := authorizeProfile(c, req.Name); err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	profile, err := terrarium.CreateCredentialProfile(requestContext(c), req.Name, req.Credentials)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to create the credential profile (name: %s)", req.Name)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusCreated, profile)
+}
+
+// GetCredentialProfile godoc
+// @Summary Get a credential profile
+// @Description Get a credential profile with the providers whose credentials are configured.
+// @Tags [Credential] Profile management
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Credential profile name" default(admin)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.CredentialProfile "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 403 {object} model.Response "Forbidden (e.g., not held by the caller)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable (e.g., the secrets store is not configured)"
+// @Router /credential-profiles/{name} [get]
+func GetCredentialProfile(c echo.Context) error {
+
+	name := c.Param("name")
+	if err := authorizeProfile(c, name); err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	profile, err := terrarium.GetCredentialProfile(requestContext(c), name)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to get the credential profile (name: %s)", name)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// UpdateCredentialProfile godoc
+// @Summary Update a credential profile
+// @Description Update the credentials of the providers in a credential profile.
+// @Description The credential of a provider is replaced, or deleted if empty (e.g., {"tencent": {}}). The other providers are kept.
+// @Tags [Credential] Profile management
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Credential profile name" default(admin)
+// @Param RequestBody body model.UpdateCredentialProfileRequest true "Credentials to replace (or delete if empty) by provider"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.CredentialProfile "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 403 {object} model.Response "Forbidden (e.g., not held by the caller)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable (e.g., the secrets store is not configured)"
+// @Router /credential-profiles/{name} [put]
+func UpdateCredentialProfile(c echo.Context) error {
+
+	name := c.Param("name")
+	req := new(model.UpdateCredentialProfileRequest)
+	if err := c.Bind(req); err != nil {
+		res := model.Response{Success: false, Message: "failed to bind the request"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	if err := authorizeProfile(c, name); err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	profile, err := terrarium.UpdateCredentialProfile(requestContext(c), name, req.Credentials)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to update the credential profile (name: %s)", name)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// DeleteCredentialProfile godoc
+// @Summary Delete a credential profile
+// @Description Delete a credential profile with all its credentials.
+// @Description A credential profile used by any terrarium is not deleted, and the admin profile cannot be deleted.
+// @Tags [Credential] Profile management
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Credential profile name"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., used by terrariums)"
+// @Failure 403 {object} model.Response "Forbidden (e.g., not held by the caller)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable (e.g., the secrets store is not configured)"
+// @Router /credential-profiles/{name} [delete]
+func DeleteCredentialProfile(c echo.Context) error {
+
+	name := c.Param("name")
+	if err := authorizeProfile(c, name); err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	err := terrarium.DeleteCredentialProfile(requestContext(c), name)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to delete the credential profile (name: %s)", name)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	res := model.Response{Success: true, Message: "the credential profile (" + name + ") is deleted"}
+	return c.JSON(http.StatusOK, res)
+}
+
+// VerifyCredentialProfile godoc
+// @Summary Verify the credential of a provider in a credential profile
+// @Description Verify the credential of a provider in a credential profile before running the enrichments,
+// @Description by running a tiny, read-only tofu configuration (e.g., aws_caller_identity) in a scratch directory.
+// @Description No resource is created. It takes a while (i.e., tofu init and plan) but much less than a failed apply.
+// @Description The result is passed, or failed at a stage (credential, init or plan) with the diagnostic of tofu (e.g., an expired or mis-scoped key).
+// @Tags [Credential] Profile management
+// @Accept  json
+// @Produce  json
+// @Param name path string true "Credential profile name" default(admin)
+// @Param provider query string true "Provider" Enums(aws, azure, gcp, alibaba, tencent, ibm, ncp, dcs) default(aws)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.CredentialVerification "OK (passed or failed)"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 403 {object} model.Response "Forbidden (e.g., not held by the caller)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /credential-profiles/{name}/verify [post]
+func VerifyCredentialProfile(c echo.Context) error {
+
+	name := c.Param("name")
+	provider := c.QueryParam("provider")
+	if err := authorizeProfile(c, name); err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	result, err := terrarium.VerifyCredential(requestContext(c), name, provider, reqId)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to verify the credential (name: %s, provider: %s)", name, provider)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	log.Debug().Msgf("%+v", result) // debug
+
+	return c.JSON(http.StatusOK, result)
+}
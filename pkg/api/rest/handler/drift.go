@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// GetTerrariumDrift godoc
+// @Summary Get the drift reports of a terrarium
+// @Description Get the drift reports of the applied enrichments of a terrarium, i.e., the resources changed outside mc-terrarium.
+// @Description The drift is detected periodically by `tofu plan -refresh-only` if the drift detector is enabled (terrarium.drift.interval_min).
+// @Description A report shows the drifted resources and their changed attributes (the sensitive values are not shown) at the last detection.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param enrichment query string false "Enrichment (e.g., vpn/aws-to-site, sql-db)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.DriftReport "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/drift [get]
+func GetTerrariumDrift(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	reports, err := terrarium.GetDriftReports(trId, c.QueryParam("enrichment"))
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to get the drift reports (trId: %s)", trId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	return c.JSON(http.StatusOK, reports)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+/*
+ * [API - Enrichments] Legacy operations (deprecated)
+ *
+ * The former APIs of the enrichments (e.g., /tr/{trId}/sql-db/env) are kept as aliases
+ * of the generic APIs (/tr/{trId}/enrichments/{kind}/env), whose kind is taken from the path.
+ */
+
+// withLegacyKind binds the kind of the enrichment in the path of a legacy API (e.g., /tr/:trId/sql-db/env)
+// to the kind parameter of the generic APIs.
+func withLegacyKind(c echo.Context) echo.Context {
+	_, rest, _ := strings.Cut(c.Path(), "/tr/:trId/")
+	kind, _, _ := strings.Cut(rest, "/")
+
+	// Copy the names and values, which may be shared with the router
+	names := append(append([]string{}, c.ParamNames()...), "kind")
+	values := append(append([]string{}, c.ParamValues()...), kind)
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	return c
+}
+
+// InitEnvForLegacyEnrichment godoc
+// @Summary Initialize a multi-cloud terrarium for an enrichment (deprecated)
+// @Description Deprecated: use POST /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param provider query string true "Provider" Enums(aws, azure, gcp, ncp) default(aws)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.Response "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Deprecated
+// @Router /tr/{trId}/sql-db/env [post]
+// @Router /tr/{trId}/object-storage/env [post]
+// @Router /tr/{trId}/message-broker/env [post]
+func InitEnvForLegacyEnrichment(c echo.Context) error {
+	return InitEnvForEnrichment(withLegacyKind(c))
+}
+
+// ClearEnvOfLegacyEnrichment godoc
+// @Summary Clear the entire directory and configuration files of an enrichment (deprecated)
+// @Description Deprecated: use DELETE /tr/{trId}/enrichments/{kind}/env, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Deprecated
+// @Router /tr/{trId}/sql-db/env [delete]
+// @Router /tr/{trId}/object-storage/env [delete]
+// @Router /tr/{trId}/message-broker/env [delete]
+func ClearEnvOfLegacyEnrichment(c echo.Context) error {
+	return ClearEnvOfEnrichment(withLegacyKind(c))
+}
+
+// CreateInfracodeForLegacyEnrichment godoc
+// @Summary Create the infracode for an enrichment (deprecated)
+// @Description Deprecated: use POST /tr/{trId}/enrichments/{kind}/infracode, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param ParamsForInfracode body model.CreateInfracodeOfEnrichmentRequest true "Parameters of infracode for the enrichment"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.Response "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Deprecated
+// @Router /tr/{trId}/sql-db/infracode [post]
+// @Router /tr/{trId}/object-storage/infracode [post]
+// @Router /tr/{trId}/message-broker/infracode [post]
+func CreateInfracodeForLegacyEnrichment(c echo.Context) error {
+	return CreateInfracodeForEnrichment(withLegacyKind(c))
+}
+
+// CheckInfracodeForLegacyEnrichment godoc
+// @Summary Check and show changes by the current infracode of an enrichment (deprecated)
+// @Description Deprecated: use POST /tr/{trId}/enrichments/{kind}/plan, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Deprecated
+// @Router /tr/{trId}/sql-db/plan [post]
+// @Router /tr/{trId}/object-storage/plan [post]
+// @Router /tr/{trId}/message-broker/plan [post]
+func CheckInfracodeForLegacyEnrichment(c echo.Context) error {
+	return CheckInfracodeForEnrichment(withLegacyKind(c))
+}
+
+// CreateLegacyEnrichment godoc
+// @Summary Create the resources of an enrichment (deprecated)
+// @Description Deprecated: use POST /tr/{trId}/enrichments/{kind}, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId query string false "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Deprecated
+// @Router /tr/{trId}/sql-db [post]
+// @Router /tr/{trId}/object-storage [post]
+// @Router /tr/{trId}/message-broker [post]
+func CreateLegacyEnrichment(c echo.Context) error {
+	return CreateEnrichment(withLegacyKind(c))
+}
+
+// GetResourceInfoOfLegacyEnrichment godoc
+// @Summary Get resource info of an enrichment (deprecated)
+// @Description Deprecated: use GET /tr/{trId}/enrichments/{kind}, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
+// @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Deprecated
+// @Router /tr/{trId}/sql-db [get]
+// @Router /tr/{trId}/object-storage [get]
+// @Router /tr/{trId}/message-broker [get]
+func GetResourceInfoOfLegacyEnrichment(c echo.Context) error {
+	return GetResourceInfoOfEnrichment(withLegacyKind(c))
+}
+
+// DestroyLegacyEnrichment godoc
+// @Summary Destroy the resources of an enrichment (deprecated)
+// @Description Deprecated: use DELETE /tr/{trId}/enrichments/{kind}, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Deprecated
+// @Router /tr/{trId}/sql-db [delete]
+// @Router /tr/{trId}/object-storage [delete]
+// @Router /tr/{trId}/message-broker [delete]
+func DestroyLegacyEnrichment(c echo.Context) error {
+	return DestroyEnrichment(withLegacyKind(c))
+}
+
+// GetRequestStatusOfLegacyEnrichment godoc
+// @Summary Check the status of a specific request by its ID (deprecated)
+// @Description Deprecated: use GET /tr/{trId}/enrichments/{kind}/request/{requestId}, which this API is an alias of.
+// @Tags [Enrichment] Legacy operations (deprecated)
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param requestId path string true "Request ID"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Deprecated
+// @Router /tr/{trId}/sql-db/request/{requestId} [get]
+// @Router /tr/{trId}/object-storage/request/{requestId} [get]
+// @Router /tr/{trId}/message-broker/request/{requestId} [get]
+func GetRequestStatusOfLegacyEnrichment(c echo.Context) error {
+	return GetRequestStatusOfEnrichment(withLegacyKind(c))
+}
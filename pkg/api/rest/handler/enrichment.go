@@ -0,0 +1,744 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/enrichment"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	tfutil "github.com/cloud-barista/mc-terrarium/pkg/tofu/util"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+	"github.com/tidwall/gjson"
+)
+
+/*
+ * [API - Enrichments] Operations
+ *
+ * The operations are served for the enrichments registered in the enrichment package
+ * (e.g., sql-db, object-storage, message-broker).
+ */
+
+// enrichmentOf returns the enrichment of the kind in the path and the terrarium info.
+// The terrarium must have the enrichment unless allowNew is set (i.e., to add it to the terrarium).
+func enrichmentOf(c echo.Context, allowNew bool) (enrichment.Enrichment, model.TerrariumInfo, error) {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		return nil, model.TerrariumInfo{}, err
+	}
+
+	e, err := enrichment.Get(c.Param("kind"))
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return nil, model.TerrariumInfo{}, err
+	}
+
+	trInfo, exist, err := terrarium.GetInfo(trId)
+	if !exist {
+		err := fmt.Errorf("%w (trId: %s)", terrarium.ErrTerrariumNotFound, trId)
+		log.Warn().Msg(err.Error())
+		return nil, model.TerrariumInfo{}, err
+	}
+	if err != nil {
+		err2 := fmt.Errorf("failed to read terrarium information")
+		log.Error().Err(err).Msg(err2.Error())
+		return nil, model.TerrariumInfo{}, err2
+	}
+
+	if _, exists := trInfo.Enrichments[e.Name()]; exists || allowNew {
+		return e, trInfo, nil
+	}
+	err = fmt.Errorf("%w, the terrarium (trId: %s) is not initialized for %s", terrarium.ErrEnrichmentNotFound, trId, e.Name())
+	log.Warn().Msg(err.Error())
+	return nil, model.TerrariumInfo{}, err
+}
+
+// ListEnrichments godoc
+// @Summary List the enrichments
+// @Description List the enrichments (i.e., kinds of resources) served by /tr/{trId}/enrichments/{kind}
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Response "OK"
+// @Router /enrichments [get]
+func ListEnrichments(c echo.Context) error {
+
+	var list []interface{}
+	for _, e := range enrichment.List() {
+		list = append(list, model.EnrichmentKind{
+			Kind:      e.Name(),
+			Providers: e.Providers(),
+			Output:    e.OutputName(),
+		})
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: fmt.Sprintf("%d enrichment(s)", len(list)),
+		List:    list,
+	}
+	return c.JSON(http.StatusOK, res)
+}
+
+// InitEnvForEnrichment godoc
+// @Summary Initialize a multi-cloud terrarium for an enrichment
+// @Description Initialize a multi-cloud terrarium for an enrichment
+// @Description The template files of the enrichment for the provider are copied to the working directory, and then initialized.
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param provider query string true "Provider" Enums(aws, azure, gcp, ncp) default(aws)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.Response "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind}/env [post]
+func InitEnvForEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, true)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	provider := c.QueryParam("provider")
+	if provider == "" || !enrichment.Supports(e, provider) {
+		err := fmt.Errorf("invalid request, provider must be one of [%s]", strings.Join(e.Providers(), ", "))
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	// Check the credential of the provider under the credential profile of the terrarium (fail fast)
+	err = terrarium.CheckCredentials(requestContext(c), trInfo.CredentialProfile, []string{provider})
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// Add the enrichment and the provider to terrarium information
+	err = terrarium.AddEnrichment(trId, e.Name(), []string{provider})
+	if err != nil {
+		err2 := fmt.Errorf("failed to update terrarium information")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	// Get (or create) the working directory of the enrichment
+	workingDir, err := terrarium.GetTerrariumEnvPath(trId, e.Name())
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	// Copy template files to the working directory (overwrite)
+	templateTfsPath := config.Terrarium.Root + "/templates/" + e.TemplatePath(provider)
+	err = tfutil.CopyFiles(templateTfsPath, workingDir)
+	if err != nil {
+		err2 := fmt.Errorf("failed to copy template files to working directory")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	ret, err := terrarium.Init(requestContext(c), trId, e.Name(), reqId)
+	if err != nil {
+		err2 := fmt.Errorf("failed to initialize an infrastructure terrarium")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: fmt.Sprintf("the infrastructure terrarium is successfully initialized for %s (provider: %s)", e.Name(), provider),
+		Detail:  ret,
+	}
+
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// ClearEnvOfEnrichment godoc
+// @Summary Clear the entire directory and configuration files of an enrichment
+// @Description Clear the entire directory and configuration files of an enrichment
+// @Description The enrichment is removed from the terrarium after it is cleared.
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, resources not destroyed)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind}/env [delete]
+func ClearEnvOfEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	// Check if a previous request is still in progress
+	if status, exists := tofu.GetExecutionStatus(trId, e.Name()); exists && status == tofu.StatusRunning {
+		err := fmt.Errorf("%w (trId: %s, enrichment: %s)", tofu.ErrInProgress, trId, e.Name())
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Check if resources may exist (i.e., not destroyed)
+	if err := terrarium.CheckOperation(trId, e.Name(), terrarium.OpEmptyOut); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	workingDir := config.Terrarium.Root + "/.terrarium/" + trId + "/" + e.Name()
+	err = os.RemoveAll(workingDir)
+	if err != nil {
+		err2 := fmt.Errorf("failed to remove working directory and all configuration files")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	// Remove the enrichment from terrarium information
+	err = terrarium.RemoveEnrichment(trId, e.Name())
+	if err != nil {
+		err2 := fmt.Errorf("failed to remove the enrichment")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: "successfully remove all in the working directory",
+	}
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// CreateInfracodeForEnrichment godoc
+// @Summary Create the infracode for an enrichment
+// @Description Create the infracode (i.e., tfvars) for an enrichment
+// @Description The tfVars depend on the kind: model.TfVarsSqlDb (sql-db), model.TfVarsObjectStorage (object-storage) and model.TfVarsMessageBroker (message-broker).
+// @Description The terrarium_id is set to the terrarium ID if omitted.
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param ParamsForInfracode body model.CreateInfracodeOfEnrichmentRequest true "Parameters of infracode for the enrichment"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.Response "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind}/infracode [post]
+func CreateInfracodeForEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	req := new(model.CreateInfracodeOfEnrichmentRequest)
+	refs, err := bindWithReferences(c, trId, e.Name(), "tfVars", req)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	log.Debug().Msgf("%+v", req) // debug
+
+	if req.TfVars == nil {
+		req.TfVars = map[string]interface{}{}
+	}
+	if v, ok := req.TfVars["terrarium_id"]; !ok || v == "" {
+		req.TfVars["terrarium_id"] = trId
+	}
+
+	// Decode the tfVars into the tfvars type of the enrichment
+	tfVars := e.NewTfVars()
+	b, err := json.Marshal(req.TfVars)
+	if err == nil {
+		err = json.Unmarshal(b, tfVars)
+	}
+	if err != nil {
+		err2 := fmt.Errorf("invalid tfVars for %s, %v", e.Name(), err)
+		log.Warn().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	provider := ""
+	if providers := trInfo.Enrichments[e.Name()].Providers; len(providers) > 0 {
+		provider = providers[0]
+	}
+	if err := e.Validate(provider, tfVars); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	err = terrarium.SaveTfVars(trId, e.Name(), tfVars)
+	if err != nil {
+		err2 := fmt.Errorf("failed to save tfVars to a file")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	// Keep the references to the outputs of the other enrichments to resolve them again on update
+	err = terrarium.SaveReferences(trId, e.Name(), refs)
+	if err != nil {
+		log.Error().Err(err).Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: fmt.Sprintf("the infracode for %s is successfully created", e.Name()),
+	}
+
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusCreated, res)
+}
+
+// CheckInfracodeForEnrichment godoc
+// @Summary Check and show changes by the current infracode of an enrichment
+// @Description Check and show changes by the current infracode of an enrichment
+// @Description The plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind}/plan [post]
+func CheckInfracodeForEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	plan, ret, err := terrarium.Plan(ctx, trInfo.Id, e.Name(), reqId)
+	if err != nil {
+		err2 := fmt.Errorf("failed to plan the infrastructure terrarium")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error(), Detail: ret}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: fmt.Sprintf("the infracode checking process is successfully completed (planId: %s)", plan.Id),
+		Detail:  ret,
+		Object:  planObject(plan),
+	}
+
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// CreateEnrichment godoc
+// @Summary Create the resources of an enrichment
+// @Description Create the resources of an enrichment, and return the refined resource info
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param planId query string false "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., stale plan, not initialized)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind} [post]
+func CreateEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	_, err = terrarium.Apply(ctx, trId, e.Name(), reqId, c.QueryParam("planId"))
+	if err != nil {
+		err2 := fmt.Errorf("failed to apply the infrastructure terrarium, %v", err)
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	resourceInfo, err := outputOfEnrichment(c, e, trId, reqId)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: "refined read resource info (map)",
+		Object:  resourceInfo,
+	}
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// outputOfEnrichment returns the refined resource info specified as the output of the enrichment.
+func outputOfEnrichment(c echo.Context, e enrichment.Enrichment, trId, reqId string) (map[string]interface{}, error) {
+
+	ret, err := terrarium.Output(requestContext(c), trId, e.Name(), reqId, e.OutputName(), "-json")
+	if err != nil {
+		err2 := fmt.Errorf("failed to read resource info (detail: %s) specified as 'output' in the state file", "refined")
+		log.Error().Err(err).Msg(err2.Error())
+		return nil, err2
+	}
+
+	var resourceInfo map[string]interface{}
+	err = json.Unmarshal([]byte(ret), &resourceInfo)
+	if err != nil {
+		err2 := fmt.Errorf("failed to unmarshal resource info")
+		log.Error().Err(err).Msg(err2.Error())
+		return nil, err2
+	}
+
+	return resourceInfo, nil
+}
+
+// GetResourceInfoOfEnrichment godoc
+// @Summary Get resource info of an enrichment
+// @Description Get resource info of an enrichment
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
+// @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind} [get]
+func GetResourceInfoOfEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	// Use this struct like the enum
+	var DetailOptions = struct {
+		Refined string
+		Raw     string
+	}{
+		Refined: "refined",
+		Raw:     "raw",
+	}
+
+	// valid detail options
+	validDetailOptions := map[string]bool{
+		DetailOptions.Refined: true,
+		DetailOptions.Raw:     true,
+	}
+
+	detail := strings.ToLower(c.QueryParam("detail"))
+	if detail == "" || !validDetailOptions[detail] {
+		err := fmt.Errorf("invalid detail (%s), use the default (%s)", detail, DetailOptions.Refined)
+		log.Warn().Msg(err.Error())
+		detail = DetailOptions.Refined
+	}
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Refresh the state to sync with the current CSP status (default: true)
+	refreshParam := strings.ToLower(c.QueryParam("refresh"))
+	if refreshParam != "" && refreshParam != "true" && refreshParam != "false" {
+		err := fmt.Errorf("invalid refresh value (%s), allowed values: true, false", refreshParam)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	if refreshParam != "false" {
+		log.Info().Msgf("Refreshing state for terrarium (trId: %s) to sync with CSP", trId)
+		_, err := terrarium.Refresh(ctx, trId, e.Name(), reqId)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to refresh state, proceeding with cached state")
+		}
+	}
+
+	// Get the resource info by the detail option
+	switch detail {
+	case DetailOptions.Raw:
+
+		ret, err := terrarium.Show(requestContext(c), trId, e.Name(), reqId, "-json")
+		if err != nil {
+			err2 := fmt.Errorf("failed to read resource info (detail: %s) from the state or plan file", DetailOptions.Raw)
+			log.Error().Err(err).Msg(err2.Error())
+			res := model.Response{Success: false, Message: err2.Error()}
+			return c.JSON(http.StatusInternalServerError, res)
+		}
+
+		// Parse the resource info in the root module and child modules
+		var resourceInfoList []interface{}
+		resourceJsons := []gjson.Result{gjson.Get(ret, "values.root_module.resources")}
+		resourceJsons = append(resourceJsons, gjson.Get(ret, "values.root_module.child_modules.#.resources").Array()...)
+		for _, resourceJson := range resourceJsons {
+			if !resourceJson.Exists() {
+				continue
+			}
+			var temp []interface{}
+			if err := json.Unmarshal([]byte(resourceJson.Raw), &temp); err != nil {
+				log.Error().Err(err).Msg("") // error
+				res := model.Response{Success: false, Message: "failed to unmarshal resource info"}
+				return c.JSON(http.StatusInternalServerError, res)
+			}
+			resourceInfoList = append(resourceInfoList, temp...)
+		}
+
+		if len(resourceInfoList) == 0 {
+			err2 := fmt.Errorf("could not find resource info (trId: %s)", trId)
+			log.Warn().Msg(err2.Error())
+			res := model.Response{Success: false, Message: err2.Error()}
+			return c.JSON(http.StatusOK, res)
+		}
+
+		res := model.Response{
+			Success: true,
+			Message: "raw resource info (list)",
+			List:    resourceInfoList,
+		}
+		log.Debug().Msgf("%+v", res) // debug
+
+		return c.JSON(http.StatusOK, res)
+
+	default:
+
+		resourceInfo, err := outputOfEnrichment(c, e, trId, reqId)
+		if err != nil {
+			res := model.Response{Success: false, Message: err.Error()}
+			return c.JSON(http.StatusInternalServerError, res)
+		}
+
+		res := model.Response{
+			Success: true,
+			Message: "refined read resource info (map)",
+			Object:  resourceInfo,
+		}
+		log.Debug().Msgf("%+v", res) // debug
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// DestroyEnrichment godoc
+// @Summary Destroy the resources of an enrichment
+// @Description Destroy the resources of an enrichment
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind} [delete]
+func DestroyEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	ret, err := terrarium.Destroy(ctx, trId, e.Name(), reqId)
+	if err != nil {
+		err2 := fmt.Errorf("failed to destroy the infrastructure terrarium")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: fmt.Sprintf("the destroying process is successfully completed (trId: %s, enrichments: %s)", trId, e.Name()),
+		Detail:  ret,
+	}
+
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetRequestStatusOfEnrichment godoc
+// @Summary Check the status of a specific request by its ID
+// @Description Check the status of a specific request by its ID
+// @Tags [Enrichment] Operations
+// @Accept json
+// @Produce json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param kind path string true "Kind of the enrichment" Enums(sql-db, object-storage, message-broker) default(sql-db)
+// @Param requestId path string true "Request ID"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Response "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/enrichments/{kind}/request/{requestId} [get]
+func GetRequestStatusOfEnrichment(c echo.Context) error {
+
+	e, trInfo, err := enrichmentOf(c, false)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	trId := trInfo.Id
+
+	reqId := c.Param("requestId")
+	if reqId == "" {
+		err := fmt.Errorf("invalid request, request ID (requestId: %s) is required", reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	workingDir := config.Terrarium.Root + "/.terrarium/" + trId + "/" + e.Name()
+	statusLogFile := fmt.Sprintf("%s/runningLogs/%s.log", workingDir, reqId)
+
+	// Check the statusReport of the request
+	statusReport, err := tofu.GetExcutionHistory(trId, reqId, statusLogFile)
+	if err != nil {
+		err2 := fmt.Errorf("failed to get the status of the request")
+		log.Error().Err(err).Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: "the status of a specific request",
+		Detail:  statusReport,
+	}
+
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusOK, res)
+}
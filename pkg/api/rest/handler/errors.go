@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/auth"
+	"github.com/cloud-barista/mc-terrarium/pkg/enrichment"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+)
+
+// httpStatusOf returns the HTTP status code for an error returned by the terrarium or enrichment package.
+// It returns 500 (Internal Server Error) for an unknown error.
+func httpStatusOf(err error) int {
+	switch {
+	case errors.Is(err, terrarium.ErrTerrariumNotFound),
+		errors.Is(err, terrarium.ErrPlanNotFound),
+		errors.Is(err, terrarium.ErrEnrichmentNotFound),
+		errors.Is(err, enrichment.ErrNotRegistered),
+		errors.Is(err, terrarium.ErrCredentialProfileNotFound),
+		errors.Is(err, auth.ErrApiKeyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errInvalidRequestFormat),
+		errors.Is(err, enrichment.ErrInvalidTfVars),
+		errors.Is(err, terrarium.ErrInvalidReference),
+		errors.Is(err, terrarium.ErrInvalidArchive),
+		errors.Is(err, terrarium.ErrInvalidLabel),
+		errors.Is(err, terrarium.ErrInvalidLease),
+		errors.Is(err, terrarium.ErrInvalidListOptions),
+		errors.Is(err, terrarium.ErrInvalidCredentialProfile),
+		errors.Is(err, terrarium.ErrMissingCredential),
+		errors.Is(err, auth.ErrInvalidApiKey):
+		return http.StatusBadRequest
+	case errors.Is(err, terrarium.ErrSelfApproval),
+		errors.Is(err, errForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, terrarium.ErrStalePlan),
+		errors.Is(err, terrarium.ErrPlanNotApplicable),
+		errors.Is(err, terrarium.ErrPlanNotApproved),
+		errors.Is(err, terrarium.ErrPlanExpired),
+		errors.Is(err, terrarium.ErrPlanNotPending),
+		errors.Is(err, terrarium.ErrUnresolvedReference),
+		errors.Is(err, terrarium.ErrInvalidTransition),
+		errors.Is(err, terrarium.ErrLiveResources),
+		errors.Is(err, terrarium.ErrTerrariumExists),
+		errors.Is(err, terrarium.ErrCredentialProfileExists),
+		errors.Is(err, terrarium.ErrCredentialProfileInUse),
+		errors.Is(err, tofu.ErrInProgress),
+		errors.Is(err, job.ErrOtherHost):
+		return http.StatusConflict
+	case errors.Is(err, secrets.ErrNotConfigured):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
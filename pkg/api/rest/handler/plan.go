@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/middlewares"
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// planObject converts a plan to the object of a response.
+func planObject(plan model.Plan) map[string]interface{} {
+	object := map[string]interface{}{}
+	b, err := json.Marshal(plan)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to marshal the plan")
+		return object
+	}
+	if err := json.Unmarshal(b, &object); err != nil {
+		log.Warn().Err(err).Msg("failed to unmarshal the plan")
+	}
+	return object
+}
+
+// planIdOf returns the plan ID in the response of a plan action.
+func planIdOf(res model.Response) string {
+	planId, _ := res.Object["id"].(string)
+	return planId
+}
+
+// isPendingApproval checks if the plan in the response of a plan action is pending approval.
+func isPendingApproval(res model.Response) bool {
+	status, _ := res.Object["status"].(string)
+	return status == terrarium.PlanStatusPendingApproval
+}
+
+// ListPlans godoc
+// @Summary List the saved plans of a terrarium
+// @Description List the saved plans of a terrarium in order of their creation time.
+// @Description Each plan has the summary of the resource changes parsed from `tofu show -json`.
+// @Tags [Terrarium] Plan management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.Plan "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/plans [get]
+func ListPlans(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	plans, err := terrarium.ListPlans(trId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list the plans")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	return c.JSON(http.StatusOK, plans)
+}
+
+// GetPlan godoc
+// @Summary Get a saved plan of a terrarium
+// @Description Get a saved plan of a terrarium with the summary of the resource changes
+// @Tags [Terrarium] Plan management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId path string true "Plan ID"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Plan "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/plans/{planId} [get]
+func GetPlan(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	planId := c.Param("planId")
+	if planId == "" {
+		err := fmt.Errorf("invalid request, plan ID (planId: %s) is required", planId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	plan, exists, err := terrarium.GetPlan(trId, planId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the plan")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+	if !exists {
+		err := fmt.Errorf("no plan (trId: %s, planId: %s)", trId, planId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+
+	return c.JSON(http.StatusOK, plan)
+}
+
+// ApprovePlan godoc
+// @Summary Approve a saved plan of a terrarium
+// @Description Approve a saved plan pending approval, so that it can be applied by its plan ID (planId).
+// @Description The approver is the authenticated principal, who must be different from the creator of the plan.
+// @Description Approving a plan is refused if the authentication is disabled.
+// @Description The approval expires after the configured time (TERRARIUM_APPROVAL_EXPIRY_MIN) if the plan is not applied.
+// @Tags [Terrarium] Plan management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId path string true "Plan ID"
+// @Param ReqBody body model.PlanReviewRequest false "Comment"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Plan "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 403 {object} model.Response "Forbidden (e.g., self-approval, or the authentication is disabled)"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., not pending approval, reviewed by another request in the meantime)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/plans/{planId}/approve [post]
+func ApprovePlan(c echo.Context) error {
+	return reviewPlan(c, terrarium.ApprovePlan)
+}
+
+// RejectPlan godoc
+// @Summary Reject a saved plan of a terrarium
+// @Description Reject a saved plan pending approval, so that it cannot be applied.
+// @Description The rejecter is the authenticated principal, and rejecting a plan is refused if the authentication is disabled.
+// @Tags [Terrarium] Plan management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId path string true "Plan ID"
+// @Param ReqBody body model.PlanReviewRequest false "Comment"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Plan "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 403 {object} model.Response "Forbidden (e.g., the authentication is disabled)"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., not pending approval, reviewed by another request in the meantime)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/plans/{planId}/reject [post]
+func RejectPlan(c echo.Context) error {
+	return reviewPlan(c, terrarium.RejectPlan)
+}
+
+// reviewPlan approves or rejects a plan by the given review function.
+func reviewPlan(c echo.Context, review func(trId, planId, reviewer, comment string) (model.Plan, error)) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	planId := c.Param("planId")
+	if planId == "" {
+		err := fmt.Errorf("invalid request, plan ID (planId: %s) is required", planId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	req := new(model.PlanReviewRequest)
+	if err := c.Bind(req); err != nil {
+		err2 := fmt.Errorf("invalid request format, %v", err)
+		log.Warn().Err(err).Msg("invalid request format")
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	// The reviewer is the authenticated principal, so that the creator cannot approve its plan by another name
+	principal, authenticated := middlewares.PrincipalFrom(c.Request().Context())
+	if !authenticated {
+		err := fmt.Errorf("%w, reviewing a plan requires an authenticated principal (the authentication is disabled)", errForbidden)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	plan, err := review(trId, planId, principal.Name, req.Comment)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to review the plan (planId: %s)", planId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, plan)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/middlewares"
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+)
+
+// GetRecoveryReport godoc
+// @Summary Get the report of the recovery at startup
+// @Description Get the report of the recovery at startup, which reconciles the requests and terrariums left running by a previous server process.
+// @Description The requests whose process no longer exists are marked Interrupted and the execution locks of their terrariums are released.
+// @Description The state locks left by the requests are released if force unlock is configured (`terrarium.recovery.force_unlock`).
+// @Description The caller, if not an admin, gets the requests and terrariums of its own credential holder only.
+// @Tags [System] Utility
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} model.RecoveryReport "Report of the recovery"
+// @Failure 404 {object} model.Response
+// @Router /recovery [get]
+func GetRecoveryReport(c echo.Context) error {
+
+	report, exists := terrarium.GetRecoveryReport()
+	if !exists {
+		res := model.Response{Success: false, Message: "no recovery has been performed"}
+		return c.JSON(http.StatusNotFound, res)
+	}
+
+	// The caller, if not an admin, gets the report of its own credential holder only
+	if !middlewares.IsAdmin(c) {
+		report = terrarium.FilterRecoveryReport(report, job.CredentialHolderFrom(c.Request().Context()))
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
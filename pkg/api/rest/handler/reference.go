@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+)
+
+// errInvalidRequestFormat is returned if a request body cannot be bound to a request.
+var errInvalidRequestFormat = errors.New("invalid request format")
+
+// bindWithReferences binds the request body to req after resolving the references to the outputs
+// of the other enrichments in the terrarium (e.g., {"$ref": "testbed.aws_testbed_info.vpc_id"}).
+// It returns the references in the tfvars, which is at tfVarsKey of the body (or the body itself if empty),
+// to be kept by terrarium.SaveReferences after the tfvars are saved.
+func bindWithReferences(c echo.Context, trId, enrichment, tfVarsKey string, req any) ([]model.OutputReference, error) {
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w, failed to read the request body: %w", errInvalidRequestFormat, err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, nil
+	}
+	if !json.Valid(body) {
+		return nil, fmt.Errorf("%w, the request body is not a valid JSON", errInvalidRequestFormat)
+	}
+
+	resolved, refs, err := terrarium.ResolveReferences(trId, enrichment, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(resolved, req); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRequestFormat, err)
+	}
+
+	tfVarsRefs := []model.OutputReference{}
+	for _, ref := range refs {
+		switch {
+		case tfVarsKey == "":
+			tfVarsRefs = append(tfVarsRefs, ref)
+		case strings.HasPrefix(ref.Path, tfVarsKey+"."):
+			ref.Path = strings.TrimPrefix(ref.Path, tfVarsKey+".")
+			tfVarsRefs = append(tfVarsRefs, ref)
+		}
+	}
+	return tfVarsRefs, nil
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2019 The Cloud-Barista Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// requestContext returns the context to execute tofu commands for a request.
+// It keeps the values of the request context but it is not cancelled when the client disconnects,
+// so that a dropped connection does not interrupt a running tofu command (e.g., apply).
+// Use the cancel API to stop a running command.
+func requestContext(c echo.Context) context.Context {
+	return context.WithoutCancel(c.Request().Context())
+}
+
+const (
+	// minRunTimeout and maxRunTimeout bound the timeout of the tofu commands given by a request
+	minRunTimeout = time.Minute
+	maxRunTimeout = 24 * time.Hour
+)
+
+// runContext returns the context to run tofu commands (e.g., plan, apply, destroy, refresh) for a request,
+// which carries the timeout given by the timeout query parameter (e.g., 30m, 2h), if any (see tofu.WithTimeout).
+// Without it, the commands time out by the default (terrarium.tofu.timeout_min).
+func runContext(c echo.Context) (context.Context, error) {
+	ctx := requestContext(c)
+
+	param := c.QueryParam("timeout")
+	if param == "" {
+		return ctx, nil
+	}
+	timeout, err := time.ParseDuration(param)
+	if err != nil || timeout < minRunTimeout || timeout > maxRunTimeout {
+		return nil, fmt.Errorf("%w, invalid timeout (%s), a duration between %s and %s (e.g., 30m, 2h)",
+			errInvalidRequestFormat, param, minRunTimeout, maxRunTimeout)
+	}
+	return tofu.WithTimeout(ctx, timeout), nil
+}
+
+// ListRequests godoc
+// @Summary List the requests of a terrarium
+// @Description List the requests (jobs) processed in a terrarium in order of their start time.
+// @Description Each request records the OpenTofu commands, the enrichment, the start/end time, the exit code, the status and the credential holder.
+// @Tags [Terrarium] Request management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.Job "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/requests [get]
+func ListRequests(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	jobs, err := job.List(trId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list the requests")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	return c.JSON(http.StatusOK, jobs)
+}
+
+// GetRequest godoc
+// @Summary Get a request of a terrarium
+// @Description Get a request (job) processed in a terrarium
+// @Tags [Terrarium] Request management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param reqId path string true "Request ID"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.Job "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/requests/{reqId} [get]
+func GetRequest(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	reqId := c.Param("reqId")
+	if reqId == "" {
+		err := fmt.Errorf("invalid request, request ID (reqId: %s) is required", reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	reqJob, exists, err := job.Get(trId, reqId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the request")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+	if !exists {
+		err := fmt.Errorf("no request (trId: %s, reqId: %s)", trId, reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+
+	return c.JSON(http.StatusOK, reqJob)
+}
+
+// GetRequestLogs godoc
+// @Summary Get the running logs of a request
+// @Description Get the running logs (OpenTofu output) of a request.
+// @Description With follow=true, the logs are streamed while the request is running, and the stream is closed when the request finishes.
+// @Description The logs are streamed as Server-Sent Events if the Accept header includes "text/event-stream", otherwise as chunked plain text.
+// @Tags [Terrarium] Request management
+// @Accept  json
+// @Produce  plain
+// @Produce  text/event-stream
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param reqId path string true "Request ID"
+// @Param follow query boolean false "Stream the logs while the request is running" default(false)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {string} string "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., processed by another host)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/requests/{reqId}/logs [get]
+func GetRequestLogs(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	reqId := c.Param("reqId")
+	if reqId == "" {
+		err := fmt.Errorf("invalid request, request ID (reqId: %s) is required", reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	followParam := strings.ToLower(c.QueryParam("follow"))
+	if followParam != "" && followParam != "true" && followParam != "false" {
+		err := fmt.Errorf("invalid follow value (%s), allowed values: true, false", followParam)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	follow := followParam == "true"
+
+	reqJob, exists, err := job.Get(trId, reqId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the request")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+	if !exists {
+		err := fmt.Errorf("no request (trId: %s, reqId: %s)", trId, reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+
+	// The logs of a request processed by another host sharing the store are not available on this host
+	if err := job.CheckLocal(reqJob); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	sse := strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream")
+
+	w := c.Response()
+	if sse {
+		w.Header().Set(echo.HeaderContentType, "text/event-stream")
+		w.Header().Set(echo.HeaderCacheControl, "no-cache")
+		w.Header().Set(echo.HeaderConnection, "keep-alive")
+	} else {
+		w.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	// Stream the logs until the request finishes or the client disconnects
+	err = job.ReadLog(c.Request().Context(), trId, reqId, follow, func(line string) error {
+		var err error
+		if sse {
+			_, err = fmt.Fprintf(w, "data: %s\n\n", line)
+		} else {
+			_, err = fmt.Fprintln(w, line)
+		}
+		if err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Warn().Err(err).Msgf("stopped streaming the logs (trId: %s, reqId: %s)", trId, reqId)
+		return nil
+	}
+
+	// Notify the end of the logs with the final status of the request
+	if sse {
+		status := ""
+		if reqJob, _, err := job.Get(trId, reqId); err == nil {
+			status = reqJob.Status
+		}
+		fmt.Fprintf(w, "event: end\ndata: %s\n\n", status)
+		w.Flush()
+	}
+
+	return nil
+}
+
+// GetRequestEvents godoc
+// @Summary Get the progress events of a request
+// @Description Get the progress events of a request, which are decoded from the machine-readable UI of OpenTofu
+// @Description (i.e., plan, apply, destroy and refresh with -json).
+// @Description The event types include version, log, diagnostic, planned_change, resource_drift, change_summary, outputs,
+// @Description apply_start, apply_progress, apply_complete, apply_errored, refresh_start and refresh_complete.
+// @Description The per-resource progress and the change summary are available in the progress of the request.
+// @Tags [Terrarium] Request management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param reqId path string true "Request ID"
+// @Param type query string false "Event types to filter (comma-separated, e.g., apply_start,apply_complete)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} uistream.Event "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., processed by another host)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/requests/{reqId}/events [get]
+func GetRequestEvents(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	reqId := c.Param("reqId")
+	if reqId == "" {
+		err := fmt.Errorf("invalid request, request ID (reqId: %s) is required", reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	types := splitQueryParam(c.QueryParam("type"))
+
+	_, exists, err := job.Get(trId, reqId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the request")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+	if !exists {
+		err := fmt.Errorf("no request (trId: %s, reqId: %s)", trId, reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+
+	events, err := job.Events(trId, reqId, types...)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the events of the request")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
+
+// CancelRequest godoc
+// @Summary Cancel a running request
+// @Description Cancel the running OpenTofu command of a request.
+// @Description The command receives SIGINT first to stop gracefully (e.g., release the state lock),
+// @Description and it is killed if it does not stop within the grace period.
+// @Description A request running on another host sharing the store is cancelled by the host only (409 Conflict).
+// @Tags [Terrarium] Request management
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param reqId path string true "Request ID"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 202 {object} model.Response "Accepted"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., processed by another host)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/requests/{reqId}/cancel [post]
+func CancelRequest(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	reqId := c.Param("reqId")
+	if reqId == "" {
+		err := fmt.Errorf("invalid request, request ID (reqId: %s) is required", reqId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	err := tofu.CancelCommand(trId, reqId)
+	if errors.Is(err, tofu.ErrNoRunningCommand) {
+		err2 := fmt.Errorf("no running command for the request (trId: %s, reqId: %s)", trId, reqId)
+		log.Warn().Msg(err2.Error())
+		res := model.Response{Success: false, Message: err2.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to cancel the request")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	res := model.Response{
+		Success: true,
+		Message: fmt.Sprintf("cancellation of the request (reqId: %s) is in progress", reqId),
+	}
+
+	log.Debug().Msgf("%+v", res) // debug
+
+	return c.JSON(http.StatusAccepted, res)
+}
@@ -17,10 +17,16 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/middlewares"
 	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
 	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
 	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -28,6 +34,8 @@ import (
 // IssueTerrarium godoc
 // @Summary Issue/create a terrarium
 // @Description Issue/create a terrarium
+// @Description The terrarium is held by the credential holder of the request (x-credential-holder),
+// @Description which is bound to the authenticated caller in strict mode (terrarium.api.auth.strict_holder), where only admins may name another holder.
 // @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
 // @Accept  json
 // @Produce  json
@@ -46,10 +54,17 @@ func IssueTerrarium(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, res)
 	}
 
-	// * Info: Get the credential profile (holder) from the header
-	credentialHolder := c.Request().Header.Get(model.HeaderXCredentialHolder)
-	if credentialHolder == "" {
-		credentialHolder = "admin"
+	// * Info: The terrarium is held by the credential holder of the request
+	credentialHolder := job.CredentialHolderFrom(c.Request().Context())
+
+	if err := terrarium.ValidateLabels(req.Labels); err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	expiresAt, err := terrarium.LeaseExpiry(req.TTL, req.ExpiresAt)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
 	}
 
 	projectRoot := config.Terrarium.Root
@@ -59,17 +74,20 @@ func IssueTerrarium(c echo.Context) error {
 		Name:              req.Name,
 		Description:       req.Description,
 		Id:                req.Name,
-		Enrichments:       "",
+		Enrichments:       model.Enrichments{},
 		Providers:         []string{},
 		CredentialProfile: credentialHolder,
+		ApprovalRequired:  req.ApprovalRequired,
+		Labels:            req.Labels,
+		ExpiresAt:         expiresAt,
 	}
 	trId := terrariumInfo.Id
 
 	// * Info: Check and issue the terrarium ID
-	err := terrarium.IssueID(*terrariumInfo)
+	err = terrarium.IssueID(*terrariumInfo)
 	if err != nil {
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	// Create the the working directory if it dosen't exist
@@ -101,20 +119,66 @@ func IssueTerrarium(c echo.Context) error {
 
 // ReadAllTerrarium godoc
 // @Summary Read all terrarium
-// @Description Read all terrarium
+// @Description Read all terrarium, which are selected by labels, filtered, sorted and paginated.
+// @Description The label selector is a comma-separated list of the requirements, e.g., "env=staging,team!=network,region in (us-east-1,us-west-2),!deprecated".
+// @Description The filters (enrichment, provider, phase and holder) match any of the comma-separated values.
+// @Description If more terrariums remain, the cursor to the next page is returned by the x-next-cursor header.
+// @Description In strict mode (terrarium.api.auth.strict_holder), a caller other than an admin lists the terrariums of its own credential holder only.
 // @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
 // @Accept  json
 // @Produce  json
+// @Param selector query string false "Label selector (e.g., env=staging,team!=network)"
+// @Param enrichment query string false "Enrichments (comma-separated, e.g., testbed,sql-db)"
+// @Param provider query string false "Providers (comma-separated, e.g., aws,gcp)"
+// @Param phase query string false "Phases (comma-separated, e.g., Ready,Failed)"
+// @Param holder query string false "Credential holders (comma-separated)"
+// @Param sort query string false "Field to sort by, prefixed with '-' in descending order" Enums(id, -id, name, -name, phase, -phase, createdAt, -createdAt) default(id)
+// @Param limit query int false "Maximum number of terrariums in a page (0: all)" default(0)
+// @Param cursor query string false "Cursor to the next page (i.e., x-next-cursor of the previous page)"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {array} model.TerrariumInfo "OK"
+// @Header 200 {string} x-next-cursor "Cursor to the next page (if more terrariums remain)"
 // @Failure 400 {object} model.Response "Bad Request"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr [get]
 func ReadAllTerrarium(c echo.Context) error {
 
-	trInfoList, _ := terrarium.ReadAllInfo()
+	opts := terrarium.ListOptions{
+		Selector:    c.QueryParam("selector"),
+		Enrichments: splitQueryParam(c.QueryParam("enrichment")),
+		Providers:   splitQueryParam(c.QueryParam("provider")),
+		Phases:      splitQueryParam(c.QueryParam("phase")),
+		Holders:     splitQueryParam(c.QueryParam("holder")),
+		Sort:        c.QueryParam("sort"),
+		Cursor:      c.QueryParam("cursor"),
+	}
+	if limit := c.QueryParam("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			err := fmt.Errorf("invalid limit value (%s), it must be a non-negative integer", limit)
+			log.Warn().Msg(err.Error())
+			res := model.Response{Success: false, Message: err.Error()}
+			return c.JSON(http.StatusBadRequest, res)
+		}
+		opts.Limit = n
+	}
 
+	// The caller, if not an admin, lists the terrariums of its own credential holder only
+	if !middlewares.IsAdmin(c) {
+		opts.Holders = []string{job.CredentialHolderFrom(c.Request().Context())}
+	}
+
+	trInfoList, next, err := terrarium.List(opts)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to list the terrariums")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	if next != "" {
+		c.Response().Header().Set(model.HeaderXNextCursor, next)
+	}
 	return c.JSON(http.StatusOK, trInfoList)
 }
 
@@ -154,17 +218,203 @@ func ReadTerrarium(c echo.Context) error {
 	return c.JSON(http.StatusOK, trInfo)
 }
 
+// CloneTerrarium godoc
+// @Summary Clone a terrarium as a new terrarium
+// @Description Clone a terrarium as a new terrarium (e.g., to stand up the same testbed or VPN in another region).
+// @Description The enrichments are copied with their templates and tfvars, but with a fresh, empty state,
+// @Description and the providers of the enrichments are installed (i.e., init).
+// @Description The tfvars of each enrichment (key: enrichment) are overridden by tfVars as a JSON merge patch,
+// @Description e.g., {"testbed": {"aws_region": "ap-northeast-1"}}, and the terrarium_id is set to the new terrarium.
+// @Description The new terrarium is validated (e.g., undeclared variables, required tfvars) before it is saved.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param RequestBody body model.CloneTerrariumRequest true "The new terrarium and the tfvars to override"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 201 {object} model.TerrariumInfo "Created"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., the terrarium already exists)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/clone [post]
+func CloneTerrarium(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		res := model.Response{Success: false, Message: "require the terrarium ID"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	req := new(model.CloneTerrariumRequest)
+	if err := c.Bind(req); err != nil {
+		res := model.Response{Success: false, Message: "failed to bind the request"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	if req.Name == "" {
+		res := model.Response{Success: false, Message: "require the name of the new terrarium"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// * Info: The new terrarium is held by the credential holder of the request
+	credentialHolder := job.CredentialHolderFrom(c.Request().Context())
+
+	trInfo, err := terrarium.Clone(requestContext(c), trId, *req, credentialHolder, reqId)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to clone the terrarium (trId: %s)", trId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	log.Debug().Msgf("%+v", trInfo) // debug
+
+	return c.JSON(http.StatusCreated, trInfo)
+}
+
+// UpdateTerrariumLabels godoc
+// @Summary Update the labels of a terrarium
+// @Description Update (replace) the labels of a terrarium, which are used to select terrariums (e.g., GET /tr?selector=env=staging).
+// @Description A key is 1-63 alphanumeric characters, '-', '_', '.' or '/', and a value is 0-63 alphanumeric characters, '-', '_' or '.',
+// @Description beginning and ending with an alphanumeric. Empty labels remove all the labels.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param RequestBody body model.UpdateLabelsRequest true "Labels of the terrarium"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.TerrariumInfo "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/labels [put]
+func UpdateTerrariumLabels(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		res := model.Response{Success: false, Message: "require the terrarium ID"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	req := new(model.UpdateLabelsRequest)
+	if err := c.Bind(req); err != nil {
+		res := model.Response{Success: false, Message: "failed to bind the request"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	trInfo, err := terrarium.SetLabels(trId, req.Labels)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to update the labels (trId: %s)", trId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, trInfo)
+}
+
+// ExtendTerrariumLease godoc
+// @Summary Extend the lease of a terrarium
+// @Description Extend the lease of a terrarium, after which its resources are destroyed and its environments are emptied out.
+// @Description With ttl, the lease is extended by the duration from the current expiry (or now if expired or not set).
+// @Description With expiresAt, the lease is extended (or shortened) to the time.
+// @Description The expiry is warned again by an event (expiry_warning) within the grace period before the new expiry.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param RequestBody body model.ExtendLeaseRequest true "Duration to extend the lease by (ttl), or the new expiry (expiresAt)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} model.TerrariumInfo "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., expired and being reaped)"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/extend [post]
+func ExtendTerrariumLease(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		res := model.Response{Success: false, Message: "require the terrarium ID"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	req := new(model.ExtendLeaseRequest)
+	if err := c.Bind(req); err != nil {
+		res := model.Response{Success: false, Message: "failed to bind the request"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	trInfo, err := terrarium.ExtendLease(trId, req.TTL, req.ExpiresAt)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to extend the lease (trId: %s)", trId)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	return c.JSON(http.StatusOK, trInfo)
+}
+
+// ListTerrariumEvents godoc
+// @Summary List the events of a terrarium
+// @Description List the events of a terrarium in order of time,
+// @Description e.g., expiry_warning, lease_extended, expired, reaped and reap_failed.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param type query string false "Event types to filter (comma-separated, e.g., expiry_warning,reaped)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.TerrariumEvent "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 503 {object} model.Response "Service Unavailable"
+// @Router /tr/{trId}/events [get]
+func ListTerrariumEvents(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		res := model.Response{Success: false, Message: "require the terrarium ID"}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	events, err := terrarium.ListEvents(trId, splitQueryParam(c.QueryParam("type"))...)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list the events")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
+
 // EraseTerrarium godoc
 // @Summary Erase the entire terrarium including directories and configuration files
 // @Description Erase the entire terrarium including directories and configuration files
+// @Description The state of each enrichment is inspected (i.e., `tofu state list`) before erasing,
+// @Description and it is refused with the list of the resources if any exist, which would be orphaned.
+// @Description With destroy=true, the resources are destroyed first (the enrichments referencing the outputs of another enrichment before it),
+// @Description and the terrarium is erased only if it succeeds.
+// @Description With force=true, the terrarium is erased without inspecting the state (i.e., the resources are orphaned).
+// @Description The terrarium is not erased while a request is running in any enrichment, even with force=true.
 // @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param destroy query boolean false "Destroy the resources before erasing" default(false)
+// @Param force query boolean false "Erase without inspecting the state (orphan the resources)" default(false)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., resources still exist, a request in progress)"
+// @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId} [delete]
 func EraseTerrarium(c echo.Context) error {
@@ -175,6 +425,35 @@ func EraseTerrarium(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, res)
 	}
 
+	// Get the request ID
+	reqId := c.Response().Header().Get("x-request-id")
+
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	forceParam := strings.ToLower(c.QueryParam("force"))
+	if forceParam != "" && forceParam != "true" && forceParam != "false" {
+		err := fmt.Errorf("invalid force value (%s), allowed values: true, false", forceParam)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	force := forceParam == "true"
+
+	destroyParam := strings.ToLower(c.QueryParam("destroy"))
+	if destroyParam != "" && destroyParam != "true" && destroyParam != "false" {
+		err := fmt.Errorf("invalid destroy value (%s), allowed values: true, false", destroyParam)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+	destroy := destroyParam == "true"
+
 	projectRoot := config.Terrarium.Root
 
 	// Check if the working directory exists
@@ -185,7 +464,59 @@ func EraseTerrarium(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, res)
 	}
 
-	err := os.RemoveAll(workingDir)
+	// Check if a command is running in any enrichment, whose working directory must not be removed (even if forced)
+	executing, err := tofu.IsExecuting(trId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to check the execution status of the terrarium")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+	if executing {
+		err := fmt.Errorf("%w (trId: %s), the terrarium is not erased while running", tofu.ErrInProgress, trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Check if resources still exist in the state, which would be orphaned by erasing
+	if !force {
+		resources, err := terrarium.ListLiveResources(requestContext(c), trId, reqId)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to inspect the state of the terrarium")
+			res := model.Response{Success: false, Message: err.Error()}
+			return c.JSON(httpStatusOf(err), res)
+		}
+
+		if len(resources) > 0 && !destroy {
+			err := fmt.Errorf("%w (trId: %s), destroy them first, or erase with destroy=true (or force=true to orphan them)", terrarium.ErrLiveResources, trId)
+			log.Warn().Msg(err.Error())
+			object := map[string]interface{}{}
+			for name, addresses := range resources {
+				object[name] = addresses
+			}
+			res := model.Response{Success: false, Message: err.Error(), Object: object}
+			return c.JSON(httpStatusOf(err), res)
+		}
+
+		// Destroy the resources first (the dependents before their dependencies), and erase only after it succeeds
+		order, err := terrarium.DestroyOrder(trId, sortedKeys(resources))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to order the enrichments to destroy")
+			res := model.Response{Success: false, Message: err.Error()}
+			return c.JSON(httpStatusOf(err), res)
+		}
+		for _, name := range order {
+			_, err := terrarium.Destroy(ctx, trId, name, reqId)
+			if err != nil {
+				err2 := fmt.Errorf("failed to destroy the resources (trId: %s, enrichment: %s), the terrarium is not erased: %w", trId, name, err)
+				log.Error().Err(err).Msg(err2.Error())
+				res := model.Response{Success: false, Message: err2.Error()}
+				return c.JSON(httpStatusOf(err), res)
+			}
+		}
+	}
+
+	err = os.RemoveAll(workingDir)
 	if err != nil {
 		res := model.Response{Success: false, Message: "failed to erase the entire terrarium"}
 		return c.JSON(http.StatusInternalServerError, res)
@@ -197,9 +528,145 @@ func EraseTerrarium(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	err = job.DeleteAll(trId)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to delete the requests of the terrarium (trId: %s)", trId)
+	}
+
+	err = terrarium.DeletePlans(trId)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to delete the plans of the terrarium (trId: %s)", trId)
+	}
+
 	text := fmt.Sprintf("successfully erased the entire terrarium (trId: %v)", trId)
 	res := model.Response{Success: true, Message: text}
 	log.Debug().Msgf("%+v", res) // debug
 
 	return c.JSON(http.StatusOK, res)
 }
+
+// ListTerrariumEnrichments godoc
+// @Summary List the enrichments of a terrarium
+// @Description List the enrichments of a terrarium (e.g., testbed, vpn/site-to-site, sql-db) in order of their names.
+// @Description Each enrichment has its own working directory, state, execution status and providers.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {array} model.EnrichmentInfo "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Router /tr/{trId}/enrichments [get]
+func ListTerrariumEnrichments(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	trInfo, exist, err := terrarium.GetInfo(trId)
+	if !exist {
+		err := fmt.Errorf("no terrarium with the given ID (trId: %s)", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the terrarium information")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	enrichments := []model.EnrichmentInfo{}
+	for _, name := range trInfo.Enrichments.Names() {
+		enrichments = append(enrichments, trInfo.Enrichments[name])
+	}
+
+	return c.JSON(http.StatusOK, enrichments)
+}
+
+// GetTerrariumOutputs godoc
+// @Summary Get the outputs of the enrichments in a terrarium
+// @Description Get the output values of the enrichments stored after they are applied or refreshed (sensitive values are not stored).
+// @Description An enrichment can reference the outputs of another (e.g., a VPN using the VPC IDs of a testbed).
+// @Description The outputs of all the enrichments are returned by enrichment if enrichment is omitted.
+// @Tags [Terrarium] An environment to enrich the multi-cloud infrastructure
+// @Accept  json
+// @Produce  json
+// @Param trId path string true "Terrarium ID" default(tr01)
+// @Param enrichment query string false "Enrichment name (e.g., testbed, vpn/site-to-site, sql-db)"
+// @Param x-request-id header string false "Custom request ID"
+// @Param x-credential-holder header string false "Credential holder (profile) name"
+// @Success 200 {object} map[string]interface{} "OK"
+// @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 500 {object} model.Response "Internal Server Error"
+// @Router /tr/{trId}/outputs [get]
+func GetTerrariumOutputs(c echo.Context) error {
+
+	trId := c.Param("trId")
+	if trId == "" {
+		err := fmt.Errorf("invalid request, terrarium ID (trId: %s) is required", trId)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusBadRequest, res)
+	}
+
+	name := c.QueryParam("enrichment")
+	if name == "" {
+		outputs, err := terrarium.ListOutputs(trId)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to list the outputs")
+			res := model.Response{Success: false, Message: err.Error()}
+			return c.JSON(http.StatusInternalServerError, res)
+		}
+		return c.JSON(http.StatusOK, outputs)
+	}
+
+	_, exist, err := terrarium.GetEnrichment(trId, name)
+	if err != nil {
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusNotFound, res)
+	}
+	if !exist {
+		err := fmt.Errorf("%w (trId: %s, enrichment: %s)", terrarium.ErrEnrichmentNotFound, trId, name)
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	outputs, _, err := terrarium.GetOutputs(trId, name)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get the outputs")
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	return c.JSON(http.StatusOK, outputs)
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitQueryParam splits a comma-separated query parameter into the values.
+func splitQueryParam(param string) []string {
+	values := []string{}
+	for _, v := range strings.Split(param, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -14,6 +14,9 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// testbedEnrichment is the name of the testbed enrichment in a terrarium
+const testbedEnrichment = "testbed"
+
 /*
  * [API - Multi-Cloud Testbed] OpenTofu Actions (for fine-grained control)
  */
@@ -21,6 +24,7 @@ import (
 // InitTestbed godoc
 // @Summary Init testbed
 // @Description Init testbed
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
 // @Tags [Testbed] OpenTofu Actions (for fine-grained control)
 // @Accept json
 // @Produce json
@@ -30,6 +34,7 @@ import (
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed/actions/init [post]
@@ -39,7 +44,7 @@ func InitTestbed(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -60,10 +65,10 @@ func initTestbed(c echo.Context) (model.Response, error) {
 	}
 
 	req := new(model.CreateTestbedRequest)
-	if err := c.Bind(req); err != nil {
-		err2 := fmt.Errorf("invalid request format, %v", err)
-		log.Warn().Err(err).Msg("invalid request format")
-		return emptyRes, err2
+	refs, err := bindWithReferences(c, trId, testbedEnrichment, "testbed_config", req)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
 	}
 	log.Debug().Msgf("%#v", req) // debug
 
@@ -79,34 +84,18 @@ func initTestbed(c echo.Context) (model.Response, error) {
 	reqId := c.Response().Header().Get("x-request-id")
 
 	// Set the enrichments and engaged providers
-	enrichments := "testbed"
+	enrichments := testbedEnrichment
 	providers := req.TestbedConfig.DesiredProviders
 
-	// Check if the terrarium already used for another purpose
-	trInfo, _, err := terrarium.GetInfo(trId)
-	if err != nil {
-		log.Error().Err(err).Msg(err.Error())
-		return emptyRes, err
-	}
-	if trInfo.Enrichments != "" && trInfo.Enrichments != enrichments {
-		err := fmt.Errorf("the terrarium (trId: %s) is already used for another purpose", trId)
-		log.Warn().Msg(err.Error())
-		return emptyRes, err
-	}
-
-	// Set the terrarium information
-	trInfo.Enrichments = enrichments
-	trInfo.Providers = providers
-
-	// Update the terrarium info
-	err = terrarium.UpdateInfo(trInfo)
+	// Create the terrarium environment
+	err = terrarium.CreateEnv(trId, enrichments, providers)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
-	// Create the terrarium environment
-	err = terrarium.CreateEnv(trInfo)
+	// Add the enrichment and engaged providers to the terrarium
+	err = terrarium.AddEnrichment(trId, enrichments, providers)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
@@ -142,8 +131,15 @@ func initTestbed(c echo.Context) (model.Response, error) {
 		return emptyRes, err
 	}
 
+	// Keep the references to the outputs of the other enrichments to resolve them again on update
+	err = terrarium.SaveReferences(trId, enrichments, refs)
+	if err != nil {
+		log.Error().Err(err).Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the init command
-	ret, err := terrarium.Init(trId, reqId)
+	ret, err := terrarium.Init(requestContext(c), trId, testbedEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to initialize an infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -168,14 +164,18 @@ func initTestbed(c echo.Context) (model.Response, error) {
 // PlanTestbed godoc
 // @Summary Plan the testbed
 // @Description Plan the testbed
+// @Description The plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.
+// @Description Use the plan ID (planId) to apply exactly the saved plan.
 // @Tags [Testbed] OpenTofu Actions (for fine-grained control)
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(testbed01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed/actions/plan [post]
@@ -185,7 +185,7 @@ func PlanTestbed(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, ret)
@@ -205,8 +205,15 @@ func planTestbed(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the plan command
-	ret, err := terrarium.Plan(trId, reqId)
+	plan, ret, err := terrarium.Plan(ctx, trId, testbedEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to plan the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -215,8 +222,9 @@ func planTestbed(c echo.Context) (model.Response, error) {
 
 	res := model.Response{
 		Success: true,
-		Message: "successfully planned the infrastructure terrarium",
+		Message: fmt.Sprintf("successfully planned the infrastructure terrarium (planId: %s)", plan.Id),
 		Detail:  ret,
+		Object:  planObject(plan),
 	}
 
 	log.Debug().Msgf("%+v", res) // debug
@@ -231,26 +239,30 @@ func planTestbed(c echo.Context) (model.Response, error) {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(testbed01)
+// @Param planId query string false "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., stale plan, not initialized)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed/actions/apply [post]
 func ApplyTestbed(c echo.Context) error {
 
-	res, err := applyTestbed(c)
+	res, err := applyTestbed(c, c.QueryParam("planId"))
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
 }
 
-func applyTestbed(c echo.Context) (model.Response, error) {
+func applyTestbed(c echo.Context, planId string) (model.Response, error) {
 
 	emptyRes := model.Response{}
 
@@ -264,8 +276,15 @@ func applyTestbed(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the apply command
-	ret, err := terrarium.Apply(trId, reqId)
+	ret, err := terrarium.Apply(ctx, trId, testbedEnrichment, reqId, planId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to apply the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -290,10 +309,12 @@ func applyTestbed(c echo.Context) (model.Response, error) {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(testbed01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed/actions/destroy [delete]
@@ -303,7 +324,7 @@ func DestroyTestbed(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -323,8 +344,15 @@ func destroyTestbed(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the destroy command
-	ret, err := terrarium.Destroy(trId, reqId)
+	ret, err := terrarium.Destroy(ctx, trId, testbedEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to destroy the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -351,6 +379,7 @@ func destroyTestbed(c echo.Context) (model.Response, error) {
 // @Param trId path string true "Terrarium ID" default(testbed01)
 // @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -364,7 +393,7 @@ func OutputTestbed(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -408,6 +437,13 @@ func outputTestbed(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Refresh the state to sync with the current CSP status (default: true)
 	refreshParam := strings.ToLower(c.QueryParam("refresh"))
 	if refreshParam != "" && refreshParam != "true" && refreshParam != "false" {
@@ -417,7 +453,7 @@ func outputTestbed(c echo.Context) (model.Response, error) {
 	}
 	if refreshParam != "false" {
 		log.Info().Msgf("Refreshing state for terrarium (trId: %s) to sync with CSP", trId)
-		_, err := terrarium.Refresh(trId, reqId)
+		_, err := terrarium.Refresh(ctx, trId, testbedEnrichment, reqId)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to refresh state, proceeding with cached state")
 		}
@@ -431,21 +467,21 @@ func outputTestbed(c echo.Context) (model.Response, error) {
 		 * NOTE: Set the output object (e.g., "testbed_info") to get the refined resource info
 		 */
 
-		trInfo, exists, err := terrarium.GetInfo(trId)
+		enrichment, exists, err := terrarium.GetEnrichment(trId, testbedEnrichment)
 		if err != nil {
 			log.Error().Err(err).Msg(err.Error())
 			return emptyRes, err
 		}
 		if !exists {
-			err2 := fmt.Errorf("no terrarium with the given ID (trId: %s)", trId)
+			err2 := fmt.Errorf("no enrichment (%s) in the terrarium (trId: %s)", testbedEnrichment, trId)
 			log.Warn().Msg(err2.Error())
 			return emptyRes, err2
 		}
 
 		// In-parallel, retrieve and merge the resource info
-		providers := trInfo.Providers
+		providers := enrichment.Providers
 		if len(providers) == 0 {
-			err2 := fmt.Errorf("no providers in the terrarium (trId: %s)", trId)
+			err2 := fmt.Errorf("no providers in the enrichment (%s) of the terrarium (trId: %s)", testbedEnrichment, trId)
 			log.Warn().Msg(err2.Error())
 			return emptyRes, err2
 		}
@@ -466,7 +502,7 @@ func outputTestbed(c echo.Context) (model.Response, error) {
 				defer wg.Done()
 				// Retrieve provider's resource info
 				targetObject := fmt.Sprintf("%s_testbed_info", provider)
-				resourceInfo, err := terrarium.Output(trId, reqId, targetObject, "-json")
+				resourceInfo, err := terrarium.Output(requestContext(c), trId, testbedEnrichment, reqId, targetObject, "-json")
 				if err != nil {
 					results <- resultWithError{
 						provider: provider,
@@ -523,7 +559,7 @@ func outputTestbed(c echo.Context) (model.Response, error) {
 	case DetailOptions.Raw:
 
 		// Execute the show command
-		ret, err := terrarium.Show(trId, reqId, "-json")
+		ret, err := terrarium.Show(requestContext(c), trId, testbedEnrichment, reqId, "-json")
 		if err != nil {
 			err2 := fmt.Errorf("failed to show the infrastructure terrarium")
 			log.Error().Err(err).Msg(err2.Error())
@@ -599,6 +635,7 @@ func outputTestbed(c echo.Context) (model.Response, error) {
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed/actions/emptyout [delete]
@@ -608,7 +645,7 @@ func EmptyOutTestbed(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -625,32 +662,32 @@ func emptyOutTestbed(c echo.Context) (model.Response, error) {
 		return emptyRes, err
 	}
 
-	enrichments := "testbed"
+	enrichments := testbedEnrichment
 
-	existingEnrichments, exist, err := terrarium.GetEnrichments(trId)
+	_, exist, err := terrarium.GetEnrichment(trId, enrichments)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
-	if !exist || existingEnrichments != enrichments {
+	if !exist {
 		err := fmt.Errorf("the terrarium (trId: %s) is not used for the testbed", trId)
 		log.Warn().Msg(err.Error())
 		return emptyRes, err
 	}
 
 	// Execute the emptyout command
-	err = terrarium.EmptyOutTerrariumEnv(trId)
+	err = terrarium.EmptyOutTerrariumEnv(trId, enrichments)
 	if err != nil {
-		err2 := fmt.Errorf("failed to empty out the infrastructure terrarium")
+		err2 := fmt.Errorf("failed to empty out the infrastructure terrarium: %w", err)
 		log.Error().Err(err).Msg(err2.Error())
 		return emptyRes, err2
 	}
 
-	// Unset the enrichments
-	err = terrarium.SetEnrichments(trId, "")
+	// Remove the enrichment from the terrarium
+	err = terrarium.RemoveEnrichment(trId, enrichments)
 	if err != nil {
-		err2 := fmt.Errorf("failed to unset the enrichments")
+		err2 := fmt.Errorf("failed to remove the enrichment")
 		log.Error().Err(err).Msg(err2.Error())
 		return emptyRes, err2
 	}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -14,15 +15,19 @@ import (
 // CreateTestbed godoc
 // @Summary Create the testbed
 // @Description Create the testbed
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
 // @Tags [Testbed] Resource Operations
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(testbed01)
 // @Param ReqBody body model.CreateTestbedRequest true "Parameters requied to create a testbed"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
+// @Success 202 {object} model.Response "Accepted (the plan is pending approval, apply it by its plan ID after approval)"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed [post]
@@ -33,22 +38,38 @@ func CreateTestbed(c echo.Context) error {
 	// 2. Plan
 	// 3. Apply
 
+	// Validate the timeout of the plan and apply before initializing
+	if _, err := runContext(c); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	res, err := initTestbed(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	res, err = planTestbed(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Wait for the approval of the plan if the terrarium requires it
+	if isPendingApproval(res) {
+		return c.JSON(http.StatusAccepted, res)
 	}
 
-	res, err = applyTestbed(c)
+	// Apply exactly the plan above
+	res, err = applyTestbed(c, planIdOf(res))
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -63,6 +84,7 @@ func CreateTestbed(c echo.Context) error {
 // @Param trId path string true "Terrarium ID" default(testbed01)
 // @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -78,7 +100,8 @@ func GetTestbed(c echo.Context) error {
 	res, err := outputTestbed(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -91,10 +114,12 @@ func GetTestbed(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(testbed01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/testbed [delete]
@@ -106,7 +131,8 @@ func DeleteTestbed(c echo.Context) error {
 	res, err := destroyTestbed(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	// res, err = cleanTestbed(c)
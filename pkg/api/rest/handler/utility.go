@@ -76,7 +76,7 @@ func HTTPVersion(c echo.Context) error {
 // @Router /tofuVersion [get]
 func TofuVersion(c echo.Context) error {
 
-	ret, err := tfutil.Version()
+	ret, err := tfutil.Version(c.Request().Context())
 	if err != nil {
 		res := model.Response{Success: false, Message: "failed to get Tofu version"}
 		return c.JSON(http.StatusInternalServerError, res)
@@ -14,6 +14,9 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// awsToSiteVpnEnrichment is the name of the AWS to site VPN enrichment in a terrarium
+const awsToSiteVpnEnrichment = "vpn/aws-to-site"
+
 /*
  * [API - AWS to Site VPN] OpenTofu Actions (for fine-grained control)
  */
@@ -21,6 +24,7 @@ import (
 // InitAwsToSiteVpn godoc
 // @Summary Init AWS to site VPN
 // @Description Init AWS to site VPN
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
 // @Tags [AWS to site VPN] OpenTofu Actions (for fine-grained control)
 // @Accept json
 // @Produce json
@@ -30,6 +34,7 @@ import (
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site/actions/init [post]
@@ -39,7 +44,7 @@ func InitAwsToSiteVpn(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -60,10 +65,10 @@ func initAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	}
 
 	req := new(model.CreateAwsToSiteVpnRequest)
-	if err := c.Bind(req); err != nil {
-		err2 := fmt.Errorf("invalid request format, %v", err)
-		log.Warn().Err(err).Msg("invalid request format")
-		return emptyRes, err2
+	refs, err := bindWithReferences(c, trId, awsToSiteVpnEnrichment, "", req)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
 	}
 	log.Debug().Msgf("%#v", req) // debug
 
@@ -84,48 +89,39 @@ func initAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	reqId := c.Response().Header().Get("x-request-id")
 
 	// Set the enrichments
-	enrichments := "vpn/aws-to-site"
+	enrichments := awsToSiteVpnEnrichment
 	providers := []string{"aws", req.VpnConfig.TargetCsp.Type}
 
-	// Check if the terrarium is already used for another purpose
-	trInfo, _, err := terrarium.GetInfo(trId)
+	// Create the terrarium environment
+	err = terrarium.CreateEnv(trId, enrichments, providers)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
-	if trInfo.Enrichments != "" && trInfo.Enrichments != enrichments {
-		err := fmt.Errorf("the terrarium (trId: %s) is already used for another purpose", trId)
-		log.Warn().Msg(err.Error())
-		return emptyRes, err
-	}
 
-	// Set the terrarium information
-	trInfo.Enrichments = enrichments
-	trInfo.Providers = providers
-
-	// Update the terrarium info
-	err = terrarium.UpdateInfo(trInfo)
+	// Add the enrichment and engaged providers to the terrarium
+	err = terrarium.AddEnrichment(trId, enrichments, providers)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
-	// Create the terrarium environment
-	err = terrarium.CreateEnv(trInfo)
+	// Set the tfvars
+	err = terrarium.SaveTfVars(trId, enrichments, req)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
-	// Set the tfvars
-	err = terrarium.SaveTfVars(trId, enrichments, req)
+	// Keep the references to the outputs of the other enrichments to resolve them again on update
+	err = terrarium.SaveReferences(trId, enrichments, refs)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
 	// Execute the init command
-	ret, err := terrarium.Init(trId, reqId)
+	ret, err := terrarium.Init(requestContext(c), trId, awsToSiteVpnEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to initialize an infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -150,14 +146,18 @@ func initAwsToSiteVpn(c echo.Context) (model.Response, error) {
 // PlanAwsToSiteVpn godoc
 // @Summary Plan AWS to site VPN
 // @Description Plan AWS to site VPN
+// @Description The plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.
+// @Description Use the plan ID (planId) to apply exactly the saved plan.
 // @Tags [AWS to site VPN] OpenTofu Actions (for fine-grained control)
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site/actions/plan [post]
@@ -167,7 +167,7 @@ func PlanAwsToSiteVpn(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, ret)
@@ -187,8 +187,15 @@ func planAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the plan command
-	ret, err := terrarium.Plan(trId, reqId)
+	plan, ret, err := terrarium.Plan(ctx, trId, awsToSiteVpnEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to plan the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -197,8 +204,9 @@ func planAwsToSiteVpn(c echo.Context) (model.Response, error) {
 
 	res := model.Response{
 		Success: true,
-		Message: "successfully planned the infrastructure terrarium",
+		Message: fmt.Sprintf("successfully planned the infrastructure terrarium (planId: %s)", plan.Id),
 		Detail:  ret,
+		Object:  planObject(plan),
 	}
 
 	log.Debug().Msgf("%+v", res) // debug
@@ -213,26 +221,30 @@ func planAwsToSiteVpn(c echo.Context) (model.Response, error) {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId query string false "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., stale plan, not initialized)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site/actions/apply [post]
 func ApplyAwsToSiteVpn(c echo.Context) error {
 
-	res, err := applyAwsToSiteVpn(c)
+	res, err := applyAwsToSiteVpn(c, c.QueryParam("planId"))
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
 }
 
-func applyAwsToSiteVpn(c echo.Context) (model.Response, error) {
+func applyAwsToSiteVpn(c echo.Context, planId string) (model.Response, error) {
 
 	emptyRes := model.Response{}
 
@@ -246,8 +258,15 @@ func applyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the apply command
-	ret, err := terrarium.Apply(trId, reqId)
+	ret, err := terrarium.Apply(ctx, trId, awsToSiteVpnEnrichment, reqId, planId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to apply the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -272,10 +291,12 @@ func applyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site/actions/destroy [delete]
@@ -285,7 +306,7 @@ func DestroyAwsToSiteVpn(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -305,8 +326,12 @@ func destroyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
-	// Define err variable to track errors
-	var err error
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
 
 	// Add a deferred function to refresh state if an error occurs
 	/*
@@ -314,7 +339,7 @@ func destroyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 			log.Info().Msg("recover imports.tf and refresh state for the next destroy request")
 
 			// Recover the imports.tf
-			recoverErr := terrarium.RecoverImportTf(trId)
+			recoverErr := terrarium.RecoverImportTf(trId, awsToSiteVpnEnrichment)
 			if recoverErr != nil {
 				log.Error().Err(recoverErr).Msg("Failed to recover imports.tf")
 			} else {
@@ -322,7 +347,7 @@ func destroyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 			}
 
 			// Refresh the state
-			_, refreshErr := terrarium.Refresh(trId, reqId)
+			_, refreshErr := terrarium.Refresh(ctx, trId, awsToSiteVpnEnrichment, reqId)
 			if refreshErr != nil {
 				log.Error().Err(refreshErr).Msg("Failed to refresh state after error")
 			} else {
@@ -331,7 +356,7 @@ func destroyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 		}()
 
 		// Detach the imported route table for preventing to destroy the imported resource
-		err = terrarium.DetachImportedResource(trId, reqId, "aws_route_table.imported_route_table")
+		err = terrarium.DetachImportedResource(requestContext(c), trId, awsToSiteVpnEnrichment, reqId, "aws_route_table.imported_route_table")
 		if err != nil {
 			err2 := fmt.Errorf("failed to remove the imported route table")
 			log.Error().Err(err).Msg(err2.Error())
@@ -341,7 +366,7 @@ func destroyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 
 	// Execute the destroy command
 	var ret string
-	ret, err = terrarium.Destroy(trId, reqId)
+	ret, err = terrarium.Destroy(ctx, trId, awsToSiteVpnEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to destroy the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -368,6 +393,7 @@ func destroyAwsToSiteVpn(c echo.Context) (model.Response, error) {
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -381,7 +407,7 @@ func OutputAwsToSiteVpn(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -425,6 +451,13 @@ func outputAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Refresh the state to sync with the current CSP status (default: true)
 	refreshParam := strings.ToLower(c.QueryParam("refresh"))
 	if refreshParam != "" && refreshParam != "true" && refreshParam != "false" {
@@ -434,7 +467,7 @@ func outputAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	}
 	if refreshParam != "false" {
 		log.Info().Msgf("Refreshing state for terrarium (trId: %s) to sync with CSP", trId)
-		_, err := terrarium.Refresh(trId, reqId)
+		_, err := terrarium.Refresh(ctx, trId, awsToSiteVpnEnrichment, reqId)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to refresh state, proceeding with cached state")
 		}
@@ -448,21 +481,21 @@ func outputAwsToSiteVpn(c echo.Context) (model.Response, error) {
 		 * NOTE: Set the output object (e.g., "vpn_info") to get the refined resource info
 		 */
 
-		trInfo, exists, err := terrarium.GetInfo(trId)
+		enrichment, exists, err := terrarium.GetEnrichment(trId, awsToSiteVpnEnrichment)
 		if err != nil {
 			log.Error().Err(err).Msg(err.Error())
 			return emptyRes, err
 		}
 		if !exists {
-			err2 := fmt.Errorf("no terrarium with the given ID (trId: %s)", trId)
+			err2 := fmt.Errorf("no enrichment (%s) in the terrarium (trId: %s)", awsToSiteVpnEnrichment, trId)
 			log.Warn().Msg(err2.Error())
 			return emptyRes, err2
 		}
 
 		// In-parallel, retrieve and merge the resource info
-		providers := trInfo.Providers
+		providers := enrichment.Providers
 		if len(providers) == 0 {
-			err2 := fmt.Errorf("no providers in the terrarium (trId: %s)", trId)
+			err2 := fmt.Errorf("no providers in the enrichment (%s) of the terrarium (trId: %s)", awsToSiteVpnEnrichment, trId)
 			log.Warn().Msg(err2.Error())
 			return emptyRes, err2
 		}
@@ -482,7 +515,7 @@ func outputAwsToSiteVpn(c echo.Context) (model.Response, error) {
 				defer wg.Done()
 				// Retrieve provider's resource info
 				targetObject := fmt.Sprintf("%s_vpn_info", provider)
-				resourceInfo, err := terrarium.Output(trId, reqId, targetObject, "-json")
+				resourceInfo, err := terrarium.Output(requestContext(c), trId, awsToSiteVpnEnrichment, reqId, targetObject, "-json")
 				if err != nil {
 					results <- resultWithError{
 						provider: provider,
@@ -550,7 +583,7 @@ func outputAwsToSiteVpn(c echo.Context) (model.Response, error) {
 	case DetailOptions.Raw:
 
 		// Execute the show command
-		ret, err := terrarium.Show(trId, reqId, "-json")
+		ret, err := terrarium.Show(requestContext(c), trId, awsToSiteVpnEnrichment, reqId, "-json")
 		if err != nil {
 			err2 := fmt.Errorf("failed to show the infrastructure terrarium")
 			log.Error().Err(err).Msg(err2.Error())
@@ -669,6 +702,7 @@ func mergeResourceInfo(existing, new map[string]any) {
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site/actions/emptyout [delete]
@@ -678,7 +712,7 @@ func EmptyOutAwsToSiteVpn(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -695,32 +729,32 @@ func emptyOutAwsToSiteVpn(c echo.Context) (model.Response, error) {
 		return emptyRes, err
 	}
 
-	enrichments := "vpn/aws-to-site"
+	enrichments := awsToSiteVpnEnrichment
 
-	existingEnrichments, exist, err := terrarium.GetEnrichments(trId)
+	_, exist, err := terrarium.GetEnrichment(trId, enrichments)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
-	if !exist || existingEnrichments != enrichments {
-		err := fmt.Errorf("the terrarium (trId: %s) is used for the other purpose (%s)", trId, existingEnrichments)
+	if !exist {
+		err := fmt.Errorf("the terrarium (trId: %s) is not used for the AWS to site VPN", trId)
 		log.Warn().Msg(err.Error())
 		return emptyRes, err
 	}
 
 	// Execute the emptyout command
-	err = terrarium.EmptyOutTerrariumEnv(trId)
+	err = terrarium.EmptyOutTerrariumEnv(trId, enrichments)
 	if err != nil {
-		err2 := fmt.Errorf("failed to empty out the infrastructure terrarium")
+		err2 := fmt.Errorf("failed to empty out the infrastructure terrarium: %w", err)
 		log.Error().Err(err).Msg(err2.Error())
 		return emptyRes, err2
 	}
 
-	// Unset the enrichments
-	err = terrarium.SetEnrichments(trId, "")
+	// Remove the enrichment from the terrarium
+	err = terrarium.RemoveEnrichment(trId, enrichments)
 	if err != nil {
-		err2 := fmt.Errorf("failed to unset the enrichments")
+		err2 := fmt.Errorf("failed to remove the enrichment")
 		log.Error().Err(err).Msg(err2.Error())
 		return emptyRes, err2
 	}
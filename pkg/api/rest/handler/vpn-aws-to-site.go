@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -14,15 +15,19 @@ import (
 // CreateAwsToSiteVpn godoc
 // @Summary Create AWS to site VPN
 // @Description Create AWS to site VPN
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
 // @Tags [AWS to site VPN] Resource Operations
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param ReqBody body model.CreateAwsToSiteVpnRequest true "Parameters requied to create the AWS to site VPN"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
+// @Success 202 {object} model.Response "Accepted (the plan is pending approval, apply it by its plan ID after approval)"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site [post]
@@ -33,22 +38,38 @@ func CreateAwsToSiteVpn(c echo.Context) error {
 	// 2. Plan
 	// 3. Apply
 
+	// Validate the timeout of the plan and apply before initializing
+	if _, err := runContext(c); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	res, err := initAwsToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	res, err = planAwsToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Wait for the approval of the plan if the terrarium requires it
+	if isPendingApproval(res) {
+		return c.JSON(http.StatusAccepted, res)
 	}
 
-	res, err = applyAwsToSiteVpn(c)
+	// Apply exactly the plan above
+	res, err = applyAwsToSiteVpn(c, planIdOf(res))
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -63,6 +84,7 @@ func CreateAwsToSiteVpn(c echo.Context) error {
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -78,7 +100,8 @@ func GetAwsToSiteVpn(c echo.Context) error {
 	res, err := outputAwsToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -91,10 +114,12 @@ func GetAwsToSiteVpn(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/aws-to-site [delete]
@@ -107,13 +132,15 @@ func DeleteAwsToSiteVpn(c echo.Context) error {
 	res, err := destroyAwsToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	res, err = emptyOutAwsToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
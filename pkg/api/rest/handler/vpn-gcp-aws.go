@@ -30,6 +30,9 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// gcpAwsVpnEnrichment is the name of the GCP to AWS VPN enrichment in a terrarium
+const gcpAwsVpnEnrichment = "vpn/gcp-aws"
+
 // ////////////////////////////////////////////////////
 // GCP and AWS
 
@@ -64,19 +67,10 @@ func InitEnvForGcpAwsVpn(c echo.Context) error {
 	reqId := c.Response().Header().Get("x-request-id")
 
 	// Set the enrichments
-	enrichments := "vpn/gcp-aws"
-
-	// Read and set the enrichments to terrarium information
-	trInfo, _, err := terrarium.GetInfo(trId)
-	if err != nil {
-		err2 := fmt.Errorf("failed to read terrarium information")
-		log.Error().Err(err).Msg(err2.Error())
-		res := model.Response{Success: false, Message: err2.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
-	}
+	enrichments := gcpAwsVpnEnrichment
 
-	trInfo.Enrichments = enrichments
-	err = terrarium.UpdateInfo(trInfo)
+	// Add the enrichment to terrarium information
+	err := terrarium.AddEnrichment(trId, enrichments, []string{"gcp", "aws"})
 	if err != nil {
 		err2 := fmt.Errorf("failed to update terrarium information")
 		log.Error().Err(err).Msg(err2.Error())
@@ -110,9 +104,8 @@ func InitEnvForGcpAwsVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
-	// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-aws
-	// init: subcommand
-	ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "init")
+	// Execute the init command
+	ret, err := terrarium.Init(requestContext(c), trId, enrichments, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to initialize an infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -120,7 +113,7 @@ func InitEnvForGcpAwsVpn(c echo.Context) error {
 			Success: false,
 			Message: err2.Error(),
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 	res := model.Response{
 		Success: true,
@@ -144,6 +137,7 @@ func InitEnvForGcpAwsVpn(c echo.Context) error {
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., resources not destroyed)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/gcp-aws/env [delete]
@@ -174,6 +168,16 @@ func ClearGcpAwsVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	// Check if resources may exist (i.e., not destroyed)
+	if err := terrarium.CheckOperation(trId, gcpAwsVpnEnrichment, terrarium.OpEmptyOut); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{
+			Success: false,
+			Message: err.Error(),
+		}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	err := os.RemoveAll(workingDir)
 	if err != nil {
 		err2 := fmt.Errorf("failed to remove working directory and all configuration files")
@@ -185,6 +189,12 @@ func ClearGcpAwsVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	// Remove the enrichment from terrarium information
+	err = terrarium.RemoveEnrichment(trId, gcpAwsVpnEnrichment)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to remove the enrichment (trId: %s)", trId)
+	}
+
 	text := "successfully remove all in the working directory"
 	res := model.Response{
 		Success: true,
@@ -204,6 +214,7 @@ func ClearGcpAwsVpn(c echo.Context) error {
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param detail query string false "Resource info by detail (refined, raw)" default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -251,6 +262,14 @@ func GetResourceInfoOfGcpAwsVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	// Refresh the state to sync with the current CSP status (default: true)
 	refreshParam := strings.ToLower(c.QueryParam("refresh"))
 	if refreshParam != "" && refreshParam != "true" && refreshParam != "false" {
@@ -261,7 +280,7 @@ func GetResourceInfoOfGcpAwsVpn(c echo.Context) error {
 	}
 	if refreshParam != "false" {
 		log.Info().Msgf("Refreshing state for terrarium (trId: %s) to sync with CSP", trId)
-		_, err := terrarium.Refresh(trId, reqId)
+		_, err := terrarium.Refresh(ctx, trId, gcpAwsVpnEnrichment, reqId)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to refresh state, proceeding with cached state")
 		}
@@ -288,7 +307,7 @@ func GetResourceInfoOfGcpAwsVpn(c echo.Context) error {
 
 		// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-aws
 		// show: subcommand
-		ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "output", "-json", "vpn_info")
+		ret, err := tofu.ExecuteCommand(requestContext(c), trId, reqId, "-chdir="+workingDir, "output", "-json", "vpn_info")
 		if err != nil {
 			err2 := fmt.Errorf("failed to read resource info (detail: %s) specified as 'output' in the state file", DetailOptions.Refined)
 			log.Error().Err(err).Msg(err2.Error())
@@ -325,7 +344,7 @@ func GetResourceInfoOfGcpAwsVpn(c echo.Context) error {
 		// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-aws
 		// show: subcommand
 		// Get resource info from the state or plan file
-		ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "show", "-json")
+		ret, err := tofu.ExecuteCommand(requestContext(c), trId, reqId, "-chdir="+workingDir, "show", "-json")
 		if err != nil {
 			err2 := fmt.Errorf("failed to read resource info (detail: %s) from the state or plan file", DetailOptions.Raw)
 			log.Error().Err(err).Msg(err2.Error()) // error
@@ -420,17 +439,8 @@ func CreateInfracodeOfGcpAwsVpn(c echo.Context) error {
 
 	projectRoot := config.Terrarium.Root
 
-	// Read and set the enrichments to terrarium information
-	trInfo, _, err := terrarium.GetInfo(trId)
-	if err != nil {
-		err2 := fmt.Errorf("failed to read terrarium information")
-		log.Error().Err(err).Msg(err2.Error())
-		res := model.Response{Success: false, Message: err2.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
-	}
-
 	// Check if the working directory exists
-	workingDir := projectRoot + "/.terrarium/" + trId + "/" + trInfo.Enrichments
+	workingDir := projectRoot + "/.terrarium/" + trId + "/" + gcpAwsVpnEnrichment
 	if _, err := os.Stat(workingDir); os.IsNotExist(err) {
 		err2 := fmt.Errorf("working directory dose not exist")
 		log.Warn().Err(err).Msg(err2.Error())
@@ -446,7 +456,7 @@ func CreateInfracodeOfGcpAwsVpn(c echo.Context) error {
 		req.TfVars.TerrariumId = trId
 	}
 
-	err = terrarium.SaveTfVars(trId, trInfo.Enrichments, req.TfVars)
+	err := terrarium.SaveTfVars(trId, gcpAwsVpnEnrichment, req.TfVars)
 	if err != nil {
 		err2 := fmt.Errorf("failed to save tfVars to a file")
 		log.Error().Err(err).Msg(err2.Error())
@@ -470,10 +480,13 @@ func CreateInfracodeOfGcpAwsVpn(c echo.Context) error {
 // CheckInfracodeOfGcpAwsVpn godoc
 // @Summary Check and show changes by the current infracode to configure GCP to AWS VPN tunnels
 // @Description Check and show changes by the current infracode to configure GCP to AWS VPN tunnels
+// @Description The plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.
+// @Description Use the plan ID (planId) to apply exactly the saved plan.
 // @Tags [VPN] GCP to AWS VPN tunnel configuration (PoC - Not officially supported)
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -497,8 +510,16 @@ func CheckInfracodeOfGcpAwsVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	// Execute the plan command
-	ret, err := terrarium.Plan(trId, reqId)
+	plan, ret, err := terrarium.Plan(ctx, trId, gcpAwsVpnEnrichment, reqId)
 	if err != nil {
 		log.Error().Err(err).Msg("") // error
 		res := model.Response{
@@ -512,8 +533,9 @@ func CheckInfracodeOfGcpAwsVpn(c echo.Context) error {
 	// Return the result
 	res := model.Response{
 		Success: true,
-		Message: "successfully completed infrastructure code check",
+		Message: fmt.Sprintf("successfully completed infrastructure code check (planId: %s)", plan.Id),
 		Detail:  ret,
+		Object:  planObject(plan),
 	}
 
 	log.Debug().Msgf("%+v", res) // debug
@@ -528,10 +550,14 @@ func CheckInfracodeOfGcpAwsVpn(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId query string false "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., stale plan, not initialized)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/gcp-aws [post]
@@ -551,8 +577,19 @@ func CreateGcpAwsVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Get the plan ID to apply exactly the saved plan
+	planId := c.QueryParam("planId")
+
 	// Excute the apply command
-	ret, err := terrarium.Apply(trId, reqId)
+	ret, err := terrarium.Apply(ctx, trId, gcpAwsVpnEnrichment, reqId, planId)
 	if err != nil {
 		log.Error().Err(err).Msg("") // error
 		res := model.Response{
@@ -560,7 +597,7 @@ func CreateGcpAwsVpn(c echo.Context) error {
 			Message: err.Error(),
 			Detail:  ret,
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	// Return the result
@@ -582,6 +619,7 @@ func CreateGcpAwsVpn(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -606,6 +644,14 @@ func DestroyGcpAwsVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	projectRoot := config.Terrarium.Root
 
 	// Check if the working directory exists
@@ -621,7 +667,7 @@ func DestroyGcpAwsVpn(c echo.Context) error {
 	}
 
 	// Excute the destroy command
-	ret, err := terrarium.Destroy(trId, reqId)
+	ret, err := terrarium.Destroy(ctx, trId, gcpAwsVpnEnrichment, reqId)
 	if err != nil {
 		log.Error().Err(err).Msg("") // error
 		res := model.Response{
@@ -697,7 +743,7 @@ func GetRequestStatusOfGcpAwsVpn(c echo.Context) error {
 	statusLogFile := fmt.Sprintf("%s/runningLogs/%s.log", workingDir, reqId)
 
 	// Check the executionHistory of the request
-	executionHistory, err := tofu.GetExcutionHistory(trId, statusLogFile)
+	executionHistory, err := tofu.GetExcutionHistory(trId, reqId, statusLogFile)
 	if err != nil {
 		err2 := fmt.Errorf("failed to get the status of the request")
 		log.Error().Err(err).Msg(err2.Error()) // error
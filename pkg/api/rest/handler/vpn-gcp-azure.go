@@ -30,6 +30,9 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// gcpAzureVpnEnrichment is the name of the GCP to Azure VPN enrichment in a terrarium
+const gcpAzureVpnEnrichment = "vpn/gcp-azure"
+
 // ////////////////////////////////////////////////////
 // GCP and Azure
 
@@ -64,19 +67,10 @@ func InitEnvForGcpAzureVpn(c echo.Context) error {
 	reqId := c.Response().Header().Get("x-request-id")
 
 	// Set the enrichments
-	enrichments := "vpn/gcp-azure"
-
-	// Read and set the enrichments to terrarium information
-	trInfo, _, err := terrarium.GetInfo(trId)
-	if err != nil {
-		err2 := fmt.Errorf("failed to read terrarium information")
-		log.Error().Err(err).Msg(err2.Error())
-		res := model.Response{Success: false, Message: err2.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
-	}
+	enrichments := gcpAzureVpnEnrichment
 
-	trInfo.Enrichments = enrichments
-	err = terrarium.UpdateInfo(trInfo)
+	// Add the enrichment to terrarium information
+	err := terrarium.AddEnrichment(trId, enrichments, []string{"gcp", "azure"})
 	if err != nil {
 		err2 := fmt.Errorf("failed to update terrarium information")
 		log.Error().Err(err).Msg(err2.Error())
@@ -113,9 +107,8 @@ func InitEnvForGcpAzureVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
-	// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-azure
-	// init: subcommand
-	ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "init")
+	// Execute the init command
+	ret, err := terrarium.Init(requestContext(c), trId, enrichments, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to initialize an infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -123,7 +116,7 @@ func InitEnvForGcpAzureVpn(c echo.Context) error {
 			Success: false,
 			Message: err2.Error(),
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 	res := model.Response{
 		Success: true,
@@ -147,6 +140,7 @@ func InitEnvForGcpAzureVpn(c echo.Context) error {
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., resources not destroyed)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/gcp-azure/env [delete]
@@ -177,6 +171,16 @@ func ClearGcpAzureVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	// Check if resources may exist (i.e., not destroyed)
+	if err := terrarium.CheckOperation(trId, gcpAzureVpnEnrichment, terrarium.OpEmptyOut); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{
+			Success: false,
+			Message: err.Error(),
+		}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	err := os.RemoveAll(workingDir)
 	if err != nil {
 		err2 := fmt.Errorf("failed to remove working directory and all configuration files")
@@ -188,6 +192,12 @@ func ClearGcpAzureVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	// Remove the enrichment from terrarium information
+	err = terrarium.RemoveEnrichment(trId, gcpAzureVpnEnrichment)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to remove the enrichment (trId: %s)", trId)
+	}
+
 	text := "successfully remove all in the working directory"
 	res := model.Response{
 		Success: true,
@@ -207,6 +217,7 @@ func ClearGcpAzureVpn(c echo.Context) error {
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param detail query string false "Resource info by detail (refined, raw)" default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -254,6 +265,14 @@ func GetResourceInfoOfGcpAzureVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	// Refresh the state to sync with the current CSP status (default: true)
 	refreshParam := strings.ToLower(c.QueryParam("refresh"))
 	if refreshParam != "" && refreshParam != "true" && refreshParam != "false" {
@@ -264,7 +283,7 @@ func GetResourceInfoOfGcpAzureVpn(c echo.Context) error {
 	}
 	if refreshParam != "false" {
 		log.Info().Msgf("Refreshing state for terrarium (trId: %s) to sync with CSP", trId)
-		_, err := terrarium.Refresh(trId, reqId)
+		_, err := terrarium.Refresh(ctx, trId, gcpAzureVpnEnrichment, reqId)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to refresh state, proceeding with cached state")
 		}
@@ -291,7 +310,7 @@ func GetResourceInfoOfGcpAzureVpn(c echo.Context) error {
 
 		// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-aws
 		// show: subcommand
-		ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "output", "-json", "vpn_info")
+		ret, err := tofu.ExecuteCommand(requestContext(c), trId, reqId, "-chdir="+workingDir, "output", "-json", "vpn_info")
 		if err != nil {
 			err2 := fmt.Errorf("failed to read resource info (detail: %s) specified as 'output' in the state file", DetailOptions.Refined)
 			log.Error().Err(err).Msg(err2.Error())
@@ -328,7 +347,7 @@ func GetResourceInfoOfGcpAzureVpn(c echo.Context) error {
 		// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-aws
 		// show: subcommand
 		// Get resource info from the state or plan file
-		ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "show", "-json")
+		ret, err := tofu.ExecuteCommand(requestContext(c), trId, reqId, "-chdir="+workingDir, "show", "-json")
 		if err != nil {
 			err2 := fmt.Errorf("failed to read resource info (detail: %s) from the state or plan file", DetailOptions.Raw)
 			log.Error().Err(err).Msg(err2.Error()) // error
@@ -422,17 +441,8 @@ func CreateInfracodeOfGcpAzureVpn(c echo.Context) error {
 
 	projectRoot := config.Terrarium.Root
 
-	// Read and set the enrichments to terrarium information
-	trInfo, _, err := terrarium.GetInfo(trId)
-	if err != nil {
-		err2 := fmt.Errorf("failed to read terrarium information")
-		log.Error().Err(err).Msg(err2.Error())
-		res := model.Response{Success: false, Message: err2.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
-	}
-
 	// Check if the working directory exists
-	workingDir := projectRoot + "/.terrarium/" + trId + "/" + trInfo.Enrichments
+	workingDir := projectRoot + "/.terrarium/" + trId + "/" + gcpAzureVpnEnrichment
 	if _, err := os.Stat(workingDir); os.IsNotExist(err) {
 		err2 := fmt.Errorf("working directory dose not exist")
 		log.Warn().Err(err).Msg(err2.Error())
@@ -448,7 +458,7 @@ func CreateInfracodeOfGcpAzureVpn(c echo.Context) error {
 		req.TfVars.TerrariumId = trId
 	}
 
-	err = terrarium.SaveTfVars(trId, trInfo.Enrichments, req.TfVars)
+	err := terrarium.SaveTfVars(trId, gcpAzureVpnEnrichment, req.TfVars)
 	if err != nil {
 		err2 := fmt.Errorf("failed to save tfVars to a file")
 		log.Error().Err(err).Msg(err2.Error())
@@ -476,6 +486,7 @@ func CreateInfracodeOfGcpAzureVpn(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -499,6 +510,14 @@ func CheckInfracodeOfGcpAzureVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	projectRoot := config.Terrarium.Root
 
 	// Check if the working directory exists
@@ -515,7 +534,7 @@ func CheckInfracodeOfGcpAzureVpn(c echo.Context) error {
 
 	// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-azure
 	// subcommand: plan
-	ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "plan")
+	ret, err := tofu.ExecuteCommand(ctx, trId, reqId, "-chdir="+workingDir, "plan")
 	if err != nil {
 		err2 := fmt.Errorf("encountered an issue during the infracode checking process")
 		log.Error().Err(err).Msg(err2.Error()) // error
@@ -545,10 +564,12 @@ func CheckInfracodeOfGcpAzureVpn(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/gcp-azure [post]
@@ -568,6 +589,14 @@ func CreateGcpAzureVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	projectRoot := config.Terrarium.Root
 
 	// Check if the working directory exists
@@ -582,17 +611,30 @@ func CreateGcpAzureVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	// Check the phase and mark it as provisioning,
+	// which is settled by the execution status after the asynchronous apply
+	previous, err := terrarium.StartOperation(trId, gcpAzureVpnEnrichment, terrarium.OpApply)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{
+			Success: false,
+			Message: err.Error(),
+		}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}/vpn/gcp-azure
 	// subcommand: apply
-	ret, err := tofu.ExecuteCommandAsync(trId, reqId, "-chdir="+workingDir, "apply", "-auto-approve")
+	ret, err := tofu.ExecuteCommandAsync(ctx, trId, reqId, "-chdir="+workingDir, "apply", "-auto-approve")
 	if err != nil {
+		terrarium.FinishOperation(trId, gcpAzureVpnEnrichment, terrarium.OpApply, previous, err)
 		err2 := fmt.Errorf("failed, previous request in progress")
 		log.Error().Err(err).Msg(err2.Error()) // error
 		res := model.Response{
 			Success: false,
 			Message: err2.Error(),
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	res := model.Response{
@@ -613,11 +655,13 @@ func CreateGcpAzureVpn(c echo.Context) error {
 // @Accept  json
 // @Produce  json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
 // @Failure 500 {object} model.Response "Internal Server Error"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/gcp-azure [delete]
@@ -637,6 +681,14 @@ func DestroyGcpAzureVpn(c echo.Context) error {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	projectRoot := config.Terrarium.Root
 
 	// Check if the working directory exists
@@ -651,10 +703,22 @@ func DestroyGcpAzureVpn(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, res)
 	}
 
+	// Check the phase and mark it as destroying
+	previous, err := terrarium.StartOperation(trId, gcpAzureVpnEnrichment, terrarium.OpDestroy)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{
+			Success: false,
+			Message: err.Error(),
+		}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	// Destroy the infrastructure
 	// global option to set working dir: -chdir=/home/ubuntu/dev/cloud-barista/mc-terrarium/.terrarium/{trId}
 	// subcommand: destroy
-	ret, err := tofu.ExecuteCommand(trId, reqId, "-chdir="+workingDir, "destroy", "-auto-approve")
+	ret, err := tofu.ExecuteCommand(ctx, trId, reqId, "-chdir="+workingDir, "destroy", "-auto-approve")
+	terrarium.FinishOperation(trId, gcpAzureVpnEnrichment, terrarium.OpDestroy, previous, err)
 	if err != nil {
 		err2 := fmt.Errorf("failed, previous request in progress")
 		log.Error().Err(err).Msg(err2.Error()) // error
@@ -662,7 +726,7 @@ func DestroyGcpAzureVpn(c echo.Context) error {
 			Success: false,
 			Message: err2.Error(),
 		}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 	res := model.Response{
 		Success: true,
@@ -729,7 +793,7 @@ func GetRequestStatusOfGcpAzureVpn(c echo.Context) error {
 	statusLogFile := fmt.Sprintf("%s/runningLogs/%s.log", workingDir, reqId)
 
 	// Check the statusReport of the request
-	statusReport, err := tofu.GetExcutionHistory(trId, statusLogFile)
+	statusReport, err := tofu.GetExcutionHistory(trId, reqId, statusLogFile)
 	if err != nil {
 		err2 := fmt.Errorf("failed to get the status of the request")
 		log.Error().Err(err).Msg(err2.Error()) // error
@@ -17,6 +17,9 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// siteToSiteVpnEnrichment is the name of the site-to-site VPN enrichment in a terrarium
+const siteToSiteVpnEnrichment = "vpn/site-to-site"
+
 /*
  * [API - Site-to-Site VPN] OpenTofu Actions (for fine-grained control)
  */
@@ -24,6 +27,7 @@ import (
 // InitSiteToSiteVpn godoc
 // @Summary Init Site-to-Site VPN
 // @Description Init Site-to-Site VPN
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
 // @Tags [Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)
 // @Accept json
 // @Produce json
@@ -33,6 +37,7 @@ import (
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site/actions/init [post]
@@ -42,7 +47,7 @@ func InitSiteToSiteVpn(c echo.Context) error {
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		res := model.Response{Success: false, Message: err.Error()}
-		return c.JSON(http.StatusInternalServerError, res)
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -63,10 +68,10 @@ func initSiteToSiteVpn(c echo.Context) (model.Response, error) {
 	}
 
 	req := new(model.CreateSiteToSiteVpnRequest)
-	if err := c.Bind(req); err != nil {
-		err2 := fmt.Errorf("invalid request format: %w", err)
-		log.Warn().Msg(err2.Error())
-		return emptyRes, err2
+	refs, err := bindWithReferences(c, trId, siteToSiteVpnEnrichment, "", req)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
 	}
 	log.Debug().Msgf("%#v", req) // debug
 
@@ -99,36 +104,20 @@ func initSiteToSiteVpn(c echo.Context) (model.Response, error) {
 	reqId := c.Response().Header().Get("x-request-id")
 
 	// Set the enrichments
-	enrichments := "vpn/site-to-site"
+	enrichments := siteToSiteVpnEnrichment
 
 	// Sort providers in alphabetical order
 	sort.Strings(providers)
 
-	// Check if the terrarium is already used for another purpose
-	trInfo, _, err := terrarium.GetInfo(trId)
-	if err != nil {
-		log.Error().Err(err).Msg(err.Error())
-		return emptyRes, err
-	}
-	if trInfo.Enrichments != "" && trInfo.Enrichments != enrichments {
-		err := fmt.Errorf("the terrarium (trId: %s) is already used for another purpose", trId)
-		log.Warn().Msg(err.Error())
-		return emptyRes, err
-	}
-
-	// Set the terrarium information
-	trInfo.Enrichments = enrichments
-	trInfo.Providers = providers
-
-	// Update the terrarium info
-	err = terrarium.UpdateInfo(trInfo)
+	// Create the terrarium environment
+	err = terrarium.CreateEnv(trId, enrichments, providers)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
 	}
 
-	// Create the terrarium environment
-	err = terrarium.CreateEnv(trInfo)
+	// Add the enrichment and engaged providers to the terrarium
+	err = terrarium.AddEnrichment(trId, enrichments, providers)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
 		return emptyRes, err
@@ -157,8 +146,15 @@ func initSiteToSiteVpn(c echo.Context) (model.Response, error) {
 		return emptyRes, err
 	}
 
+	// Keep the references to the outputs of the other enrichments to resolve them again on update
+	err = terrarium.SaveReferences(trId, enrichments, refs)
+	if err != nil {
+		log.Error().Err(err).Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the init command
-	ret, err := terrarium.Init(trId, reqId)
+	ret, err := terrarium.Init(requestContext(c), trId, siteToSiteVpnEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to initialize an infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -210,14 +206,18 @@ func getProvidersFromRequest(req *model.CreateSiteToSiteVpnRequest) []string {
 // PlanSiteToSiteVpn godoc
 // @Summary Plan Site-to-Site VPN
 // @Description Plan Site-to-Site VPN
+// @Description The plan is saved, and the plan ID with the summary of the changes is returned in the object of the response.
+// @Description Use the plan ID (planId) to apply exactly the saved plan.
 // @Tags [Site-to-Site VPN] OpenTofu Actions (for fine-grained control) (Under development - Paused)
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site/actions/plan [post]
@@ -226,6 +226,8 @@ func PlanSiteToSiteVpn(c echo.Context) error {
 	ret, err := planSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, ret)
@@ -245,8 +247,15 @@ func planSiteToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the plan command
-	ret, err := terrarium.Plan(trId, reqId)
+	plan, ret, err := terrarium.Plan(ctx, trId, siteToSiteVpnEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to plan the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -255,8 +264,9 @@ func planSiteToSiteVpn(c echo.Context) (model.Response, error) {
 
 	res := model.Response{
 		Success: true,
-		Message: "successfully planned the infrastructure terrarium for Site-to-Site VPN",
+		Message: fmt.Sprintf("successfully planned the infrastructure terrarium for Site-to-Site VPN (planId: %s)", plan.Id),
 		Detail:  ret,
+		Object:  planObject(plan),
 	}
 
 	log.Debug().Msgf("%+v", res) // debug
@@ -271,25 +281,30 @@ func planSiteToSiteVpn(c echo.Context) (model.Response, error) {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param planId query string false "Plan ID to apply exactly the saved plan (if omitted, plan and apply at once)"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 404 {object} model.Response "Not Found"
+// @Failure 409 {object} model.Response "Conflict (e.g., stale plan, not initialized)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site/actions/apply [post]
 func ApplySiteToSiteVpn(c echo.Context) error {
 
-	res, err := applySiteToSiteVpn(c)
+	res, err := applySiteToSiteVpn(c, c.QueryParam("planId"))
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
 }
 
-func applySiteToSiteVpn(c echo.Context) (model.Response, error) {
+func applySiteToSiteVpn(c echo.Context, planId string) (model.Response, error) {
 
 	emptyRes := model.Response{}
 
@@ -303,8 +318,15 @@ func applySiteToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the apply command
-	ret, err := terrarium.Apply(trId, reqId)
+	ret, err := terrarium.Apply(ctx, trId, siteToSiteVpnEnrichment, reqId, planId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to apply the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -329,10 +351,12 @@ func applySiteToSiteVpn(c echo.Context) (model.Response, error) {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site/actions/destroy [delete]
@@ -341,7 +365,8 @@ func DestroySiteToSiteVpn(c echo.Context) error {
 	res, err := destroySiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -372,9 +397,16 @@ func destroySiteToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Execute the destroy command
 	var ret string
-	ret, err = terrarium.Destroy(trId, reqId)
+	ret, err = terrarium.Destroy(ctx, trId, siteToSiteVpnEnrichment, reqId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to destroy the infrastructure terrarium")
 		log.Error().Err(err).Msg(err2.Error())
@@ -410,6 +442,7 @@ func Contains(slice []string, item string) bool {
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -422,7 +455,8 @@ func OutputSiteToSiteVpn(c echo.Context) error {
 	res, err := outputSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -466,6 +500,13 @@ func outputSiteToSiteVpn(c echo.Context) (model.Response, error) {
 	// Get the request ID
 	reqId := c.Response().Header().Get("x-request-id")
 
+	// Get the context to run the tofu commands, with the timeout of the request if given
+	ctx, err := runContext(c)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return emptyRes, err
+	}
+
 	// Refresh the state to sync with the current CSP status (default: true)
 	refreshParam := strings.ToLower(c.QueryParam("refresh"))
 	if refreshParam != "" && refreshParam != "true" && refreshParam != "false" {
@@ -475,7 +516,7 @@ func outputSiteToSiteVpn(c echo.Context) (model.Response, error) {
 	}
 	if refreshParam != "false" {
 		log.Info().Msgf("Refreshing state for terrarium (trId: %s) to sync with CSP", trId)
-		_, err := terrarium.Refresh(trId, reqId)
+		_, err := terrarium.Refresh(ctx, trId, siteToSiteVpnEnrichment, reqId)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to refresh state, proceeding with cached state")
 		}
@@ -489,21 +530,21 @@ func outputSiteToSiteVpn(c echo.Context) (model.Response, error) {
 		 * NOTE: Set the output object (e.g., "vpn_info") to get the refined resource info
 		 */
 
-		trInfo, exists, err := terrarium.GetInfo(trId)
+		enrichment, exists, err := terrarium.GetEnrichment(trId, siteToSiteVpnEnrichment)
 		if err != nil {
 			log.Error().Err(err).Msg(err.Error())
 			return emptyRes, err
 		}
 		if !exists {
-			err2 := fmt.Errorf("no terrarium with the given ID (trId: %s)", trId)
+			err2 := fmt.Errorf("no enrichment (%s) in the terrarium (trId: %s)", siteToSiteVpnEnrichment, trId)
 			log.Warn().Msg(err2.Error())
 			return emptyRes, err2
 		}
 
 		// In-parallel, retrieve and merge the resource info
-		providers := trInfo.Providers
+		providers := enrichment.Providers
 		if len(providers) == 0 {
-			err2 := fmt.Errorf("no providers in the terrarium (trId: %s)", trId)
+			err2 := fmt.Errorf("no providers in the enrichment (%s) of the terrarium (trId: %s)", siteToSiteVpnEnrichment, trId)
 			log.Warn().Msg(err2.Error())
 			return emptyRes, err2
 		}
@@ -523,7 +564,7 @@ func outputSiteToSiteVpn(c echo.Context) (model.Response, error) {
 				defer wg.Done()
 				// Retrieve provider's resource info
 				targetObject := fmt.Sprintf("%s_vpn_info", provider)
-				resourceInfo, err := terrarium.Output(trId, reqId, targetObject, "-json")
+				resourceInfo, err := terrarium.Output(requestContext(c), trId, siteToSiteVpnEnrichment, reqId, targetObject, "-json")
 				if err != nil {
 					results <- resultWithError{
 						provider: provider,
@@ -591,7 +632,7 @@ func outputSiteToSiteVpn(c echo.Context) (model.Response, error) {
 	case DetailOptions.Raw:
 
 		// Execute the show command
-		ret, err := terrarium.Show(trId, reqId, "-json")
+		ret, err := terrarium.Show(requestContext(c), trId, siteToSiteVpnEnrichment, reqId, "-json")
 		if err != nil {
 			err2 := fmt.Errorf("failed to show the infrastructure terrarium")
 			log.Error().Err(err).Msg(err2.Error())
@@ -670,6 +711,7 @@ func outputSiteToSiteVpn(c echo.Context) (model.Response, error) {
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site/actions/emptyout [delete]
@@ -678,7 +720,8 @@ func EmptyOutSiteToSiteVpn(c echo.Context) error {
 	res, err := emptyOutSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -695,30 +738,30 @@ func emptyOutSiteToSiteVpn(c echo.Context) (model.Response, error) {
 		return emptyRes, err
 	}
 
-	enrichments := "vpn/site-to-site"
+	enrichments := siteToSiteVpnEnrichment
 
-	existingEnrichments, exist, err := terrarium.GetEnrichments(trId)
+	_, exist, err := terrarium.GetEnrichment(trId, enrichments)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get enrichments")
+		log.Error().Err(err).Msg("failed to get the enrichment")
 		return emptyRes, err
 	}
 
-	if !exist || existingEnrichments != enrichments {
+	if !exist {
 		err := fmt.Errorf("terrarium (trId: %s) is not configured for Site-to-Site VPN", trId)
 		log.Warn().Msg(err.Error())
 		return emptyRes, err
 	}
 
 	// Execute the emptyout command
-	err = terrarium.EmptyOutTerrariumEnv(trId)
+	err = terrarium.EmptyOutTerrariumEnv(trId, enrichments)
 	if err != nil {
-		err2 := fmt.Errorf("failed to empty out the infrastructure terrarium")
+		err2 := fmt.Errorf("failed to empty out the infrastructure terrarium: %w", err)
 		log.Error().Err(err).Msg(err2.Error())
-		return emptyRes, err
+		return emptyRes, err2
 	}
 
-	// Unset the enrichments
-	err = terrarium.SetEnrichments(trId, "")
+	// Remove the enrichment from the terrarium
+	err = terrarium.RemoveEnrichment(trId, enrichments)
 	if err != nil {
 		err2 := fmt.Errorf("failed to unset the terrarium enrichments")
 		log.Error().Err(err).Msg(err2.Error())
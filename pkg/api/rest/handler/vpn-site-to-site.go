@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
@@ -14,15 +15,19 @@ import (
 // CreateSiteToSiteVpn godoc
 // @Summary Create Site-to-Site VPN
 // @Description Create Site-to-Site VPN between two cloud sites
+// @Description A field can reference an output of another enrichment in the terrarium by {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., testbed.aws_testbed_info.vpc_id), which is resolved again on update.
 // @Tags [Site-to-Site VPN] Resource Operations (Under development - Paused)
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param ReqBody body model.CreateSiteToSiteVpnRequest true "Parameters required to create the Site-to-Site VPN"
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 201 {object} model.Response "Created"
+// @Success 202 {object} model.Response "Accepted (the plan is pending approval, apply it by its plan ID after approval)"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site [post]
@@ -33,22 +38,38 @@ func CreateSiteToSiteVpn(c echo.Context) error {
 	// 2. Plan
 	// 3. Apply
 
+	// Validate the timeout of the plan and apply before initializing
+	if _, err := runContext(c); err != nil {
+		log.Warn().Msg(err.Error())
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
 	res, err := initSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	res, err = planSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
+	}
+
+	// Wait for the approval of the plan if the terrarium requires it
+	if isPendingApproval(res) {
+		return c.JSON(http.StatusAccepted, res)
 	}
 
-	res, err = applySiteToSiteVpn(c)
+	// Apply exactly the plan above
+	res, err = applySiteToSiteVpn(c, planIdOf(res))
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusCreated, res)
@@ -63,6 +84,7 @@ func CreateSiteToSiteVpn(c echo.Context) error {
 // @Param trId path string true "Terrarium ID" default(tr01)
 // @Param detail query string false "Resource info by detail (refined, raw)" Enums(refined, raw) default(refined)
 // @Param refresh query boolean false "Refresh the state before getting the info" default(true)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
@@ -78,7 +100,8 @@ func GetSiteToSiteVpn(c echo.Context) error {
 	res, err := outputSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
@@ -111,7 +134,7 @@ func GetSiteToSiteVpn(c echo.Context) error {
 // 		return c.JSON(http.StatusInternalServerError, res)
 // 	}
 
-// 	res, err = applySiteToSiteVpn(c)
+// 	res, err = applySiteToSiteVpn(c, planIdOf(res))
 // 	if err != nil {
 // 		log.Error().Err(err).Msg(err.Error())
 // 		return c.JSON(http.StatusInternalServerError, res)
@@ -127,10 +150,12 @@ func GetSiteToSiteVpn(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param trId path string true "Terrarium ID" default(tr01)
+// @Param timeout query string false "Timeout of the OpenTofu commands, between 1m and 24h (e.g., 30m, 2h), by default terrarium.tofu.timeout_min"
 // @Param x-request-id header string false "Custom request ID"
 // @Param x-credential-holder header string false "Credential holder (profile) name"
 // @Success 200 {object} model.Response "OK"
 // @Failure 400 {object} model.Response "Bad Request"
+// @Failure 409 {object} model.Response "Conflict (e.g., a request in progress, invalid phase)"
 // @Failure 500 {object} model.Response "Internal Server Error"
 // @Failure 503 {object} model.Response "Service Unavailable"
 // @Router /tr/{trId}/vpn/site-to-site [delete]
@@ -143,13 +168,15 @@ func DeleteSiteToSiteVpn(c echo.Context) error {
 	res, err := destroySiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	res, err = emptyOutSiteToSiteVpn(c)
 	if err != nil {
 		log.Error().Err(err).Msg(err.Error())
-		return c.JSON(http.StatusInternalServerError, res)
+		res := model.Response{Success: false, Message: err.Error()}
+		return c.JSON(httpStatusOf(err), res)
 	}
 
 	return c.JSON(http.StatusOK, res)
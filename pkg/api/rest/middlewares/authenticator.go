@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Authentication of a request
+ * The caller of a request is authenticated by one of
+ *   - Authorization: Bearer <token>, where the token is an API key (trk_...) or a JWT (see the auth package)
+ *   - x-api-key: <API key>
+ *   - Authorization: Basic <username:password>, i.e., the shared account (BasicAuth)
+ * and set as the principal of the request (see SetPrincipal), which binds the credential holder in strict mode.
+ */
+
+// basicRealm is the realm of BasicAuth, which lets the browsers (e.g., Swagger UI) prompt for the shared account.
+const basicRealm = "Restricted"
+
+// AuthenticatorConfig is the configuration of Authenticator.
+type AuthenticatorConfig struct {
+	// Skipper skips the authentication of some routes (e.g., readyz)
+	Skipper middleware.Skipper
+	// BasicValidator validates the username and password of BasicAuth, and returns the principal if valid
+	BasicValidator func(username, password string, c echo.Context) (model.Principal, bool)
+}
+
+// Authenticator returns a middleware to authenticate the caller of a request by a bearer token (API key or JWT) or BasicAuth.
+func Authenticator(config AuthenticatorConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			authorization := c.Request().Header.Get(echo.HeaderAuthorization)
+			scheme, credentials, _ := strings.Cut(authorization, " ")
+
+			// Bearer token (or x-api-key)
+			token := c.Request().Header.Get(model.HeaderXApiKey)
+			if strings.EqualFold(scheme, "Bearer") {
+				token = strings.TrimSpace(credentials)
+			}
+			if token != "" {
+				principal, err := auth.Authenticate(c.Request().Context(), token)
+				if err != nil {
+					log.Warn().Err(err).Msgf("failed to authenticate the bearer token (remote: %s)", c.RealIP())
+					c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+					res := model.Response{Success: false, Message: err.Error()}
+					return c.JSON(http.StatusUnauthorized, res)
+				}
+				SetPrincipal(c, principal)
+				return next(c)
+			}
+
+			// BasicAuth
+			if strings.EqualFold(scheme, "Basic") && config.BasicValidator != nil {
+				decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(credentials))
+				if err == nil {
+					username, password, ok := strings.Cut(string(decoded), ":")
+					if ok {
+						if principal, valid := config.BasicValidator(username, password, c); valid {
+							SetPrincipal(c, principal)
+							return next(c)
+						}
+					}
+				}
+			}
+
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Basic realm="+basicRealm)
+			res := model.Response{Success: false, Message: "unauthorized, a valid bearer token (API key or JWT) or BasicAuth is required"}
+			return c.JSON(http.StatusUnauthorized, res)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
+	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Credential holder of a request
+ * The credential holder (profile) of a request is resolved once by CredentialHolderResolver,
+ * and carried by the request context (see job.CredentialHolderFrom) to the handlers and the jobs.
+ *   - Strict mode (terrarium.api.auth.strict_holder): the holder is bound to the authenticated principal.
+ *     A request without a principal (or a holder bound to it) is rejected.
+ *     x-credential-holder may name another holder only if the principal is an admin (i.e., an explicit override),
+ *     and the terrarium of the request (trId) must be held by the holder.
+ *   - Legacy mode: the holder is x-credential-holder, or the one bound to the principal,
+ *     or "admin" if neither is given.
+ */
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the authenticated principal,
+// and its name for the packages below the handlers (see job.PrincipalNameFrom).
+func WithPrincipal(ctx context.Context, principal model.Principal) context.Context {
+	ctx = job.WithPrincipalName(ctx, principal.Name)
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the authenticated principal carried by ctx, if any.
+func PrincipalFrom(ctx context.Context) (model.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(model.Principal)
+	return principal, ok
+}
+
+// SetPrincipal sets the authenticated principal of a request (e.g., by an authentication middleware).
+func SetPrincipal(c echo.Context, principal model.Principal) {
+	c.SetRequest(c.Request().WithContext(WithPrincipal(c.Request().Context(), principal)))
+}
+
+// StrictHolder reports whether the credential holder is bound to the authenticated principal.
+func StrictHolder() bool {
+	return config.Terrarium.API.Auth.StrictHolder
+}
+
+// CredentialHolderResolver is a middleware to resolve the credential holder (profile) of a request.
+func CredentialHolderResolver(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		provided := c.Request().Header.Get(model.HeaderXCredentialHolder)
+		principal, authenticated := PrincipalFrom(c.Request().Context())
+
+		holder := provided
+		if !StrictHolder() {
+			if holder == "" && authenticated {
+				holder = principal.CredentialHolder
+			}
+			// Legacy: the implicit holder, which is not allowed in strict mode
+			if holder == "" {
+				holder = secrets.AdminProfile
+			}
+		} else {
+			if !authenticated {
+				res := model.Response{Success: false, Message: "authentication is required to resolve the credential holder"}
+				return c.JSON(http.StatusUnauthorized, res)
+			}
+			switch {
+			case holder == "" && principal.CredentialHolder == "":
+				res := model.Response{Success: false, Message: "missing credential holder, no holder is bound to " + principal.Name + " nor given by " + model.HeaderXCredentialHolder}
+				return c.JSON(http.StatusBadRequest, res)
+			case holder == "":
+				holder = principal.CredentialHolder
+			case holder != principal.CredentialHolder && !principal.Admin:
+				log.Warn().Msgf("denied %s to act as the credential holder (%s)", principal.Name, holder)
+				res := model.Response{Success: false, Message: "forbidden to act as the credential holder (" + holder + "), only admins may override the holder"}
+				return c.JSON(http.StatusForbidden, res)
+			case holder != principal.CredentialHolder:
+				log.Info().Msgf("admin %s acts as the credential holder (%s)", principal.Name, holder)
+			}
+
+			// The terrarium of the request must be held by the holder (e.g., reading or erasing a terrarium)
+			if trId := c.Param("trId"); trId != "" {
+				if err := terrarium.ValidateCredentialProfile(trId, holder); err != nil {
+					log.Warn().Err(err).Msg("failed to validate credential profile (holder)")
+					res := model.Response{Success: false, Message: err.Error()}
+					return c.JSON(http.StatusForbidden, res)
+				}
+			}
+		}
+
+		// Set the credential holder to be recorded in the jobs of the request
+		ctx := job.WithCredentialHolder(c.Request().Context(), holder)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		return next(c)
+	}
+}
+
+// IsAdmin reports whether the caller of a request may manage the resources of any credential holder,
+// i.e., an admin principal in strict mode, or anyone in legacy mode.
+func IsAdmin(c echo.Context) bool {
+	if !StrictHolder() {
+		return true
+	}
+	principal, ok := PrincipalFrom(c.Request().Context())
+	return ok && principal.Admin
+}
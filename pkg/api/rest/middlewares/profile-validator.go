@@ -18,12 +18,14 @@ import (
 	"net/http"
 
 	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
 	"github.com/cloud-barista/mc-terrarium/pkg/terrarium"
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 )
 
 // CredentialProfileValidator is a middleware to validate the credential profile (holder)
+// of a terrarium against the one of the request resolved by CredentialHolderResolver.
 func CredentialProfileValidator(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		trId := c.Param("trId")
@@ -31,10 +33,10 @@ func CredentialProfileValidator(next echo.HandlerFunc) echo.HandlerFunc {
 			return next(c)
 		}
 
-		providedProfile := c.Request().Header.Get(model.HeaderXCredentialHolder)
-		// TODO: Remove this later for more secure credential profile management
+		providedProfile := job.CredentialHolderFrom(c.Request().Context())
 		if providedProfile == "" {
-			providedProfile = "admin"
+			res := model.Response{Success: false, Message: "missing credential holder of the request"}
+			return c.JSON(http.StatusForbidden, res)
 		}
 
 		if err := terrarium.ValidateCredentialProfile(trId, providedProfile); err != nil {
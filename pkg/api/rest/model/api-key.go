@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// ApiKey represents an API key of a user, which authenticates the user as a principal.
+// The key itself is shown only once when created, and only its hash is stored.
+type ApiKey struct {
+	Id string `json:"id" example:"3f9c2a7d51e0b84c"`
+	// Name is the name of the principal authenticated by the key (e.g., a team member)
+	Name string `json:"name" example:"alice"`
+	// CredentialHolder is the credential holder (profile) bound to the principal
+	CredentialHolder string `json:"credentialHolder" example:"alice"`
+	// Admin is true if the principal may act as another credential holder
+	Admin       bool       `json:"admin" example:"false"`
+	Description string     `json:"description,omitempty" example:"CI pipeline of team A"`
+	CreatedBy   string     `json:"createdBy" example:"default"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreateApiKeyRequest represents a request to create an API key.
+type CreateApiKeyRequest struct {
+	// Name is the name of the principal (default: the caller)
+	Name string `json:"name" example:"alice"`
+	// CredentialHolder is the credential holder bound to the principal (default: the credential holder bound to the caller)
+	CredentialHolder string `json:"credentialHolder" example:"alice"`
+	Admin            bool   `json:"admin" example:"false"`
+	Description      string `json:"description" example:"CI pipeline of team A"`
+	// TtlHours is the hours until the key expires (0: never)
+	TtlHours int `json:"ttlHours" example:"720"`
+}
+
+// CreateApiKeyResponse represents a created API key with the key, which is not shown again.
+type CreateApiKeyResponse struct {
+	ApiKey
+	// Key is the bearer token (i.e., Authorization: Bearer <key>, or x-api-key: <key>)
+	Key string `json:"key" example:"trk_3f9c2a7d51e0b84c_5b0e..."`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// ArchiveManifest describes an archive (tar.gz) of a terrarium exported to be imported into another server.
+// The version is increased when the layout of the archive changes,
+// so that an archive of an older version can be migrated on import.
+type ArchiveManifest struct {
+	Version     string    `json:"version" example:"1"`
+	TrId        string    `json:"trId" example:"tr01"`
+	Enrichments []string  `json:"enrichments,omitempty" example:"testbed,vpn/site-to-site"`
+	ExportedAt  time.Time `json:"exportedAt"`
+	ExportedBy  string    `json:"exportedBy,omitempty" example:"admin"`
+	// Sensitive is how the sensitive values in the state and tfvars are protected (i.e., Redacted, Encrypted)
+	Sensitive string `json:"sensitive" example:"Redacted"`
+	// Salt is the salt (base64) to derive the key from the passphrase if the sensitive values are encrypted
+	Salt string `json:"salt,omitempty"`
+}
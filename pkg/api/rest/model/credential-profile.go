@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// CredentialProfile represents a credential profile (i.e., a credential holder) in the secrets store,
+// with the providers whose credentials are configured (i.e., not placeholders).
+type CredentialProfile struct {
+	Name      string   `json:"name" example:"alice"`
+	Providers []string `json:"providers" example:"aws,azure"`
+	// Path is the path of the credentials in the secrets store
+	Path string `json:"path" example:"secret/users/alice/csp/"`
+}
+
+// CreateCredentialProfileRequest represents a request to create a credential profile.
+type CreateCredentialProfileRequest struct {
+	Name string `json:"name" validate:"required" example:"alice"`
+	// Credentials are the credentials by provider (e.g., {"aws": {"AWS_ACCESS_KEY_ID": "...", "AWS_SECRET_ACCESS_KEY": "..."}})
+	Credentials map[string]map[string]string `json:"credentials" validate:"required"`
+}
+
+// UpdateCredentialProfileRequest represents a request to update the credentials of a credential profile.
+// The credential of a provider is replaced, or deleted if empty (e.g., {"tencent": {}}). The other providers are kept.
+type UpdateCredentialProfileRequest struct {
+	Credentials map[string]map[string]string `json:"credentials" validate:"required"`
+}
+
+// CredentialVerification represents the result of the verification of the credential of a provider in a credential profile.
+type CredentialVerification struct {
+	Profile  string `json:"profile" example:"alice"`
+	Provider string `json:"provider" example:"aws"`
+	Passed   bool   `json:"passed" example:"false"`
+	// Stage is the stage failed (i.e., credential, init, plan)
+	Stage string `json:"stage,omitempty" example:"plan"`
+	// Diagnostic is the diagnostic of tofu (or the reason) if failed
+	Diagnostic string    `json:"diagnostic,omitempty" example:"Error: Retrieving AWS account details: validating provider credentials: ... InvalidClientTokenId"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+	// ElapsedSec is the seconds taken to verify
+	ElapsedSec float64 `json:"elapsedSec" example:"12.3"`
+}
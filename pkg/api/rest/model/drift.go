@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// DriftReport represents the drift of the resources of an enrichment,
+// i.e., the changes made outside mc-terrarium, detected by `tofu plan -refresh-only`.
+type DriftReport struct {
+	TrId       string          `json:"trId" example:"tr01"`
+	Enrichment string          `json:"enrichment" example:"vpn/aws-to-site"`
+	ReqId      string          `json:"reqId" example:"1718000000000000000"`
+	Drifted    bool            `json:"drifted" example:"true"`
+	Resources  []ResourceDrift `json:"resources,omitempty"`
+	// DetectedAt is the time when the current drift was first detected
+	DetectedAt *time.Time `json:"detectedAt,omitempty"`
+	CheckedAt  time.Time  `json:"checkedAt"`
+}
+
+// ResourceDrift represents a resource changed (or deleted) outside mc-terrarium.
+type ResourceDrift struct {
+	Address      string           `json:"address" example:"aws_vpn_connection.main"`
+	Type         string           `json:"type" example:"aws_vpn_connection"`
+	Name         string           `json:"name" example:"main"`
+	ProviderName string           `json:"providerName" example:"registry.opentofu.org/hashicorp/aws"`
+	Actions      []string         `json:"actions" example:"update"`
+	Attributes   []AttributeDrift `json:"attributes,omitempty"`
+}
+
+// AttributeDrift represents a changed attribute of a resource.
+// The sensitive values are not shown (i.e., "(sensitive value)").
+type AttributeDrift struct {
+	Path   string      `json:"path" example:"tags.Owner"`
+	Before interface{} `json:"before,omitempty" swaggertype:"string" example:"alice"`
+	After  interface{} `json:"after,omitempty" swaggertype:"string" example:"bob"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// EnrichmentKind represents an enrichment registered in the server,
+// which is a kind of resources provisioned in a terrarium by /tr/{trId}/enrichments/{kind}.
+type EnrichmentKind struct {
+	Kind      string   `json:"kind" example:"sql-db"`
+	Providers []string `json:"providers" example:"aws,azure,gcp,ncp"`
+	Output    string   `json:"output" example:"sql_db_info"`
+}
@@ -19,4 +19,10 @@ const (
 	HeaderXRequestId = "x-request-id"
 	// HeaderXCredentialHolder is a header key for x-credential-holder
 	HeaderXCredentialHolder = "x-credential-holder"
+	// HeaderXArchivePassphrase is a header key for x-archive-passphrase
+	HeaderXArchivePassphrase = "x-archive-passphrase"
+	// HeaderXNextCursor is a header key for x-next-cursor
+	HeaderXNextCursor = "x-next-cursor"
+	// HeaderXApiKey is a header key for x-api-key
+	HeaderXApiKey = "x-api-key"
 )
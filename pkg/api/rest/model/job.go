@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Job represents a request (reqId) processed in a terrarium.
+// A request may run several OpenTofu commands (e.g., init, plan and apply), each recorded as a step.
+type Job struct {
+	TrId             string `json:"trId" example:"tr01"`
+	ReqId            string `json:"reqId" example:"1718000000000000000"`
+	Command          string `json:"command" example:"tofu apply -auto-approve"`
+	Enrichment       string `json:"enrichment,omitempty" example:"vpn/aws-to-site"`
+	CredentialHolder string `json:"credentialHolder,omitempty" example:"admin"`
+	PlanId           string `json:"planId,omitempty" example:"1718000000000000000"`
+	ApprovedBy       string `json:"approvedBy,omitempty" example:"alice"`
+	Status           string `json:"status" example:"Success"`
+	// Host and Pid identify the process of the running command, which is checked by the recovery at startup
+	Host      string       `json:"host,omitempty" example:"mc-terrarium-0"`
+	Pid       int          `json:"pid,omitempty" example:"12345"`
+	ExitCode  *int         `json:"exitCode,omitempty" example:"0"`
+	Error     string       `json:"error,omitempty"`
+	StartedAt time.Time    `json:"startedAt"`
+	EndedAt   *time.Time   `json:"endedAt,omitempty"`
+	Steps     []JobStep    `json:"steps,omitempty"`
+	Progress  *JobProgress `json:"progress,omitempty"`
+	// System marks a request of the background loops (e.g., drift detection, reaping), which are pruned to the latest ones
+	System bool `json:"system,omitempty" example:"false"`
+}
+
+// JobStep represents an OpenTofu command executed for a request.
+type JobStep struct {
+	Command   string     `json:"command" example:"tofu apply -auto-approve"`
+	Status    string     `json:"status" example:"Success"`
+	ExitCode  *int       `json:"exitCode,omitempty" example:"0"`
+	Error     string     `json:"error,omitempty"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt,omitempty"`
+}
+
+// JobProgress represents the progress of a request decoded from the machine-readable UI of OpenTofu
+// (i.e., plan, apply, destroy and refresh with -json).
+type JobProgress struct {
+	// Resources is the progress of each resource (key: resource address).
+	Resources map[string]ResourceProgress `json:"resources,omitempty"`
+	// Summary is the number of changes of the last plan or apply.
+	Summary *ChangeSummary `json:"summary,omitempty"`
+	// Diagnostics are the warnings and errors reported by OpenTofu.
+	Diagnostics []JobDiagnostic `json:"diagnostics,omitempty"`
+}
+
+// ResourceProgress represents the progress of an operation on a resource.
+type ResourceProgress struct {
+	Addr           string    `json:"addr" example:"aws_vpc.main"`
+	Action         string    `json:"action" example:"create"`
+	Status         string    `json:"status" example:"InProgress"`
+	IdValue        string    `json:"idValue,omitempty" example:"vpc-0123456789abcdef0"`
+	ElapsedSeconds int       `json:"elapsedSeconds,omitempty" example:"10"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ChangeSummary represents the number of resources to add, change and destroy (or added, changed and destroyed).
+type ChangeSummary struct {
+	Operation string `json:"operation" example:"apply"`
+	Add       int    `json:"add" example:"3"`
+	Change    int    `json:"change" example:"0"`
+	Destroy   int    `json:"destroy" example:"0"`
+	Import    int    `json:"import" example:"0"`
+}
+
+// JobDiagnostic represents a warning or an error reported by OpenTofu.
+type JobDiagnostic struct {
+	Severity string `json:"severity" example:"error"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
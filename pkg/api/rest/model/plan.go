@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Plan represents a plan saved by `tofu plan -out`.
+// Applying a plan applies exactly the changes reviewed in the plan.
+// CreatedBy, ApprovedBy and RejectedBy are the authenticated principals of the requests.
+type Plan struct {
+	Id           string      `json:"id" example:"1718000000000000000"`
+	TrId         string      `json:"trId" example:"tr01"`
+	Enrichment   string      `json:"enrichment" example:"vpn/aws-to-site"`
+	ReqId        string      `json:"reqId" example:"1718000000000000000"`
+	Status       string      `json:"status" example:"Planned"`
+	Summary      PlanSummary `json:"summary"`
+	CreatedBy    string      `json:"createdBy,omitempty" example:"bob"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	ApprovedBy   string      `json:"approvedBy,omitempty" example:"alice"`
+	RejectedBy   string      `json:"rejectedBy,omitempty" example:"bob"`
+	ReviewedAt   *time.Time  `json:"reviewedAt,omitempty"`
+	Comment      string      `json:"comment,omitempty" example:"Reviewed the VPN gateway changes"`
+	ExpiresAt    *time.Time  `json:"expiresAt,omitempty"`
+	AppliedAt    *time.Time  `json:"appliedAt,omitempty"`
+	AppliedReqId string      `json:"appliedReqId,omitempty" example:"1718000000000000001"`
+	StateSerial  int64       `json:"stateSerial" example:"3"`
+	StateLineage string      `json:"stateLineage,omitempty" example:"a1b2c3d4-e5f6-7890-abcd-ef1234567890"`
+}
+
+// PlanSummary represents the changes of a plan parsed from `tofu show -json`.
+type PlanSummary struct {
+	Add             int                     `json:"add" example:"3"`
+	Change          int                     `json:"change" example:"0"`
+	Destroy         int                     `json:"destroy" example:"0"`
+	Replace         int                     `json:"replace" example:"0"`
+	ResourceChanges []PlannedResourceChange `json:"resourceChanges,omitempty"`
+}
+
+// PlannedResourceChange represents a planned change of a resource.
+type PlannedResourceChange struct {
+	Address      string   `json:"address" example:"aws_vpc.main"`
+	Type         string   `json:"type" example:"aws_vpc"`
+	Name         string   `json:"name" example:"main"`
+	ProviderName string   `json:"providerName" example:"registry.opentofu.org/hashicorp/aws"`
+	Actions      []string `json:"actions" example:"create"`
+}
+
+// PlanReviewRequest represents a request to approve or reject a plan.
+// The reviewer is the authenticated principal of the request, not given by the request.
+type PlanReviewRequest struct {
+	Comment string `json:"comment,omitempty" example:"Reviewed the VPN gateway changes"`
+}
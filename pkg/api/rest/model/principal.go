@@ -0,0 +1,13 @@
+package model
+
+// Principal represents the authenticated caller of a request.
+type Principal struct {
+	// Name is the name of the caller (e.g., the username of BasicAuth)
+	Name string `json:"name" example:"default"`
+	// CredentialHolder is the credential holder (profile) bound to the caller
+	CredentialHolder string `json:"credentialHolder" example:"admin"`
+	// Admin is true if the caller may act as another credential holder
+	Admin bool `json:"admin" example:"true"`
+	// Method is the authentication method (e.g., basic)
+	Method string `json:"method" example:"basic"`
+}
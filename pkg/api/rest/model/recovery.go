@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// RecoveryReport represents the result of the recovery at startup,
+// which reconciles the requests and terrariums left running by a previous server process.
+type RecoveryReport struct {
+	Host        string               `json:"host" example:"mc-terrarium-0"`
+	StartedAt   time.Time            `json:"startedAt"`
+	FinishedAt  time.Time            `json:"finishedAt"`
+	ForceUnlock bool                 `json:"forceUnlock" example:"false"`
+	Requests    []RecoveredRequest   `json:"requests"`
+	Terrariums  []RecoveredTerrarium `json:"terrariums"`
+}
+
+// RecoveredRequest represents a request found running at startup.
+type RecoveredRequest struct {
+	TrId       string    `json:"trId" example:"tr01"`
+	ReqId      string    `json:"reqId" example:"1718000000000000000"`
+	Enrichment string    `json:"enrichment,omitempty" example:"vpn/aws-to-site"`
+	Command    string    `json:"command" example:"tofu apply -auto-approve"`
+	Pid        int       `json:"pid,omitempty" example:"12345"`
+	StartedAt  time.Time `json:"startedAt"`
+	// Action is Interrupted (the process no longer exists) or Skipped (the process still exists)
+	Action    string     `json:"action" example:"Interrupted"`
+	StateLock *StateLock `json:"stateLock,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// RecoveredTerrarium represents a terrarium (or an enrichment of it) whose execution status was Running at startup.
+type RecoveredTerrarium struct {
+	TrId string `json:"trId" example:"tr01"`
+	// Enrichment whose execution status was Running (empty for the terrarium itself)
+	Enrichment string `json:"enrichment,omitempty" example:"vpn/aws-to-site"`
+	ReqId      string `json:"reqId,omitempty" example:"1718000000000000000"`
+	// Action is Interrupted (the execution lock is released) or Skipped (the request is still running)
+	Action string `json:"action" example:"Interrupted"`
+	Error  string `json:"error,omitempty"`
+}
+
+// StateLock represents a lock of the OpenTofu state (i.e., .terraform.tfstate.lock.info)
+// left in the working directory of an interrupted request.
+type StateLock struct {
+	Id        string `json:"id" example:"b6a1f6d4-2a0c-4e4f-8f2b-5c1d2e3f4a5b"`
+	Operation string `json:"operation,omitempty" example:"OperationTypeApply"`
+	Who       string `json:"who,omitempty" example:"root@mc-terrarium-0"`
+	Created   string `json:"created,omitempty" example:"2024-06-10T00:00:00Z"`
+	Unlocked  bool   `json:"unlocked" example:"false"`
+	Error     string `json:"error,omitempty"`
+}
@@ -24,17 +24,8 @@ type CreateInfracodeOfGcpAzureVpnRequest struct {
 	TfVars TfVarsGcpAzureVpnTunnel `json:"tfVars"`
 }
 
-// Request body for sql-db
-type CreateInfracodeOfSqlDbRequest struct {
-	TfVars TfVarsSqlDb `json:"tfVars"`
-}
-
-// Request body for object-storage
-type CreateInfracodeOfObjectStorageRequest struct {
-	TfVars TfVarsObjectStorage `json:"tfVars"`
-}
-
-// Request body for message-broker
-type CreateInfracodeOfMessageBrokerRequest struct {
-	TfVars TfVarsMessageBroker `json:"tfVars"`
+// Request body for enrichments (e.g., sql-db, object-storage, message-broker)
+// The tfVars are decoded into the tfvars type of the enrichment (e.g., TfVarsSqlDb).
+type CreateInfracodeOfEnrichmentRequest struct {
+	TfVars map[string]interface{} `json:"tfVars" swaggertype:"object"`
 }
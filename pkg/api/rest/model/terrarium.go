@@ -1,15 +1,159 @@
 package model
 
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
 type TerrariumCreationRequest struct {
 	Name        string `json:"name" default:"tr01" example:"tr01" validate:"required"`
 	Description string `json:"description,omitempty" default:"This terrarium enriches ..." example:"This terrarium enriches ..."`
+	// ApprovalRequired requires a second person to approve a plan before it is applied
+	ApprovalRequired bool `json:"approvalRequired,omitempty" default:"false" example:"false"`
+	// Labels are the labels to organize and select terrariums (e.g., env=staging, team=network)
+	Labels map[string]string `json:"labels,omitempty"`
+	// TTL is the time to live of the terrarium (e.g., 72h), after which its resources are destroyed (exclusive with expiresAt)
+	TTL string `json:"ttl,omitempty" example:"72h"`
+	// ExpiresAt is the time after which the resources of the terrarium are destroyed (exclusive with ttl)
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// CloneTerrariumRequest represents a request to clone a terrarium as a new terrarium.
+type CloneTerrariumRequest struct {
+	// Name is the name (ID) of the new terrarium
+	Name        string `json:"name" default:"tr02" example:"tr02" validate:"required"`
+	Description string `json:"description,omitempty" example:"This terrarium enriches ..."`
+	// TfVars override the tfvars of each enrichment (key: enrichment) as a JSON merge patch (i.e., null removes a tfvar)
+	TfVars map[string]map[string]interface{} `json:"tfVars,omitempty" swaggertype:"object"`
 }
 
 type TerrariumInfo struct {
-	Name              string   `json:"name" default:"tr01" example:"tr01" validate:"required"`
-	Description       string   `json:"description,omitempty" default:"This terrarium enriches ..." example:"This terrarium enriches ..."`
-	Id                string   `json:"id" default:"tr01" example:"tr01" validate:"required"`
-	Enrichments       string   `json:"enrichments,omitempty" default:"" example:"vpn/aws-to-site"`
+	Name        string `json:"name" default:"tr01" example:"tr01" validate:"required"`
+	Description string `json:"description,omitempty" default:"This terrarium enriches ..." example:"This terrarium enriches ..."`
+	Id          string `json:"id" default:"tr01" example:"tr01" validate:"required"`
+	// Phase is the least progressed phase of the enrichments (e.g., Empty, Initialized, Planned, Ready, Failed)
+	Phase string `json:"phase,omitempty" example:"Ready"`
+	// EnrichmentNames are the names of the enrichments, separated by commas (e.g., testbed,vpn/site-to-site).
+	// Deprecated: it is kept in the shape of the former single enrichment (i.e., a string), see enrichmentDetails instead.
+	EnrichmentNames string `json:"enrichments,omitempty" default:"" example:"vpn/aws-to-site"`
+	// Enrichments are the enrichments of the terrarium by name (e.g., testbed, vpn/site-to-site, sql-db)
+	Enrichments Enrichments `json:"enrichmentDetails,omitempty"`
+	// Providers are the providers engaged in the enrichments
 	Providers         []string `json:"providers,omitempty" default:"" example:"aws,azure,gcp"`
 	CredentialProfile string   `json:"credentialProfile"` // The name of the credential profile (holder) used for this terrarium
+	ApprovalRequired  bool     `json:"approvalRequired,omitempty" example:"false"`
+	// Labels are the labels to organize and select terrariums (e.g., env=staging, team=network)
+	Labels    map[string]string `json:"labels,omitempty"`
+	CreatedAt time.Time         `json:"createdAt"`
+	// ExpiresAt is the time after which the resources are destroyed and the environments are emptied out (i.e., auto-destroy)
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// ExpiryWarnedAt is the time when the expiry was warned (i.e., within the grace period)
+	ExpiryWarnedAt *time.Time `json:"expiryWarnedAt,omitempty"`
+	// ReapingSince is the time when the reaper claimed the expired terrarium to destroy its resources,
+	// during which the lease cannot be extended
+	ReapingSince *time.Time `json:"reapingSince,omitempty"`
+}
+
+// MarshalJSON encodes the terrarium info with the names of the enrichments (i.e., enrichments) derived from enrichmentDetails.
+func (t TerrariumInfo) MarshalJSON() ([]byte, error) {
+	type plain TerrariumInfo
+	t.EnrichmentNames = strings.Join(t.Enrichments.Names(), ",")
+	return json.Marshal(plain(t))
+}
+
+// UnmarshalJSON decodes the terrarium info.
+// The enrichments stored by an older version (i.e., a name, or the enrichments by name in enrichments)
+// are decoded as enrichmentDetails if enrichmentDetails is absent.
+func (t *TerrariumInfo) UnmarshalJSON(data []byte) error {
+	type plain TerrariumInfo
+	aux := struct {
+		*plain
+		// Enrichments shadows EnrichmentNames of plain to decode both the former shapes
+		Enrichments Enrichments `json:"enrichments,omitempty"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(t.Enrichments) == 0 && len(aux.Enrichments) > 0 {
+		t.Enrichments = aux.Enrichments
+	}
+	t.EnrichmentNames = strings.Join(t.Enrichments.Names(), ",")
+	return nil
+}
+
+// ExtendLeaseRequest represents a request to extend the lease of a terrarium.
+// Either ttl or expiresAt is given.
+type ExtendLeaseRequest struct {
+	// TTL is the duration to extend the lease by from the current expiry (or now if expired or not set)
+	TTL string `json:"ttl,omitempty" example:"24h"`
+	// ExpiresAt is the new expiry of the lease
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// TerrariumEvent represents an event of a terrarium (e.g., the expiry is coming, the resources are destroyed on expiry).
+type TerrariumEvent struct {
+	Type      string    `json:"type" example:"expiry_warning"`
+	Message   string    `json:"message" example:"the terrarium (trId: tr01) expires in 1h0m0s"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UpdateLabelsRequest represents a request to replace the labels of a terrarium.
+type UpdateLabelsRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// EnrichmentInfo represents an enrichment of a terrarium,
+// which has its own working directory (i.e., .terrarium/{trId}/{name}), state, status and providers.
+type EnrichmentInfo struct {
+	Name      string   `json:"name" example:"testbed"`
+	Providers []string `json:"providers,omitempty" example:"aws,gcp"`
+	// Phase is the phase of the enrichment
+	// (i.e., Empty, Initialized, Planned, Provisioning, Ready, Destroying, Destroyed, Failed)
+	Phase string `json:"phase,omitempty" example:"Ready"`
+	// Status is the execution status of the last command in the enrichment (e.g., Running, Success, Failed)
+	Status    string    `json:"status,omitempty" example:"Success"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Enrichments are the enrichments of a terrarium by name.
+type Enrichments map[string]EnrichmentInfo
+
+// UnmarshalJSON decodes the enrichments.
+// A name (string) stored by an older version, which allowed a single enrichment, is decoded as an enrichment of the name.
+func (e *Enrichments) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*e = Enrichments{}
+		if name != "" {
+			(*e)[name] = EnrichmentInfo{Name: name}
+		}
+		return nil
+	}
+
+	m := map[string]EnrichmentInfo{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*e = m
+	return nil
+}
+
+// Names returns the names of the enrichments in order.
+func (e Enrichments) Names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OutputReference represents a reference to an output of another enrichment in a request (or tfvars),
+// which is given as {"$ref": "{enrichment}.{output}[.{path}]"} (e.g., {"$ref": "testbed.aws_testbed_info.vpc_id"}).
+type OutputReference struct {
+	// Path is the location of the reference in the tfvars (e.g., aws.vpc_id)
+	Path string `json:"path" example:"aws.vpc_id"`
+	Ref  string `json:"ref" example:"testbed.aws_testbed_info.vpc_id"`
 }
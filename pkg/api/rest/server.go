@@ -35,6 +35,8 @@ import (
 
 	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/handler"
 	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/middlewares"
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
 
 	// REST API (echo)
 	"github.com/labstack/echo/v4"
@@ -140,8 +142,9 @@ func RunServer(port string) {
 	// 3. In Validator, use: bcrypt.CompareHashAndPassword([]byte(apiPass), []byte(password))
 	// 4. Generate hash: make bcrypt PASSWORD=mypassword
 
+	// Authenticate the callers by a bearer token (API key or JWT), or by BasicAuth (i.e., the shared account)
 	if enableAuth {
-		e.Use(middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		e.Use(middlewares.Authenticator(middlewares.AuthenticatorConfig{
 			// Skip authentication for some routes that do not require authentication
 			Skipper: func(c echo.Context) bool {
 				if c.Path() == "/terrarium/readyz" ||
@@ -152,18 +155,24 @@ func RunServer(port string) {
 				}
 				return false
 			},
-			Validator: func(username, password string, c echo.Context) (bool, error) {
+			BasicValidator: func(username, password string, c echo.Context) (model.Principal, bool) {
 				// Username verification using constant time comparison to prevent timing attacks
 				if subtle.ConstantTimeCompare([]byte(username), []byte(apiUser)) != 1 {
-					return false, nil // Authentication failed: invalid username
+					return model.Principal{}, false // Authentication failed: invalid username
 				}
 
 				// Password verification using constant time comparison (plaintext)
 				if subtle.ConstantTimeCompare([]byte(password), []byte(apiPass)) != 1 {
-					return false, nil // Authentication failed: invalid password
+					return model.Principal{}, false // Authentication failed: invalid password
 				}
 
-				return true, nil // Authentication successful
+				// The shared account is an admin, which holds the admin credentials
+				return model.Principal{
+					Name:             username,
+					CredentialHolder: secrets.AdminProfile,
+					Admin:            true,
+					Method:           "basic",
+				}, true // Authentication successful
 			},
 		}))
 	}
@@ -189,18 +198,75 @@ func RunServer(port string) {
 	e.GET("/terrarium/httpVersion", handler.HTTPVersion)
 	e.GET("/terrarium/tofuVersion", handler.TofuVersion)
 
+	if middlewares.StrictHolder() && !enableAuth {
+		log.Warn().Msg("strict credential holder is enabled without authentication, so that the requests of terrariums are rejected")
+	}
+
 	// A terrarium group has /terrarium as prefix
-	gTr := e.Group("/terrarium")
+	// (the credential holder of a request is resolved for all the APIs of the group)
+	gTr := e.Group("/terrarium", middlewares.CredentialHolderResolver)
 
 	// Terrarium APIs
 	gTr.POST("/tr", handler.IssueTerrarium)
 	gTr.GET("/tr", handler.ReadAllTerrarium)
 	gTr.GET("/tr/:trId", handler.ReadTerrarium)
 	gTr.DELETE("/tr/:trId", handler.EraseTerrarium)
+	gTr.POST("/tr/import", handler.ImportTerrarium)
+
+	// Enrichments (i.e., kinds of resources) served by /tr/:trId/enrichments/:kind
+	gTr.GET("/enrichments", handler.ListEnrichments)
+
+	// Recovery API (the report is filtered by the credential holder of the caller)
+	gTr.GET("/recovery", handler.GetRecoveryReport)
+
+	// Credential profile APIs (i.e., the credential holders in the secrets store)
+	gTr.GET("/credential-profiles", handler.ListCredentialProfiles)
+	gTr.POST("/credential-profiles", handler.CreateCredentialProfile)
+	gTr.GET("/credential-profiles/:name", handler.GetCredentialProfile)
+	gTr.PUT("/credential-profiles/:name", handler.UpdateCredentialProfile)
+	gTr.DELETE("/credential-profiles/:name", handler.DeleteCredentialProfile)
+	gTr.POST("/credential-profiles/:name/verify", handler.VerifyCredentialProfile)
+
+	// API key APIs (i.e., the bearer tokens of the users)
+	gTr.GET("/whoami", handler.WhoAmI)
+	gTr.GET("/api-keys", handler.ListApiKeys)
+	gTr.POST("/api-keys", handler.CreateApiKey)
+	gTr.DELETE("/api-keys/:keyId", handler.RevokeApiKey)
 
 	// Secured group for resource operations
 	gTrSecured := gTr.Group("/tr/:trId", middlewares.CredentialProfileValidator)
 
+	// Request management APIs
+	gTrSecured.GET("/requests", handler.ListRequests)
+	gTrSecured.GET("/requests/:reqId", handler.GetRequest)
+	gTrSecured.GET("/requests/:reqId/logs", handler.GetRequestLogs)
+	gTrSecured.GET("/requests/:reqId/events", handler.GetRequestEvents)
+	gTrSecured.POST("/requests/:reqId/cancel", handler.CancelRequest)
+
+	// Plan management APIs
+	gTrSecured.GET("/plans", handler.ListPlans)
+	gTrSecured.GET("/plans/:planId", handler.GetPlan)
+	gTrSecured.POST("/plans/:planId/approve", handler.ApprovePlan)
+	gTrSecured.POST("/plans/:planId/reject", handler.RejectPlan)
+
+	// Enrichment and output APIs of a terrarium
+	gTrSecured.GET("/enrichments", handler.ListTerrariumEnrichments)
+	gTrSecured.GET("/outputs", handler.GetTerrariumOutputs)
+
+	// Export and clone APIs of a terrarium
+	gTrSecured.GET("/export", handler.ExportTerrarium)
+	gTrSecured.POST("/clone", handler.CloneTerrarium)
+
+	// Label API of a terrarium
+	gTrSecured.PUT("/labels", handler.UpdateTerrariumLabels)
+
+	// Lease and event APIs of a terrarium
+	gTrSecured.POST("/extend", handler.ExtendTerrariumLease)
+	gTrSecured.GET("/events", handler.ListTerrariumEvents)
+
+	// Drift API of a terrarium
+	gTrSecured.GET("/drift", handler.GetTerrariumDrift)
+
 	// [Testbed] Resource operations (high-level APIs for resource-centric operations)
 	gTrSecured.POST("/testbed", handler.CreateTestbed)
 	gTrSecured.GET("/testbed", handler.GetTestbed)
@@ -264,35 +330,27 @@ func RunServer(port string) {
 	gTrSecured.DELETE("/vpn/gcp-azure", handler.DestroyGcpAzureVpn)
 	gTrSecured.GET("/vpn/gcp-azure/request/:requestId", handler.GetRequestStatusOfGcpAzureVpn)
 
-	// SQL database APIs
-	gTrSecured.POST("/sql-db/env", handler.InitEnvForSqlDb)
-	gTrSecured.DELETE("/sql-db/env", handler.ClearEnvOfSqlDb)
-	gTrSecured.POST("/sql-db/infracode", handler.CreateInfracodeForSqlDb)
-	gTrSecured.POST("/sql-db/plan", handler.CheckInfracodeForSqlDb)
-	gTrSecured.POST("/sql-db", handler.CreateSqlDb)
-	gTrSecured.GET("/sql-db", handler.GetResourceInfoOfSqlDb)
-	gTrSecured.DELETE("/sql-db", handler.DestroySqlDb)
-	gTrSecured.GET("/sql-db/request/:requestId", handler.GetRequestStatusOfSqlDb)
-
-	// Object Storage APIs
-	gTrSecured.POST("/object-storage/env", handler.InitEnvForObjectStorage)
-	gTrSecured.DELETE("/object-storage/env", handler.ClearEnvOfObjectStorage)
-	gTrSecured.POST("/object-storage/infracode", handler.CreateInfracodeForObjectStorage)
-	gTrSecured.POST("/object-storage/plan", handler.CheckInfracodeForObjectStorage)
-	gTrSecured.POST("/object-storage", handler.CreateObjectStorage)
-	gTrSecured.GET("/object-storage", handler.GetResourceInfoOfObjectStorage)
-	gTrSecured.DELETE("/object-storage", handler.DestroyObjectStorage)
-	gTrSecured.GET("/object-storage/request/:requestId", handler.GetRequestStatusOfObjectStorage)
-
-	// Message Broker APIs
-	gTrSecured.POST("/message-broker/env", handler.InitEnvForMessageBroker)
-	gTrSecured.DELETE("/message-broker/env", handler.ClearEnvOfMessageBroker)
-	gTrSecured.POST("/message-broker/infracode", handler.CreateInfracodeForMessageBroker)
-	gTrSecured.POST("/message-broker/plan", handler.CheckInfracodeForMessageBroker)
-	gTrSecured.POST("/message-broker", handler.CreateMessageBroker)
-	gTrSecured.GET("/message-broker", handler.GetResourceInfoOfMessageBroker)
-	gTrSecured.DELETE("/message-broker", handler.DestroyMessageBroker)
-	gTrSecured.GET("/message-broker/request/:requestId", handler.GetRequestStatusOfMessageBroker)
+	// Enrichment APIs (e.g., sql-db, object-storage, message-broker registered in the enrichment package)
+	gTrSecured.POST("/enrichments/:kind/env", handler.InitEnvForEnrichment)
+	gTrSecured.DELETE("/enrichments/:kind/env", handler.ClearEnvOfEnrichment)
+	gTrSecured.POST("/enrichments/:kind/infracode", handler.CreateInfracodeForEnrichment)
+	gTrSecured.POST("/enrichments/:kind/plan", handler.CheckInfracodeForEnrichment)
+	gTrSecured.POST("/enrichments/:kind", handler.CreateEnrichment)
+	gTrSecured.GET("/enrichments/:kind", handler.GetResourceInfoOfEnrichment)
+	gTrSecured.DELETE("/enrichments/:kind", handler.DestroyEnrichment)
+	gTrSecured.GET("/enrichments/:kind/request/:requestId", handler.GetRequestStatusOfEnrichment)
+
+	// Legacy enrichment APIs (deprecated aliases of /enrichments/:kind)
+	for _, kind := range []string{"sql-db", "object-storage", "message-broker"} {
+		gTrSecured.POST("/"+kind+"/env", handler.InitEnvForLegacyEnrichment)
+		gTrSecured.DELETE("/"+kind+"/env", handler.ClearEnvOfLegacyEnrichment)
+		gTrSecured.POST("/"+kind+"/infracode", handler.CreateInfracodeForLegacyEnrichment)
+		gTrSecured.POST("/"+kind+"/plan", handler.CheckInfracodeForLegacyEnrichment)
+		gTrSecured.POST("/"+kind, handler.CreateLegacyEnrichment)
+		gTrSecured.GET("/"+kind, handler.GetResourceInfoOfLegacyEnrichment)
+		gTrSecured.DELETE("/"+kind, handler.DestroyLegacyEnrichment)
+		gTrSecured.GET("/"+kind+"/request/:requestId", handler.GetRequestStatusOfLegacyEnrichment)
+	}
 
 	selfEndpoint := config.Terrarium.Self.Endpoint
 	apidashboard := " http://" + selfEndpoint + "/terrarium/api"
@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] API keys
+ * An API key is "trk_{keyId}_{secret}", where the secret is 256 random bits.
+ * The record of a key (/apikey/{keyId}) has the SHA-256 hash of the secret only,
+ * which is enough for a random secret of this size (i.e., no need of a slow hash like bcrypt),
+ * so that a key cannot be recovered from the lkvstore.
+ * A revoked key is kept with revokedAt for the audit.
+ */
+
+// ApiKeyPrefix is the prefix of the API keys, which tells the API keys from the JWTs.
+const ApiKeyPrefix = "trk_"
+
+var (
+	// namePattern is the pattern of the principal names of the API keys (e.g., alice, alice@example.com)
+	namePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._@-]{0,126}[A-Za-z0-9])?$`)
+	// holderPattern is the pattern of the credential holders (i.e., the credential profile names)
+	holderPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+	keyIdPattern  = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+// apiKeyRecord is the record of an API key in the lkvstore.
+type apiKeyRecord struct {
+	model.ApiKey
+	// Hash is the SHA-256 hash of the secret (hex)
+	Hash string `json:"hash"`
+}
+
+func apiKeyKey(keyId string) string {
+	return "/apikey/" + keyId
+}
+
+// CreateApiKey creates an API key for a principal, and returns it with the key, which is not shown again.
+func CreateApiKey(req model.CreateApiKeyRequest, createdBy string) (model.CreateApiKeyResponse, error) {
+	if !namePattern.MatchString(req.Name) {
+		return model.CreateApiKeyResponse{}, fmt.Errorf("%w, name (%s) must be 1-128 alphanumeric characters, '-', '_', '.' or '@', beginning and ending with an alphanumeric",
+			ErrInvalidApiKey, req.Name)
+	}
+	if !holderPattern.MatchString(req.CredentialHolder) {
+		return model.CreateApiKeyResponse{}, fmt.Errorf("%w, credential holder (%s) must be a credential profile name",
+			ErrInvalidApiKey, req.CredentialHolder)
+	}
+	if req.TtlHours < 0 {
+		return model.CreateApiKeyResponse{}, fmt.Errorf("%w, ttlHours (%d) must not be negative", ErrInvalidApiKey, req.TtlHours)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return model.CreateApiKeyResponse{}, err
+	}
+	sum := sha256.Sum256([]byte(secret))
+
+	now := time.Now()
+	record := apiKeyRecord{
+		ApiKey: model.ApiKey{
+			Name:             req.Name,
+			CredentialHolder: req.CredentialHolder,
+			Admin:            req.Admin,
+			Description:      req.Description,
+			CreatedBy:        createdBy,
+			CreatedAt:        now,
+		},
+		Hash: hex.EncodeToString(sum[:]),
+	}
+	if req.TtlHours > 0 {
+		expiresAt := now.Add(time.Duration(req.TtlHours) * time.Hour)
+		record.ExpiresAt = &expiresAt
+	}
+
+	// Retry on the (unlikely) collision of the key IDs
+	for i := 0; i < 3; i++ {
+		keyId, err := randomHex(8)
+		if err != nil {
+			return model.CreateApiKeyResponse{}, err
+		}
+		record.Id = keyId
+		ok, err := lkvstore.PutIfAbsent(apiKeyKey(keyId), record)
+		if err != nil {
+			return model.CreateApiKeyResponse{}, fmt.Errorf("failed to store the API key: %w", err)
+		}
+		if ok {
+			log.Info().Msgf("created the API key (id: %s, name: %s, holder: %s, admin: %t) by %s",
+				keyId, record.Name, record.CredentialHolder, record.Admin, createdBy)
+			return model.CreateApiKeyResponse{
+				ApiKey: record.ApiKey,
+				Key:    ApiKeyPrefix + keyId + "_" + secret,
+			}, nil
+		}
+	}
+	return model.CreateApiKeyResponse{}, fmt.Errorf("failed to allocate an ID of the API key")
+}
+
+// GetApiKey reads an API key (without the hash).
+func GetApiKey(keyId string) (model.ApiKey, error) {
+	record, err := getApiKeyRecord(keyId)
+	if err != nil {
+		return model.ApiKey{}, err
+	}
+	return record.ApiKey, nil
+}
+
+// ListApiKeys lists the API keys of a principal (or all if name is empty) in order of their creation time.
+func ListApiKeys(name string) ([]model.ApiKey, error) {
+
+	keys := []model.ApiKey{}
+	values, exists := lkvstore.GetWithPrefix("/apikey/")
+	if !exists {
+		return keys, nil
+	}
+
+	for _, value := range values {
+		record := apiKeyRecord{}
+		if err := json.Unmarshal([]byte(value), &record); err != nil {
+			log.Debug().Msgf("failed to unmarshal API key: %v", err)
+			continue
+		}
+		if name == "" || record.Name == name {
+			keys = append(keys, record.ApiKey)
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// RevokeApiKey revokes an API key, which is kept for the audit. Revoking a revoked key does nothing.
+func RevokeApiKey(keyId string) (model.ApiKey, error) {
+	record, err := getApiKeyRecord(keyId)
+	if err != nil {
+		return model.ApiKey{}, err
+	}
+	if record.RevokedAt != nil {
+		return record.ApiKey, nil
+	}
+
+	now := time.Now()
+	record.RevokedAt = &now
+	if err := lkvstore.Put(apiKeyKey(keyId), record); err != nil {
+		return model.ApiKey{}, fmt.Errorf("failed to store the API key: %w", err)
+	}
+	log.Info().Msgf("revoked the API key (id: %s, name: %s)", keyId, record.Name)
+	return record.ApiKey, nil
+}
+
+// AuthenticateApiKey authenticates an API key, and returns the principal bound to it.
+func AuthenticateApiKey(key string) (model.Principal, error) {
+	keyId, secret, ok := strings.Cut(strings.TrimPrefix(key, ApiKeyPrefix), "_")
+	if !ok || !keyIdPattern.MatchString(keyId) || secret == "" {
+		return model.Principal{}, fmt.Errorf("%w, malformed API key", ErrInvalidToken)
+	}
+
+	record, err := getApiKeyRecord(keyId)
+	if err != nil {
+		return model.Principal{}, fmt.Errorf("%w, unknown API key", ErrInvalidToken)
+	}
+	sum := sha256.Sum256([]byte(secret))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(record.Hash)) != 1 {
+		return model.Principal{}, fmt.Errorf("%w, unknown API key", ErrInvalidToken)
+	}
+	if record.RevokedAt != nil {
+		return model.Principal{}, fmt.Errorf("%w, API key (%s) was revoked at %s", ErrInvalidToken, keyId, record.RevokedAt.Format(time.RFC3339))
+	}
+	if record.ExpiresAt != nil && time.Now().After(*record.ExpiresAt) {
+		return model.Principal{}, fmt.Errorf("%w, API key (%s) expired at %s", ErrInvalidToken, keyId, record.ExpiresAt.Format(time.RFC3339))
+	}
+
+	return model.Principal{
+		Name:             record.Name,
+		CredentialHolder: record.CredentialHolder,
+		Admin:            record.Admin,
+		Method:           MethodApiKey,
+	}, nil
+}
+
+func getApiKeyRecord(keyId string) (apiKeyRecord, error) {
+	record := apiKeyRecord{}
+	if !keyIdPattern.MatchString(keyId) {
+		return record, fmt.Errorf("%w (id: %s)", ErrApiKeyNotFound, keyId)
+	}
+	value, exists := lkvstore.Get(apiKeyKey(keyId))
+	if !exists {
+		return record, fmt.Errorf("%w (id: %s)", ErrApiKeyNotFound, keyId)
+	}
+	if err := json.Unmarshal([]byte(value), &record); err != nil {
+		return record, fmt.Errorf("failed to unmarshal API key: %w", err)
+	}
+	return record, nil
+}
+
+// randomHex returns n random bytes in hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,89 @@
+// Auth
+//
+// The auth package authenticates the callers of the API by bearer tokens, besides BasicAuth (i.e., the shared account):
+//   - API keys: per-user keys issued by the API (see CreateApiKey), as "trk_{keyId}_{secret}".
+//     Only the SHA-256 hash of the secret is stored in the lkvstore, and the key is shown once when created.
+//     A key can be revoked, or expires if it has a TTL.
+//   - JWTs: tokens issued by an OIDC issuer (e.g., Keycloak), verified by the JSON Web Key Set (JWKS) of the issuer,
+//     which is given by a URL, discovered from the issuer (/.well-known/openid-configuration), or read from a local file (e.g., for tests).
+//
+// Both are resolved to a principal (model.Principal), whose credential holder is bound to the API key,
+// or mapped from a claim of the JWT (e.g., credential_holder), so that each user has its own identity.
+//
+// The package-level functions operate on the configuration initialized by Init.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+)
+
+// Authentication methods of the principals
+const (
+	MethodApiKey = "apikey"
+	MethodJwt    = "jwt"
+)
+
+var (
+	// ErrInvalidToken is returned if a bearer token is malformed, not verified, expired or revoked.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInvalidApiKey is returned if a request to create an API key is invalid.
+	ErrInvalidApiKey = errors.New("invalid API key")
+	// ErrApiKeyNotFound is returned if an API key does not exist.
+	ErrApiKeyNotFound = errors.New("API key not found")
+)
+
+// Config is the configuration of the authentication by bearer tokens.
+type Config struct {
+	// Jwt is the configuration of the JWT verification (disabled if neither issuer nor JWKS is given)
+	Jwt JwtConfig
+}
+
+var (
+	mu       sync.RWMutex
+	verifier *JwtVerifier
+)
+
+// Init initializes the JWT verifier if configured. API keys need no initialization.
+func Init(config Config) error {
+	var v *JwtVerifier
+	if config.Jwt.Issuer != "" || config.Jwt.JwksUrl != "" || config.Jwt.JwksFile != "" {
+		var err error
+		v, err = NewJwtVerifier(config.Jwt)
+		if err != nil {
+			return err
+		}
+	}
+
+	mu.Lock()
+	verifier = v
+	mu.Unlock()
+	return nil
+}
+
+// JwtEnabled reports whether the JWTs are accepted.
+func JwtEnabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return verifier != nil
+}
+
+// Authenticate authenticates a bearer token, which is an API key (i.e., prefixed with trk_) or a JWT.
+func Authenticate(ctx context.Context, token string) (model.Principal, error) {
+	if strings.HasPrefix(token, ApiKeyPrefix) {
+		return AuthenticateApiKey(token)
+	}
+
+	mu.RLock()
+	v := verifier
+	mu.RUnlock()
+	if v == nil {
+		return model.Principal{}, fmt.Errorf("%w, not an API key and JWT is not configured", ErrInvalidToken)
+	}
+	return v.Authenticate(ctx, token)
+}
@@ -0,0 +1,534 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha512" // SHA-384 and SHA-512 of RS384, ES512, etc.
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] JWT verification
+ * A JWT is verified by the key of its kid in the JWKS, by one of RS256/384/512, PS256/384/512, ES256/384/512 or EdDSA
+ * (i.e., no "none" nor HMAC, which would need a shared secret). RSA keys shorter than 2048 bits are skipped.
+ * The JWKS is cached and refreshed periodically, or on an unknown kid (e.g., a rotated key) at most once per minute.
+ * The token must not be expired (exp is required), and must have the issuer and the audience if configured.
+ */
+
+const (
+	defaultNameClaim       = "preferred_username"
+	defaultHolderClaim     = "credential_holder"
+	defaultLeeway          = time.Minute
+	defaultRefreshInterval = time.Hour
+	// minRefreshInterval limits the refresh of the JWKS on unknown kids
+	minRefreshInterval = time.Minute
+	maxJwksSize        = 1 << 20
+	// minRsaKeyBits is the minimum size of the RSA keys in the JWKS
+	minRsaKeyBits = 2048
+)
+
+// JwtConfig is the configuration of the JWT verification.
+type JwtConfig struct {
+	// Issuer is the expected issuer (iss), whose JWKS is discovered if neither JwksUrl nor JwksFile is given
+	Issuer string
+	// Audience is the expected audience (aud), not checked if empty
+	Audience string
+	// JwksUrl is the URL of the JWKS (e.g., https://keycloak/realms/{realm}/protocol/openid-connect/certs)
+	JwksUrl string
+	// JwksFile is the local file of the JWKS, preferred to JwksUrl (e.g., for tests)
+	JwksFile string
+	// NameClaim is the claim of the principal name (default: preferred_username, or sub if missing)
+	NameClaim string
+	// HolderClaim is the claim of the credential holder (default: credential_holder)
+	HolderClaim string
+	// AdminClaim and AdminValue make the principal an admin if the claim has (or contains) the value
+	// (e.g., groups and terrarium-admin, or a boolean claim and true)
+	AdminClaim string
+	AdminValue string
+	// Leeway is the clock skew allowed to check exp and nbf (default: 1m)
+	Leeway time.Duration
+	// RefreshInterval is the interval to refresh the JWKS (default: 1h)
+	RefreshInterval time.Duration
+	// Timeout is the timeout of a request to the issuer (default: 10s)
+	Timeout time.Duration
+}
+
+// JwtVerifier verifies the JWTs by the JWKS of an issuer.
+type JwtVerifier struct {
+	config JwtConfig
+	http   *http.Client
+
+	mu sync.Mutex
+	// jwksUrl is the URL of the JWKS, given or discovered
+	jwksUrl     string
+	keys        map[string]jsonWebKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+}
+
+// jsonWebKey is a public key in the JWKS.
+type jsonWebKey struct {
+	// alg is the algorithm of the key, if specified by the JWKS
+	alg string
+	key crypto.PublicKey
+}
+
+// NewJwtVerifier returns a JWT verifier, loading the JWKS.
+// It fails if the JWKS file cannot be loaded, but not if the JWKS URL cannot be reached (i.e., loaded on demand).
+func NewJwtVerifier(config JwtConfig) (*JwtVerifier, error) {
+	if config.NameClaim == "" {
+		config.NameClaim = defaultNameClaim
+	}
+	if config.HolderClaim == "" {
+		config.HolderClaim = defaultHolderClaim
+	}
+	if config.Leeway <= 0 {
+		config.Leeway = defaultLeeway
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = defaultRefreshInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.Issuer == "" && config.JwksUrl == "" && config.JwksFile == "" {
+		return nil, errors.New("issuer, JWKS URL or JWKS file is required to verify the JWTs")
+	}
+
+	v := &JwtVerifier{
+		config:  config,
+		http:    &http.Client{Timeout: config.Timeout},
+		jwksUrl: config.JwksUrl,
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.refresh(context.Background()); err != nil {
+		if config.JwksFile != "" {
+			return nil, err
+		}
+		log.Warn().Err(err).Msg("failed to load the JWKS, which will be loaded on demand")
+	}
+	return v, nil
+}
+
+// Authenticate verifies a JWT, and returns the principal mapped from its claims.
+func (v *JwtVerifier) Authenticate(ctx context.Context, token string) (model.Principal, error) {
+	claims, err := v.Verify(ctx, token)
+	if err != nil {
+		return model.Principal{}, err
+	}
+
+	name := stringClaim(claims, v.config.NameClaim)
+	if name == "" {
+		name = stringClaim(claims, "sub")
+	}
+	if name == "" {
+		return model.Principal{}, fmt.Errorf("%w, neither %s nor sub is in the claims", ErrInvalidToken, v.config.NameClaim)
+	}
+
+	admin := false
+	if v.config.AdminClaim != "" && v.config.AdminValue != "" {
+		admin = hasValue(claimValue(claims, v.config.AdminClaim), v.config.AdminValue)
+	}
+
+	return model.Principal{
+		Name:             name,
+		CredentialHolder: stringClaim(claims, v.config.HolderClaim),
+		Admin:            admin,
+		Method:           MethodJwt,
+	}, nil
+}
+
+// Verify verifies the signature and the claims of a JWT, and returns the claims.
+func (v *JwtVerifier) Verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w, malformed JWT", ErrInvalidToken)
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w, malformed JWT header: %v", ErrInvalidToken, err)
+	}
+	claims := map[string]interface{}{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w, malformed JWT claims: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w, malformed JWT signature: %v", ErrInvalidToken, err)
+	}
+
+	key, err := v.keyOf(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return nil, fmt.Errorf("%w, algorithm (%s) does not match the key (%s)", ErrInvalidToken, header.Alg, key.alg)
+	}
+	if err := verifySignature(header.Alg, key.key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validateClaims checks the registered claims (i.e., exp, nbf, iss and aud).
+func (v *JwtVerifier) validateClaims(claims map[string]interface{}) error {
+	now := time.Now()
+
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("%w, no expiration time (exp)", ErrInvalidToken)
+	}
+	if now.After(exp.Add(v.config.Leeway)) {
+		return fmt.Errorf("%w, expired at %s", ErrInvalidToken, exp.Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(v.config.Leeway).Before(nbf) {
+		return fmt.Errorf("%w, not valid before %s", ErrInvalidToken, nbf.Format(time.RFC3339))
+	}
+	if v.config.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.config.Issuer {
+			return fmt.Errorf("%w, unexpected issuer (%s)", ErrInvalidToken, iss)
+		}
+	}
+	if v.config.Audience != "" && !hasValue(claims["aud"], v.config.Audience) {
+		return fmt.Errorf("%w, audience does not contain %s", ErrInvalidToken, v.config.Audience)
+	}
+	return nil
+}
+
+// keyOf returns the key of a kid, refreshing the JWKS if stale or the kid is unknown.
+// A JWT without kid is verified by the only key of the JWKS.
+func (v *JwtVerifier) keyOf(ctx context.Context, kid string) (jsonWebKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	_, known := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > v.config.RefreshInterval
+	if (!known || stale) && time.Since(v.attemptedAt) > minRefreshInterval {
+		if err := v.refresh(ctx); err != nil {
+			log.Warn().Err(err).Msg("failed to refresh the JWKS")
+		}
+	}
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	return jsonWebKey{}, fmt.Errorf("%w, no key (kid: %s) in the JWKS", ErrInvalidToken, kid)
+}
+
+// refresh loads the JWKS. The caller must hold v.mu.
+func (v *JwtVerifier) refresh(ctx context.Context) error {
+	v.attemptedAt = time.Now()
+
+	var data []byte
+	var err error
+	if v.config.JwksFile != "" {
+		data, err = os.ReadFile(v.config.JwksFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the JWKS file: %w", err)
+		}
+	} else {
+		if v.jwksUrl == "" {
+			if v.jwksUrl, err = v.discover(ctx); err != nil {
+				return err
+			}
+		}
+		if data, err = v.get(ctx, v.jwksUrl); err != nil {
+			return fmt.Errorf("failed to get the JWKS: %w", err)
+		}
+	}
+
+	keys, err := parseJwks(data)
+	if err != nil {
+		return err
+	}
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	log.Debug().Msgf("loaded the JWKS (%d keys)", len(keys))
+	return nil
+}
+
+// discover returns the URL of the JWKS by the OpenID Provider Configuration of the issuer.
+func (v *JwtVerifier) discover(ctx context.Context) (string, error) {
+	url := strings.TrimSuffix(v.config.Issuer, "/") + "/.well-known/openid-configuration"
+	data, err := v.get(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover the JWKS of the issuer: %w", err)
+	}
+
+	configuration := struct {
+		Issuer  string `json:"issuer"`
+		JwksUri string `json:"jwks_uri"`
+	}{}
+	if err := json.Unmarshal(data, &configuration); err != nil {
+		return "", fmt.Errorf("failed to unmarshal the OpenID Provider Configuration: %w", err)
+	}
+	if configuration.Issuer != v.config.Issuer {
+		return "", fmt.Errorf("issuer of the OpenID Provider Configuration (%s) is not %s", configuration.Issuer, v.config.Issuer)
+	}
+	if configuration.JwksUri == "" {
+		return "", fmt.Errorf("no jwks_uri in the OpenID Provider Configuration of %s", v.config.Issuer)
+	}
+	return configuration.JwksUri, nil
+}
+
+func (v *JwtVerifier) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := v.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status (%s) of %s", res.Status, url)
+	}
+	return io.ReadAll(io.LimitReader(res.Body, maxJwksSize))
+}
+
+// parseJwks parses the signing keys in a JWKS (i.e., RSA, EC and Ed25519), skipping the others.
+func parseJwks(data []byte) (map[string]jsonWebKey, error) {
+	set := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}{}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the JWKS: %w", err)
+	}
+
+	keys := map[string]jsonWebKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaPublicKey(k.N, k.E)
+		case "EC":
+			key, err = ecdsaPublicKey(k.Crv, k.X, k.Y)
+		case "OKP":
+			key, err = ed25519PublicKey(k.Crv, k.X)
+		default:
+			err = fmt.Errorf("unsupported key type (%s)", k.Kty)
+		}
+		if err != nil {
+			log.Debug().Err(err).Msgf("skipped a key (kid: %s) of the JWKS", k.Kid)
+			continue
+		}
+		keys[k.Kid] = jsonWebKey{alg: k.Alg, key: key}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no signing key in the JWKS")
+	}
+	return keys, nil
+}
+
+func rsaPublicKey(n, e string) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus (n): %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil || len(eBytes) == 0 || len(eBytes) > 4 {
+		return nil, errors.New("invalid exponent (e)")
+	}
+	exponent := 0
+	for _, b := range eBytes {
+		exponent = exponent<<8 | int(b)
+	}
+	modulus := new(big.Int).SetBytes(nBytes)
+	if modulus.BitLen() < minRsaKeyBits {
+		return nil, fmt.Errorf("too short RSA key (%d bits), at least %d bits required", modulus.BitLen(), minRsaKeyBits)
+	}
+	return &rsa.PublicKey{N: modulus, E: exponent}, nil
+}
+
+func ecdsaPublicKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve (%s)", crv)
+	}
+	xBytes, errX := base64.RawURLEncoding.DecodeString(x)
+	yBytes, errY := base64.RawURLEncoding.DecodeString(y)
+	if errX != nil || errY != nil {
+		return nil, errors.New("invalid coordinates (x, y)")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xBytes), Y: new(big.Int).SetBytes(yBytes)}, nil
+}
+
+func ed25519PublicKey(crv, x string) (ed25519.PublicKey, error) {
+	if crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported curve (%s)", crv)
+	}
+	xBytes, err := base64.RawURLEncoding.DecodeString(x)
+	if err != nil || len(xBytes) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key (x)")
+	}
+	return ed25519.PublicKey(xBytes), nil
+}
+
+// verifySignature verifies the signature of the signing input (i.e., header.claims) by an algorithm and a key.
+func verifySignature(alg string, key crypto.PublicKey, input, signature []byte) error {
+	// The hash of RS, PS and ES by the suffix (e.g., RS256)
+	var hash crypto.Hash
+	switch strings.TrimLeft(alg, "RPES") {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	}
+
+	verified := false
+	switch alg {
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w, algorithm (%s) does not match the key type", ErrInvalidToken, alg)
+		}
+		h := hash.New()
+		h.Write(input)
+		if alg[0] == 'R' {
+			verified = rsa.VerifyPKCS1v15(pub, hash, h.Sum(nil), signature) == nil
+		} else {
+			verified = rsa.VerifyPSS(pub, hash, h.Sum(nil), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}) == nil
+		}
+	case "ES256", "ES384", "ES512":
+		pub, ok := key.(*ecdsa.PublicKey)
+		bitSize := map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}[alg]
+		if !ok || pub.Curve.Params().BitSize != bitSize {
+			return fmt.Errorf("%w, algorithm (%s) does not match the key type", ErrInvalidToken, alg)
+		}
+		size := (bitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("%w, invalid signature length", ErrInvalidToken)
+		}
+		h := hash.New()
+		h.Write(input)
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		verified = ecdsa.Verify(pub, h.Sum(nil), r, s)
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w, algorithm (%s) does not match the key type", ErrInvalidToken, alg)
+		}
+		verified = ed25519.Verify(pub, input, signature)
+	default:
+		return fmt.Errorf("%w, unsupported algorithm (%s)", ErrInvalidToken, alg)
+	}
+
+	if !verified {
+		return fmt.Errorf("%w, signature is not verified", ErrInvalidToken)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// numericDate returns the time of a NumericDate claim (i.e., seconds since the epoch).
+func numericDate(value interface{}) (time.Time, bool) {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// claimValue returns the value of a claim, or a nested claim by dots (e.g., realm_access.roles)
+// unless a claim has the name with dots (e.g., https://example.com/roles).
+func claimValue(claims map[string]interface{}, name string) interface{} {
+	if value, ok := claims[name]; ok {
+		return value
+	}
+	var value interface{} = claims
+	for _, key := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claimValue(claims, name).(string)
+	return value
+}
+
+// hasValue reports whether a claim is (or contains, if an array) the value.
+func hasValue(claim interface{}, value string) bool {
+	switch c := claim.(type) {
+	case string:
+		return c == value
+	case bool:
+		return strconv.FormatBool(c) == value
+	case []interface{}:
+		for _, item := range c {
+			if s, ok := item.(string); ok && s == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,465 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+/*
+ * [Note] Fixture of the JWT tests
+ * The keys are generated for each test run, and the JWKS of their public keys is written to a file (JwksFile),
+ * i.e., no issuer is needed. The tokens are signed by the test itself (see sign).
+ */
+
+const (
+	testIssuer   = "https://issuer.example.com/realms/cb"
+	testAudience = "mc-terrarium"
+)
+
+// fixture is the signing keys of the test by kid.
+type fixture struct {
+	keys map[string]crypto.Signer
+	// algs are the algorithms specified in the JWKS by kid (e.g., rs256-only)
+	algs map[string]string
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{keys: map[string]crypto.Signer{}, algs: map[string]string{}}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.keys["rsa"] = rsaKey
+	f.keys["rs256-only"] = rsaKey
+	f.algs["rs256-only"] = "RS256"
+	for kid, curve := range map[string]elliptic.Curve{"p256": elliptic.P256(), "p384": elliptic.P384(), "p521": elliptic.P521()} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.keys[kid] = ecKey
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.keys["ed"] = edKey
+	return f
+}
+
+// jwks returns the JWKS of the public keys of the given kids (all if none given).
+func (f *fixture) jwks(t *testing.T, kids ...string) []byte {
+	t.Helper()
+	if len(kids) == 0 {
+		for kid := range f.keys {
+			kids = append(kids, kid)
+		}
+	}
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	keys := []map[string]string{}
+	for _, kid := range kids {
+		jwk := map[string]string{"kid": kid, "use": "sig"}
+		if alg := f.algs[kid]; alg != "" {
+			jwk["alg"] = alg
+		}
+		switch pub := f.keys[kid].Public().(type) {
+		case *rsa.PublicKey:
+			jwk["kty"] = "RSA"
+			jwk["n"] = b64(pub.N.Bytes())
+			jwk["e"] = b64(big.NewInt(int64(pub.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (pub.Curve.Params().BitSize + 7) / 8
+			jwk["kty"] = "EC"
+			jwk["crv"] = pub.Curve.Params().Name
+			jwk["x"] = b64(pub.X.FillBytes(make([]byte, size)))
+			jwk["y"] = b64(pub.Y.FillBytes(make([]byte, size)))
+		case ed25519.PublicKey:
+			jwk["kty"] = "OKP"
+			jwk["crv"] = "Ed25519"
+			jwk["x"] = b64(pub)
+		}
+		keys = append(keys, jwk)
+	}
+	// A key for encryption is skipped
+	keys = append(keys, map[string]string{"kid": "enc", "kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"})
+
+	data, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// writeJwks writes the JWKS of the given kids to the file.
+func (f *fixture) writeJwks(t *testing.T, file string, kids ...string) {
+	t.Helper()
+	if err := os.WriteFile(file, f.jwks(t, kids...), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sign signs the claims by the key of a kid with an algorithm, both of which are put in the header.
+func (f *fixture) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	return f.signWithHeader(t, map[string]any{"alg": alg, "kid": kid}, alg, kid, claims)
+}
+
+// signWithHeader signs the claims by the key of a kid with an algorithm, regardless of the header
+// (e.g., another alg or kid in the header).
+func (f *fixture) signWithHeader(t *testing.T, header map[string]any, alg, kid string, claims map[string]any) string {
+	t.Helper()
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	header["typ"] = "JWT"
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := b64(h) + "." + b64(c)
+
+	hashOf := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+	var signature []byte
+	var err error
+	switch alg {
+	case "none":
+		signature = []byte{}
+	case "HS256":
+		// Signed by the public key as the secret, i.e., the algorithm confusion
+		pub, _ := json.Marshal(f.keys[kid].Public())
+		mac := hmac.New(sha256.New, pub)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case "RS256", "RS384", "RS512", "PS256", "PS384", "PS512":
+		hash := hashOf[alg[2:]]
+		hasher := hash.New()
+		hasher.Write([]byte(input))
+		key := f.keys[kid].(*rsa.PrivateKey)
+		if alg[0] == 'R' {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, hasher.Sum(nil))
+		} else {
+			signature, err = rsa.SignPSS(rand.Reader, key, hash, hasher.Sum(nil), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case "ES256", "ES384", "ES512":
+		hash := hashOf[alg[2:]]
+		hasher := hash.New()
+		hasher.Write([]byte(input))
+		key := f.keys[kid].(*ecdsa.PrivateKey)
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, hasher.Sum(nil))
+		if err == nil {
+			size := (key.Curve.Params().BitSize + 7) / 8
+			signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+		}
+	case "EdDSA":
+		signature = ed25519.Sign(f.keys[kid].(ed25519.PrivateKey), []byte(input))
+	default:
+		t.Fatalf("unsupported algorithm (%s) to sign", alg)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return input + "." + b64(signature)
+}
+
+// validClaims returns the claims valid for the verifier of newTestVerifier.
+func validClaims() map[string]any {
+	now := time.Now()
+	return map[string]any{
+		"iss":                testIssuer,
+		"aud":                testAudience,
+		"sub":                "f3b0c9a2",
+		"preferred_username": "alice",
+		"exp":                now.Add(time.Hour).Unix(),
+		"iat":                now.Unix(),
+	}
+}
+
+func newTestVerifier(t *testing.T, f *fixture, config JwtConfig) *JwtVerifier {
+	t.Helper()
+	if config.JwksFile == "" {
+		config.JwksFile = filepath.Join(t.TempDir(), "jwks.json")
+		f.writeJwks(t, config.JwksFile)
+	}
+	v, err := NewJwtVerifier(config)
+	if err != nil {
+		t.Fatalf("failed to create the verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifyJwt(t *testing.T) {
+	f := newFixture(t)
+	v := newTestVerifier(t, f, JwtConfig{Issuer: testIssuer, Audience: testAudience})
+
+	with := func(changes map[string]any) map[string]any {
+		claims := validClaims()
+		for k, value := range changes {
+			if value == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = value
+			}
+		}
+		return claims
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		// Each algorithm
+		{name: "RS256", token: f.sign(t, "RS256", "rsa", validClaims())},
+		{name: "RS384", token: f.sign(t, "RS384", "rsa", validClaims())},
+		{name: "RS512", token: f.sign(t, "RS512", "rsa", validClaims())},
+		{name: "PS256", token: f.sign(t, "PS256", "rsa", validClaims())},
+		{name: "PS384", token: f.sign(t, "PS384", "rsa", validClaims())},
+		{name: "PS512", token: f.sign(t, "PS512", "rsa", validClaims())},
+		{name: "ES256", token: f.sign(t, "ES256", "p256", validClaims())},
+		{name: "ES384", token: f.sign(t, "ES384", "p384", validClaims())},
+		{name: "ES512", token: f.sign(t, "ES512", "p521", validClaims())},
+		{name: "EdDSA", token: f.sign(t, "EdDSA", "ed", validClaims())},
+		{name: "alg of the key in the JWKS", token: f.sign(t, "RS256", "rs256-only", validClaims())},
+
+		// Algorithm and kid mismatch
+		{name: "alg other than the alg of the key", token: f.sign(t, "RS512", "rs256-only", validClaims()), wantErr: true},
+		{name: "ES256 by a P-384 key", token: resign(t, f, "ES384", "p384", "ES256"), wantErr: true},
+		{name: "RSA alg by an EC key", token: resign(t, f, "ES256", "p256", "RS256"), wantErr: true},
+		{name: "EC alg by an RSA key", token: resign(t, f, "RS256", "rsa", "ES256"), wantErr: true},
+		{name: "EdDSA alg by an RSA key", token: resign(t, f, "RS256", "rsa", "EdDSA"), wantErr: true},
+		{name: "signed by another key of the kid", token: rekid(t, f, "ES256", "p256", "p384"), wantErr: true},
+		{name: "unknown kid", token: rekid(t, f, "RS256", "rsa", "unknown"), wantErr: true},
+		{name: "no kid with many keys", token: withoutKid(t, f, "RS256", "rsa"), wantErr: true},
+		{name: "kid of an encryption key", token: rekid(t, f, "RS256", "rsa", "enc"), wantErr: true},
+
+		// none and HMAC
+		{name: "none", token: f.sign(t, "none", "rsa", validClaims()), wantErr: true},
+		{name: "none without kid", token: withoutKid(t, f, "none", "rsa"), wantErr: true},
+		{name: "none with the kid of an RS256 key", token: f.sign(t, "none", "rs256-only", validClaims()), wantErr: true},
+		{name: "HS256 by the public key", token: f.sign(t, "HS256", "rsa", validClaims()), wantErr: true},
+
+		// Signature and format
+		{name: "tampered claims", token: tamper(t, f.sign(t, "RS256", "rsa", validClaims())), wantErr: true},
+		{name: "malformed", token: "not.a.jwt", wantErr: true},
+		{name: "two segments", token: "a.b", wantErr: true},
+
+		// exp and nbf
+		{name: "expired", token: f.sign(t, "RS256", "rsa", with(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()})), wantErr: true},
+		{name: "expired within the leeway", token: f.sign(t, "RS256", "rsa", with(map[string]any{"exp": now.Add(-30 * time.Second).Unix()}))},
+		{name: "no exp", token: f.sign(t, "RS256", "rsa", with(map[string]any{"exp": nil})), wantErr: true},
+		{name: "exp not a number", token: f.sign(t, "RS256", "rsa", with(map[string]any{"exp": "tomorrow"})), wantErr: true},
+		{name: "not valid yet", token: f.sign(t, "RS256", "rsa", with(map[string]any{"nbf": now.Add(2 * time.Minute).Unix()})), wantErr: true},
+		{name: "nbf within the leeway", token: f.sign(t, "RS256", "rsa", with(map[string]any{"nbf": now.Add(30 * time.Second).Unix()}))},
+		{name: "nbf in the past", token: f.sign(t, "RS256", "rsa", with(map[string]any{"nbf": now.Add(-time.Hour).Unix()}))},
+
+		// iss and aud
+		{name: "other issuer", token: f.sign(t, "RS256", "rsa", with(map[string]any{"iss": "https://evil.example.com"})), wantErr: true},
+		{name: "no issuer", token: f.sign(t, "RS256", "rsa", with(map[string]any{"iss": nil})), wantErr: true},
+		{name: "other audience", token: f.sign(t, "RS256", "rsa", with(map[string]any{"aud": "account"})), wantErr: true},
+		{name: "no audience", token: f.sign(t, "RS256", "rsa", with(map[string]any{"aud": nil})), wantErr: true},
+		{name: "audiences containing the audience", token: f.sign(t, "RS256", "rsa", with(map[string]any{"aud": []string{"account", testAudience}}))},
+		{name: "audiences without the audience", token: f.sign(t, "RS256", "rsa", with(map[string]any{"aud": []string{"account"}})), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("err = %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// resign signs the valid claims by the key of a kid with an algorithm (signAlg), and puts another algorithm (alg) in the header.
+func resign(t *testing.T, f *fixture, signAlg, kid, alg string) string {
+	t.Helper()
+	return f.signWithHeader(t, map[string]any{"alg": alg, "kid": kid}, signAlg, kid, validClaims())
+}
+
+// rekid signs the valid claims by the key of a kid (signKid), and puts another kid (kid) in the header.
+func rekid(t *testing.T, f *fixture, alg, signKid, kid string) string {
+	t.Helper()
+	return f.signWithHeader(t, map[string]any{"alg": alg, "kid": kid}, alg, signKid, validClaims())
+}
+
+// withoutKid signs the valid claims by the key of a kid without the kid in the header.
+func withoutKid(t *testing.T, f *fixture, alg, kid string) string {
+	t.Helper()
+	return f.signWithHeader(t, map[string]any{"alg": alg}, alg, kid, validClaims())
+}
+
+// tamper changes the claims of a token, keeping the signature.
+func tamper(t *testing.T, token string) string {
+	t.Helper()
+	claims := validClaims()
+	claims["preferred_username"] = "admin"
+	c, _ := json.Marshal(claims)
+	parts := strings.Split(token, ".")
+	return parts[0] + "." + base64.RawURLEncoding.EncodeToString(c) + "." + parts[2]
+}
+
+func TestVerifyJwtWithoutKid(t *testing.T) {
+	f := newFixture(t)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	f.writeJwks(t, file, "ed")
+	v := newTestVerifier(t, f, JwtConfig{JwksFile: file})
+
+	// The only key of the JWKS verifies a JWT without kid
+	if _, err := v.Verify(context.Background(), withoutKid(t, f, "EdDSA", "ed")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRotatedKid(t *testing.T) {
+	f := newFixture(t)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	f.writeJwks(t, file, "rsa")
+	v := newTestVerifier(t, f, JwtConfig{JwksFile: file})
+
+	if _, err := v.Verify(context.Background(), f.sign(t, "RS256", "rsa", validClaims())); err != nil {
+		t.Fatalf("unexpected error before the rotation: %v", err)
+	}
+
+	// The issuer rotates the key (i.e., p256 added, rsa removed)
+	f.writeJwks(t, file, "p256")
+	rotated := f.sign(t, "ES256", "p256", validClaims())
+
+	// The JWKS is refreshed on an unknown kid at most once per minRefreshInterval
+	if _, err := v.Verify(context.Background(), rotated); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want %v (refreshed just before)", err, ErrInvalidToken)
+	}
+	v.mu.Lock()
+	v.attemptedAt = time.Now().Add(-2 * minRefreshInterval)
+	v.mu.Unlock()
+	if _, err := v.Verify(context.Background(), rotated); err != nil {
+		t.Errorf("unexpected error after the rotation: %v", err)
+	}
+
+	// The removed key is no longer accepted
+	if _, err := v.Verify(context.Background(), f.sign(t, "RS256", "rsa", validClaims())); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("err = %v, want %v (the key is removed)", err, ErrInvalidToken)
+	}
+}
+
+func TestShortRsaKey(t *testing.T) {
+	f := newFixture(t)
+	shortKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.keys["short"] = shortKey
+
+	// The JWKS of the short key only has no signing key
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	f.writeJwks(t, file, "short")
+	if _, err := NewJwtVerifier(JwtConfig{JwksFile: file}); err == nil {
+		t.Errorf("err = nil, want an error (no signing key)")
+	}
+
+	// The short key is skipped, and a JWT signed by it is not verified
+	f.writeJwks(t, file, "short", "rsa")
+	v := newTestVerifier(t, f, JwtConfig{JwksFile: file})
+	for _, alg := range []string{"RS256", "PS256"} {
+		if _, err := v.Verify(context.Background(), f.sign(t, alg, "short", validClaims())); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s by the short key: err = %v, want %v", alg, err, ErrInvalidToken)
+		}
+	}
+	if _, err := v.Verify(context.Background(), f.sign(t, "RS256", "rsa", validClaims())); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewJwtVerifier(t *testing.T) {
+	f := newFixture(t)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		config  JwtConfig
+		content string
+		wantErr bool
+	}{
+		{name: "no issuer nor JWKS", config: JwtConfig{}, wantErr: true},
+		{name: "missing JWKS file", config: JwtConfig{JwksFile: filepath.Join(dir, "missing.json")}, wantErr: true},
+		{name: "malformed JWKS file", config: JwtConfig{JwksFile: filepath.Join(dir, "malformed.json")}, content: "{", wantErr: true},
+		{name: "no signing key", config: JwtConfig{JwksFile: filepath.Join(dir, "empty.json")}, content: `{"keys":[]}`, wantErr: true},
+		{name: "JWKS file", config: JwtConfig{JwksFile: filepath.Join(dir, "jwks.json")}, content: string(f.jwks(t))},
+		// The JWKS URL is loaded on demand
+		{name: "unreachable JWKS URL", config: JwtConfig{JwksUrl: "http://127.0.0.1:1/certs", Timeout: time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.content != "" {
+				if err := os.WriteFile(tt.config.JwksFile, []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, err := NewJwtVerifier(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthenticateJwt(t *testing.T) {
+	f := newFixture(t)
+	v := newTestVerifier(t, f, JwtConfig{AdminClaim: "realm_access.roles", AdminValue: "terrarium-admin"})
+
+	tests := []struct {
+		name    string
+		claims  map[string]any
+		want    string
+		holder  string
+		admin   bool
+		wantErr bool
+	}{
+		{name: "name claim", claims: map[string]any{"preferred_username": "alice", "credential_holder": "alice"}, want: "alice", holder: "alice"},
+		{name: "sub if no name claim", claims: map[string]any{"sub": "f3b0c9a2"}, want: "f3b0c9a2"},
+		{name: "admin by a nested claim", claims: map[string]any{"preferred_username": "bob", "realm_access": map[string]any{"roles": []string{"user", "terrarium-admin"}}}, want: "bob", admin: true},
+		{name: "not admin", claims: map[string]any{"preferred_username": "carol", "realm_access": map[string]any{"roles": []string{"user"}}}, want: "carol"},
+		{name: "no name nor sub", claims: map[string]any{"credential_holder": "alice"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.claims["exp"] = time.Now().Add(time.Hour).Unix()
+			principal, err := v.Authenticate(context.Background(), f.sign(t, "ES256", "p256", tt.claims))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidToken) {
+					t.Errorf("err = %v, want %v", err, ErrInvalidToken)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if principal.Name != tt.want || principal.CredentialHolder != tt.holder || principal.Admin != tt.admin || principal.Method != MethodJwt {
+				t.Errorf("principal = %+v, want name %s, holder %s, admin %v", principal, tt.want, tt.holder, tt.admin)
+			}
+		})
+	}
+}
@@ -33,6 +33,11 @@ type TerrariumConfig struct {
 	Node        NodeConfig        `mapstructure:"node"`
 	AutoControl AutoControlConfig `mapstructure:"autocontrol"`
 	Tumblebug   TumblebugConfig   `mapstructure:"tumblebug"`
+	Tofu        TofuConfig        `mapstructure:"tofu"`
+	Approval    ApprovalConfig    `mapstructure:"approval"`
+	Recovery    RecoveryConfig    `mapstructure:"recovery"`
+	Drift       DriftConfig       `mapstructure:"drift"`
+	Secrets     SecretsConfig     `mapstructure:"secrets"`
 	// LKVStore    LkvStoreConfig    `mapstructure:"lkvstore"`
 }
 
@@ -52,10 +57,45 @@ type AllowConfig struct {
 }
 type AuthConfig struct {
 	Enabled bool `mapstructure:"enabled"`
+	// StrictHolder binds the credential holder to the authenticated principal (no implicit "admin"),
+	// where only admins may act as another holder by x-credential-holder
+	StrictHolder bool `mapstructure:"strict_holder"`
+	// Jwt validates the bearer tokens issued by an OIDC issuer (disabled if neither issuer nor JWKS is configured)
+	Jwt JwtConfig `mapstructure:"jwt"`
+}
+
+type JwtConfig struct {
+	// Issuer is the expected issuer (iss), whose JWKS is discovered by /.well-known/openid-configuration if no JWKS is given
+	Issuer string `mapstructure:"issuer"`
+	// Audience is the expected audience (aud), not checked if empty
+	Audience string `mapstructure:"audience"`
+	// JwksUrl or JwksFile is the JSON Web Key Set to verify the tokens (e.g., a local file for tests)
+	JwksUrl  string `mapstructure:"jwks_url"`
+	JwksFile string `mapstructure:"jwks_file"`
+	// Claims mapped to the principal (e.g., realm_access.roles for a nested claim)
+	NameClaim   string `mapstructure:"name_claim"`
+	HolderClaim string `mapstructure:"holder_claim"`
+	AdminClaim  string `mapstructure:"admin_claim"`
+	AdminValue  string `mapstructure:"admin_value"`
 }
 
 type LkvStoreConfig struct {
-	Path string `mapstructure:"path"`
+	// Backend is the storage backend: file (default), bolt or etcd
+	Backend string `mapstructure:"backend"`
+	Path    string `mapstructure:"path"`
+	// Compaction of the write-ahead log into the db file
+	CompactionThreshold   int                `mapstructure:"compaction_threshold"`
+	CompactionIntervalSec int                `mapstructure:"compaction_interval_sec"`
+	Etcd                  LkvStoreEtcdConfig `mapstructure:"etcd"`
+}
+
+type LkvStoreEtcdConfig struct {
+	// Endpoints is a comma-separated list of etcd endpoints
+	Endpoints      string `mapstructure:"endpoints"`
+	Username       string `mapstructure:"username"`
+	Password       string `mapstructure:"password"`
+	Prefix         string `mapstructure:"prefix"`
+	DialTimeoutSec int    `mapstructure:"dial_timeout_sec"`
 }
 
 type LogfileConfig struct {
@@ -71,7 +111,45 @@ type NodeConfig struct {
 }
 
 type AutoControlConfig struct {
+	// DurationMilliSec is the interval to check the expiry of the terrariums
 	DurationMilliSec int `mapstructure:"duration_ms"`
+	// GraceMin is the grace period before the expiry of a terrarium, in which the expiry is warned
+	GraceMin int `mapstructure:"grace_min"`
+}
+
+type TofuConfig struct {
+	TimeoutMin     int `mapstructure:"timeout_min"`
+	GracePeriodSec int `mapstructure:"grace_period_sec"`
+}
+
+type ApprovalConfig struct {
+	ExpiryMin int `mapstructure:"expiry_min"`
+}
+
+type RecoveryConfig struct {
+	ForceUnlock bool `mapstructure:"force_unlock"`
+}
+
+type DriftConfig struct {
+	// IntervalMin is the interval to detect the drift of the applied enrichments (0: disabled)
+	IntervalMin int `mapstructure:"interval_min"`
+	// WebhookUrl is the URL to which a notification is posted when a drift is detected (optional)
+	WebhookUrl string `mapstructure:"webhook_url"`
+}
+
+type SecretsConfig struct {
+	// Address is the address of OpenBao (e.g., http://openbao:8200); the secrets client is disabled if empty
+	Address string `mapstructure:"address"`
+	// Mount is the mount of the KV v2 secrets engine storing the credentials (default: secret)
+	Mount   string               `mapstructure:"mount"`
+	AppRole SecretsAppRoleConfig `mapstructure:"approle"`
+}
+
+type SecretsAppRoleConfig struct {
+	RoleId   string `mapstructure:"role_id"`
+	SecretId string `mapstructure:"secret_id"`
+	// SecretIdFile is the file containing the secret_id, if secret_id is not given
+	SecretIdFile string `mapstructure:"secret_id_file"`
 }
 
 type TumblebugConfig struct {
@@ -183,9 +261,26 @@ func bindEnvironmentVariables() {
 	viper.BindEnv("terrarium.self.endpoint", "TERRARIUM_SELF_ENDPOINT")
 	viper.BindEnv("terrarium.api.allow.origins", "TERRARIUM_API_ALLOW_ORIGINS")
 	viper.BindEnv("terrarium.api.auth.enabled", "TERRARIUM_API_AUTH_ENABLED")
+	viper.BindEnv("terrarium.api.auth.strict_holder", "TERRARIUM_API_AUTH_STRICT_HOLDER")
+	viper.BindEnv("terrarium.api.auth.jwt.issuer", "TERRARIUM_API_AUTH_JWT_ISSUER")
+	viper.BindEnv("terrarium.api.auth.jwt.audience", "TERRARIUM_API_AUTH_JWT_AUDIENCE")
+	viper.BindEnv("terrarium.api.auth.jwt.jwks_url", "TERRARIUM_API_AUTH_JWT_JWKS_URL")
+	viper.BindEnv("terrarium.api.auth.jwt.jwks_file", "TERRARIUM_API_AUTH_JWT_JWKS_FILE")
+	viper.BindEnv("terrarium.api.auth.jwt.name_claim", "TERRARIUM_API_AUTH_JWT_NAME_CLAIM")
+	viper.BindEnv("terrarium.api.auth.jwt.holder_claim", "TERRARIUM_API_AUTH_JWT_HOLDER_CLAIM")
+	viper.BindEnv("terrarium.api.auth.jwt.admin_claim", "TERRARIUM_API_AUTH_JWT_ADMIN_CLAIM")
+	viper.BindEnv("terrarium.api.auth.jwt.admin_value", "TERRARIUM_API_AUTH_JWT_ADMIN_VALUE")
 	viper.BindEnv("terrarium.api.username", "TERRARIUM_API_USERNAME")
 	viper.BindEnv("terrarium.api.password", "TERRARIUM_API_PASSWORD")
+	viper.BindEnv("terrarium.lkvstore.backend", "TERRARIUM_LKVSTORE_BACKEND")
 	viper.BindEnv("terrarium.lkvstore.path", "TERRARIUM_LKVSTORE_PATH")
+	viper.BindEnv("terrarium.lkvstore.compaction_threshold", "TERRARIUM_LKVSTORE_COMPACTION_THRESHOLD")
+	viper.BindEnv("terrarium.lkvstore.compaction_interval_sec", "TERRARIUM_LKVSTORE_COMPACTION_INTERVAL_SEC")
+	viper.BindEnv("terrarium.lkvstore.etcd.endpoints", "TERRARIUM_LKVSTORE_ETCD_ENDPOINTS")
+	viper.BindEnv("terrarium.lkvstore.etcd.username", "TERRARIUM_LKVSTORE_ETCD_USERNAME")
+	viper.BindEnv("terrarium.lkvstore.etcd.password", "TERRARIUM_LKVSTORE_ETCD_PASSWORD")
+	viper.BindEnv("terrarium.lkvstore.etcd.prefix", "TERRARIUM_LKVSTORE_ETCD_PREFIX")
+	viper.BindEnv("terrarium.lkvstore.etcd.dial_timeout_sec", "TERRARIUM_LKVSTORE_ETCD_DIAL_TIMEOUT_SEC")
 	viper.BindEnv("terrarium.logfile.path", "TERRARIUM_LOGFILE_PATH")
 	viper.BindEnv("terrarium.logfile.maxsize", "TERRARIUM_LOGFILE_MAXSIZE")
 	viper.BindEnv("terrarium.logfile.maxbackups", "TERRARIUM_LOGFILE_MAXBACKUPS")
@@ -195,6 +290,18 @@ func bindEnvironmentVariables() {
 	viper.BindEnv("terrarium.logwriter", "TERRARIUM_LOGWRITER")
 	viper.BindEnv("terrarium.node.env", "TERRARIUM_NODE_ENV")
 	viper.BindEnv("terrarium.autocontrol.duration_ms", "TERRARIUM_AUTOCONTROL_DURATION_MS")
+	viper.BindEnv("terrarium.autocontrol.grace_min", "TERRARIUM_AUTOCONTROL_GRACE_MIN")
+	viper.BindEnv("terrarium.tofu.timeout_min", "TERRARIUM_TOFU_TIMEOUT_MIN")
+	viper.BindEnv("terrarium.tofu.grace_period_sec", "TERRARIUM_TOFU_GRACE_PERIOD_SEC")
+	viper.BindEnv("terrarium.approval.expiry_min", "TERRARIUM_APPROVAL_EXPIRY_MIN")
+	viper.BindEnv("terrarium.recovery.force_unlock", "TERRARIUM_RECOVERY_FORCE_UNLOCK")
+	viper.BindEnv("terrarium.drift.interval_min", "TERRARIUM_DRIFT_INTERVAL_MIN")
+	viper.BindEnv("terrarium.drift.webhook_url", "TERRARIUM_DRIFT_WEBHOOK_URL")
+	viper.BindEnv("terrarium.secrets.address", "TERRARIUM_SECRETS_ADDRESS")
+	viper.BindEnv("terrarium.secrets.mount", "TERRARIUM_SECRETS_MOUNT")
+	viper.BindEnv("terrarium.secrets.approle.role_id", "TERRARIUM_SECRETS_APPROLE_ROLE_ID")
+	viper.BindEnv("terrarium.secrets.approle.secret_id", "TERRARIUM_SECRETS_APPROLE_SECRET_ID")
+	viper.BindEnv("terrarium.secrets.approle.secret_id_file", "TERRARIUM_SECRETS_APPROLE_SECRET_ID_FILE")
 	viper.BindEnv("terrarium.tumblebug.endpoint", "TERRARIUM_TUMBLEBUG_ENDPOINT")
 	viper.BindEnv("terrarium.tumblebug.api.username", "TERRARIUM_TUMBLEBUG_API_USERNAME")
 	viper.BindEnv("terrarium.tumblebug.api.password", "TERRARIUM_TUMBLEBUG_API_PASSWORD")
@@ -0,0 +1,45 @@
+package enrichment
+
+import "github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+
+/*
+ * [Note] Built-in enrichments
+ *
+ * The templates of an enrichment are placed in templates/{kind}/{provider}.
+ */
+
+func init() {
+	Register(&Spec{
+		Kind:               "sql-db",
+		SupportedProviders: []string{"aws", "azure", "gcp", "ncp"},
+		Output:             "sql_db_info",
+		TfVars:             func() any { return &model.TfVarsSqlDb{} },
+		Required: map[string][]string{
+			"aws":   {"terrarium_id", "csp_vnet_id", "csp_subnet1_id", "csp_subnet2_id"},
+			"azure": {"terrarium_id"},
+			"gcp":   {"terrarium_id"},
+			"ncp":   {"terrarium_id", "csp_subnet1_id"},
+		},
+	})
+
+	Register(&Spec{
+		Kind:               "object-storage",
+		SupportedProviders: []string{"aws", "azure"},
+		Output:             "object_storage_info",
+		TfVars:             func() any { return &model.TfVarsObjectStorage{} },
+		Required: map[string][]string{
+			"aws":   {"terrarium_id"},
+			"azure": {"terrarium_id"},
+		},
+	})
+
+	Register(&Spec{
+		Kind:               "message-broker",
+		SupportedProviders: []string{"aws"},
+		Output:             "message_broker_info",
+		TfVars:             func() any { return &model.TfVarsMessageBroker{} },
+		Required: map[string][]string{
+			"aws": {"terrarium_id", "csp_vnet_id"},
+		},
+	})
+}
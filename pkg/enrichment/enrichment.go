@@ -0,0 +1,96 @@
+// Package enrichment provides the registry of the enrichments,
+// which are the kinds of resources provisioned in a terrarium (e.g., sql-db, object-storage).
+//
+// An enrichment describes its templates, tfvars and outputs,
+// so that the generic APIs (/tr/{trId}/enrichments/{kind}) can serve it without a dedicated handler.
+// To add a new kind of resources, put the templates in templates/{kind}/{provider} and register it (see builtin.go).
+package enrichment
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrNotRegistered is returned if no enrichment is registered for a kind.
+var ErrNotRegistered = errors.New("enrichment is not registered")
+
+// ErrInvalidTfVars is returned if the tfvars are not valid for an enrichment.
+var ErrInvalidTfVars = errors.New("invalid tfvars")
+
+// Enrichment is the interface of a kind of resources provisioned in a terrarium.
+type Enrichment interface {
+	// Name returns the kind of the enrichment (e.g., "sql-db"),
+	// which is also used as the name of the working directory in a terrarium.
+	Name() string
+	// Providers returns the supported providers (e.g., "aws", "azure").
+	Providers() []string
+	// TemplatePath returns the path of the template files for a provider,
+	// relative to the templates directory (e.g., "sql-db/aws").
+	TemplatePath(provider string) string
+	// NewTfVars returns a pointer to a new value of the tfvars type (e.g., *model.TfVarsSqlDb).
+	NewTfVars() any
+	// Validate validates the tfvars for a provider.
+	Validate(provider string, tfVars any) error
+	// OutputName returns the name of the output holding the refined resource info (e.g., "sql_db_info").
+	OutputName() string
+}
+
+var (
+	mu          sync.RWMutex
+	enrichments = map[string]Enrichment{}
+)
+
+// Register registers an enrichment.
+// It panics if the name is empty or an enrichment of the same name is already registered.
+func Register(e Enrichment) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	name := e.Name()
+	if name == "" {
+		panic("enrichment: Register with an empty name")
+	}
+	if _, dup := enrichments[name]; dup {
+		panic("enrichment: Register called twice for " + name)
+	}
+	enrichments[name] = e
+}
+
+// Get returns the enrichment registered for a kind.
+func Get(kind string) (Enrichment, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	e, ok := enrichments[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w (kind: %s)", ErrNotRegistered, kind)
+	}
+	return e, nil
+}
+
+// List returns the registered enrichments in order of the names.
+func List() []Enrichment {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Enrichment, 0, len(enrichments))
+	for _, e := range enrichments {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list
+}
+
+// Supports reports whether the enrichment supports a provider.
+func Supports(e Enrichment, provider string) bool {
+	for _, p := range e.Providers() {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
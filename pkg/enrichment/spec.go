@@ -0,0 +1,75 @@
+package enrichment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Spec is an Enrichment described by its fields,
+// which is enough for an enrichment whose templates are placed in templates/{kind}/{provider}.
+type Spec struct {
+	// Kind is the name of the enrichment (e.g., "sql-db")
+	Kind string
+	// SupportedProviders are the providers having the templates
+	SupportedProviders []string
+	// Output is the name of the output holding the refined resource info
+	Output string
+	// TfVars returns a pointer to a new value of the tfvars type
+	TfVars func() any
+	// Required are the tfvars (JSON names) required by the templates of a provider,
+	// which have no default value in variables.tf
+	Required map[string][]string
+}
+
+func (s *Spec) Name() string {
+	return s.Kind
+}
+
+func (s *Spec) Providers() []string {
+	return s.SupportedProviders
+}
+
+func (s *Spec) TemplatePath(provider string) string {
+	return s.Kind + "/" + provider
+}
+
+func (s *Spec) NewTfVars() any {
+	if s.TfVars == nil {
+		return &map[string]interface{}{}
+	}
+	return s.TfVars()
+}
+
+func (s *Spec) OutputName() string {
+	return s.Output
+}
+
+// Validate checks that the provider is supported and the required tfvars are set.
+func (s *Spec) Validate(provider string, tfVars any) error {
+	if !Supports(s, provider) {
+		return fmt.Errorf("%w, provider (%s) must be one of [%s] for %s",
+			ErrInvalidTfVars, provider, strings.Join(s.SupportedProviders, ", "), s.Kind)
+	}
+
+	b, err := json.Marshal(tfVars)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tfVars: %w", err)
+	}
+	var tfVarsMap map[string]interface{}
+	if err := json.Unmarshal(b, &tfVarsMap); err != nil {
+		return fmt.Errorf("failed to unmarshal tfVars to map: %w", err)
+	}
+
+	var missing []string
+	for _, name := range s.Required[provider] {
+		if v, ok := tfVarsMap[name]; !ok || v == nil || v == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w, [%s] required for %s on %s",
+			ErrInvalidTfVars, strings.Join(missing, ", "), s.Kind, provider)
+	}
+	return nil
+}
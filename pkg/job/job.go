@@ -0,0 +1,434 @@
+// Package job records the requests (i.e., OpenTofu commands) processed in terrariums.
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+// Status of a request (i.e., a job and its steps).
+// They are the execution status of the terrariums as well (see tofu.StatusRunning and so on).
+const (
+	StatusRunning   = "Running"
+	StatusSuccess   = "Success"
+	StatusFailed    = "Failed"
+	StatusCancelled = "Cancelled"
+	// StatusInterrupted is the status of a request which stopped with the server (see the recovery at startup)
+	StatusInterrupted = "Interrupted"
+)
+
+// mu serializes the read-modify-write of job records
+// (e.g., outputs of providers are read in parallel with the same reqId).
+var mu sync.Mutex
+
+type credentialHolderKey struct{}
+
+// WithCredentialHolder returns a copy of ctx that carries the credential holder of the caller.
+func WithCredentialHolder(ctx context.Context, holder string) context.Context {
+	return context.WithValue(ctx, credentialHolderKey{}, holder)
+}
+
+// CredentialHolderFrom returns the credential holder of the caller carried by ctx.
+func CredentialHolderFrom(ctx context.Context) string {
+	holder, _ := ctx.Value(credentialHolderKey{}).(string)
+	return holder
+}
+
+type principalNameKey struct{}
+
+// WithPrincipalName returns a copy of ctx that carries the name of the authenticated principal (i.e., the caller).
+func WithPrincipalName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, principalNameKey{}, name)
+}
+
+// PrincipalNameFrom returns the name of the authenticated principal carried by ctx,
+// or an empty string if the caller is not authenticated (e.g., the authentication is disabled).
+func PrincipalNameFrom(ctx context.Context) string {
+	name, _ := ctx.Value(principalNameKey{}).(string)
+	return name
+}
+
+type planKey struct{}
+
+type planInfo struct {
+	planId     string
+	approvedBy string
+}
+
+// WithPlan returns a copy of ctx that carries the saved plan to apply and its approver (if any).
+func WithPlan(ctx context.Context, planId, approvedBy string) context.Context {
+	return context.WithValue(ctx, planKey{}, planInfo{planId: planId, approvedBy: approvedBy})
+}
+
+// planFrom returns the saved plan and its approver carried by ctx.
+func planFrom(ctx context.Context) (string, string) {
+	plan, _ := ctx.Value(planKey{}).(planInfo)
+	return plan.planId, plan.approvedBy
+}
+
+type systemKey struct{}
+
+// WithSystem returns a copy of ctx that marks the requests as system requests (i.e., of the background loops).
+func WithSystem(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemKey{}, true)
+}
+
+// isSystem reports whether ctx marks the requests as system requests.
+func isSystem(ctx context.Context) bool {
+	system, _ := ctx.Value(systemKey{}).(bool)
+	return system
+}
+
+func key(trId, reqId string) string {
+	return "/job/" + trId + "/" + reqId
+}
+
+var (
+	hostname     string
+	hostnameOnce sync.Once
+)
+
+// ErrOtherHost is returned if a request is processed by another host sharing the store,
+// whose running command and logs are not available on this host.
+var ErrOtherHost = errors.New("request is processed by another host")
+
+// CheckLocal checks if the running command and the logs of a job are available on this host,
+// i.e., the job is of this host, or its log is found (e.g., a shared volume).
+func CheckLocal(job model.Job) error {
+	if job.Host == "" || job.Host == Hostname() {
+		return nil
+	}
+	if _, err := os.Stat(LogFile(job)); err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w (%s), send the request to the host (trId: %s, reqId: %s)", ErrOtherHost, job.Host, job.TrId, job.ReqId)
+}
+
+// Hostname returns the name of the host running this server,
+// which identifies the jobs of this server if the store is shared by multiple servers.
+func Hostname() string {
+	hostnameOnce.Do(func() {
+		name, err := os.Hostname()
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to get the hostname")
+		}
+		hostname = name
+	})
+	return hostname
+}
+
+// Start records the start of a command for a given request.
+// The args are the arguments of the tofu CLI (e.g., "-chdir=...", "apply", "-auto-approve").
+func Start(ctx context.Context, trId, reqId, cliName string, args []string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	command := cliName + " " + strings.Join(commandArgs(args), " ")
+
+	job, exists, err := get(trId, reqId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		job = model.Job{
+			TrId:             trId,
+			ReqId:            reqId,
+			CredentialHolder: CredentialHolderFrom(ctx),
+			StartedAt:        now,
+			System:           isSystem(ctx),
+		}
+	}
+
+	if enrichment := EnrichmentOf(trId, args); enrichment != "" {
+		job.Enrichment = enrichment
+	}
+	if planId, approvedBy := planFrom(ctx); planId != "" {
+		job.PlanId = planId
+		job.ApprovedBy = approvedBy
+	}
+	job.Command = command
+	job.Status = StatusRunning
+	job.Host = Hostname()
+	job.Pid = 0
+	job.ExitCode = nil
+	job.Error = ""
+	job.EndedAt = nil
+	job.Steps = append(job.Steps, model.JobStep{
+		Command:   command,
+		Status:    StatusRunning,
+		StartedAt: now,
+	})
+
+	return lkvstore.Put(key(trId, reqId), job)
+}
+
+// Finish records the result of the last command of a given request.
+func Finish(trId, reqId, status string, exitCode int, cmdErr error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	job, exists, err := get(trId, reqId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+	}
+
+	// Persist the progress kept in memory while running
+	if progress, tracked := untrack(trId, reqId); tracked {
+		job.Progress = progress
+	}
+
+	now := time.Now()
+	errMsg := ""
+	if cmdErr != nil {
+		errMsg = cmdErr.Error()
+	}
+
+	job.Status = status
+	job.ExitCode = &exitCode
+	job.Error = errMsg
+	job.EndedAt = &now
+	if n := len(job.Steps); n > 0 {
+		step := &job.Steps[n-1]
+		step.Status = status
+		step.ExitCode = &exitCode
+		step.Error = errMsg
+		step.EndedAt = &now
+	}
+
+	return lkvstore.Put(key(trId, reqId), job)
+}
+
+// SetPid records the process of the running command of a given request.
+func SetPid(trId, reqId string, pid int) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	job, exists, err := get(trId, reqId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+	}
+
+	job.Pid = pid
+	return lkvstore.Put(key(trId, reqId), job)
+}
+
+// Interrupt records that the command of a given request was interrupted
+// (e.g., the server stopped while the command was running).
+func Interrupt(trId, reqId, reason string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	job, exists, err := get(trId, reqId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+	}
+
+	if progress, tracked := untrack(trId, reqId); tracked {
+		job.Progress = progress
+	}
+
+	now := time.Now()
+	job.Status = StatusInterrupted
+	job.Error = reason
+	job.EndedAt = &now
+	if n := len(job.Steps); n > 0 && job.Steps[n-1].EndedAt == nil {
+		step := &job.Steps[n-1]
+		step.Status = StatusInterrupted
+		step.Error = reason
+		step.EndedAt = &now
+	}
+
+	return lkvstore.Put(key(trId, reqId), job)
+}
+
+// Get returns the job of a given request, with the progress in memory if running on this host.
+func Get(trId, reqId string) (model.Job, bool, error) {
+	job, exists, err := get(trId, reqId)
+	if err != nil || !exists {
+		return job, exists, err
+	}
+	return withLiveProgress(job), true, nil
+}
+
+func get(trId, reqId string) (model.Job, bool, error) {
+	job := model.Job{}
+	value, exists := lkvstore.Get(key(trId, reqId))
+	if !exists {
+		return job, false, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &job); err != nil {
+		return job, true, fmt.Errorf("failed to unmarshal job: %w", err)
+	}
+	return job, true, nil
+}
+
+// List returns the jobs of a given terrarium in order of their start time (see Get for the progress).
+func List(trId string) ([]model.Job, error) {
+	jobs := []model.Job{}
+	values, exists := lkvstore.GetWithPrefix("/job/" + trId + "/")
+	if !exists {
+		return jobs, nil
+	}
+
+	for _, value := range values {
+		job := model.Job{}
+		if err := json.Unmarshal([]byte(value), &job); err != nil {
+			log.Debug().Msgf("failed to unmarshal job: %v", err)
+			continue
+		}
+		jobs = append(jobs, withLiveProgress(job))
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].StartedAt.Before(jobs[j].StartedAt)
+	})
+
+	return jobs, nil
+}
+
+// ListAll returns the jobs of all terrariums.
+func ListAll() ([]model.Job, error) {
+	jobs := []model.Job{}
+	kvs, err := lkvstore.List("/job/")
+	if err != nil {
+		return jobs, err
+	}
+
+	for _, kv := range kvs {
+		job := model.Job{}
+		if err := json.Unmarshal([]byte(kv.Value), &job); err != nil {
+			log.Debug().Msgf("failed to unmarshal job: %v", err)
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// Restore stores a job as it is (e.g., imported from an archive of a terrarium).
+func Restore(job model.Job) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := lkvstore.Put(key(job.TrId, job.ReqId), job); err != nil {
+		return fmt.Errorf("failed to restore the job (trId: %s, reqId: %s): %w", job.TrId, job.ReqId, err)
+	}
+	return nil
+}
+
+// SystemJobsToKeep is the number of the system requests kept for each enrichment of a terrarium (see PruneSystem).
+const SystemJobsToKeep = 10
+
+// PruneSystem deletes the system requests of a given terrarium, and their log files,
+// except the latest ones (SystemJobsToKeep) for each enrichment, so that the background loops do not pile them up.
+func PruneSystem(trId string) error {
+	jobs, err := List(trId)
+	if err != nil {
+		return err
+	}
+
+	// The jobs are in order of their start time
+	system := map[string][]model.Job{}
+	for _, job := range jobs {
+		if job.System && job.Status != StatusRunning {
+			system[job.Enrichment] = append(system[job.Enrichment], job)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, jobs := range system {
+		if len(jobs) <= SystemJobsToKeep {
+			continue
+		}
+		for _, job := range jobs[:len(jobs)-SystemJobsToKeep] {
+			if err := lkvstore.Delete(key(trId, job.ReqId)); err != nil {
+				return fmt.Errorf("failed to delete the job (trId: %s, reqId: %s): %w", trId, job.ReqId, err)
+			}
+			for _, file := range []string{LogFile(job), EventFile(job)} {
+				if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Warn().Err(err).Msgf("failed to remove the log file (%s)", file)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteAll deletes all jobs of a given terrarium.
+func DeleteAll(trId string) error {
+	jobs, err := List(trId)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		lkvstore.Delete(key(trId, job.ReqId))
+	}
+	return nil
+}
+
+// WorkingDir returns the working directory (i.e., the terrarium environment) of a job.
+func WorkingDir(job model.Job) string {
+	return filepath.Join(config.Terrarium.Root, ".terrarium", job.TrId, job.Enrichment)
+}
+
+// LogFile returns the path of the running log file of a job.
+func LogFile(job model.Job) string {
+	return filepath.Join(WorkingDir(job), "runningLogs", job.ReqId+".log")
+}
+
+// commandArgs returns the args without the global option to change the working directory,
+// which is a server-side path.
+func commandArgs(args []string) []string {
+	ret := []string{}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-chdir=") {
+			continue
+		}
+		ret = append(ret, arg)
+	}
+	return ret
+}
+
+// EnrichmentOf returns the enrichment of a command from its working directory
+// (i.e., {root}/.terrarium/{trId}/{enrichments}).
+func EnrichmentOf(trId string, args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-chdir=") {
+			continue
+		}
+		workingDir := filepath.Clean(strings.TrimPrefix(arg, "-chdir="))
+		trDir := filepath.Join(config.Terrarium.Root, ".terrarium", trId)
+		rel, err := filepath.Rel(trDir, workingDir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		return filepath.ToSlash(rel)
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package job
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// logPollInterval is the interval to check if the running log grows.
+	logPollInterval = 500 * time.Millisecond
+	// logLinger is the time to keep following the running log after a command finishes,
+	// because a request may run the next command (e.g., init, plan and then apply).
+	logLinger = 3 * time.Second
+)
+
+// ReadLog reads the running log of a given request line by line and calls onLine for each line.
+// If follow is true, it keeps reading the log as it grows until the request finishes or ctx is done.
+func ReadLog(ctx context.Context, trId, reqId string, follow bool, onLine func(line string) error) error {
+	job, exists, err := Get(trId, reqId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+	}
+	if err := CheckLocal(job); err != nil {
+		return err
+	}
+	logFile := LogFile(job)
+
+	var file *os.File
+	var reader *bufio.Reader
+	var partial string
+	var finishedAt time.Time
+
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	for {
+		// Open the log file when it is created
+		if file == nil {
+			file, err = os.Open(logFile)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to open the log file: %w", err)
+			}
+			if file != nil {
+				reader = bufio.NewReader(file)
+			}
+		}
+
+		// Read the lines written so far
+		if reader != nil {
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil && err != io.EOF {
+					return fmt.Errorf("failed to read the log file: %w", err)
+				}
+				if err == io.EOF {
+					// Keep the incomplete line until the rest is written
+					partial += line
+					break
+				}
+				if err := onLine(trimNewline(partial + line)); err != nil {
+					return err
+				}
+				partial = ""
+			}
+		}
+
+		if !follow || !isRunning(trId, reqId, &finishedAt) {
+			if partial != "" {
+				return onLine(partial)
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(logPollInterval):
+		}
+	}
+}
+
+// isRunning checks if a request is running or it may run the next command soon.
+// finishedAt keeps the time when the request was first seen as finished.
+func isRunning(trId, reqId string, finishedAt *time.Time) bool {
+	job, _, err := Get(trId, reqId)
+	if err != nil {
+		return false
+	}
+	if job.Status == StatusRunning {
+		*finishedAt = time.Time{}
+		return true
+	}
+	if finishedAt.IsZero() {
+		*finishedAt = time.Now()
+	}
+	return time.Since(*finishedAt) < logLinger
+}
+
+func trimNewline(line string) string {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,270 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu/uistream"
+)
+
+// Status of the operation on a resource
+const (
+	ResourcePlanned    = "Planned"
+	ResourceInProgress = "InProgress"
+	ResourceComplete   = "Complete"
+	ResourceErrored    = "Errored"
+)
+
+// EventFile returns the path of the file which keeps the machine-readable UI messages of a job.
+func EventFile(job model.Job) string {
+	return filepath.Join(WorkingDir(job), "runningLogs", job.ReqId+".jsonl")
+}
+
+// Events returns the machine-readable UI messages of a given request.
+// If types are given, only the messages of the types are returned.
+func Events(trId, reqId string, types ...string) ([]uistream.Event, error) {
+	job, exists, err := Get(trId, reqId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+	}
+	if err := CheckLocal(job); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(EventFile(job))
+	if errors.Is(err, os.ErrNotExist) {
+		return []uistream.Event{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the event file: %w", err)
+	}
+	defer file.Close()
+
+	events, err := uistream.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the event file: %w", err)
+	}
+	if len(types) == 0 {
+		return events, nil
+	}
+
+	filtered := []uistream.Event{}
+	for _, event := range events {
+		for _, t := range types {
+			if event.Type == t {
+				filtered = append(filtered, event)
+				break
+			}
+		}
+	}
+	return filtered, nil
+}
+
+/*
+ * [Note] Tracking the progress of a request
+ * The progress is kept in memory while a command runs (see trackers), and persisted to the job
+ * only when the state of a resource changes (e.g., planned, started, completed), at most once per progressFlushInterval.
+ * The elapsed time of a resource (i.e., apply_progress every few seconds) is kept in memory,
+ * and persisted with the next change or when the command finishes (see Finish).
+ * Get and List return the progress in memory of the running commands of this host.
+ */
+
+// progressFlushInterval is the minimum interval to persist the progress of a request
+const progressFlushInterval = time.Second
+
+// tracker keeps the progress of a running command in memory.
+type tracker struct {
+	progress *model.JobProgress
+	// dirty means that the state has changed since the progress was persisted
+	dirty     bool
+	flushedAt time.Time
+}
+
+// trackers are the trackers of the running commands by job key (guarded by mu)
+var trackers = map[string]*tracker{}
+
+// Track updates the progress of a given request with a message of the machine-readable UI.
+// The messages not related to the progress (e.g., version, log and outputs) are ignored.
+func Track(trId, reqId string, event uistream.Event) error {
+	switch event.Type {
+	case uistream.TypePlannedChange, uistream.TypeChangeSummary, uistream.TypeDiagnostic,
+		uistream.TypeApplyStart, uistream.TypeApplyProgress, uistream.TypeApplyComplete, uistream.TypeApplyErrored:
+	default:
+		return nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	t, exists := trackers[key(trId, reqId)]
+	if !exists {
+		// Continue the progress of the previous steps of the request, if any
+		job, exists, err := get(trId, reqId)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+		}
+		t = &tracker{progress: job.Progress}
+		if t.progress == nil {
+			t.progress = &model.JobProgress{}
+		}
+		trackers[key(trId, reqId)] = t
+	}
+
+	if update(t.progress, event) {
+		t.dirty = true
+	}
+	if !t.dirty || time.Since(t.flushedAt) < progressFlushInterval {
+		return nil
+	}
+	return flush(trId, reqId, t)
+}
+
+// flush persists the progress of a tracker to the job (with mu held).
+func flush(trId, reqId string, t *tracker) error {
+	job, exists, err := get(trId, reqId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("no job (trId: %s, reqId: %s)", trId, reqId)
+	}
+	job.Progress = copyProgress(t.progress)
+	if err := lkvstore.Put(key(trId, reqId), job); err != nil {
+		return err
+	}
+	t.dirty = false
+	t.flushedAt = time.Now()
+	return nil
+}
+
+// untrack removes the tracker of a request, returning its progress, if any (with mu held).
+func untrack(trId, reqId string) (*model.JobProgress, bool) {
+	t, exists := trackers[key(trId, reqId)]
+	if !exists {
+		return nil, false
+	}
+	delete(trackers, key(trId, reqId))
+	return t.progress, true
+}
+
+// withLiveProgress returns a job with the progress in memory, if its command is running on this host.
+func withLiveProgress(job model.Job) model.Job {
+	mu.Lock()
+	defer mu.Unlock()
+	if t, exists := trackers[key(job.TrId, job.ReqId)]; exists {
+		job.Progress = copyProgress(t.progress)
+	}
+	return job
+}
+
+// copyProgress returns a copy of a progress, not to share the resources with a tracker.
+func copyProgress(progress *model.JobProgress) *model.JobProgress {
+	copied := *progress
+	if progress.Resources != nil {
+		copied.Resources = make(map[string]model.ResourceProgress, len(progress.Resources))
+		for addr, resource := range progress.Resources {
+			copied.Resources[addr] = resource
+		}
+	}
+	copied.Diagnostics = append([]model.JobDiagnostic(nil), progress.Diagnostics...)
+	return &copied
+}
+
+// update updates a progress with a message of the machine-readable UI,
+// and reports whether the state has changed (i.e., not only the elapsed time of a resource).
+func update(progress *model.JobProgress, event uistream.Event) bool {
+	if progress.Resources == nil {
+		progress.Resources = map[string]model.ResourceProgress{}
+	}
+
+	updatedAt := event.Timestamp
+	if updatedAt.IsZero() {
+		updatedAt = time.Now()
+	}
+
+	switch event.Type {
+	case uistream.TypePlannedChange:
+		if event.Change == nil {
+			return false
+		}
+		addr := event.Change.Resource.Addr
+		progress.Resources[addr] = model.ResourceProgress{
+			Addr:      addr,
+			Action:    event.Change.Action,
+			Status:    ResourcePlanned,
+			UpdatedAt: updatedAt,
+		}
+		return true
+
+	case uistream.TypeApplyStart, uistream.TypeApplyProgress, uistream.TypeApplyComplete, uistream.TypeApplyErrored:
+		if event.Hook == nil {
+			return false
+		}
+		addr := event.Hook.Resource.Addr
+		resource := progress.Resources[addr]
+		previous := resource
+		resource.Addr = addr
+		if event.Hook.Action != "" {
+			resource.Action = event.Hook.Action
+		}
+		if event.Hook.IdValue != "" {
+			resource.IdValue = event.Hook.IdValue
+		}
+		if event.Hook.ElapsedSeconds > 0 {
+			resource.ElapsedSeconds = event.Hook.ElapsedSeconds
+		}
+		switch event.Type {
+		case uistream.TypeApplyStart:
+			resource.Status = ResourceInProgress
+			resource.ElapsedSeconds = 0
+		case uistream.TypeApplyProgress:
+			resource.Status = ResourceInProgress
+		case uistream.TypeApplyComplete:
+			resource.Status = ResourceComplete
+		case uistream.TypeApplyErrored:
+			resource.Status = ResourceErrored
+		}
+		resource.UpdatedAt = updatedAt
+		progress.Resources[addr] = resource
+		return event.Type != uistream.TypeApplyProgress ||
+			resource.Status != previous.Status || resource.Action != previous.Action || resource.IdValue != previous.IdValue
+
+	case uistream.TypeChangeSummary:
+		if event.Changes == nil {
+			return false
+		}
+		progress.Summary = &model.ChangeSummary{
+			Operation: event.Changes.Operation,
+			Add:       event.Changes.Add,
+			Change:    event.Changes.Change,
+			Destroy:   event.Changes.Remove,
+			Import:    event.Changes.Import,
+		}
+		return true
+
+	case uistream.TypeDiagnostic:
+		if event.Diagnostic == nil {
+			return false
+		}
+		progress.Diagnostics = append(progress.Diagnostics, model.JobDiagnostic{
+			Severity: event.Diagnostic.Severity,
+			Summary:  event.Diagnostic.Summary,
+			Detail:   event.Diagnostic.Detail,
+			Address:  event.Diagnostic.Address,
+		})
+		return true
+	}
+
+	return false
+}
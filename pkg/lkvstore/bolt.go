@@ -0,0 +1,160 @@
+package lkvstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+/*
+ * [Note] bbolt backend
+ *
+ * The key-value pairs are stored in a bucket of an embedded bbolt database,
+ * which is durable on every write and does not need to be loaded into memory.
+ * The database file is locked by a process, so it is not shared by multiple instances.
+ */
+
+var boltBucket = []byte("lkvstore")
+
+type boltStore struct {
+	db       *bolt.DB
+	watchers watchers
+}
+
+func newBoltStore(dbFilePath string) (*boltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(dbFilePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create db directory: %w", err)
+	}
+
+	db, err := bolt.Open(dbFilePath, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db (%s): %w", dbFilePath, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func (s *boltStore) Get(key string) (string, bool, error) {
+	var value []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(boltBucket).Get([]byte(key)); v != nil {
+			// The value is valid only during the transaction
+			value = bytes.Clone(v)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if value == nil {
+		return "", false, nil
+	}
+	return string(value), true, nil
+}
+
+func (s *boltStore) Put(key, value string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(key), []byte(value))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put the value (key: %s): %w", key, err)
+	}
+	s.watchers.notify(WatchEvent{Type: EventPut, Key: key, Value: value})
+	return nil
+}
+
+func (s *boltStore) Delete(key string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete the value (key: %s): %w", key, err)
+	}
+	s.watchers.notify(WatchEvent{Type: EventDelete, Key: key})
+	return nil
+}
+
+func (s *boltStore) List(prefix string) ([]KeyValue, error) {
+	kvs := []KeyValue{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		p := []byte(prefix)
+		for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+			kvs = append(kvs, KeyValue{Key: string(k), Value: string(v)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the values (prefix: %s): %w", prefix, err)
+	}
+	return kvs, nil
+}
+
+func (s *boltStore) Watch(ctx context.Context, prefix string) (<-chan WatchEvent, error) {
+	return s.watchers.add(ctx, prefix), nil
+}
+
+func (s *boltStore) CompareAndSwap(key, oldValue, newValue string) (bool, error) {
+	return s.Txn([]Cmp{{Key: key, Value: oldValue}}, []Op{{Type: EventPut, Key: key, Value: newValue}})
+}
+
+func (s *boltStore) Txn(cmps []Cmp, ops []Op) (bool, error) {
+	succeeded := false
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(boltBucket)
+		for _, cmp := range cmps {
+			current := b.Get([]byte(cmp.Key))
+			if cmp.Value == "" && current != nil {
+				return nil
+			}
+			if cmp.Value != "" && (current == nil || string(current) != cmp.Value) {
+				return nil
+			}
+		}
+		for _, op := range ops {
+			var err error
+			switch op.Type {
+			case EventPut:
+				err = b.Put([]byte(op.Key), []byte(op.Value))
+			case EventDelete:
+				err = b.Delete([]byte(op.Key))
+			default:
+				err = fmt.Errorf("unsupported operation of a transaction: %s", op.Type)
+			}
+			if err != nil {
+				// Roll back the transaction
+				return err
+			}
+		}
+		succeeded = true
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+	if succeeded {
+		for _, op := range ops {
+			s.watchers.notify(WatchEvent{Type: op.Type, Key: op.Key, Value: op.Value})
+		}
+	}
+	return succeeded, nil
+}
+
+func (s *boltStore) Close() error {
+	s.watchers.closeAll()
+	return s.db.Close()
+}
@@ -0,0 +1,198 @@
+package lkvstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+/*
+ * [Note] etcd backend
+ *
+ * The key-value pairs are stored in an etcd cluster under a key prefix,
+ * so that multiple mc-terrarium instances can share them.
+ * Only the key-value data is shared: the working directories (i.e., the tofu states and the logs)
+ * must be on a volume shared by the instances, and the running commands are cancelled by their host only.
+ * The background loops (e.g., the reaper) run on the leader only (see terrarium.StartLeaderElection).
+ */
+
+const (
+	defaultEtcdPrefix      = "/mc-terrarium"
+	defaultEtcdDialTimeout = 5 * time.Second
+	// etcdRequestTimeout is the timeout of a request to etcd.
+	etcdRequestTimeout = 10 * time.Second
+)
+
+// EtcdConfig is the configuration of the etcd backend.
+type EtcdConfig struct {
+	Endpoints []string
+	Username  string
+	Password  string
+	// Prefix is prepended to the keys (default: /mc-terrarium)
+	Prefix string
+	// DialTimeout is the timeout to connect to etcd (default: 5s)
+	DialTimeout time.Duration
+}
+
+type etcdStore struct {
+	client *clientv3.Client
+	prefix string
+}
+
+func newEtcdStore(config EtcdConfig) (*etcdStore, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("etcd endpoints are required for the etcd backend")
+	}
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = defaultEtcdPrefix
+	}
+	dialTimeout := config.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = defaultEtcdDialTimeout
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   config.Endpoints,
+		Username:    config.Username,
+		Password:    config.Password,
+		DialTimeout: dialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to etcd (%s): %w", strings.Join(config.Endpoints, ","), err)
+	}
+
+	return &etcdStore{client: client, prefix: strings.TrimSuffix(prefix, "/")}, nil
+}
+
+func (s *etcdStore) etcdKey(key string) string {
+	return s.prefix + key
+}
+
+func (s *etcdStore) Get(key string) (string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	resp, err := s.client.Get(ctx, s.etcdKey(key))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get the value (key: %s): %w", key, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return "", false, nil
+	}
+	return string(resp.Kvs[0].Value), true, nil
+}
+
+func (s *etcdStore) Put(key, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	if _, err := s.client.Put(ctx, s.etcdKey(key), value); err != nil {
+		return fmt.Errorf("failed to put the value (key: %s): %w", key, err)
+	}
+	return nil
+}
+
+func (s *etcdStore) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	if _, err := s.client.Delete(ctx, s.etcdKey(key)); err != nil {
+		return fmt.Errorf("failed to delete the value (key: %s): %w", key, err)
+	}
+	return nil
+}
+
+func (s *etcdStore) List(prefix string) ([]KeyValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	resp, err := s.client.Get(ctx, s.etcdKey(prefix), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the values (prefix: %s): %w", prefix, err)
+	}
+
+	kvs := make([]KeyValue, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		kvs = append(kvs, KeyValue{Key: strings.TrimPrefix(string(kv.Key), s.prefix), Value: string(kv.Value)})
+	}
+	return kvs, nil
+}
+
+func (s *etcdStore) Watch(ctx context.Context, prefix string) (<-chan WatchEvent, error) {
+	ch := make(chan WatchEvent, watchBufferSize)
+	watchCh := s.client.Watch(clientv3.WithRequireLeader(ctx), s.etcdKey(prefix), clientv3.WithPrefix())
+
+	go func() {
+		defer close(ch)
+		for resp := range watchCh {
+			if err := resp.Err(); err != nil {
+				log.Error().Err(err).Msgf("failed to watch etcd (prefix: %s)", prefix)
+				return
+			}
+			for _, ev := range resp.Events {
+				event := WatchEvent{Key: strings.TrimPrefix(string(ev.Kv.Key), s.prefix)}
+				switch ev.Type {
+				case clientv3.EventTypePut:
+					event.Type = EventPut
+					event.Value = string(ev.Kv.Value)
+				case clientv3.EventTypeDelete:
+					event.Type = EventDelete
+				}
+				select {
+				case ch <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (s *etcdStore) CompareAndSwap(key, oldValue, newValue string) (bool, error) {
+	return s.Txn([]Cmp{{Key: key, Value: oldValue}}, []Op{{Type: EventPut, Key: key, Value: newValue}})
+}
+
+func (s *etcdStore) Txn(cmps []Cmp, ops []Op) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+
+	etcdCmps := make([]clientv3.Cmp, 0, len(cmps))
+	for _, cmp := range cmps {
+		k := s.etcdKey(cmp.Key)
+		if cmp.Value == "" {
+			// The key does not exist if it has not been created
+			etcdCmps = append(etcdCmps, clientv3.Compare(clientv3.CreateRevision(k), "=", 0))
+		} else {
+			etcdCmps = append(etcdCmps, clientv3.Compare(clientv3.Value(k), "=", cmp.Value))
+		}
+	}
+
+	etcdOps := make([]clientv3.Op, 0, len(ops))
+	for _, op := range ops {
+		switch op.Type {
+		case EventPut:
+			etcdOps = append(etcdOps, clientv3.OpPut(s.etcdKey(op.Key), op.Value))
+		case EventDelete:
+			etcdOps = append(etcdOps, clientv3.OpDelete(s.etcdKey(op.Key)))
+		default:
+			return false, fmt.Errorf("unsupported operation of a transaction: %s", op.Type)
+		}
+	}
+
+	resp, err := s.client.Txn(ctx).If(etcdCmps...).Then(etcdOps...).Commit()
+	if err != nil {
+		return false, fmt.Errorf("failed to commit the transaction: %w", err)
+	}
+	return resp.Succeeded, nil
+}
+
+func (s *etcdStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,459 @@
+package lkvstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] File backend
+ *
+ * The key-value pairs are kept in memory (sync.Map) and persisted durably:
+ * every Put and Delete is appended to a write-ahead log (WAL) and synced to disk before it is applied,
+ * and the WAL is compacted into the snapshot (db file) periodically.
+ * On startup, the snapshot is loaded and the WAL is replayed on top of it.
+ */
+
+const (
+	// defaultCompactionThreshold is the number of WAL records to trigger compaction.
+	defaultCompactionThreshold = 1000
+	// defaultCompactionInterval is the interval of periodic compaction.
+	defaultCompactionInterval = 5 * time.Minute
+)
+
+// walRecord represents an operation in the write-ahead log.
+// A transaction is written as a record with the operations, so that it is replayed entirely or not at all.
+type walRecord struct {
+	Op    string      `json:"op"`
+	Key   string      `json:"key,omitempty"`
+	Value string      `json:"value,omitempty"`
+	Ops   []walRecord `json:"ops,omitempty"`
+}
+
+const (
+	opPut    = "put"
+	opDelete = "delete"
+	opTxn    = "txn"
+)
+
+type fileStore struct {
+	data       sync.Map
+	dbFilePath string
+
+	// mu serializes the writes (WAL appends and compaction),
+	// so that the snapshot and the WAL are consistent with the map.
+	mu                  sync.Mutex
+	walFile             *os.File
+	walRecords          int
+	compactionThreshold int
+	compactionInterval  time.Duration
+	stopCompaction      chan struct{}
+
+	watchers watchers
+}
+
+func newFileStore(dbFilePath string, compactionThreshold int, compactionInterval time.Duration) *fileStore {
+	s := &fileStore{
+		dbFilePath:          dbFilePath,
+		compactionThreshold: defaultCompactionThreshold,
+		compactionInterval:  defaultCompactionInterval,
+	}
+	if compactionThreshold > 0 {
+		s.compactionThreshold = compactionThreshold
+	}
+	if compactionInterval > 0 {
+		s.compactionInterval = compactionInterval
+	}
+	return s
+}
+
+// walFilePath returns the path of the write-ahead log.
+func (s *fileStore) walFilePath() string {
+	return s.dbFilePath + ".wal"
+}
+
+// Compact writes the snapshot atomically and truncates the WAL.
+func (s *fileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.compact()
+}
+
+// Load loads the snapshot, replays the WAL on top of it and starts the periodic compaction.
+func (s *fileStore) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshotExists := true
+	if _, err := os.Stat(s.dbFilePath); os.IsNotExist(err) {
+		snapshotExists = false
+	} else if err := s.loadSnapshot(); err != nil {
+		return err
+	}
+
+	replayed, corrupted, err := s.replayWAL()
+	if err != nil {
+		return err
+	}
+	s.walRecords = replayed
+	log.Debug().Msgf("Replayed %d records of the WAL (%s)", replayed, s.walFilePath())
+
+	// Rewrite the WAL not to append records after a corrupted one
+	if corrupted {
+		if err := s.compact(); err != nil {
+			return err
+		}
+	}
+
+	s.startCompaction()
+
+	if !snapshotExists && replayed == 0 {
+		return fmt.Errorf("db file does not exist: %s", s.dbFilePath)
+	}
+	return nil
+}
+
+// Close compacts the WAL into the snapshot and stops the periodic compaction.
+func (s *fileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopCompaction != nil {
+		close(s.stopCompaction)
+		s.stopCompaction = nil
+	}
+
+	err := s.compact()
+	if s.walFile != nil {
+		s.walFile.Close()
+		s.walFile = nil
+	}
+	s.watchers.closeAll()
+	return err
+}
+
+func (s *fileStore) Get(key string) (string, bool, error) {
+	value, ok := s.data.Load(key)
+	if !ok {
+		return "", false, nil
+	}
+	return value.(string), true, nil
+}
+
+func (s *fileStore) List(prefix string) ([]KeyValue, error) {
+	kvs := []KeyValue{}
+	s.data.Range(func(key, value interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			kvs = append(kvs, KeyValue{Key: key.(string), Value: value.(string)})
+		}
+		return true
+	})
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	return kvs, nil
+}
+
+// Put stores the key-value pair.
+// The pair is written to the WAL durably before it is stored.
+func (s *fileStore) Put(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.put(key, value)
+}
+
+func (s *fileStore) put(key, value string) error {
+	if err := s.appendWAL(walRecord{Op: opPut, Key: key, Value: value}); err != nil {
+		return err
+	}
+	s.data.Store(key, value)
+	s.watchers.notify(WatchEvent{Type: EventPut, Key: key, Value: value})
+
+	s.compactIfNeeded()
+	return nil
+}
+
+// Delete deletes the key-value pair for a given key.
+// The deletion is written to the WAL durably before the pair is deleted.
+func (s *fileStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.appendWAL(walRecord{Op: opDelete, Key: key}); err != nil {
+		return err
+	}
+	s.data.Delete(key)
+	s.watchers.notify(WatchEvent{Type: EventDelete, Key: key})
+
+	s.compactIfNeeded()
+	return nil
+}
+
+func (s *fileStore) Watch(ctx context.Context, prefix string) (<-chan WatchEvent, error) {
+	return s.watchers.add(ctx, prefix), nil
+}
+
+func (s *fileStore) CompareAndSwap(key, oldValue, newValue string) (bool, error) {
+	return s.Txn([]Cmp{{Key: key, Value: oldValue}}, []Op{{Type: EventPut, Key: key, Value: newValue}})
+}
+
+func (s *fileStore) Txn(cmps []Cmp, ops []Op) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, cmp := range cmps {
+		current, exists := s.data.Load(cmp.Key)
+		if cmp.Value == "" && exists {
+			return false, nil
+		}
+		if cmp.Value != "" && (!exists || current.(string) != cmp.Value) {
+			return false, nil
+		}
+	}
+
+	record := walRecord{Op: opTxn}
+	for _, op := range ops {
+		switch op.Type {
+		case EventPut:
+			record.Ops = append(record.Ops, walRecord{Op: opPut, Key: op.Key, Value: op.Value})
+		case EventDelete:
+			record.Ops = append(record.Ops, walRecord{Op: opDelete, Key: op.Key})
+		default:
+			return false, fmt.Errorf("unsupported operation of a transaction: %s", op.Type)
+		}
+	}
+	if err := s.appendWAL(record); err != nil {
+		return false, err
+	}
+	s.apply(record)
+	for _, op := range ops {
+		s.watchers.notify(WatchEvent{Type: op.Type, Key: op.Key, Value: op.Value})
+	}
+
+	s.compactIfNeeded()
+	return true, nil
+}
+
+// apply applies a WAL record to the map.
+func (s *fileStore) apply(record walRecord) {
+	switch record.Op {
+	case opPut:
+		s.data.Store(record.Key, record.Value)
+	case opDelete:
+		s.data.Delete(record.Key)
+	case opTxn:
+		for _, op := range record.Ops {
+			s.apply(op)
+		}
+	}
+}
+
+// loadSnapshot loads the key-value pairs from the snapshot (db file).
+func (s *fileStore) loadSnapshot() error {
+	file, err := os.Open(s.dbFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open db file: %w", err)
+	}
+	defer file.Close()
+
+	var tempMap map[string]string
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&tempMap); err != nil {
+		return fmt.Errorf("failed to decode map: %w", err)
+	}
+
+	for key, value := range tempMap {
+		s.data.Store(key, value)
+	}
+
+	return nil
+}
+
+// replayWAL applies the records in the WAL to the map and returns the number of the records.
+// An incomplete record at the end (e.g., written partially at a crash) is skipped and reported as corrupted.
+func (s *fileStore) replayWAL() (int, bool, error) {
+	file, err := os.Open(s.walFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to open wal file: %w", err)
+	}
+	defer file.Close()
+
+	count := 0
+	corrupted := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record walRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			log.Warn().Err(err).Msgf("skipped a corrupted record of the WAL (record: %d)", count+1)
+			corrupted = true
+			continue
+		}
+
+		s.apply(record)
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, corrupted, fmt.Errorf("failed to read wal file: %w", err)
+	}
+
+	return count, corrupted, nil
+}
+
+// appendWAL appends a record to the WAL and syncs it to disk.
+func (s *fileStore) appendWAL(record walRecord) error {
+	if s.walFile == nil {
+		if err := os.MkdirAll(filepath.Dir(s.dbFilePath), 0755); err != nil {
+			return fmt.Errorf("failed to create db directory: %w", err)
+		}
+		file, err := os.OpenFile(s.walFilePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open wal file: %w", err)
+		}
+		s.walFile = file
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal wal record: %w", err)
+	}
+	if _, err := s.walFile.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write wal record: %w", err)
+	}
+	if err := s.walFile.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal file: %w", err)
+	}
+	s.walRecords++
+
+	return nil
+}
+
+// compactIfNeeded compacts the WAL if it has grown over the threshold.
+func (s *fileStore) compactIfNeeded() {
+	if s.walRecords < s.compactionThreshold {
+		return
+	}
+	if err := s.compact(); err != nil {
+		log.Error().Err(err).Msg("failed to compact the lkvstore")
+	}
+}
+
+// startCompaction starts compacting the WAL periodically.
+func (s *fileStore) startCompaction() {
+	if s.stopCompaction != nil {
+		return
+	}
+	stop := make(chan struct{})
+	s.stopCompaction = stop
+
+	go func() {
+		ticker := time.NewTicker(s.compactionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				s.mu.Lock()
+				if s.walRecords > 0 {
+					if err := s.compact(); err != nil {
+						log.Error().Err(err).Msg("failed to compact the lkvstore")
+					}
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// compact writes the snapshot atomically and truncates the WAL.
+// The snapshot is written to a temporary file, synced and renamed to the db file,
+// so that the db file is either the previous or the new snapshot even at a crash.
+// It must be called with mu held.
+func (s *fileStore) compact() error {
+
+	// Ensure the DB file directory exists before creating the snapshot
+	dir := filepath.Dir(s.dbFilePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create db directory: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp(dir, filepath.Base(s.dbFilePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp db file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	defer os.Remove(tempPath) // no-op after the rename
+	if err := tempFile.Chmod(0644); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to set the mode of temp db file: %w", err)
+	}
+
+	tempMap := make(map[string]string)
+	s.data.Range(func(key, value interface{}) bool {
+		tempMap[key.(string)] = value.(string)
+		return true
+	})
+
+	encoder := json.NewEncoder(tempFile)
+	if err := encoder.Encode(tempMap); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to encode map: %w", err)
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to sync temp db file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temp db file: %w", err)
+	}
+	if err := os.Rename(tempPath, s.dbFilePath); err != nil {
+		return fmt.Errorf("failed to rename temp db file: %w", err)
+	}
+	syncDir(dir)
+
+	// Truncate the WAL, which is now included in the snapshot
+	if s.walFile != nil {
+		s.walFile.Close()
+		s.walFile = nil
+	}
+	if err := os.Truncate(s.walFilePath(), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to truncate wal file: %w", err)
+	}
+	s.walRecords = 0
+
+	return nil
+}
+
+// syncDir syncs a directory to make a rename in it durable.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		log.Debug().Err(err).Msgf("failed to sync the directory (%s)", dir)
+	}
+}
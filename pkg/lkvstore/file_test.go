@@ -0,0 +1,120 @@
+package lkvstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// crashedFileStore returns the path of a db file whose WAL has the given records,
+// followed by a tail written as is (e.g., a torn record), as if the server crashed before compaction.
+func crashedFileStore(t *testing.T, write func(s *fileStore), tail string) string {
+	t.Helper()
+
+	dbFilePath := filepath.Join(t.TempDir(), "lkvstore.db")
+	s := newFileStore(dbFilePath, 0, 0)
+	write(s)
+	// Crash: the WAL is neither compacted nor closed by Close
+	s.walFile.Close()
+
+	file, err := os.OpenFile(s.walFilePath(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open the WAL: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(tail); err != nil {
+		t.Fatalf("failed to write the tail of the WAL: %v", err)
+	}
+	return dbFilePath
+}
+
+func loadFileStore(t *testing.T, dbFilePath string) *fileStore {
+	t.Helper()
+
+	s := newFileStore(dbFilePath, 0, 0)
+	if err := s.Load(); err != nil {
+		t.Fatalf("failed to load the store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func assertValue(t *testing.T, s Store, key, want string, wantExists bool) {
+	t.Helper()
+
+	value, exists, err := s.Get(key)
+	if err != nil {
+		t.Fatalf("failed to get %s: %v", key, err)
+	}
+	if exists != wantExists || value != want {
+		t.Errorf("Get(%s) = (%q, %v), want (%q, %v)", key, value, exists, want, wantExists)
+	}
+}
+
+func TestReplayWAL(t *testing.T) {
+	write := func(s *fileStore) {
+		s.Put("/a", `"1"`)
+		s.Put("/b", `"2"`)
+		s.Delete("/a")
+		s.Put("/a", `"3"`)
+	}
+
+	tests := []struct {
+		name string
+		tail string
+	}{
+		{name: "complete", tail: ""},
+		{name: "torn put", tail: `{"op":"put","key":"/c","val`},
+		{name: "torn txn", tail: `{"op":"txn","ops":[{"op":"put","key":"/c","value":"\"4\""},{"op":"delete","ke`},
+		{name: "corrupt", tail: "\x00\x00\x00\x00\n"},
+		{name: "corrupt without newline", tail: "}{"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbFilePath := crashedFileStore(t, write, tt.tail)
+
+			s := loadFileStore(t, dbFilePath)
+			assertValue(t, s, "/a", `"3"`, true)
+			assertValue(t, s, "/b", `"2"`, true)
+			assertValue(t, s, "/c", "", false)
+
+			// The records written after the recovery must be replayed (i.e., not appended to the corrupted record)
+			if err := s.Put("/d", `"5"`); err != nil {
+				t.Fatalf("failed to put after the recovery: %v", err)
+			}
+			s.walFile.Close()
+			s.walFile = nil
+
+			reloaded := loadFileStore(t, dbFilePath)
+			assertValue(t, reloaded, "/a", `"3"`, true)
+			assertValue(t, reloaded, "/b", `"2"`, true)
+			assertValue(t, reloaded, "/c", "", false)
+			assertValue(t, reloaded, "/d", `"5"`, true)
+		})
+	}
+}
+
+func TestReplayWALOnSnapshot(t *testing.T) {
+	dbFilePath := crashedFileStore(t, func(s *fileStore) {
+		s.Put("/a", `"1"`)
+		s.Put("/b", `"2"`)
+		if err := s.Compact(); err != nil {
+			t.Fatalf("failed to compact: %v", err)
+		}
+		s.Put("/a", `"3"`)
+		s.Delete("/b")
+	}, `{"op":"delete","key":"/a"`)
+
+	s := loadFileStore(t, dbFilePath)
+	assertValue(t, s, "/a", `"3"`, true)
+	assertValue(t, s, "/b", "", false)
+}
+
+func TestLoadWithoutDbFile(t *testing.T) {
+	s := newFileStore(filepath.Join(t.TempDir(), "lkvstore.db"), 0, 0)
+	t.Cleanup(func() { s.Close() })
+	if err := s.Load(); err == nil {
+		t.Error("Load() succeeded without the db file and the WAL")
+	}
+}
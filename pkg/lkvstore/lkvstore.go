@@ -1,132 +1,207 @@
-// Local Key-Value Store based on sync.Map and file I/O
+// Local Key-Value Store
+//
+// The key-value pairs are stored in a storage backend implementing the Store interface:
+//   - "file" (default): an in-memory map persisted to a snapshot (db file) and a write-ahead log (WAL)
+//   - "bolt": an embedded bbolt database
+//   - "etcd": an etcd cluster, which can be shared by multiple mc-terrarium instances
+//
+// The package-level functions operate on the store selected by Init.
 package lkvstore
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
-	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
-var (
-	lkvstore   sync.Map
-	dbFilePath string
+// Storage backends
+const (
+	BackendFile = "file"
+	BackendBolt = "bolt"
+	BackendEtcd = "etcd"
 )
 
+// Store is the interface of a storage backend.
+// The values are strings (i.e., JSON documents marshaled by Put).
+type Store interface {
+	// Get returns the value for a given key.
+	Get(key string) (string, bool, error)
+	// Put stores the key-value pair.
+	Put(key, value string) error
+	// Delete deletes the key-value pair for a given key.
+	Delete(key string) error
+	// List returns the key-value pairs for a given key prefix in order of the keys.
+	List(prefix string) ([]KeyValue, error)
+	// Watch returns a channel notifying the changes of the keys with a given prefix until ctx is done.
+	Watch(ctx context.Context, prefix string) (<-chan WatchEvent, error)
+	// CompareAndSwap stores the new value only if the current value is equal to the old value.
+	// An empty old value means that the key must not exist.
+	CompareAndSwap(key, oldValue, newValue string) (bool, error)
+	// Txn applies the operations atomically only if all the conditions hold.
+	// It returns false without applying any operation if a condition does not hold.
+	Txn(cmps []Cmp, ops []Op) (bool, error)
+	// Close releases the resources of the store.
+	Close() error
+}
+
+// KeyValue represents a key-value pair.
+type KeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Types of watch events
+const (
+	EventPut    = "put"
+	EventDelete = "delete"
+)
+
+// WatchEvent represents a change of a key.
+type WatchEvent struct {
+	Type  string `json:"type"`
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+// Cmp is a condition of a transaction, which holds if the current value of the key is equal to the value.
+// An empty value means that the key must not exist.
+type Cmp struct {
+	Key   string
+	Value string
+}
+
+// Op is an operation of a transaction.
+type Op struct {
+	// Type is the type of the operation: EventPut or EventDelete
+	Type  string
+	Key   string
+	Value string
+}
+
 type Config struct {
+	// Backend is the storage backend: "file" (default), "bolt" or "etcd"
+	Backend    string
 	DbFilePath string
+	// CompactionThreshold is the number of WAL records to trigger compaction (default: 1000, file backend only)
+	CompactionThreshold int
+	// CompactionInterval is the interval of periodic compaction (default: 5m, file backend only)
+	CompactionInterval time.Duration
+	// Etcd is the configuration of the etcd backend
+	Etcd EtcdConfig
 }
 
-func Init(config Config) {
-	if config.DbFilePath != "" {
-		dbFilePath = config.DbFilePath
-	} else {
-		dbFilePath = ".lkvstore/lkvstore.db"
-	}
-}
+var store Store
 
-// Save lkvstore to file
-func SaveLkvStore() error {
-	if dbFilePath == "" {
-		return fmt.Errorf("db file path is not set")
+// Init initializes the store of the configured backend.
+func Init(config Config) error {
+	if config.DbFilePath == "" {
+		config.DbFilePath = ".lkvstore/lkvstore.db"
 	}
 
-	// Ensure the DB file directory exists before creating the log file
-	dir := filepath.Dir(dbFilePath)
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		// Create the directory if it does not exist
-		err = os.MkdirAll(dir, 0755) // Set permissions as needed
-		if err != nil {
-			log.Error().Msgf("Failed to Create the DB Directory: : [%v]", err)
-		}
+	s, err := newStore(config)
+	if err != nil {
+		return err
 	}
+	if store != nil {
+		store.Close()
+	}
+	store = s
+	return nil
+}
 
-	file, err := os.Create(dbFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create db file: %w", err)
+func newStore(config Config) (Store, error) {
+	switch config.Backend {
+	case "", BackendFile:
+		return newFileStore(config.DbFilePath, config.CompactionThreshold, config.CompactionInterval), nil
+	case BackendBolt:
+		return newBoltStore(config.DbFilePath)
+	case BackendEtcd:
+		return newEtcdStore(config.Etcd)
+	default:
+		return nil, fmt.Errorf("unsupported lkvstore backend: %s", config.Backend)
 	}
-	defer file.Close()
+}
 
-	tempMap := make(map[string]interface{})
-	lkvstore.Range(func(key, value interface{}) bool {
-		tempMap[key.(string)] = value
-		return true
-	})
+// Default returns the store selected by Init.
+func Default() Store {
+	return store
+}
 
-	encoder := json.NewEncoder(file)
-	if err := encoder.Encode(tempMap); err != nil {
-		return fmt.Errorf("failed to encode map: %w", err)
+// Save lkvstore to file
+// It compacts the WAL into the snapshot for the file backend, and does nothing for the others.
+func SaveLkvStore() error {
+	if s, ok := store.(*fileStore); ok {
+		return s.Compact()
 	}
-
 	return nil
 }
 
 // Load the info from file
+// It loads the snapshot and replays the WAL for the file backend, and does nothing for the others.
 func LoadLkvStore() error {
-	if dbFilePath == "" {
-		return fmt.Errorf("db file path is not set")
+	if store == nil {
+		return fmt.Errorf("lkvstore is not initialized")
 	}
-
-	if _, err := os.Stat(dbFilePath); os.IsNotExist(err) {
-		return fmt.Errorf("db file does not exist: %w", err)
-	}
-
-	file, err := os.Open(dbFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to open db file: %w", err)
-	}
-	defer file.Close()
-
-	var tempMap map[string]interface{}
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&tempMap); err != nil {
-		return fmt.Errorf("failed to decode map: %w", err)
+	if s, ok := store.(*fileStore); ok {
+		return s.Load()
 	}
+	return nil
+}
 
-	for key, value := range tempMap {
-		lkvstore.Store(key, value)
+// Close closes the store.
+func Close() error {
+	if store == nil {
+		return nil
 	}
-
-	return nil
+	return store.Close()
 }
 
 // Get returns the value for a given key.
 func Get(key string) (string, bool) {
 
-	value, ok := lkvstore.Load(key)
+	value, ok, err := store.Get(key)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to get the value (key: %s)", key)
+		return "", false
+	}
 	if !ok {
 		log.Debug().Msgf("Get key: %s, value: %v", key, nil)
 		return "", ok
 	}
 
-	return value.(string), ok
+	return value, ok
 }
 
 // GetWithPrefix returns the values for a given key prefix.
 func GetWithPrefix(keyPrefix string) ([]string, bool) {
-	var results []string
-	var exists bool
-
-	lkvstore.Range(func(key, value interface{}) bool {
-		if strings.HasPrefix(key.(string), keyPrefix) {
-			results = append(results, value.(string))
-			exists = true
-		}
-		return true
-	})
-
-	if !exists {
+
+	kvs, err := store.List(keyPrefix)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to list the values (prefix: %s)", keyPrefix)
+		return nil, false
+	}
+	if len(kvs) == 0 {
 		return nil, false
 	}
 
+	results := make([]string, 0, len(kvs))
+	for _, kv := range kvs {
+		results = append(results, kv.Value)
+	}
+
 	return results, true
 }
 
+// List returns the key-value pairs for a given key prefix.
+func List(keyPrefix string) ([]KeyValue, error) {
+	return store.List(keyPrefix)
+}
+
 // Put the key-value pair.
+// The value is stored as a JSON string.
 func Put(key string, value interface{}) error {
 
 	// Marshal the value to JSON
@@ -136,11 +211,56 @@ func Put(key string, value interface{}) error {
 	}
 
 	// Store the JSON string
-	lkvstore.Store(key, string(jsonValue))
-	return nil
+	return store.Put(key, string(jsonValue))
 }
 
 // Delete the key-value pair for a given key.
-func Delete(key string) {
-	lkvstore.Delete(key)
+func Delete(key string) error {
+	return store.Delete(key)
+}
+
+// Watch returns a channel notifying the changes of the keys with a given prefix until ctx is done.
+func Watch(ctx context.Context, keyPrefix string) (<-chan WatchEvent, error) {
+	return store.Watch(ctx, keyPrefix)
+}
+
+// CompareAndSwap stores the new value as a JSON string
+// only if the current value is equal to the old value (i.e., the value returned by Get).
+// An empty old value means that the key must not exist.
+func CompareAndSwap(key, oldValue string, newValue interface{}) (bool, error) {
+
+	jsonValue, err := json.Marshal(newValue)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	return store.CompareAndSwap(key, oldValue, string(jsonValue))
+}
+
+// PutIfAbsent stores the key-value pair only if the key does not exist.
+// It returns false if the key already exists.
+func PutIfAbsent(key string, value interface{}) (bool, error) {
+	return CompareAndSwap(key, "", value)
+}
+
+// Txn applies the operations atomically only if all the conditions hold.
+// It returns false without applying any operation if a condition does not hold.
+func Txn(cmps []Cmp, ops ...Op) (bool, error) {
+	return store.Txn(cmps, ops)
+}
+
+// OpPut returns an operation of a transaction storing the value as a JSON string.
+func OpPut(key string, value interface{}) (Op, error) {
+
+	jsonValue, err := json.Marshal(value)
+	if err != nil {
+		return Op{}, fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	return Op{Type: EventPut, Key: key, Value: string(jsonValue)}, nil
+}
+
+// OpDelete returns an operation of a transaction deleting the key.
+func OpDelete(key string) Op {
+	return Op{Type: EventDelete, Key: key}
 }
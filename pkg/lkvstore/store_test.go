@@ -0,0 +1,210 @@
+package lkvstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// backends returns a new store of each local backend (the etcd backend needs a cluster).
+func backends(t *testing.T) map[string]Store {
+	t.Helper()
+
+	bolt, err := newBoltStore(filepath.Join(t.TempDir(), "lkvstore.bolt"))
+	if err != nil {
+		t.Fatalf("failed to open the bolt store: %v", err)
+	}
+	stores := map[string]Store{
+		BackendFile: newFileStore(filepath.Join(t.TempDir(), "lkvstore.db"), 0, 0),
+		BackendBolt: bolt,
+	}
+	for _, s := range stores {
+		t.Cleanup(func() { s.Close() })
+	}
+	return stores
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	for backend, s := range backends(t) {
+		t.Run(backend, func(t *testing.T) {
+			// An empty old value means that the key must not exist
+			if ok, err := s.CompareAndSwap("/k", "", `"1"`); err != nil || !ok {
+				t.Fatalf("CompareAndSwap() on an absent key = (%v, %v), want (true, nil)", ok, err)
+			}
+			if ok, err := s.CompareAndSwap("/k", "", `"2"`); err != nil || ok {
+				t.Errorf("CompareAndSwap() on an existing key = (%v, %v), want (false, nil)", ok, err)
+			}
+			assertValue(t, s, "/k", `"1"`, true)
+
+			if ok, err := s.CompareAndSwap("/k", `"0"`, `"2"`); err != nil || ok {
+				t.Errorf("CompareAndSwap() with a stale value = (%v, %v), want (false, nil)", ok, err)
+			}
+			assertValue(t, s, "/k", `"1"`, true)
+
+			if ok, err := s.CompareAndSwap("/k", `"1"`, `"2"`); err != nil || !ok {
+				t.Errorf("CompareAndSwap() with the current value = (%v, %v), want (true, nil)", ok, err)
+			}
+			assertValue(t, s, "/k", `"2"`, true)
+
+			// A deleted key does not match its last value
+			if err := s.Delete("/k"); err != nil {
+				t.Fatalf("failed to delete: %v", err)
+			}
+			if ok, err := s.CompareAndSwap("/k", `"2"`, `"3"`); err != nil || ok {
+				t.Errorf("CompareAndSwap() on a deleted key = (%v, %v), want (false, nil)", ok, err)
+			}
+			assertValue(t, s, "/k", "", false)
+		})
+	}
+}
+
+func TestTxn(t *testing.T) {
+	for backend, s := range backends(t) {
+		t.Run(backend, func(t *testing.T) {
+			s.Put("/status", `"Success"`)
+			s.Put("/old", `"x"`)
+
+			// A condition does not hold: no operation is applied
+			ok, err := s.Txn(
+				[]Cmp{{Key: "/status", Value: `"Success"`}, {Key: "/lock", Value: `"someone"`}},
+				[]Op{{Type: EventPut, Key: "/status", Value: `"Running"`}, {Type: EventDelete, Key: "/old"}},
+			)
+			if err != nil || ok {
+				t.Errorf("Txn() with a false condition = (%v, %v), want (false, nil)", ok, err)
+			}
+			assertValue(t, s, "/status", `"Success"`, true)
+			assertValue(t, s, "/old", `"x"`, true)
+
+			// All the conditions hold: all the operations are applied
+			ok, err = s.Txn(
+				[]Cmp{{Key: "/status", Value: `"Success"`}, {Key: "/lock", Value: ""}},
+				[]Op{
+					{Type: EventPut, Key: "/status", Value: `"Running"`},
+					{Type: EventPut, Key: "/lock", Value: `"req1"`},
+					{Type: EventDelete, Key: "/old"},
+				},
+			)
+			if err != nil || !ok {
+				t.Errorf("Txn() with true conditions = (%v, %v), want (true, nil)", ok, err)
+			}
+			assertValue(t, s, "/status", `"Running"`, true)
+			assertValue(t, s, "/lock", `"req1"`, true)
+			assertValue(t, s, "/old", "", false)
+
+			// An unsupported operation fails the transaction entirely
+			ok, err = s.Txn(nil, []Op{{Type: EventPut, Key: "/status", Value: `"Failed"`}, {Type: "patch", Key: "/lock"}})
+			if err == nil || ok {
+				t.Errorf("Txn() with an unsupported operation = (%v, %v), want (false, error)", ok, err)
+			}
+			assertValue(t, s, "/status", `"Running"`, true)
+		})
+	}
+}
+
+func TestConcurrentCompareAndSwap(t *testing.T) {
+	const workers = 20
+
+	for backend, s := range backends(t) {
+		t.Run(backend, func(t *testing.T) {
+			// Only one of the concurrent callers creates a key
+			var wg sync.WaitGroup
+			created := make(chan int, workers)
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					ok, err := s.CompareAndSwap("/id", "", fmt.Sprintf(`"%d"`, i))
+					if err != nil {
+						t.Errorf("CompareAndSwap() failed: %v", err)
+					}
+					if ok {
+						created <- i
+					}
+				}(i)
+			}
+			wg.Wait()
+			close(created)
+			if len(created) != 1 {
+				t.Errorf("%d callers created the key, want 1", len(created))
+			}
+			winner := <-created
+			assertValue(t, s, "/id", fmt.Sprintf(`"%d"`, winner), true)
+
+			// No update is lost by the callers retrying on conflicts
+			s.Put("/counter", "0")
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						current, _, err := s.Get("/counter")
+						if err != nil {
+							t.Errorf("Get() failed: %v", err)
+							return
+						}
+						var n int
+						json.Unmarshal([]byte(current), &n)
+						ok, err := s.CompareAndSwap("/counter", current, fmt.Sprint(n+1))
+						if err != nil {
+							t.Errorf("CompareAndSwap() failed: %v", err)
+							return
+						}
+						if ok {
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			assertValue(t, s, "/counter", fmt.Sprint(workers), true)
+		})
+	}
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	type info struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	for _, backend := range []string{BackendFile, BackendBolt} {
+		t.Run(backend, func(t *testing.T) {
+			if err := Init(Config{Backend: backend, DbFilePath: filepath.Join(t.TempDir(), "lkvstore.db")}); err != nil {
+				t.Fatalf("failed to init the store: %v", err)
+			}
+			t.Cleanup(func() {
+				Close()
+				store = nil
+			})
+
+			if ok, err := PutIfAbsent("/tr/tr01", info{Id: "tr01", Name: "first"}); err != nil || !ok {
+				t.Fatalf("PutIfAbsent() on an absent key = (%v, %v), want (true, nil)", ok, err)
+			}
+			if ok, err := PutIfAbsent("/tr/tr01", info{Id: "tr01", Name: "second"}); err != nil || ok {
+				t.Errorf("PutIfAbsent() on an existing key = (%v, %v), want (false, nil)", ok, err)
+			}
+
+			// The value returned by Get is the old value of CompareAndSwap
+			raw, exists := Get("/tr/tr01")
+			if !exists {
+				t.Fatal("Get() found no value after PutIfAbsent()")
+			}
+			var got info
+			if err := json.Unmarshal([]byte(raw), &got); err != nil || got.Name != "first" {
+				t.Errorf("Get() = %s, want the value of the first PutIfAbsent()", raw)
+			}
+			if ok, err := CompareAndSwap("/tr/tr01", raw, info{Id: "tr01", Name: "renamed"}); err != nil || !ok {
+				t.Errorf("CompareAndSwap() with the value of Get() = (%v, %v), want (true, nil)", ok, err)
+			}
+			if ok, err := CompareAndSwap("/tr/tr01", raw, info{Id: "tr01", Name: "stale"}); err != nil || ok {
+				t.Errorf("CompareAndSwap() with a stale value = (%v, %v), want (false, nil)", ok, err)
+			}
+			raw, _ = Get("/tr/tr01")
+			if err := json.Unmarshal([]byte(raw), &got); err != nil || got.Name != "renamed" {
+				t.Errorf("Get() = %s, want the renamed value", raw)
+			}
+		})
+	}
+}
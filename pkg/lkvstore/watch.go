@@ -0,0 +1,81 @@
+package lkvstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// watchBufferSize is the number of events buffered for a watcher.
+const watchBufferSize = 64
+
+// watchers notifies the changes in a process to the watchers.
+// It is used by the embedded backends, whose changes are made only by this process.
+type watchers struct {
+	mu      sync.Mutex
+	nextId  int
+	entries map[int]watcher
+}
+
+type watcher struct {
+	prefix string
+	ch     chan WatchEvent
+}
+
+// add registers a watcher, which is removed when ctx is done.
+func (w *watchers) add(ctx context.Context, prefix string) <-chan WatchEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.entries == nil {
+		w.entries = make(map[int]watcher)
+	}
+	id := w.nextId
+	w.nextId++
+	ch := make(chan WatchEvent, watchBufferSize)
+	w.entries[id] = watcher{prefix: prefix, ch: ch}
+
+	go func() {
+		<-ctx.Done()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// The channel has been closed if the store is closed
+		if _, ok := w.entries[id]; ok {
+			delete(w.entries, id)
+			close(ch)
+		}
+	}()
+
+	return ch
+}
+
+// notify sends an event to the watchers of the key.
+// The event is dropped for a watcher not receiving the events, so that writes are not blocked.
+func (w *watchers) notify(event WatchEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, entry := range w.entries {
+		if !strings.HasPrefix(event.Key, entry.prefix) {
+			continue
+		}
+		select {
+		case entry.ch <- event:
+		default:
+			log.Warn().Msgf("dropped a watch event (key: %s), the watcher is too slow", event.Key)
+		}
+	}
+}
+
+// closeAll closes the channels of all watchers.
+func (w *watchers) closeAll() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for id, entry := range w.entries {
+		close(entry.ch)
+		delete(w.entries, id)
+	}
+}
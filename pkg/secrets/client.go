@@ -0,0 +1,346 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// retryInterval is the interval to log in again if the token cannot be renewed.
+const retryInterval = 10 * time.Second
+
+// Client is an OpenBao client logged in by AppRole.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mu sync.RWMutex
+	// token is the client token, which expires after the lease duration from obtainedAt
+	token         string
+	leaseDuration time.Duration
+	loginLease    time.Duration
+	renewable     bool
+	obtainedAt    time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// authResponse is the response of the auth APIs (e.g., login, renew-self, create).
+type authResponse struct {
+	Auth *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// login logs in by AppRole.
+func (c *Client) login(ctx context.Context) error {
+	body := map[string]string{"role_id": c.config.RoleId, "secret_id": c.config.SecretId}
+	res := authResponse{}
+	if err := c.do(ctx, http.MethodPost, "auth/approle/login", "", body, &res); err != nil {
+		return fmt.Errorf("failed to log in to %s by AppRole: %w", c.config.Address, err)
+	}
+	if res.Auth == nil || res.Auth.ClientToken == "" {
+		return fmt.Errorf("failed to log in to %s by AppRole: no token in the response", c.config.Address)
+	}
+
+	lease := time.Duration(res.Auth.LeaseDuration) * time.Second
+	c.mu.Lock()
+	c.token = res.Auth.ClientToken
+	c.leaseDuration = lease
+	c.loginLease = lease
+	c.renewable = res.Auth.Renewable
+	c.obtainedAt = time.Now()
+	c.mu.Unlock()
+
+	log.Info().Msgf("logged in to the secrets store (address: %s, lease: %s)", c.config.Address, lease)
+	return nil
+}
+
+// renew renews the client token.
+func (c *Client) renew(ctx context.Context) error {
+	res := authResponse{}
+	if err := c.do(ctx, http.MethodPost, "auth/token/renew-self", c.currentToken(), map[string]string{}, &res); err != nil {
+		return fmt.Errorf("failed to renew the token: %w", err)
+	}
+	if res.Auth == nil {
+		return fmt.Errorf("failed to renew the token: no auth in the response")
+	}
+
+	lease := time.Duration(res.Auth.LeaseDuration) * time.Second
+	c.mu.Lock()
+	c.leaseDuration = lease
+	c.renewable = res.Auth.Renewable
+	c.obtainedAt = time.Now()
+	c.mu.Unlock()
+
+	log.Debug().Msgf("renewed the token of the secrets store (lease: %s)", lease)
+	return nil
+}
+
+// renewLoop renews the token at two thirds of its lease, until the client is closed.
+// It logs in again if the token is not renewable, cannot be renewed,
+// or is about to reach its maximum TTL (i.e., the renewed lease gets short).
+func (c *Client) renewLoop() {
+	for {
+		c.mu.RLock()
+		lease, loginLease, renewable, obtainedAt := c.leaseDuration, c.loginLease, c.renewable, c.obtainedAt
+		c.mu.RUnlock()
+
+		// A token without a lease (e.g., periodic without a TTL) does not expire
+		if lease == 0 {
+			<-c.done
+			return
+		}
+
+		select {
+		case <-c.done:
+			return
+		case <-time.After(time.Until(obtainedAt.Add(lease * 2 / 3))):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		var err error
+		if renewable {
+			err = c.renew(ctx)
+			c.mu.RLock()
+			short := c.leaseDuration < loginLease/3
+			c.mu.RUnlock()
+			if err == nil && short {
+				err = fmt.Errorf("the token is about to reach its maximum TTL")
+			}
+		} else {
+			err = fmt.Errorf("the token is not renewable")
+		}
+		if err != nil {
+			log.Debug().Msgf("logging in to the secrets store again (%v)", err)
+			err = c.login(ctx)
+		}
+		cancel()
+
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to keep the token of the secrets store, retry in %s", retryInterval)
+			select {
+			case <-c.done:
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
+
+// Close stops renewing the token and revokes it.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.revokeToken(c.currentToken())
+	})
+	return nil
+}
+
+func (c *Client) currentToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// ReadCredential reads the credential of a provider in a credential profile.
+func (c *Client) ReadCredential(ctx context.Context, profile, provider string) (map[string]string, error) {
+	path := CredentialPath(profile, provider)
+	res := struct {
+		Data *struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}{}
+	err := c.do(ctx, http.MethodGet, c.config.Mount+"/data/"+path, c.currentToken(), nil, &res)
+	if statusOf(err) == http.StatusNotFound {
+		return nil, fmt.Errorf("%w (profile: %s, provider: %s, path: %s/%s)", ErrCredentialNotFound, profile, provider, c.config.Mount, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the credential (profile: %s, provider: %s): %w", profile, provider, err)
+	}
+	if res.Data == nil || res.Data.Data == nil {
+		// Deleted (or destroyed) versions have no data
+		return nil, fmt.Errorf("%w (profile: %s, provider: %s, path: %s/%s)", ErrCredentialNotFound, profile, provider, c.config.Mount, path)
+	}
+
+	credential := map[string]string{}
+	for k, v := range res.Data.Data {
+		if s, ok := v.(string); ok {
+			credential[k] = s
+		} else {
+			credential[k] = fmt.Sprint(v)
+		}
+	}
+	return credential, nil
+}
+
+// WriteCredential writes the credential of a provider in a credential profile (as a new version).
+func (c *Client) WriteCredential(ctx context.Context, profile, provider string, credential map[string]string) error {
+	path := CredentialPath(profile, provider)
+	body := map[string]any{"data": credential}
+	if err := c.do(ctx, http.MethodPost, c.config.Mount+"/data/"+path, c.currentToken(), body, nil); err != nil {
+		return fmt.Errorf("failed to write the credential (profile: %s, provider: %s): %w", profile, provider, err)
+	}
+	return nil
+}
+
+// DeleteCredential deletes the credential of a provider in a credential profile with all its versions.
+func (c *Client) DeleteCredential(ctx context.Context, profile, provider string) error {
+	path := CredentialPath(profile, provider)
+	if err := c.do(ctx, http.MethodDelete, c.config.Mount+"/metadata/"+path, c.currentToken(), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete the credential (profile: %s, provider: %s): %w", profile, provider, err)
+	}
+	return nil
+}
+
+// list lists the keys under a path (relative to the mount), where the folders end with '/'.
+// It returns an empty list if nothing exists under the path.
+func (c *Client) list(ctx context.Context, path string) ([]string, error) {
+	res := struct {
+		Data struct {
+			Keys []string `json:"keys"`
+		} `json:"data"`
+	}{}
+	err := c.do(ctx, http.MethodGet, c.config.Mount+"/metadata/"+path+"?list=true", c.currentToken(), nil, &res)
+	if statusOf(err) == http.StatusNotFound {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the secrets (path: %s): %w", path, err)
+	}
+	if res.Data.Keys == nil {
+		return []string{}, nil
+	}
+	return res.Data.Keys, nil
+}
+
+// IssueRunEnv issues the environment of a run of tofu with a child token, which expires after the ttl.
+// The child token has the policies of the client token, and cannot be renewed.
+func (c *Client) IssueRunEnv(ctx context.Context, ttl time.Duration, displayName string) (*RunEnv, error) {
+	seconds := int(ttl.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	body := map[string]any{
+		"ttl":              fmt.Sprintf("%ds", seconds),
+		"explicit_max_ttl": fmt.Sprintf("%ds", seconds),
+		"renewable":        false,
+		"display_name":     displayName,
+	}
+	res := authResponse{}
+	if err := c.do(ctx, http.MethodPost, "auth/token/create", c.currentToken(), body, &res); err != nil {
+		return nil, fmt.Errorf("failed to issue a token for the run: %w", err)
+	}
+	if res.Auth == nil || res.Auth.ClientToken == "" {
+		return nil, fmt.Errorf("failed to issue a token for the run: no token in the response")
+	}
+
+	return &RunEnv{
+		Env: []string{
+			"VAULT_ADDR=" + c.config.Address,
+			"VAULT_TOKEN=" + res.Auth.ClientToken,
+		},
+		client: c,
+		token:  res.Auth.ClientToken,
+	}, nil
+}
+
+// revokeToken revokes a token by itself, logging a failure (e.g., already expired).
+func (c *Client) revokeToken(token string) {
+	if token == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	if err := c.do(ctx, http.MethodPost, "auth/token/revoke-self", token, nil, nil); err != nil {
+		log.Debug().Msgf("failed to revoke a token of the secrets store: %v", err)
+	}
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	status int
+	path   string
+	errors []string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("status %d %s (%s): %s", e.status, http.StatusText(e.status), e.path, strings.Join(e.errors, "; "))
+}
+
+// Unwrap maps the status to the errors of the package (e.g., 403 to ErrPermissionDenied).
+func (e *apiError) Unwrap() error {
+	if e.status == http.StatusForbidden {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+// statusOf returns the status of an error response, or 0 if not an error response.
+func statusOf(err error) int {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr.status
+	}
+	return 0
+}
+
+// do sends a request to the API (/v1/{path}) and decodes the response into out, if given.
+func (c *Client) do(ctx context.Context, method, path, token string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.config.Address, "/")+"/v1/"+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		res := struct {
+			Errors []string `json:"errors"`
+		}{}
+		json.Unmarshal(b, &res)
+		return &apiError{status: resp.StatusCode, path: path, errors: res.Errors}
+	}
+
+	if out != nil && len(b) > 0 {
+		if err := json.Unmarshal(b, out); err != nil {
+			return fmt.Errorf("failed to unmarshal the response (%s): %w", path, err)
+		}
+	}
+	return nil
+}
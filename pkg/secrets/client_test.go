@@ -0,0 +1,326 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBao is a fake OpenBao serving the APIs used by the client (i.e., AppRole login, token renew, create,
+// revoke and KV v2), which records the requests.
+type fakeBao struct {
+	t *testing.T
+
+	mu sync.Mutex
+	// loginLease and renewLease are the lease durations (in seconds) of the login and the renewal
+	loginLease int
+	renewLease int
+	renewable  bool
+	// secrets are the KV v2 secrets by path (e.g., csp/aws), where nil means a deleted version
+	secrets map[string]map[string]any
+
+	issued  int
+	logins  []time.Time
+	renews  []time.Time
+	revoked []string
+	events  chan string
+}
+
+func newFakeBao(t *testing.T) (*fakeBao, *httptest.Server) {
+	f := &fakeBao{
+		t:          t,
+		loginLease: 3,
+		renewLease: 3,
+		renewable:  true,
+		secrets:    map[string]map[string]any{},
+		events:     make(chan string, 100),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeBao) auth(w http.ResponseWriter, token string, lease int, renewable bool) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"auth": map[string]any{"client_token": token, "lease_duration": lease, "renewable": renewable},
+	})
+}
+
+func (f *fakeBao) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	token := r.Header.Get("X-Vault-Token")
+	switch {
+	case r.Method == http.MethodPost && path == "auth/approle/login":
+		body := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"errors":["invalid role or secret ID"]}`)
+			return
+		}
+		f.issued++
+		f.logins = append(f.logins, time.Now())
+		f.auth(w, fmt.Sprintf("token-%d", f.issued), f.loginLease, f.renewable)
+		f.events <- "login"
+
+	case r.Method == http.MethodPost && path == "auth/token/renew-self":
+		f.renews = append(f.renews, time.Now())
+		f.auth(w, token, f.renewLease, f.renewable)
+		f.events <- "renew"
+
+	case r.Method == http.MethodPost && path == "auth/token/create":
+		body := map[string]any{}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["renewable"] != false || body["ttl"] != body["explicit_max_ttl"] {
+			f.t.Errorf("unexpected request to create a token: %v", body)
+		}
+		f.issued++
+		f.auth(w, fmt.Sprintf("child-%d", f.issued), 60, false)
+
+	case r.Method == http.MethodPost && path == "auth/token/revoke-self":
+		f.revoked = append(f.revoked, token)
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == http.MethodGet && strings.HasPrefix(path, "secret/data/"):
+		data, exists := f.secrets[strings.TrimPrefix(path, "secret/data/")]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[]}`)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+
+	default:
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+	}
+}
+
+// wait waits for an event (e.g., login, renew) of the fake.
+func (f *fakeBao) wait(t *testing.T, event string, timeout time.Duration) {
+	t.Helper()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case e := <-f.events:
+			if e == event {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("no %s within %s", event, timeout)
+		}
+	}
+}
+
+// eventually waits until a condition holds (e.g., the client has handled a response).
+func eventually(t *testing.T, timeout time.Duration, condition func() bool) bool {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+func newTestClient(t *testing.T, address string) *Client {
+	t.Helper()
+	c := &Client{
+		config: Config{Address: address, Mount: DefaultMount, RoleId: "role", SecretId: "secret", Timeout: 5 * time.Second},
+		http:   &http.Client{Timeout: 5 * time.Second},
+		done:   make(chan struct{}),
+	}
+	if err := c.login(context.Background()); err != nil {
+		t.Fatalf("failed to log in: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestLogin(t *testing.T) {
+	f, srv := newFakeBao(t)
+
+	c := newTestClient(t, srv.URL)
+	if got := c.currentToken(); got != "token-1" {
+		t.Errorf("token = %q, want token-1", got)
+	}
+	if c.leaseDuration != 3*time.Second || c.loginLease != 3*time.Second || !c.renewable {
+		t.Errorf("lease = %s (login: %s, renewable: %v), want 3s renewable", c.leaseDuration, c.loginLease, c.renewable)
+	}
+
+	bad := &Client{
+		config: Config{Address: srv.URL, RoleId: "role", SecretId: "wrong", Timeout: 5 * time.Second},
+		http:   &http.Client{},
+		done:   make(chan struct{}),
+	}
+	err := bad.login(context.Background())
+	if statusOf(err) != http.StatusBadRequest {
+		t.Errorf("login with a wrong secret ID: err = %v, want status 400", err)
+	}
+	if len(f.logins) != 1 {
+		t.Errorf("logins = %d, want 1", len(f.logins))
+	}
+}
+
+func TestRenewAtTwoThirdsOfLease(t *testing.T) {
+	t.Parallel()
+	f, srv := newFakeBao(t)
+
+	c := newTestClient(t, srv.URL)
+	f.wait(t, "login", time.Second)
+	go c.renewLoop()
+	f.wait(t, "renew", 5*time.Second)
+
+	f.mu.Lock()
+	elapsed := f.renews[0].Sub(f.logins[0])
+	logins := len(f.logins)
+	f.mu.Unlock()
+	// 2/3 of the lease (3s)
+	if elapsed < 1900*time.Millisecond || elapsed > 2500*time.Millisecond {
+		t.Errorf("renewed after %s, want about 2s", elapsed)
+	}
+	if logins != 1 {
+		t.Errorf("logins = %d, want 1 (renewed without logging in again)", logins)
+	}
+	if got := c.currentToken(); got != "token-1" {
+		t.Errorf("token = %q, want token-1 (the same token renewed)", got)
+	}
+}
+
+func TestLoginAgainAtMaxTTL(t *testing.T) {
+	t.Parallel()
+	f, srv := newFakeBao(t)
+	// The renewed lease is capped by the max TTL, i.e., shorter than a third of the login lease
+	f.renewLease = 0
+
+	c := newTestClient(t, srv.URL)
+	f.wait(t, "login", time.Second)
+	go c.renewLoop()
+	f.wait(t, "renew", 5*time.Second)
+	f.wait(t, "login", time.Second)
+
+	if !eventually(t, time.Second, func() bool { return c.currentToken() == "token-2" }) {
+		t.Errorf("token = %q, want token-2 (logged in again)", c.currentToken())
+	}
+	c.mu.RLock()
+	lease := c.leaseDuration
+	c.mu.RUnlock()
+	if lease != 3*time.Second {
+		t.Errorf("lease = %s, want 3s (of the new login)", lease)
+	}
+}
+
+func TestLoginAgainIfNotRenewable(t *testing.T) {
+	t.Parallel()
+	f, srv := newFakeBao(t)
+	f.renewable = false
+
+	c := newTestClient(t, srv.URL)
+	f.wait(t, "login", time.Second)
+	go c.renewLoop()
+	f.wait(t, "login", 5*time.Second)
+
+	f.mu.Lock()
+	renews := len(f.renews)
+	f.mu.Unlock()
+	if renews != 0 {
+		t.Errorf("renews = %d, want 0 (not renewable)", renews)
+	}
+	if !eventually(t, time.Second, func() bool { return c.currentToken() == "token-2" }) {
+		t.Errorf("token = %q, want token-2", c.currentToken())
+	}
+}
+
+func TestReadCredential(t *testing.T) {
+	f, srv := newFakeBao(t)
+	f.secrets["csp/aws"] = map[string]any{"AWS_ACCESS_KEY_ID": "id", "AWS_SECRET_ACCESS_KEY": "key"}
+	f.secrets["users/alice/csp/openstack"] = map[string]any{"OS_AUTH_URL": "https://dcs", "OS_PORT": 5000}
+	f.secrets["csp/gcp"] = nil
+
+	c := newTestClient(t, srv.URL)
+
+	tests := []struct {
+		name     string
+		profile  string
+		provider string
+		want     map[string]string
+		wantErr  error
+	}{
+		{name: "admin", profile: AdminProfile, provider: "aws", want: map[string]string{"AWS_ACCESS_KEY_ID": "id", "AWS_SECRET_ACCESS_KEY": "key"}},
+		{name: "user with a secret name", profile: "alice", provider: "dcs", want: map[string]string{"OS_AUTH_URL": "https://dcs", "OS_PORT": "5000"}},
+		{name: "not found", profile: AdminProfile, provider: "azure", wantErr: ErrCredentialNotFound},
+		{name: "not found in a profile", profile: "bob", provider: "aws", wantErr: ErrCredentialNotFound},
+		{name: "deleted version", profile: AdminProfile, provider: "gcp", wantErr: ErrCredentialNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ReadCredential(context.Background(), tt.profile, tt.provider)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("credential = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionDenied(t *testing.T) {
+	_, srv := newFakeBao(t)
+	c := newTestClient(t, srv.URL)
+
+	err := c.DeleteCredential(context.Background(), AdminProfile, "aws")
+	if !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("err = %v, want %v", err, ErrPermissionDenied)
+	}
+}
+
+func TestRunTokenRevocation(t *testing.T) {
+	f, srv := newFakeBao(t)
+	c := newTestClient(t, srv.URL)
+
+	run, err := c.IssueRunEnv(context.Background(), 90*time.Second, "tr01-testbed")
+	if err != nil {
+		t.Fatalf("failed to issue the run environment: %v", err)
+	}
+	want := []string{"VAULT_ADDR=" + srv.URL, "VAULT_TOKEN=child-2"}
+	if fmt.Sprint(run.Env) != fmt.Sprint(want) {
+		t.Errorf("env = %v, want %v", run.Env, want)
+	}
+
+	run.Revoke()
+	run.Revoke()
+	f.mu.Lock()
+	revoked := append([]string{}, f.revoked...)
+	f.mu.Unlock()
+	if fmt.Sprint(revoked) != fmt.Sprint([]string{"child-2"}) {
+		t.Errorf("revoked = %v, want [child-2] (once, by the run token itself)", revoked)
+	}
+
+	// The client token is revoked on close
+	c.Close()
+	f.mu.Lock()
+	revoked = append([]string{}, f.revoked...)
+	f.mu.Unlock()
+	if fmt.Sprint(revoked) != fmt.Sprint([]string{"child-2", "token-1"}) {
+		t.Errorf("revoked = %v, want [child-2 token-1]", revoked)
+	}
+}
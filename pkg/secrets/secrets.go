@@ -0,0 +1,276 @@
+// Secrets
+//
+// The secrets package reads the CSP credentials from OpenBao (Vault-compatible) for mc-terrarium itself.
+// The client logs in by AppRole (i.e., role_id and secret_id), and renews its token before it expires
+// (or logs in again if the token cannot be renewed any longer), so that no long-lived token (e.g., the root token)
+// is in the server environment.
+//
+// The credentials are stored in the KV v2 secrets engine (default mount: secret) by credential profile:
+//   - "admin": csp/{provider} (e.g., secret/csp/aws)
+//   - others: users/{profile}/csp/{provider} (e.g., secret/users/alice/csp/aws)
+//
+// The templates read the credentials through the vault provider with VAULT_ADDR and VAULT_TOKEN.
+// For each run of tofu, a short-lived child token of the client token is issued (see IssueRunEnv)
+// and injected into the environment of the tofu process only, then it is revoked when the run ends.
+//
+// The package-level functions operate on the client initialized by Init.
+// They return ErrNotConfigured if no client is initialized (i.e., no address is configured).
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMount is the mount of the KV v2 secrets engine storing the credentials.
+const DefaultMount = "secret"
+
+// AdminProfile is the credential profile whose credentials are stored at the top level (i.e., csp/{provider}).
+const AdminProfile = "admin"
+
+var (
+	// ErrNotConfigured is returned if the secrets client is not initialized.
+	ErrNotConfigured = errors.New("secrets store is not configured")
+	// ErrCredentialNotFound is returned if no credential exists at the path.
+	ErrCredentialNotFound = errors.New("credential not found")
+	// ErrPermissionDenied is returned if the token is not permitted to access the path.
+	ErrPermissionDenied = errors.New("permission denied by the secrets store")
+)
+
+// Config is the configuration of the secrets client.
+type Config struct {
+	// Address is the address of OpenBao (e.g., http://openbao:8200)
+	Address string
+	// Mount is the mount of the KV v2 secrets engine (default: secret)
+	Mount string
+	// RoleId and SecretId are the credentials of the AppRole to log in by
+	RoleId   string
+	SecretId string
+	// SecretIdFile is the file containing the secret_id, if SecretId is not given (e.g., a mounted secret)
+	SecretIdFile string
+	// Timeout is the timeout of a request to OpenBao (default: 10s)
+	Timeout time.Duration
+}
+
+var (
+	mu     sync.RWMutex
+	client *Client
+)
+
+// Init logs in to OpenBao by AppRole and starts renewing the token.
+// It does nothing if no address is configured, so that the secrets store is optional.
+func Init(config Config) error {
+	if config.Address == "" {
+		return nil
+	}
+	if config.Mount == "" {
+		config.Mount = DefaultMount
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.SecretId == "" && config.SecretIdFile != "" {
+		b, err := os.ReadFile(config.SecretIdFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the secret_id file: %w", err)
+		}
+		config.SecretId = strings.TrimSpace(string(b))
+	}
+	if config.RoleId == "" || config.SecretId == "" {
+		return fmt.Errorf("role_id and secret_id are required to log in to %s by AppRole", config.Address)
+	}
+
+	c := &Client{
+		config: config,
+		http:   &http.Client{Timeout: config.Timeout},
+		done:   make(chan struct{}),
+	}
+	if err := c.login(context.Background()); err != nil {
+		return err
+	}
+	go c.renewLoop()
+
+	mu.Lock()
+	previous := client
+	client = c
+	mu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// Close stops renewing the token and revokes it.
+func Close() error {
+	mu.Lock()
+	c := client
+	client = nil
+	mu.Unlock()
+	if c == nil {
+		return nil
+	}
+	return c.Close()
+}
+
+// Enabled reports whether the secrets client is initialized.
+func Enabled() bool {
+	return Default() != nil
+}
+
+// Default returns the client initialized by Init, or nil if not initialized.
+func Default() *Client {
+	mu.RLock()
+	defer mu.RUnlock()
+	return client
+}
+
+// secretNames are the names of the credentials of the providers, if different from the providers.
+var secretNames = map[string]string{
+	"dcs": "openstack",
+}
+
+// SecretName returns the name of the credential of a provider (e.g., openstack for dcs).
+func SecretName(provider string) string {
+	if name, ok := secretNames[provider]; ok {
+		return name
+	}
+	return provider
+}
+
+// ProfilePath returns the path of the credentials of a credential profile,
+// relative to the mount (e.g., csp/, users/alice/csp/).
+func ProfilePath(profile string) string {
+	if profile == "" || profile == AdminProfile {
+		return "csp/"
+	}
+	return "users/" + profile + "/csp/"
+}
+
+// CredentialPath returns the path of the credential of a provider in a credential profile,
+// relative to the mount (e.g., csp/aws, users/alice/csp/aws).
+func CredentialPath(profile, provider string) string {
+	return ProfilePath(profile) + SecretName(provider)
+}
+
+// Mount returns the mount of the KV v2 secrets engine storing the credentials.
+func Mount() string {
+	if c := Default(); c != nil {
+		return c.config.Mount
+	}
+	return DefaultMount
+}
+
+// ReadCredential reads the credential of a provider in a credential profile.
+func ReadCredential(ctx context.Context, profile, provider string) (map[string]string, error) {
+	c := Default()
+	if c == nil {
+		return nil, ErrNotConfigured
+	}
+	return c.ReadCredential(ctx, profile, provider)
+}
+
+// HasCredential checks if a credential profile has a credential of a provider,
+// which has any value (i.e., not a placeholder).
+func HasCredential(ctx context.Context, profile, provider string) (bool, error) {
+	credential, err := ReadCredential(ctx, profile, provider)
+	if errors.Is(err, ErrCredentialNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return hasValue(credential), nil
+}
+
+// WriteCredential writes the credential of a provider in a credential profile (as a new version).
+func WriteCredential(ctx context.Context, profile, provider string, credential map[string]string) error {
+	c := Default()
+	if c == nil {
+		return ErrNotConfigured
+	}
+	return c.WriteCredential(ctx, profile, provider, credential)
+}
+
+// DeleteCredential deletes the credential of a provider in a credential profile with all its versions.
+func DeleteCredential(ctx context.Context, profile, provider string) error {
+	c := Default()
+	if c == nil {
+		return ErrNotConfigured
+	}
+	return c.DeleteCredential(ctx, profile, provider)
+}
+
+// ListCredentials lists the credentials (i.e., the names, e.g., aws, openstack) stored in a credential profile,
+// including the placeholders.
+func ListCredentials(ctx context.Context, profile string) ([]string, error) {
+	c := Default()
+	if c == nil {
+		return nil, ErrNotConfigured
+	}
+	return c.list(ctx, ProfilePath(profile))
+}
+
+// ListProfiles lists the credential profiles except admin (i.e., users/{profile}).
+func ListProfiles(ctx context.Context) ([]string, error) {
+	c := Default()
+	if c == nil {
+		return nil, ErrNotConfigured
+	}
+	keys, err := c.list(ctx, "users/")
+	if err != nil {
+		return nil, err
+	}
+	profiles := []string{}
+	for _, key := range keys {
+		// Folders end with '/'
+		if profile, ok := strings.CutSuffix(key, "/"); ok {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func hasValue(credential map[string]string) bool {
+	for _, v := range credential {
+		if v != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// RunEnv is the environment of a run of tofu, which carries a short-lived token.
+type RunEnv struct {
+	// Env is the environment variables (i.e., VAULT_ADDR and VAULT_TOKEN)
+	Env []string
+
+	client   *Client
+	token    string
+	revokeMu sync.Once
+}
+
+// IssueRunEnv issues the environment of a run of tofu with a child token, which expires after the ttl.
+// The token must be revoked by Revoke when the run ends.
+func IssueRunEnv(ctx context.Context, ttl time.Duration, displayName string) (*RunEnv, error) {
+	c := Default()
+	if c == nil {
+		return nil, ErrNotConfigured
+	}
+	return c.IssueRunEnv(ctx, ttl, displayName)
+}
+
+// Revoke revokes the token of the run. It is safe to call more than once.
+func (r *RunEnv) Revoke() {
+	if r == nil {
+		return
+	}
+	r.revokeMu.Do(func() {
+		r.client.revokeToken(r.token)
+	})
+}
@@ -0,0 +1,664 @@
+package terrarium
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+)
+
+/*
+ * [Note] Archives of terrariums
+ *
+ * A terrarium is exported as an archive (tar.gz) to be imported into another server (e.g., from staging to production).
+ *   manifest.json                        the version of the layout and how the sensitive values are protected
+ *   terrarium.json                       the terrarium info
+ *   enrichments/{name}/outputs.json      the stored outputs and references of each enrichment
+ *   enrichments/{name}/references.json
+ *   env/{name}/...                       the working directory of each enrichment
+ *                                        (i.e., rendered templates, tfvars, state and running logs)
+ *   jobs/{reqId}.json                    the requests (job history)
+ * The providers (.terraform), the saved plans and the credential files are not exported.
+ * The providers are installed again (i.e., init) on import, and the plans must be created again.
+ * On import, the terrarium ID, the names of the enrichments and the requests are validated,
+ * and every entry must be written within the working directory of an enrichment of the terrarium.
+ *
+ * The sensitive values in the state and tfvars are redacted (i.e., REDACTED) unless a passphrase is given,
+ * and an archive with the redacted values is refused on import (i.e., it is only to be inspected).
+ * With a passphrase, the state and tfvars are encrypted as they are (AES-256-GCM with a key derived by PBKDF2)
+ * and the same passphrase is required to import the archive.
+ */
+
+const (
+	// ArchiveVersion is the version of the layout of an archive
+	ArchiveVersion = "1"
+
+	// SensitiveRedacted means the sensitive values in an archive are redacted
+	SensitiveRedacted = "Redacted"
+	// SensitiveEncrypted means the state and tfvars in an archive are encrypted
+	SensitiveEncrypted = "Encrypted"
+)
+
+const (
+	redactedValue    = "REDACTED"
+	encryptedSuffix  = ".enc"
+	maxArchiveSize   = 512 << 20 // decompressed
+	pbkdf2Iterations = 600000
+)
+
+// ErrInvalidArchive is returned if an archive cannot be imported (e.g., malformed, unsupported version, wrong passphrase).
+var ErrInvalidArchive = errors.New("invalid archive")
+
+var (
+	// archiveIdPattern matches the IDs in an imported archive (i.e., terrarium ID, request ID), which name the files
+	archiveIdPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+	// archiveEnrichmentPattern matches the name of an enrichment in an imported archive (e.g., testbed, vpn/site-to-site)
+	archiveEnrichmentPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(/[a-z0-9]([a-z0-9-]*[a-z0-9])?)?$`)
+)
+
+// sensitiveKeyPattern matches the names of the tfvars which are redacted (e.g., db_admin_password).
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)password|secret|token|private_key|access_key|credential`)
+
+// Export exports a terrarium as an archive (tar.gz).
+// The sensitive values are encrypted with the passphrase if given, or redacted otherwise.
+func Export(ctx context.Context, trId, passphrase string) ([]byte, error) {
+
+	trInfo, _, err := GetInfo(trId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The state may be changing while a command is running
+	executing, err := tofu.IsExecuting(trId)
+	if err != nil {
+		return nil, err
+	}
+	if executing {
+		return nil, fmt.Errorf("%w (trId: %s)", tofu.ErrInProgress, trId)
+	}
+
+	manifest := model.ArchiveManifest{
+		Version:     ArchiveVersion,
+		TrId:        trId,
+		Enrichments: trInfo.Enrichments.Names(),
+		ExportedAt:  time.Now(),
+		ExportedBy:  job.CredentialHolderFrom(ctx),
+		Sensitive:   SensitiveRedacted,
+	}
+
+	var key []byte
+	if passphrase != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("failed to generate a salt: %w", err)
+		}
+		key, err = archiveKey(passphrase, salt)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Sensitive = SensitiveEncrypted
+		manifest.Salt = base64.StdEncoding.EncodeToString(salt)
+	}
+
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	addFile := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: manifest.ExportedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write %s to the archive: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s to the archive: %w", name, err)
+		}
+		return nil
+	}
+	addJson := func(name string, v any) error {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		return addFile(name, b)
+	}
+
+	if err := addJson("manifest.json", manifest); err != nil {
+		return nil, err
+	}
+	if err := addJson("terrarium.json", withoutStatus(trInfo)); err != nil {
+		return nil, err
+	}
+
+	for _, name := range manifest.Enrichments {
+		if value, exists := lkvstore.Get(outputsKey(trId, name)); exists {
+			if err := addFile("enrichments/"+name+"/outputs.json", []byte(value)); err != nil {
+				return nil, err
+			}
+		}
+		if value, exists := lkvstore.Get(referencesKey(trId, name)); exists {
+			if err := addFile("enrichments/"+name+"/references.json", []byte(value)); err != nil {
+				return nil, err
+			}
+		}
+
+		// Working directory of the enrichment
+		workingDir := config.Terrarium.Root + "/.terrarium/" + trId + "/" + name
+		err := filepath.WalkDir(workingDir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				if p != workingDir && (d.Name() == ".terraform" || d.Name() == "plans") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !d.Type().IsRegular() || d.Name() == stateLockFile || strings.HasPrefix(d.Name(), "credential-") {
+				return nil
+			}
+
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(workingDir, p)
+			if err != nil {
+				return err
+			}
+			entry := "env/" + name + "/" + filepath.ToSlash(rel)
+
+			if isSensitiveFile(d.Name()) {
+				if key != nil {
+					data, err = encrypt(key, data)
+					entry += encryptedSuffix
+				} else {
+					data, err = redactFile(d.Name(), data)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to protect the sensitive values in %s: %w", entry, err)
+				}
+			}
+			return addFile(entry, data)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to export the working directory (trId: %s, enrichment: %s): %w", trId, name, err)
+		}
+	}
+
+	jobs, err := job.List(trId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the requests (trId: %s): %w", trId, err)
+	}
+	for _, j := range jobs {
+		if err := addJson("jobs/"+j.ReqId+".json", j); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close the archive: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close the archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Import imports a terrarium from an archive (tar.gz) exported by Export.
+// The terrarium is imported as trId if given (i.e., renamed), and held by the holder of the request.
+// The providers of the enrichments are installed again (i.e., init),
+// and the terrarium is removed if the import fails.
+func Import(ctx context.Context, archive []byte, trId, passphrase, holder, reqId string) (model.TerrariumInfo, error) {
+
+	emptyInfo := model.TerrariumInfo{}
+
+	entries, err := readArchive(archive)
+	if err != nil {
+		return emptyInfo, err
+	}
+
+	manifest := model.ArchiveManifest{}
+	if err := json.Unmarshal(entries["manifest.json"], &manifest); err != nil {
+		return emptyInfo, fmt.Errorf("%w, failed to read the manifest: %w", ErrInvalidArchive, err)
+	}
+	if err := migrateArchive(manifest, entries); err != nil {
+		return emptyInfo, err
+	}
+
+	var key []byte
+	if manifest.Sensitive == SensitiveEncrypted {
+		if passphrase == "" {
+			return emptyInfo, fmt.Errorf("%w, the archive is encrypted, a passphrase is required", ErrInvalidArchive)
+		}
+		salt, err := base64.StdEncoding.DecodeString(manifest.Salt)
+		if err != nil {
+			return emptyInfo, fmt.Errorf("%w, invalid salt: %w", ErrInvalidArchive, err)
+		}
+		key, err = archiveKey(passphrase, salt)
+		if err != nil {
+			return emptyInfo, err
+		}
+	}
+
+	trInfo := model.TerrariumInfo{}
+	if err := json.Unmarshal(entries["terrarium.json"], &trInfo); err != nil {
+		return emptyInfo, fmt.Errorf("%w, failed to read the terrarium info: %w", ErrInvalidArchive, err)
+	}
+	if trId != "" {
+		trInfo.Id = trId
+		trInfo.Name = trId
+	}
+	trId = trInfo.Id
+	if trId == "" {
+		return emptyInfo, fmt.Errorf("%w, no terrarium ID", ErrInvalidArchive)
+	}
+	if !archiveIdPattern.MatchString(trId) || strings.Contains(trId, "..") {
+		return emptyInfo, fmt.Errorf("%w, invalid terrarium ID (%s), it must match %s", ErrInvalidArchive, trId, archiveIdPattern)
+	}
+	for name := range trInfo.Enrichments {
+		if !archiveEnrichmentPattern.MatchString(name) {
+			return emptyInfo, fmt.Errorf("%w, invalid enrichment name (%s), it must match %s", ErrInvalidArchive, name, archiveEnrichmentPattern)
+		}
+	}
+	trInfo.CredentialProfile = holder
+	// The lease is not imported, so that the resources are not destroyed right after the import (e.g., expired)
+	trInfo.ExpiresAt = nil
+	trInfo.ExpiryWarnedAt = nil
+	trInfo.ReapingSince = nil
+	if trInfo.Enrichments == nil {
+		trInfo.Enrichments = model.Enrichments{}
+	}
+
+	// Decrypt the sensitive files before anything is written
+	files := map[string][]byte{}
+	for name, data := range entries {
+		rel, found := strings.CutPrefix(name, "env/")
+		if !found {
+			continue
+		}
+		if enrichmentOfEntry(trInfo.Enrichments, rel) == "" {
+			return emptyInfo, fmt.Errorf("%w, %s is not of an enrichment of the terrarium", ErrInvalidArchive, name)
+		}
+		if encrypted, found := strings.CutSuffix(rel, encryptedSuffix); found && key != nil {
+			data, err = decrypt(key, data)
+			if err != nil {
+				return emptyInfo, fmt.Errorf("%w, failed to decrypt %s (wrong passphrase?)", ErrInvalidArchive, name)
+			}
+			rel = encrypted
+		}
+		// The credential profile in the tfvars follows the holder of the imported terrarium
+		if path.Base(rel) == "terraform.tfvars.json" {
+			data, err = rebindCredentialProfile(data, holder)
+			if err != nil {
+				return emptyInfo, fmt.Errorf("%w, failed to read %s: %w", ErrInvalidArchive, name, err)
+			}
+		}
+		// The redacted values cannot be applied or destroyed, so an archive with them is not imported
+		if manifest.Sensitive == SensitiveRedacted && isSensitiveFile(path.Base(rel)) && bytes.Contains(data, []byte(`"`+redactedValue+`"`)) {
+			return emptyInfo, fmt.Errorf("%w, the sensitive values in %s are redacted, export the terrarium with a passphrase (x-archive-passphrase) to import it", ErrInvalidArchive, name)
+		}
+		files[rel] = data
+	}
+
+	// Issue the terrarium ID
+	trDir := config.Terrarium.Root + "/.terrarium/" + trId
+	if !withinDir(config.Terrarium.Root+"/.terrarium", trDir) {
+		return emptyInfo, fmt.Errorf("%w, invalid terrarium ID (%s)", ErrInvalidArchive, trId)
+	}
+	for name := range trInfo.Enrichments {
+		if !withinDir(trDir, trDir+"/"+name) {
+			return emptyInfo, fmt.Errorf("%w, invalid enrichment name (%s)", ErrInvalidArchive, name)
+		}
+	}
+	for name := range entries {
+		if rel, found := strings.CutPrefix(name, "enrichments/"); found {
+			if _, exists := trInfo.Enrichments[path.Dir(rel)]; !exists {
+				return emptyInfo, fmt.Errorf("%w, %s is not of an enrichment of the terrarium", ErrInvalidArchive, name)
+			}
+		}
+	}
+	if _, err := os.Stat(trDir); err == nil {
+		return emptyInfo, fmt.Errorf("%w (trId: %s), the working directory exists", ErrTerrariumExists, trId)
+	}
+	if err := IssueID(withoutStatus(trInfo)); err != nil {
+		return emptyInfo, err
+	}
+
+	if err := restoreArchive(trId, trDir, trInfo.Enrichments, files, entries); err != nil {
+		discard(trId, trDir)
+		return emptyInfo, err
+	}
+
+	// Install the providers again
+	for _, name := range trInfo.Enrichments.Names() {
+		if _, err := os.Stat(trDir + "/" + name); os.IsNotExist(err) {
+			continue
+		}
+		if _, err := Init(ctx, trId, name, reqId); err != nil {
+			discard(trId, trDir)
+			return emptyInfo, fmt.Errorf("failed to init the imported enrichment (trId: %s, enrichment: %s): %w", trId, name, err)
+		}
+	}
+
+	ret, _, err := GetInfo(trId)
+	if err != nil {
+		return emptyInfo, err
+	}
+	return ret, nil
+}
+
+// migrateArchive migrates an archive of an older version to the current layout (i.e., rewrites the entries).
+func migrateArchive(manifest model.ArchiveManifest, entries map[string][]byte) error {
+	switch manifest.Version {
+	case ArchiveVersion:
+		return nil
+	}
+	return fmt.Errorf("%w, unsupported version (%s), supported versions: %s", ErrInvalidArchive, manifest.Version, ArchiveVersion)
+}
+
+// restoreArchive writes the working directories, the outputs, references and requests of an imported terrarium.
+func restoreArchive(trId, trDir string, enrichments model.Enrichments, files map[string][]byte, entries map[string][]byte) error {
+
+	for rel, data := range files {
+		p := filepath.Join(trDir, filepath.FromSlash(rel))
+		if !withinDir(trDir, p) {
+			return fmt.Errorf("%w, invalid entry (env/%s)", ErrInvalidArchive, rel)
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return fmt.Errorf("failed to create the working directory (trId: %s): %w", trId, err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s (trId: %s): %w", rel, trId, err)
+		}
+	}
+
+	for name, data := range entries {
+		switch {
+		case strings.HasPrefix(name, "enrichments/") && strings.HasSuffix(name, "/outputs.json"):
+			enrichment := strings.TrimSuffix(strings.TrimPrefix(name, "enrichments/"), "/outputs.json")
+			if err := lkvstore.Put(outputsKey(trId, enrichment), json.RawMessage(data)); err != nil {
+				return fmt.Errorf("failed to restore the outputs (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+			}
+		case strings.HasPrefix(name, "enrichments/") && strings.HasSuffix(name, "/references.json"):
+			enrichment := strings.TrimSuffix(strings.TrimPrefix(name, "enrichments/"), "/references.json")
+			if err := lkvstore.Put(referencesKey(trId, enrichment), json.RawMessage(data)); err != nil {
+				return fmt.Errorf("failed to restore the references (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+			}
+		case strings.HasPrefix(name, "jobs/"):
+			j := model.Job{}
+			if err := json.Unmarshal(data, &j); err != nil {
+				return fmt.Errorf("%w, failed to read %s: %w", ErrInvalidArchive, name, err)
+			}
+			// The log of a request is read from its working directory (see job.LogFile)
+			if _, exists := enrichments[j.Enrichment]; (j.Enrichment != "" && !exists) || !archiveIdPattern.MatchString(j.ReqId) {
+				return fmt.Errorf("%w, invalid request in %s (enrichment: %s, reqId: %s)", ErrInvalidArchive, name, j.Enrichment, j.ReqId)
+			}
+			j.TrId = trId
+			if err := job.Restore(j); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readArchive reads the entries (key: name) of an archive (tar.gz).
+func readArchive(archive []byte) (map[string][]byte, error) {
+
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("%w, not a gzip: %w", ErrInvalidArchive, err)
+	}
+	defer gr.Close()
+
+	entries := map[string][]byte{}
+	tr := tar.NewReader(io.LimitReader(gr, maxArchiveSize))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w, not a tar: %w", ErrInvalidArchive, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// An entry must not be written out of the working directory
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("%w, invalid entry (%s)", ErrInvalidArchive, hdr.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("%w, failed to read %s: %w", ErrInvalidArchive, name, err)
+		}
+		entries[name] = data
+	}
+
+	if _, exists := entries["manifest.json"]; !exists {
+		return nil, fmt.Errorf("%w, no manifest", ErrInvalidArchive)
+	}
+	return entries, nil
+}
+
+// enrichmentOfEntry returns the enrichment whose working directory has a file (e.g., vpn/site-to-site/main.tf),
+// or an empty string if none.
+func enrichmentOfEntry(enrichments model.Enrichments, rel string) string {
+	for name := range enrichments {
+		if strings.HasPrefix(rel, name+"/") {
+			return name
+		}
+	}
+	return ""
+}
+
+// withinDir checks if a path is in a directory (i.e., not the directory itself, nor out of it).
+func withinDir(dir, p string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(p))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// isSensitiveFile checks if a file in a working directory may have sensitive values (i.e., state and tfvars).
+func isSensitiveFile(name string) bool {
+	return name == "terraform.tfstate" || name == "terraform.tfstate.backup" || strings.HasSuffix(name, ".tfvars.json")
+}
+
+// redactFile redacts the sensitive values in the state or tfvars.
+func redactFile(name string, data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return data, nil
+	}
+	if strings.HasSuffix(name, ".tfvars.json") {
+		return redactTfVars(data)
+	}
+	return redactState(data)
+}
+
+// rebindCredentialProfile sets the credential profile (i.e., credential_profile) in the tfvars to a holder, if it is set.
+func rebindCredentialProfile(data []byte, holder string) ([]byte, error) {
+	tfVars := map[string]any{}
+	if err := json.Unmarshal(data, &tfVars); err != nil {
+		return nil, err
+	}
+	if _, exists := tfVars["credential_profile"]; !exists || holder == "" {
+		return data, nil
+	}
+	tfVars["credential_profile"] = holder
+	return json.MarshalIndent(tfVars, "", "  ")
+}
+
+// redactTfVars redacts the tfvars whose names look sensitive (e.g., password, secret).
+func redactTfVars(data []byte) ([]byte, error) {
+	var tfVars any
+	if err := json.Unmarshal(data, &tfVars); err != nil {
+		return nil, err
+	}
+
+	var redact func(v any)
+	redact = func(v any) {
+		switch t := v.(type) {
+		case map[string]any:
+			for k, value := range t {
+				if sensitiveKeyPattern.MatchString(k) {
+					t[k] = redactedValue
+					continue
+				}
+				redact(value)
+			}
+		case []any:
+			for _, value := range t {
+				redact(value)
+			}
+		}
+	}
+	redact(tfVars)
+
+	return json.MarshalIndent(tfVars, "", "  ")
+}
+
+// redactState redacts the sensitive outputs and attributes (i.e., sensitive_attributes) in the state.
+func redactState(data []byte) ([]byte, error) {
+	state := map[string]any{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	outputs, _ := state["outputs"].(map[string]any)
+	for _, o := range outputs {
+		output, _ := o.(map[string]any)
+		if sensitive, _ := output["sensitive"].(bool); sensitive {
+			output["value"] = redactedValue
+		}
+	}
+
+	resources, _ := state["resources"].([]any)
+	for _, r := range resources {
+		resource, _ := r.(map[string]any)
+		instances, _ := resource["instances"].([]any)
+		for _, i := range instances {
+			instance, _ := i.(map[string]any)
+			paths, _ := instance["sensitive_attributes"].([]any)
+			for _, p := range paths {
+				steps, _ := p.([]any)
+				redactAttribute(instance["attributes"], steps)
+			}
+		}
+	}
+
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// redactAttribute redacts an attribute at a path of the state (e.g., [{"type": "get_attr", "value": "password"}]).
+func redactAttribute(attributes any, steps []any) {
+	if len(steps) == 0 {
+		return
+	}
+
+	current := attributes
+	for i, s := range steps {
+		step, _ := s.(map[string]any)
+		last := i == len(steps)-1
+
+		switch t := current.(type) {
+		case map[string]any:
+			key, ok := step["value"].(string)
+			if !ok {
+				return
+			}
+			if _, exists := t[key]; !exists {
+				return
+			}
+			if last {
+				t[key] = redactedValue
+				return
+			}
+			current = t[key]
+		case []any:
+			idx, ok := step["value"].(float64)
+			if !ok || int(idx) < 0 || int(idx) >= len(t) {
+				return
+			}
+			if last {
+				t[int(idx)] = redactedValue
+				return
+			}
+			current = t[int(idx)]
+		default:
+			return
+		}
+	}
+}
+
+// archiveKey derives the key to encrypt the sensitive files from a passphrase.
+func archiveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the key from the passphrase: %w", err)
+	}
+	return key, nil
+}
+
+// encrypt encrypts data by AES-256-GCM (nonce || ciphertext).
+func encrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// decrypt decrypts data encrypted by encrypt.
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
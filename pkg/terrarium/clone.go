@@ -0,0 +1,349 @@
+package terrarium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/enrichment"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Cloning a terrarium
+ *
+ * A terrarium is cloned as a template for another one (e.g., the same testbed in another region).
+ * The working directories of the enrichments (i.e., rendered templates and tfvars) are copied
+ * without the state, so the new terrarium starts from a fresh, empty state.
+ * The tfvars of each enrichment are overridden by the request as a JSON merge patch (RFC 7386),
+ * e.g., {"testbed": {"aws_region": "ap-northeast-1"}}, and the terrarium_id follows the new terrarium.
+ * The references to the outputs of the other enrichments are kept unless overridden,
+ * so they are resolved from the new terrarium when it is planned or applied.
+ * Everything is validated before the new terrarium is saved.
+ */
+
+// variablePattern matches the variable blocks in the template files.
+var variablePattern = regexp.MustCompile(`(?m)^\s*variable\s+"([^"]+)"`)
+
+// Clone clones a terrarium as a new terrarium held by the holder.
+// The tfvars of each enrichment are overridden by tfVars (key: enrichment).
+// The providers of the enrichments are installed (i.e., init),
+// and the new terrarium is removed if the clone fails.
+func Clone(ctx context.Context, srcTrId string, req model.CloneTerrariumRequest, holder, reqId string) (model.TerrariumInfo, error) {
+
+	emptyInfo := model.TerrariumInfo{}
+
+	srcInfo, exists, err := GetInfo(srcTrId)
+	if err != nil {
+		return emptyInfo, err
+	}
+	if !exists {
+		return emptyInfo, fmt.Errorf("no terrarium (trId: %s)", srcTrId)
+	}
+
+	/*
+	 * [Validate] The new terrarium is validated before it is saved
+	 */
+	trId := req.Name
+	if trId == "" {
+		return emptyInfo, fmt.Errorf("not specified the name of the new terrarium")
+	}
+	trDir := config.Terrarium.Root + "/.terrarium/" + trId
+	if _, exists, _ := GetInfo(trId); exists {
+		return emptyInfo, fmt.Errorf("%w (trId: %s)", ErrTerrariumExists, trId)
+	}
+	if _, err := os.Stat(trDir); err == nil {
+		return emptyInfo, fmt.Errorf("%w (trId: %s), the working directory exists", ErrTerrariumExists, trId)
+	}
+	for name := range req.TfVars {
+		if _, exists := srcInfo.Enrichments[name]; !exists {
+			return emptyInfo, fmt.Errorf("%w, cannot override the tfvars of %s, %s (trId: %s)",
+				enrichment.ErrInvalidTfVars, name, ErrEnrichmentNotFound, srcTrId)
+		}
+	}
+
+	tfVarsOf := map[string]map[string]any{}
+	refsOf := map[string][]model.OutputReference{}
+	for _, name := range srcInfo.Enrichments.Names() {
+		srcDir := config.Terrarium.Root + "/.terrarium/" + srcTrId + "/" + name
+
+		tfVars, err := cloneTfVars(srcTrId, trId, name, srcDir, req.TfVars[name])
+		if err != nil {
+			return emptyInfo, err
+		}
+		if err := validateClonedTfVars(name, srcInfo.Enrichments[name].Providers, tfVars); err != nil {
+			return emptyInfo, err
+		}
+		tfVarsOf[name] = tfVars
+
+		refs, err := GetReferences(srcTrId, name)
+		if err != nil {
+			return emptyInfo, err
+		}
+		refsOf[name] = withoutOverridden(refs, req.TfVars[name])
+	}
+
+	/*
+	 * [Save] Issue the terrarium ID, and copy the working directories
+	 */
+	description := req.Description
+	if description == "" {
+		description = srcInfo.Description
+	}
+	trInfo := model.TerrariumInfo{
+		Name:              trId,
+		Description:       description,
+		Id:                trId,
+		Enrichments:       model.Enrichments{},
+		Providers:         srcInfo.Providers,
+		CredentialProfile: holder,
+		ApprovalRequired:  srcInfo.ApprovalRequired,
+		Labels:            srcInfo.Labels,
+	}
+	for name, e := range srcInfo.Enrichments {
+		trInfo.Enrichments[name] = model.EnrichmentInfo{
+			Name:      name,
+			Providers: e.Providers,
+			Phase:     PhaseEmpty,
+			CreatedAt: time.Now(),
+		}
+	}
+	if err := IssueID(trInfo); err != nil {
+		return emptyInfo, err
+	}
+
+	for _, name := range trInfo.Enrichments.Names() {
+		srcDir := config.Terrarium.Root + "/.terrarium/" + srcTrId + "/" + name
+		if _, err := os.Stat(srcDir); os.IsNotExist(err) {
+			continue
+		}
+		if err := copyTemplates(srcDir, trDir+"/"+name); err != nil {
+			discard(trId, trDir)
+			return emptyInfo, fmt.Errorf("failed to copy the working directory (trId: %s, enrichment: %s): %w", srcTrId, name, err)
+		}
+		if tfVars := tfVarsOf[name]; tfVars != nil {
+			if err := SaveTfVars(trId, name, tfVars); err != nil {
+				discard(trId, trDir)
+				return emptyInfo, err
+			}
+		}
+		if err := SaveReferences(trId, name, refsOf[name]); err != nil {
+			discard(trId, trDir)
+			return emptyInfo, err
+		}
+
+		// Install the providers
+		if _, err := Init(ctx, trId, name, reqId); err != nil {
+			discard(trId, trDir)
+			return emptyInfo, fmt.Errorf("failed to init the cloned enrichment (trId: %s, enrichment: %s): %w", trId, name, err)
+		}
+	}
+
+	ret, _, err := GetInfo(trId)
+	if err != nil {
+		return emptyInfo, err
+	}
+	return ret, nil
+}
+
+// cloneTfVars returns the tfvars of an enrichment for a cloned terrarium, overridden by a JSON merge patch.
+// The terrarium_id is set to the new terrarium if it was the source terrarium.
+// It returns nil if the enrichment has no tfvars (e.g., not initialized).
+func cloneTfVars(srcTrId, trId, name, srcDir string, override map[string]any) (map[string]any, error) {
+
+	b, err := os.ReadFile(srcDir + "/terraform.tfvars.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		if len(override) > 0 {
+			return nil, fmt.Errorf("%w, cannot override the tfvars of %s, no tfvars in the terrarium (trId: %s)",
+				enrichment.ErrInvalidTfVars, name, srcTrId)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tfvars (trId: %s, enrichment: %s): %w", srcTrId, name, err)
+	}
+
+	tfVars := map[string]any{}
+	if err := json.Unmarshal(b, &tfVars); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the tfvars (trId: %s, enrichment: %s): %w", srcTrId, name, err)
+	}
+	if tfVars["terrarium_id"] == srcTrId {
+		tfVars["terrarium_id"] = trId
+	}
+
+	// Only the declared variables can be overridden (e.g., to catch a typo)
+	declared, err := declaredVariables(srcDir)
+	if err != nil {
+		return nil, err
+	}
+	var undeclared []string
+	for k := range override {
+		if len(declared) > 0 && !declared[k] {
+			undeclared = append(undeclared, k)
+		}
+	}
+	if len(undeclared) > 0 {
+		sort.Strings(undeclared)
+		return nil, fmt.Errorf("%w, [%s] not declared in the templates of %s",
+			enrichment.ErrInvalidTfVars, strings.Join(undeclared, ", "), name)
+	}
+
+	for k, v := range override {
+		if v == nil {
+			delete(tfVars, k)
+			continue
+		}
+		tfVars[k] = mergePatch(tfVars[k], v)
+	}
+	return tfVars, nil
+}
+
+// validateClonedTfVars validates the tfvars of an enrichment by the enrichment registry, if registered.
+func validateClonedTfVars(name string, providers []string, tfVars map[string]any) error {
+	e, err := enrichment.Get(name)
+	if err != nil || tfVars == nil {
+		// Not served by the registry (e.g., testbed, vpn/site-to-site)
+		return nil
+	}
+
+	typed := e.NewTfVars()
+	b, err := json.Marshal(tfVars)
+	if err == nil {
+		err = json.Unmarshal(b, typed)
+	}
+	if err != nil {
+		return fmt.Errorf("%w for %s, %v", enrichment.ErrInvalidTfVars, name, err)
+	}
+	for _, provider := range providers {
+		if err := e.Validate(provider, typed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declaredVariables returns the variables declared in the template files of a working directory.
+func declaredVariables(dir string) (map[string]bool, error) {
+	files, err := filepath.Glob(dir + "/*.tf")
+	if err != nil {
+		return nil, err
+	}
+	declared := map[string]bool{}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range variablePattern.FindAllSubmatch(b, -1) {
+			declared[string(m[1])] = true
+		}
+	}
+	return declared, nil
+}
+
+// mergePatch applies a JSON merge patch (RFC 7386) to a value.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+// withoutOverridden returns the references except the ones overridden (or nested in the overridden values) by a merge patch.
+func withoutOverridden(refs []model.OutputReference, override map[string]any) []model.OutputReference {
+	var paths []string
+	var walk func(v any, path string)
+	walk = func(v any, path string) {
+		if m, ok := v.(map[string]any); ok {
+			for k, value := range m {
+				walk(value, joinPath(path, k))
+			}
+			return
+		}
+		paths = append(paths, path)
+	}
+	for k, v := range override {
+		walk(v, k)
+	}
+
+	kept := []model.OutputReference{}
+	for _, ref := range refs {
+		overridden := false
+		for _, p := range paths {
+			if ref.Path == p || strings.HasPrefix(ref.Path, p+".") || strings.HasPrefix(p, ref.Path+".") {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			kept = append(kept, ref)
+		}
+	}
+	return kept
+}
+
+// copyTemplates copies a working directory without the state, the providers (.terraform),
+// the saved plans, the running logs and the credential files.
+func copyTemplates(srcDir, dstDir string) error {
+	return filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != srcDir && (d.Name() == ".terraform" || d.Name() == "plans" || d.Name() == "runningLogs") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dstDir, rel), 0755)
+		}
+		if !d.Type().IsRegular() || d.Name() == stateLockFile || strings.HasPrefix(d.Name(), "credential-") ||
+			strings.HasPrefix(d.Name(), "terraform.tfstate") || strings.HasSuffix(d.Name(), ".tfvars.json") {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dstDir, rel), data, 0644)
+	})
+}
+
+// discard removes a terrarium which has failed to be created (e.g., imported, cloned).
+func discard(trId, trDir string) {
+	if err := os.RemoveAll(trDir); err != nil {
+		log.Warn().Err(err).Msgf("failed to remove the working directory (trId: %s)", trId)
+	}
+	if err := DeleteInfo(trId); err != nil {
+		log.Warn().Err(err).Msgf("failed to delete the terrarium info (trId: %s)", trId)
+	}
+	if err := job.DeleteAll(trId); err != nil {
+		log.Warn().Err(err).Msgf("failed to delete the requests (trId: %s)", trId)
+	}
+}
@@ -0,0 +1,300 @@
+package terrarium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Credential Profiles
+ * A credential profile (i.e., a credential holder) is a set of the CSP credentials in the secrets store,
+ * which a terrarium uses by its credentialProfile (see secrets.CredentialPath).
+ * The "admin" profile is the default one, which is stored at the top level and cannot be created or deleted here.
+ * A credential is configured if it has any value, so that a placeholder (i.e., empty values) is not counted.
+ */
+
+var (
+	// ErrInvalidCredentialProfile is returned if a credential profile or its credentials are malformed.
+	ErrInvalidCredentialProfile = errors.New("invalid credential profile")
+	// ErrCredentialProfileNotFound is returned if a credential profile does not exist.
+	ErrCredentialProfileNotFound = errors.New("credential profile not found")
+	// ErrCredentialProfileExists is returned if a credential profile of the same name already exists.
+	ErrCredentialProfileExists = errors.New("the credential profile already exists")
+	// ErrCredentialProfileInUse is returned if a credential profile is used by terrariums.
+	ErrCredentialProfileInUse = errors.New("the credential profile is used by terrariums")
+	// ErrMissingCredential is returned if a credential profile has no credential of a provider.
+	ErrMissingCredential = errors.New("missing credential")
+)
+
+var (
+	profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+	providerPattern    = regexp.MustCompile(`^[a-z0-9]+$`)
+)
+
+// ListCredentialProfiles lists the credential profiles, including admin.
+func ListCredentialProfiles(ctx context.Context) ([]model.CredentialProfile, error) {
+	names, err := secrets.ListProfiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	names = append([]string{secrets.AdminProfile}, slices.DeleteFunc(names, func(name string) bool {
+		return name == secrets.AdminProfile
+	})...)
+
+	profiles := []model.CredentialProfile{}
+	for _, name := range names {
+		profile, err := credentialProfile(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+// GetCredentialProfile gets a credential profile with its configured providers.
+func GetCredentialProfile(ctx context.Context, name string) (model.CredentialProfile, error) {
+	if err := validateProfileName(name); err != nil {
+		return model.CredentialProfile{}, err
+	}
+	profile, err := credentialProfile(ctx, name)
+	if err != nil {
+		return model.CredentialProfile{}, err
+	}
+	// The admin profile always exists, even if it has no credential yet
+	if name != secrets.AdminProfile && len(profile.Providers) == 0 {
+		return model.CredentialProfile{}, fmt.Errorf("%w (name: %s)", ErrCredentialProfileNotFound, name)
+	}
+	return profile, nil
+}
+
+// CreateCredentialProfile creates a credential profile with the credentials of the providers.
+func CreateCredentialProfile(ctx context.Context, name string, credentials map[string]map[string]string) (model.CredentialProfile, error) {
+	if err := validateProfileName(name); err != nil {
+		return model.CredentialProfile{}, err
+	}
+	if name == secrets.AdminProfile {
+		return model.CredentialProfile{}, fmt.Errorf("%w (name: %s)", ErrCredentialProfileExists, name)
+	}
+	if len(credentials) == 0 {
+		return model.CredentialProfile{}, fmt.Errorf("%w, credentials of at least one provider are required", ErrInvalidCredentialProfile)
+	}
+	if err := validateCredentials(credentials, false); err != nil {
+		return model.CredentialProfile{}, err
+	}
+
+	existing, err := secrets.ListCredentials(ctx, name)
+	if err != nil {
+		return model.CredentialProfile{}, err
+	}
+	if len(existing) > 0 {
+		return model.CredentialProfile{}, fmt.Errorf("%w (name: %s)", ErrCredentialProfileExists, name)
+	}
+
+	if err := writeCredentials(ctx, name, credentials); err != nil {
+		return model.CredentialProfile{}, err
+	}
+	log.Info().Msgf("created the credential profile (name: %s)", name)
+	return credentialProfile(ctx, name)
+}
+
+// UpdateCredentialProfile updates the credentials of the providers in a credential profile.
+// The credential of a provider is replaced, or deleted if empty. The other providers are kept.
+func UpdateCredentialProfile(ctx context.Context, name string, credentials map[string]map[string]string) (model.CredentialProfile, error) {
+	if err := validateProfileName(name); err != nil {
+		return model.CredentialProfile{}, err
+	}
+	if len(credentials) == 0 {
+		return model.CredentialProfile{}, fmt.Errorf("%w, credentials of at least one provider are required", ErrInvalidCredentialProfile)
+	}
+	if err := validateCredentials(credentials, true); err != nil {
+		return model.CredentialProfile{}, err
+	}
+
+	if name != secrets.AdminProfile {
+		existing, err := secrets.ListCredentials(ctx, name)
+		if err != nil {
+			return model.CredentialProfile{}, err
+		}
+		if len(existing) == 0 {
+			return model.CredentialProfile{}, fmt.Errorf("%w (name: %s)", ErrCredentialProfileNotFound, name)
+		}
+	}
+
+	if err := writeCredentials(ctx, name, credentials); err != nil {
+		return model.CredentialProfile{}, err
+	}
+	log.Info().Msgf("updated the credential profile (name: %s)", name)
+	return credentialProfile(ctx, name)
+}
+
+// DeleteCredentialProfile deletes a credential profile with all its credentials.
+// A credential profile used by any terrarium is not deleted.
+func DeleteCredentialProfile(ctx context.Context, name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if name == secrets.AdminProfile {
+		return fmt.Errorf("%w, the %s profile cannot be deleted", ErrInvalidCredentialProfile, name)
+	}
+
+	existing, err := secrets.ListCredentials(ctx, name)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		return fmt.Errorf("%w (name: %s)", ErrCredentialProfileNotFound, name)
+	}
+
+	trInfoList, _, err := List(ListOptions{Holders: []string{name}})
+	if err != nil {
+		return err
+	}
+	if len(trInfoList) > 0 {
+		ids := []string{}
+		for _, trInfo := range trInfoList {
+			ids = append(ids, trInfo.Id)
+		}
+		return fmt.Errorf("%w (name: %s, terrariums: %s)", ErrCredentialProfileInUse, name, strings.Join(ids, ", "))
+	}
+
+	for _, secretName := range existing {
+		// Skip the folders, if any
+		if strings.HasSuffix(secretName, "/") {
+			continue
+		}
+		if err := secrets.DeleteCredential(ctx, name, secretName); err != nil {
+			return err
+		}
+	}
+	log.Info().Msgf("deleted the credential profile (name: %s)", name)
+	return nil
+}
+
+// CheckCredentials checks if a credential profile has the credentials of the providers,
+// so that a terrarium fails fast before running tofu without a credential.
+// It does nothing if the secrets store is not configured (i.e., the templates read the credentials by VAULT_TOKEN).
+func CheckCredentials(ctx context.Context, profile string, providers []string) error {
+	if !secrets.Enabled() {
+		return nil
+	}
+	if profile == "" {
+		profile = secrets.AdminProfile
+	}
+
+	missing := []string{}
+	for _, provider := range providers {
+		ok, err := secrets.HasCredential(ctx, profile, provider)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			missing = append(missing, provider)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w, no credential of %s under the credential profile (%s)",
+			ErrMissingCredential, strings.Join(missing, ", "), profile)
+	}
+	return nil
+}
+
+// credentialProfile gets a credential profile with its configured providers.
+func credentialProfile(ctx context.Context, name string) (model.CredentialProfile, error) {
+	secretNames, err := secrets.ListCredentials(ctx, name)
+	if err != nil {
+		return model.CredentialProfile{}, err
+	}
+
+	providers := []string{}
+	for _, secretName := range secretNames {
+		if strings.HasSuffix(secretName, "/") {
+			continue
+		}
+		ok, err := secrets.HasCredential(ctx, name, secretName)
+		if err != nil {
+			return model.CredentialProfile{}, err
+		}
+		if ok {
+			providers = append(providers, secretName)
+		}
+	}
+	sort.Strings(providers)
+
+	return model.CredentialProfile{
+		Name:      name,
+		Providers: providers,
+		Path:      secrets.Mount() + "/" + secrets.ProfilePath(name),
+	}, nil
+}
+
+// writeCredentials writes the credentials of the providers, or deletes the empty ones.
+func writeCredentials(ctx context.Context, name string, credentials map[string]map[string]string) error {
+	providers := make([]string, 0, len(credentials))
+	for provider := range credentials {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	for _, provider := range providers {
+		credential := credentials[provider]
+		if len(credential) == 0 {
+			err := secrets.DeleteCredential(ctx, name, provider)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err := secrets.WriteCredential(ctx, name, provider, credential); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("%w, name (%s) must be 1-63 alphanumeric characters, '-', '_' or '.', beginning and ending with an alphanumeric",
+			ErrInvalidCredentialProfile, name)
+	}
+	return nil
+}
+
+// validateCredentials validates the providers and the values of the credentials.
+// An empty credential (i.e., to delete it) is allowed only if allowEmpty is true.
+func validateCredentials(credentials map[string]map[string]string, allowEmpty bool) error {
+	for provider, credential := range credentials {
+		if !providerPattern.MatchString(provider) {
+			return fmt.Errorf("%w, provider (%s) must be lowercase alphanumeric characters (e.g., aws, tencent)", ErrInvalidCredentialProfile, provider)
+		}
+		if len(credential) == 0 {
+			if !allowEmpty {
+				return fmt.Errorf("%w, the credential of %s is empty", ErrInvalidCredentialProfile, provider)
+			}
+			continue
+		}
+		hasValue := false
+		for k, v := range credential {
+			if k == "" {
+				return fmt.Errorf("%w, the credential of %s has an empty key", ErrInvalidCredentialProfile, provider)
+			}
+			if v != "" {
+				hasValue = true
+			}
+		}
+		if !hasValue {
+			return fmt.Errorf("%w, the credential of %s has no value", ErrInvalidCredentialProfile, provider)
+		}
+	}
+	return nil
+}
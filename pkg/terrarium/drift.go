@@ -0,0 +1,442 @@
+package terrarium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu/tfclient"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Drift detection
+ *
+ * The resources of a terrarium may be changed outside mc-terrarium (e.g., a VPN connection modified on the CSP console).
+ * The drift detector periodically runs `tofu plan -refresh-only -detailed-exitcode -json` in each applied enrichment
+ * (i.e., Ready), which compares the real resources with the state without changing either of them.
+ * If there are changes (i.e., exit code 2), the drifted resources and their changed attributes are read
+ * from the saved plan by `tofu show -json`, and kept as the drift report of the enrichment until the next detection.
+ * When a drift is detected (or it changes), a drift_detected event is recorded
+ * and, if a webhook is configured, a notification is posted to it.
+ * A drift_resolved event is recorded when the drift has gone (e.g., applied again, or reverted on the CSP).
+ */
+
+// Event types of the drift detection
+const (
+	EventDriftDetected = "drift_detected"
+	EventDriftResolved = "drift_resolved"
+)
+
+// driftPlanFile is the path of the refresh-only plan relative to the terrarium environment.
+const driftPlanFile = "plans/drift.tfplan"
+
+// sensitiveValue replaces the sensitive values in a drift report.
+const sensitiveValue = "(sensitive value)"
+
+// webhookTimeout is the timeout to post a notification to the webhook.
+const webhookTimeout = 10 * time.Second
+
+// DriftInterval returns the interval to detect the drift of the applied enrichments (0: disabled).
+func DriftInterval() time.Duration {
+	if config.Terrarium.Drift.IntervalMin > 0 {
+		return time.Duration(config.Terrarium.Drift.IntervalMin) * time.Minute
+	}
+	return 0
+}
+
+func driftKey(trId, enrichment string) string {
+	return driftPrefix(trId) + enrichment
+}
+
+func driftPrefix(trId string) string {
+	return "/tr/" + trId + "/drift/"
+}
+
+// GetDriftReports reads the drift reports of the enrichments of a terrarium in order of the enrichment.
+// If an enrichment is given, only its drift report is returned.
+func GetDriftReports(trId, enrichment string) ([]model.DriftReport, error) {
+	reports := []model.DriftReport{}
+	if enrichment != "" {
+		report, exists, err := getDriftReport(trId, enrichment)
+		if err != nil || !exists {
+			return reports, err
+		}
+		return append(reports, report), nil
+	}
+
+	kvs, err := lkvstore.List(driftPrefix(trId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the drift reports (trId: %s): %w", trId, err)
+	}
+
+	for _, kv := range kvs {
+		report := model.DriftReport{}
+		if err := json.Unmarshal([]byte(kv.Value), &report); err != nil {
+			log.Warn().Err(err).Msgf("failed to unmarshal the drift report (key: %s)", kv.Key)
+			continue
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Enrichment < reports[j].Enrichment
+	})
+	return reports, nil
+}
+
+// DetectDrift detects the drift of the resources of an enrichment by a refresh-only plan,
+// which changes neither the resources nor the state, and saves the drift report.
+// A drift_detected (or drift_resolved) event is recorded, and notified, if the drift has changed since the last detection.
+func DetectDrift(ctx context.Context, trId, enrichment, reqId string) (model.DriftReport, error) {
+
+	emptyReport := model.DriftReport{}
+
+	// Get working directory
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get terrarium environment path")
+		return emptyReport, err
+	}
+
+	// Check the phase of the enrichment
+	if err := CheckOperation(trId, enrichment, OpRefresh); err != nil {
+		log.Warn().Err(err).Msg("failed to detect the drift")
+		return emptyReport, err
+	}
+
+	if err := os.MkdirAll(workingDir+"/plans", 0755); err != nil {
+		err2 := fmt.Errorf("failed to create the plan directory (trId: %s)", trId)
+		log.Error().Err(err).Msg(err2.Error())
+		return emptyReport, err2
+	}
+	defer os.Remove(workingDir + "/" + driftPlanFile)
+
+	// Execute tofu command: plan -refresh-only -detailed-exitcode -json -out=plans/drift.tfplan
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
+	tfcli.SetChdir(workingDir)
+
+	report := model.DriftReport{
+		TrId:       trId,
+		Enrichment: enrichment,
+		ReqId:      reqId,
+		CheckedAt:  time.Now(),
+	}
+
+	_, err = tfcli.Plan().Json().RefreshOnly().DetailedExitcode().SetOut(driftPlanFile).Exec()
+	switch {
+	case err == nil:
+		// No changes
+	case errors.Is(err, tofu.ErrChangesPresent):
+		// Execute tofu command: show -json plans/drift.tfplan
+		tfcli = tfclient.NewClient(ctx, trId, reqId)
+		tfcli.SetChdir(workingDir)
+
+		showJson, err := tfcli.Show().Json().SetArg(driftPlanFile).Exec()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to execute tofu command")
+			return emptyReport, err
+		}
+		report.Resources, err = summarizeDrift(showJson)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to summarize the drift")
+			return emptyReport, err
+		}
+		report.Drifted = len(report.Resources) > 0
+	default:
+		log.Error().Err(err).Msg("failed to execute tofu command")
+		return emptyReport, err
+	}
+
+	previous, _, err := getDriftReport(trId, enrichment)
+	if err != nil {
+		return emptyReport, err
+	}
+	if report.Drifted {
+		report.DetectedAt = &report.CheckedAt
+		if previous.Drifted && previous.DetectedAt != nil {
+			report.DetectedAt = previous.DetectedAt
+		}
+	}
+	if err := lkvstore.Put(driftKey(trId, enrichment), report); err != nil {
+		return emptyReport, fmt.Errorf("failed to save the drift report (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+
+	switch {
+	case report.Drifted && !sameDrift(previous, report):
+		notifyDrift(EventDriftDetected, report, fmt.Sprintf("%d resource(s) of %s changed outside mc-terrarium: %s",
+			len(report.Resources), enrichment, describeDrift(report.Resources)))
+	case !report.Drifted && previous.Drifted:
+		notifyDrift(EventDriftResolved, report, fmt.Sprintf("the drift of %s has been resolved", enrichment))
+	}
+
+	return report, nil
+}
+
+func getDriftReport(trId, enrichment string) (model.DriftReport, bool, error) {
+	report := model.DriftReport{}
+	value, exists := lkvstore.Get(driftKey(trId, enrichment))
+	if !exists {
+		return report, false, nil
+	}
+	if err := json.Unmarshal([]byte(value), &report); err != nil {
+		return report, true, fmt.Errorf("failed to unmarshal the drift report: %w", err)
+	}
+	return report, true, nil
+}
+
+// sameDrift reports whether two drift reports have the same drifted resources and attributes.
+func sameDrift(a, b model.DriftReport) bool {
+	if a.Drifted != b.Drifted {
+		return false
+	}
+	// Compare them in JSON, as the previous report is read from the store
+	ja, errA := json.Marshal(a.Resources)
+	jb, errB := json.Marshal(b.Resources)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// describeDrift describes the drifted resources and their changed attributes in a line.
+func describeDrift(resources []model.ResourceDrift) string {
+	var descs []string
+	for _, r := range resources {
+		var paths []string
+		for _, attr := range r.Attributes {
+			paths = append(paths, attr.Path)
+		}
+		desc := fmt.Sprintf("%s (%s", r.Address, strings.Join(r.Actions, ", "))
+		if len(paths) > 0 {
+			desc += ": " + strings.Join(paths, ", ")
+		}
+		descs = append(descs, desc+")")
+	}
+	return strings.Join(descs, "; ")
+}
+
+// notifyDrift records an event of the drift, and posts it to the webhook if configured.
+func notifyDrift(eventType string, report model.DriftReport, message string) {
+	RecordEvent(report.TrId, eventType, message)
+
+	url := config.Terrarium.Drift.WebhookUrl
+	if url == "" {
+		return
+	}
+	notification := struct {
+		Type    string            `json:"type"`
+		TrId    string            `json:"trId"`
+		Message string            `json:"message"`
+		Report  model.DriftReport `json:"report"`
+	}{eventType, report.TrId, message, report}
+
+	go func() {
+		b, err := json.Marshal(notification)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to marshal the drift notification")
+			return
+		}
+		client := &http.Client{Timeout: webhookTimeout}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(b))
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to post the drift notification (trId: %s)", report.TrId)
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Warn().Msgf("the webhook rejected the drift notification (trId: %s, status: %s)", report.TrId, resp.Status)
+		}
+	}()
+}
+
+// summarizeDrift summarizes the drifted resources of a refresh-only plan from the output of `tofu show -json`.
+func summarizeDrift(showJson string) ([]model.ResourceDrift, error) {
+
+	plan := struct {
+		ResourceDrift []struct {
+			Address      string `json:"address"`
+			Mode         string `json:"mode"`
+			Type         string `json:"type"`
+			Name         string `json:"name"`
+			ProviderName string `json:"provider_name"`
+			Change       struct {
+				Actions         []string `json:"actions"`
+				Before          any      `json:"before"`
+				After           any      `json:"after"`
+				BeforeSensitive any      `json:"before_sensitive"`
+				AfterSensitive  any      `json:"after_sensitive"`
+			} `json:"change"`
+		} `json:"resource_drift"`
+	}{}
+	// Decode the JSON document only, because the output may include the messages printed to stderr
+	start := strings.Index(showJson, "{")
+	if start < 0 {
+		return nil, errors.New("failed to find the plan in the output")
+	}
+	if err := json.NewDecoder(strings.NewReader(showJson[start:])).Decode(&plan); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the plan: %w", err)
+	}
+
+	resources := []model.ResourceDrift{}
+	for _, rd := range plan.ResourceDrift {
+		// Skip data sources, which are read but not managed
+		if rd.Mode == "data" {
+			continue
+		}
+
+		before, after := map[string]any{}, map[string]any{}
+		flattenValue(rd.Change.Before, "", before)
+		flattenValue(rd.Change.After, "", after)
+		sensitive := map[string]any{}
+		flattenValue(rd.Change.BeforeSensitive, "", sensitive)
+		flattenValue(rd.Change.AfterSensitive, "", sensitive)
+
+		var paths []string
+		for path := range before {
+			paths = append(paths, path)
+		}
+		for path := range after {
+			if _, exists := before[path]; !exists {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+
+		var attrs []model.AttributeDrift
+		for _, path := range paths {
+			if reflect.DeepEqual(before[path], after[path]) {
+				continue
+			}
+			attr := model.AttributeDrift{Path: path, Before: before[path], After: after[path]}
+			if isSensitive(sensitive, path) {
+				attr.Before, attr.After = sensitiveValue, sensitiveValue
+			}
+			attrs = append(attrs, attr)
+		}
+
+		resources = append(resources, model.ResourceDrift{
+			Address:      rd.Address,
+			Type:         rd.Type,
+			Name:         rd.Name,
+			ProviderName: rd.ProviderName,
+			Actions:      rd.Change.Actions,
+			Attributes:   attrs,
+		})
+	}
+
+	return resources, nil
+}
+
+// flattenValue flattens a value of a resource into the paths of its leaves (e.g., tags.Owner, ingress.0.cidr_blocks.1).
+func flattenValue(v any, path string, out map[string]any) {
+	switch value := v.(type) {
+	case map[string]any:
+		if len(value) == 0 && path != "" {
+			out[path] = value
+		}
+		for k, child := range value {
+			flattenValue(child, joinPath(path, k), out)
+		}
+	case []any:
+		if len(value) == 0 && path != "" {
+			out[path] = value
+		}
+		for i, child := range value {
+			flattenValue(child, joinPath(path, strconv.Itoa(i)), out)
+		}
+	default:
+		if path != "" {
+			out[path] = value
+		}
+	}
+}
+
+// isSensitive reports whether a path (or its parent) is marked as sensitive.
+func isSensitive(sensitive map[string]any, path string) bool {
+	for {
+		if sensitive[path] == true {
+			return true
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// StartDriftDetector starts the drift detector, which detects the drift of the applied enrichments periodically,
+// until the context is done. It is disabled if the interval is not configured.
+func StartDriftDetector(ctx context.Context) {
+	interval := DriftInterval()
+	if interval == 0 {
+		log.Info().Msg("the drift detector is disabled (set terrarium.drift.interval_min to enable it)")
+		return
+	}
+	log.Info().Msgf("the drift detector checks the applied enrichments every %s", interval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Another instance sharing the lkvstore detects the drift (see StartLeaderElection)
+				if !IsLeader() {
+					continue
+				}
+				detectAllDrift(ctx)
+			}
+		}
+	}()
+}
+
+// detectAllDrift detects the drift of the applied enrichments of all terrariums.
+// The drift reports of the enrichments no longer applied (e.g., destroyed) are deleted.
+func detectAllDrift(ctx context.Context) {
+	all, err := ReadAllInfo()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read the terrariums to detect the drift")
+		return
+	}
+
+	// The requests of the detection are system requests, which are pruned to the latest ones
+	ctx = job.WithSystem(ctx)
+	for _, trInfo := range all {
+		for _, name := range trInfo.Enrichments.Names() {
+			if ctx.Err() != nil {
+				return
+			}
+			if trInfo.Enrichments[name].Phase != PhaseReady {
+				lkvstore.Delete(driftKey(trInfo.Id, name))
+				continue
+			}
+			// Another request is running (e.g., apply), so detect it next time
+			if status, exists := tofu.GetExecutionStatus(trInfo.Id, name); exists && status == tofu.StatusRunning {
+				continue
+			}
+
+			reqId := fmt.Sprintf("%d", time.Now().UnixNano())
+			ctx := job.WithCredentialHolder(ctx, trInfo.CredentialProfile)
+			if _, err := DetectDrift(ctx, trInfo.Id, name, reqId); err != nil {
+				log.Warn().Err(err).Msgf("failed to detect the drift (trId: %s, enrichment: %s, reqId: %s)", trInfo.Id, name, reqId)
+			}
+		}
+		if err := job.PruneSystem(trInfo.Id); err != nil {
+			log.Warn().Err(err).Msgf("failed to prune the requests of the drift detection (trId: %s)", trInfo.Id)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package terrarium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+)
+
+/*
+ * [Note] Erasing a terrarium
+ *
+ * Erasing a terrarium removes its working directories and info, by which the resources
+ * tracked by the state are lost track of (i.e., orphaned) and keep running in the CSPs.
+ * So the state of each enrichment is inspected before erasing,
+ * and the resources must be destroyed first unless they are deliberately orphaned.
+ */
+
+// ErrLiveResources is returned if resources still exist in the state of a terrarium to erase.
+var ErrLiveResources = errors.New("resources still exist in the terrarium")
+
+// ListLiveResources lists the resources in the state of each enrichment of a terrarium by `tofu state list`
+// (key: enrichment, value: resource addresses). An enrichment without resources is omitted.
+// The data sources (e.g., data.vault_kv_secret_v2.aws) are not resources, which remain in the state after destroying.
+func ListLiveResources(ctx context.Context, trId, reqId string) (map[string][]string, error) {
+
+	trInfo, _, err := GetInfo(trId)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := map[string][]string{}
+	for _, name := range trInfo.Enrichments.Names() {
+
+		// No state, no resources (e.g., not applied yet)
+		workingDir := config.Terrarium.Root + "/.terrarium/" + trId + "/" + name
+		if _, err := os.Stat(workingDir + "/terraform.tfstate"); os.IsNotExist(err) {
+			continue
+		}
+
+		ret, err := State(ctx, trId, name, reqId, "list")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the resources (trId: %s, enrichment: %s): %w", trId, name, err)
+		}
+
+		addresses := []string{}
+		for _, line := range strings.Split(ret, "\n") {
+			if address := strings.TrimSpace(line); address != "" && !isDataSource(address) {
+				addresses = append(addresses, address)
+			}
+		}
+		if len(addresses) > 0 {
+			resources[name] = addresses
+		}
+	}
+
+	return resources, nil
+}
+
+// isDataSource checks if a resource address is of a data source,
+// i.e., its last module segment starts with "data." (e.g., data.a.b, module.m["x.y"].data.a.b).
+func isDataSource(address string) bool {
+	for strings.HasPrefix(address, "module.") {
+		// Skip the module name and its index, if any (e.g., module.m["x.y"].)
+		rest := address[len("module."):]
+		depth, end := 0, -1
+	scan:
+		for i, r := range rest {
+			switch r {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			case '.':
+				if depth == 0 {
+					end = i
+					break scan
+				}
+			}
+		}
+		if end < 0 {
+			return false
+		}
+		address = rest[end+1:]
+	}
+	return strings.HasPrefix(address, "data.")
+}
@@ -0,0 +1,341 @@
+package terrarium
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+)
+
+/*
+ * [Note] Labels and listing of terrariums
+ *
+ * A terrarium has labels (e.g., env=staging, team=network) to organize and select terrariums.
+ * The terrariums are listed with a label selector, which is a comma-separated list of requirements
+ * (e.g., "env=staging,team!=network,region in (us-east-1,us-west-2),!deprecated"),
+ * filters on the enrichment, provider, phase and credential holder, sorted by a field,
+ * and paginated by a cursor, which points after the last terrarium of a page (i.e., keyset pagination)
+ * so that a page is not shifted by the terrariums issued or erased in the meantime.
+ */
+
+var (
+	// ErrInvalidLabel is returned if a label key or value is malformed.
+	ErrInvalidLabel = errors.New("invalid label")
+	// ErrInvalidListOptions is returned if the options to list terrariums are malformed (e.g., selector, sort, cursor).
+	ErrInvalidListOptions = errors.New("invalid list options")
+)
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._/-]{0,61}[A-Za-z0-9])?$`)
+	labelValuePattern = regexp.MustCompile(`^([A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// SortFields are the fields to sort terrariums by.
+var SortFields = []string{"id", "name", "phase", "createdAt"}
+
+// ValidateLabels validates the keys and values of labels.
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("%w, key (%s) must be 1-63 alphanumeric characters, '-', '_', '.' or '/', beginning and ending with an alphanumeric", ErrInvalidLabel, k)
+		}
+		if !labelValuePattern.MatchString(v) {
+			return fmt.Errorf("%w, value (%s) of %s must be 0-63 alphanumeric characters, '-', '_' or '.', beginning and ending with an alphanumeric", ErrInvalidLabel, v, k)
+		}
+	}
+	return nil
+}
+
+// SetLabels replaces the labels of a terrarium.
+func SetLabels(trId string, labels map[string]string) (model.TerrariumInfo, error) {
+	if err := ValidateLabels(labels); err != nil {
+		return model.TerrariumInfo{}, err
+	}
+
+	err := updateInfo(trId, func(trInfo *model.TerrariumInfo) error {
+		trInfo.Labels = labels
+		return nil
+	})
+	if err != nil {
+		return model.TerrariumInfo{}, err
+	}
+
+	trInfo, _, err := GetInfo(trId)
+	return trInfo, err
+}
+
+// ListOptions are the options to list terrariums.
+// The filters (i.e., Enrichments, Providers, Phases and Holders) match any of the values.
+type ListOptions struct {
+	// Selector is a label selector (e.g., "env=staging,team!=network")
+	Selector    string
+	Enrichments []string
+	Providers   []string
+	Phases      []string
+	Holders     []string
+	// Sort is a field to sort by (i.e., id, name, phase, createdAt), prefixed with '-' in descending order
+	Sort string
+	// Limit is the maximum number of terrariums in a page (0: all)
+	Limit int
+	// Cursor is the cursor to the next page returned by List
+	Cursor string
+}
+
+// listCursor is the position after the last terrarium of a page.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    string `json:"i"`
+}
+
+// List lists the terrariums by the options, and returns the cursor to the next page if any.
+func List(opts ListOptions) ([]model.TerrariumInfo, string, error) {
+
+	selector, err := ParseSelector(opts.Selector)
+	if err != nil {
+		return nil, "", err
+	}
+
+	field, desc := strings.CutPrefix(opts.Sort, "-")
+	if field == "" {
+		field = "id"
+	}
+	if !slices.Contains(SortFields, field) {
+		return nil, "", fmt.Errorf("%w, sort (%s) must be one of [%s], prefixed with '-' in descending order",
+			ErrInvalidListOptions, opts.Sort, strings.Join(SortFields, ", "))
+	}
+	if opts.Limit < 0 {
+		return nil, "", fmt.Errorf("%w, limit (%d) must not be negative", ErrInvalidListOptions, opts.Limit)
+	}
+
+	var after *listCursor
+	if opts.Cursor != "" {
+		after, err = decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if after.Sort != opts.Sort {
+			return nil, "", fmt.Errorf("%w, the cursor is for another sort (%s)", ErrInvalidListOptions, after.Sort)
+		}
+	}
+
+	all, err := ReadAllInfo()
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Filter
+	list := []model.TerrariumInfo{}
+	for _, trInfo := range all {
+		if !selector.Matches(trInfo.Labels) ||
+			!matchesAny(opts.Enrichments, trInfo.Enrichments.Names()) ||
+			!matchesAny(opts.Providers, trInfo.Providers) ||
+			!matchesAny(opts.Phases, []string{trInfo.Phase}) ||
+			!matchesAny(opts.Holders, []string{trInfo.CredentialProfile}) {
+			continue
+		}
+		list = append(list, trInfo)
+	}
+
+	// Sort by the field, and by the ID for the same values
+	less := func(a, b listCursor) bool {
+		if a.Value != b.Value {
+			return (a.Value < b.Value) != desc
+		}
+		if a.Id != b.Id {
+			return (a.Id < b.Id) != desc
+		}
+		return false
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return less(cursorOf(list[i], opts.Sort, field), cursorOf(list[j], opts.Sort, field))
+	})
+
+	// Paginate
+	if after != nil {
+		start := sort.Search(len(list), func(i int) bool {
+			return less(*after, cursorOf(list[i], opts.Sort, field))
+		})
+		list = list[start:]
+	}
+	if opts.Limit == 0 || len(list) <= opts.Limit {
+		return list, "", nil
+	}
+	list = list[:opts.Limit]
+	next, err := encodeCursor(cursorOf(list[len(list)-1], opts.Sort, field))
+	if err != nil {
+		return nil, "", err
+	}
+	return list, next, nil
+}
+
+// cursorOf returns the position of a terrarium in the order of a field.
+func cursorOf(trInfo model.TerrariumInfo, sort, field string) listCursor {
+	value := trInfo.Id
+	switch field {
+	case "name":
+		value = trInfo.Name
+	case "phase":
+		value = trInfo.Phase
+	case "createdAt":
+		value = trInfo.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return listCursor{Sort: sort, Value: value, Id: trInfo.Id}
+}
+
+func encodeCursor(c listCursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(s string) (*listCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w, malformed cursor", ErrInvalidListOptions)
+	}
+	c := &listCursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("%w, malformed cursor", ErrInvalidListOptions)
+	}
+	return c, nil
+}
+
+// matchesAny reports whether any of the values is in the filter. An empty filter matches everything.
+func matchesAny(filter, values []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if slices.Contains(filter, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Selector is a label selector, which matches the labels satisfying all the requirements.
+type Selector []requirement
+
+// requirement is a requirement of a label selector (e.g., env=staging, region in (a,b), !deprecated).
+type requirement struct {
+	key      string
+	operator string // "=", "!=", "in", "notin", "exists" or "!"
+	values   []string
+}
+
+// ParseSelector parses a label selector, which is a comma-separated list of the requirements:
+// key=value, key==value, key!=value, key in (v1,v2), key notin (v1,v2), key (exists) and !key (not exists).
+func ParseSelector(s string) (Selector, error) {
+	selector := Selector{}
+	for _, expr := range splitRequirements(s) {
+		expr = strings.TrimSpace(expr)
+		if expr == "" {
+			continue
+		}
+		r, err := parseRequirement(expr)
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, r)
+	}
+	return selector, nil
+}
+
+// splitRequirements splits a label selector by the commas out of the parentheses.
+func splitRequirements(s string) []string {
+	var exprs []string
+	depth, start := 0, 0
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				exprs = append(exprs, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(exprs, s[start:])
+}
+
+func parseRequirement(expr string) (requirement, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w, selector (%s) %s", ErrInvalidListOptions, expr, reason)
+	}
+
+	r := requirement{}
+	switch {
+	case strings.HasPrefix(expr, "!"):
+		r.key, r.operator = strings.TrimSpace(expr[1:]), "!"
+	case strings.Contains(expr, "!="):
+		k, v, _ := strings.Cut(expr, "!=")
+		r.key, r.operator, r.values = strings.TrimSpace(k), "!=", []string{strings.TrimSpace(v)}
+	case strings.Contains(expr, "="):
+		k, v, _ := strings.Cut(expr, "=")
+		r.key, r.operator, r.values = strings.TrimSpace(k), "=", []string{strings.TrimSpace(strings.TrimPrefix(v, "="))}
+	case strings.HasSuffix(expr, ")"):
+		open := strings.Index(expr, "(")
+		if open < 0 {
+			return r, invalid("has no '('")
+		}
+		fields := strings.Fields(expr[:open])
+		if len(fields) != 2 || (fields[1] != "in" && fields[1] != "notin") {
+			return r, invalid("must be 'key in (v1,v2)' or 'key notin (v1,v2)'")
+		}
+		r.key, r.operator = fields[0], fields[1]
+		for _, v := range strings.Split(expr[open+1:len(expr)-1], ",") {
+			r.values = append(r.values, strings.TrimSpace(v))
+		}
+	default:
+		r.key, r.operator = expr, "exists"
+	}
+
+	if !labelKeyPattern.MatchString(r.key) {
+		return r, invalid("has an invalid key")
+	}
+	for _, v := range r.values {
+		if !labelValuePattern.MatchString(v) {
+			return r, invalid("has an invalid value")
+		}
+	}
+	return r, nil
+}
+
+// Matches reports whether the labels satisfy all the requirements of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		v, exists := labels[r.key]
+		switch r.operator {
+		case "=", "in":
+			if !exists || !slices.Contains(r.values, v) {
+				return false
+			}
+		case "!=", "notin":
+			if exists && slices.Contains(r.values, v) {
+				return false
+			}
+		case "exists":
+			if !exists {
+				return false
+			}
+		case "!":
+			if exists {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package terrarium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Leader of the background loops
+ * If the lkvstore is shared by multiple instances (i.e., the etcd backend), the background loops
+ * (i.e., the reaper and the drift detector) run on one instance only, the leader.
+ * The leader holds a lease in the lkvstore (/leader/background), renewed by compare-and-swap,
+ * and another instance takes it over if it is not renewed before it expires (e.g., the leader is down).
+ * With the file or bolt backend, the only instance is always the leader.
+ */
+
+const (
+	leaderKey = "/leader/background"
+	// leaderTTL is the time for another instance to take over the lease if it is not renewed
+	leaderTTL = 30 * time.Second
+	// leaderRenewInterval is the interval to renew (or to try to acquire) the lease
+	leaderRenewInterval = 10 * time.Second
+)
+
+// leaderLease is the lease of the leader in the lkvstore.
+type leaderLease struct {
+	// Id identifies the instance (i.e., {hostname}/{pid})
+	Id        string    `json:"id"`
+	RenewedAt time.Time `json:"renewedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+var leading atomic.Bool
+
+// IsLeader reports whether this instance runs the background loops (see StartLeaderElection).
+func IsLeader() bool {
+	return leading.Load()
+}
+
+// instanceId identifies this instance among the instances sharing the lkvstore.
+func instanceId() string {
+	return fmt.Sprintf("%s/%d", job.Hostname(), os.Getpid())
+}
+
+// StartLeaderElection acquires (or waits for) the lease of the leader, and keeps renewing it until the context is done.
+func StartLeaderElection(ctx context.Context) {
+	campaign(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(leaderRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				leading.Store(false)
+				return
+			case <-ticker.C:
+				campaign(time.Now())
+			}
+		}
+	}()
+}
+
+// campaign renews the lease if this instance holds it, or acquires it if it is free or expired.
+// This instance is not the leader if the lkvstore fails, so that no two instances run the loops at once.
+func campaign(now time.Time) {
+	id := instanceId()
+	lease := leaderLease{Id: id, RenewedAt: now, ExpiresAt: now.Add(leaderTTL)}
+
+	acquired := false
+	var err error
+	value, exists := lkvstore.Get(leaderKey)
+	current := leaderLease{}
+	if exists {
+		if err := json.Unmarshal([]byte(value), &current); err != nil {
+			log.Warn().Err(err).Msg("failed to unmarshal the lease of the leader, taking it over")
+		}
+	}
+	switch {
+	case !exists:
+		acquired, err = lkvstore.PutIfAbsent(leaderKey, lease)
+	case current.Id == id || now.After(current.ExpiresAt):
+		acquired, err = lkvstore.CompareAndSwap(leaderKey, value, lease)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to acquire or renew the lease of the leader")
+		acquired = false
+	}
+
+	was := leading.Swap(acquired)
+	switch {
+	case acquired && !was:
+		log.Info().Msgf("this instance (%s) is the leader, which runs the reaper and the drift detector", id)
+	case !acquired && was:
+		log.Warn().Msgf("this instance (%s) is no longer the leader", id)
+	case !acquired:
+		log.Debug().Msgf("the leader is %s (until %s)", current.Id, current.ExpiresAt.Format(time.RFC3339))
+	}
+}
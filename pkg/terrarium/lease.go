@@ -0,0 +1,413 @@
+package terrarium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Lease of a terrarium (i.e., time to live and auto-destroy)
+ *
+ * A terrarium may have an expiry (expiresAt), given by ttl or expiresAt when it is issued.
+ * The reaper checks the expiry of the terrariums periodically (autocontrol.duration_ms):
+ * within the grace period before the expiry (autocontrol.grace_min), the expiry is warned by an event,
+ * and after the expiry, the resources of each enrichment are destroyed and its environment is emptied out.
+ * The terrarium itself (i.e., info, labels, requests) is kept, and the lease is released once it is reaped.
+ * The reaper claims an expired terrarium (reapingSince) by compare-and-swap of the terrarium info
+ * before destroying anything, so that the lease is either extended before the claim or not extended until it ends,
+ * and releases the lease only if the expiry is unchanged. A claim older than reapClaimTimeout
+ * (e.g., left by an instance which stopped while reaping) is taken over.
+ * The lease is extended by POST /tr/{trId}/extend, which warns again within the new grace period.
+ * The events are kept in /tr/{trId}/events/{timestamp} and served by GET /tr/{trId}/events.
+ */
+
+// Event types of a terrarium
+const (
+	EventExpiryWarning = "expiry_warning"
+	EventLeaseExtended = "lease_extended"
+	EventExpired       = "expired"
+	EventReaped        = "reaped"
+	EventReapFailed    = "reap_failed"
+)
+
+const (
+	// defaultReaperInterval is the interval to check the expiry of the terrariums
+	defaultReaperInterval = 10 * time.Second
+	// defaultExpiryGrace is the grace period before the expiry, in which the expiry is warned
+	defaultExpiryGrace = 60 * time.Minute
+	// reapRetryInterval is the interval to retry reaping a terrarium which has failed to be reaped
+	reapRetryInterval = 10 * time.Minute
+	// reapClaimTimeout is the time after which a claim to reap a terrarium is regarded as abandoned
+	reapClaimTimeout = 2 * time.Hour
+)
+
+// ErrInvalidLease is returned if a lease (i.e., ttl, expiresAt) is malformed.
+var ErrInvalidLease = errors.New("invalid lease")
+
+// reaping holds the terrariums being reaped, and the time of the last failure to reap them
+var (
+	reapingMu  sync.Mutex
+	reaping    = map[string]bool{}
+	reapFailed = map[string]time.Time{}
+)
+
+// ExpiryGrace returns the grace period before the expiry of a terrarium, in which the expiry is warned.
+func ExpiryGrace() time.Duration {
+	if config.Terrarium.AutoControl.GraceMin > 0 {
+		return time.Duration(config.Terrarium.AutoControl.GraceMin) * time.Minute
+	}
+	return defaultExpiryGrace
+}
+
+// reaperInterval returns the interval to check the expiry of the terrariums.
+func reaperInterval() time.Duration {
+	if config.Terrarium.AutoControl.DurationMilliSec > 0 {
+		return time.Duration(config.Terrarium.AutoControl.DurationMilliSec) * time.Millisecond
+	}
+	return defaultReaperInterval
+}
+
+// LeaseExpiry returns the expiry of a lease given by ttl (e.g., 72h) or expiresAt,
+// or nil if neither is given (i.e., no expiry).
+func LeaseExpiry(ttl string, expiresAt *time.Time) (*time.Time, error) {
+	if ttl != "" && expiresAt != nil {
+		return nil, fmt.Errorf("%w, either ttl or expiresAt must be given, not both", ErrInvalidLease)
+	}
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("%w, expiresAt (%s) must be in the future", ErrInvalidLease, expiresAt.Format(time.RFC3339))
+		}
+		return expiresAt, nil
+	}
+	if ttl == "" {
+		return nil, nil
+	}
+	d, err := parseTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+	expiry := time.Now().Add(d)
+	return &expiry, nil
+}
+
+func parseTTL(ttl string) (time.Duration, error) {
+	d, err := time.ParseDuration(ttl)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%w, ttl (%s) must be a positive duration (e.g., 30m, 72h)", ErrInvalidLease, ttl)
+	}
+	return d, nil
+}
+
+// ExtendLease extends the lease of a terrarium by ttl from the current expiry (or now if expired or not set),
+// or to expiresAt. The expiry is warned again within the new grace period.
+func ExtendLease(trId, ttl string, expiresAt *time.Time) (model.TerrariumInfo, error) {
+	if ttl == "" && expiresAt == nil {
+		return model.TerrariumInfo{}, fmt.Errorf("%w, either ttl or expiresAt is required", ErrInvalidLease)
+	}
+	if ttl != "" && expiresAt != nil {
+		return model.TerrariumInfo{}, fmt.Errorf("%w, either ttl or expiresAt must be given, not both", ErrInvalidLease)
+	}
+
+	var expiry time.Time
+	err := updateInfo(trId, func(trInfo *model.TerrariumInfo) error {
+		// Checked in the same compare-and-swap as the claim of the reaper (see claimReap)
+		if reapClaimed(*trInfo, time.Now()) {
+			return fmt.Errorf("%w, the terrarium (trId: %s) has expired and is being reaped", tofu.ErrInProgress, trId)
+		}
+		if expiresAt != nil {
+			if !expiresAt.After(time.Now()) {
+				return fmt.Errorf("%w, expiresAt (%s) must be in the future", ErrInvalidLease, expiresAt.Format(time.RFC3339))
+			}
+			expiry = *expiresAt
+		} else {
+			d, err := parseTTL(ttl)
+			if err != nil {
+				return err
+			}
+			base := time.Now()
+			if trInfo.ExpiresAt != nil && trInfo.ExpiresAt.After(base) {
+				base = *trInfo.ExpiresAt
+			}
+			expiry = base.Add(d)
+		}
+		trInfo.ExpiresAt = &expiry
+		trInfo.ExpiryWarnedAt = nil
+		return nil
+	})
+	if err != nil {
+		return model.TerrariumInfo{}, err
+	}
+
+	RecordEvent(trId, EventLeaseExtended, fmt.Sprintf("the lease is extended to %s", expiry.Format(time.RFC3339)))
+
+	trInfo, _, err := GetInfo(trId)
+	return trInfo, err
+}
+
+func eventsKey(trId string) string {
+	return "/tr/" + trId + "/events/"
+}
+
+// RecordEvent records an event of a terrarium.
+func RecordEvent(trId, eventType, message string) {
+	event := model.TerrariumEvent{Type: eventType, Message: message, Timestamp: time.Now()}
+	if err := lkvstore.Put(fmt.Sprintf("%s%020d", eventsKey(trId), event.Timestamp.UnixNano()), event); err != nil {
+		log.Warn().Err(err).Msgf("failed to record the event (trId: %s, type: %s)", trId, eventType)
+	}
+	log.Info().Msgf("[%s] %s (trId: %s)", eventType, message, trId)
+}
+
+// ListEvents lists the events of a terrarium in order of time.
+// If types are given, only the events of the types are returned.
+func ListEvents(trId string, types ...string) ([]model.TerrariumEvent, error) {
+	kvs, err := lkvstore.List(eventsKey(trId))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the events (trId: %s): %w", trId, err)
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+
+	events := []model.TerrariumEvent{}
+	for _, kv := range kvs {
+		event := model.TerrariumEvent{}
+		if err := json.Unmarshal([]byte(kv.Value), &event); err != nil {
+			log.Warn().Err(err).Msgf("failed to unmarshal the event (key: %s)", kv.Key)
+			continue
+		}
+		if len(types) > 0 && !slices.Contains(types, event.Type) {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// StartReaper starts the reaper, which warns the expiry of the terrariums within the grace period,
+// and destroys the resources of the expired terrariums, until the context is done.
+func StartReaper(ctx context.Context) {
+	interval := reaperInterval()
+	log.Info().Msgf("the reaper checks the expiry of the terrariums every %s (grace period: %s)", interval, ExpiryGrace())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Another instance sharing the lkvstore reaps the terrariums (see StartLeaderElection)
+				if !IsLeader() {
+					continue
+				}
+				reapExpired(ctx, time.Now())
+			}
+		}
+	}()
+}
+
+// reapExpired warns the expiry of the terrariums within the grace period, and reaps the expired ones.
+func reapExpired(ctx context.Context, now time.Time) {
+	all, err := ReadAllInfo()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read the terrariums to check the expiry")
+		return
+	}
+
+	grace := ExpiryGrace()
+	for _, trInfo := range all {
+		if trInfo.ExpiresAt == nil {
+			continue
+		}
+		expiry := *trInfo.ExpiresAt
+
+		if now.Before(expiry) {
+			if trInfo.ExpiryWarnedAt == nil && !now.Before(expiry.Add(-grace)) {
+				warnExpiry(trInfo.Id, expiry, now)
+			}
+			continue
+		}
+
+		reapingMu.Lock()
+		failedAt, failed := reapFailed[trInfo.Id]
+		skip := reaping[trInfo.Id] || (failed && now.Sub(failedAt) < reapRetryInterval)
+		if !skip {
+			reaping[trInfo.Id] = true
+		}
+		reapingMu.Unlock()
+		if skip {
+			continue
+		}
+
+		err := reap(ctx, trInfo)
+
+		reapingMu.Lock()
+		delete(reaping, trInfo.Id)
+		if err != nil {
+			reapFailed[trInfo.Id] = now
+		} else {
+			delete(reapFailed, trInfo.Id)
+		}
+		reapingMu.Unlock()
+	}
+}
+
+// warnExpiry records the expiry warning of a terrarium once within the grace period.
+func warnExpiry(trId string, expiry, now time.Time) {
+	warned := false
+	err := updateInfo(trId, func(trInfo *model.TerrariumInfo) error {
+		// Extended or warned in the meantime
+		warned = trInfo.ExpiresAt == nil || !trInfo.ExpiresAt.Equal(expiry) || trInfo.ExpiryWarnedAt != nil
+		if !warned {
+			trInfo.ExpiryWarnedAt = &now
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to warn the expiry (trId: %s)", trId)
+		return
+	}
+	if warned {
+		return
+	}
+	RecordEvent(trId, EventExpiryWarning, fmt.Sprintf(
+		"the terrarium expires at %s (in %s), and its resources will be destroyed unless the lease is extended (POST /tr/%s/extend)",
+		expiry.Format(time.RFC3339), expiry.Sub(now).Round(time.Second), trId))
+}
+
+// reapClaimed reports whether the reaper holds a claim (not abandoned) to reap a terrarium.
+func reapClaimed(trInfo model.TerrariumInfo, now time.Time) bool {
+	return trInfo.ReapingSince != nil && now.Sub(*trInfo.ReapingSince) < reapClaimTimeout
+}
+
+// claimReap claims an expired terrarium to reap by compare-and-swap of the terrarium info,
+// and returns the terrarium info read again. It returns false if the terrarium is no longer expired
+// (e.g., the lease has been extended) or is claimed by another.
+func claimReap(trId string, expiry, now time.Time) (model.TerrariumInfo, bool, error) {
+	claimed := false
+	var current model.TerrariumInfo
+	err := updateInfo(trId, func(trInfo *model.TerrariumInfo) error {
+		claimed = trInfo.ExpiresAt != nil && trInfo.ExpiresAt.Equal(expiry) && !now.Before(expiry) && !reapClaimed(*trInfo, now)
+		if claimed {
+			trInfo.ReapingSince = &now
+		}
+		current = *trInfo
+		return nil
+	})
+	if err != nil {
+		return model.TerrariumInfo{}, false, err
+	}
+	return current, claimed, nil
+}
+
+// releaseReap ends the claim to reap a terrarium, and releases the lease if reaped and the expiry is unchanged.
+func releaseReap(trId string, expiry time.Time, reaped bool) (bool, error) {
+	released := false
+	err := updateInfo(trId, func(trInfo *model.TerrariumInfo) error {
+		trInfo.ReapingSince = nil
+		if reaped && trInfo.ExpiresAt != nil && trInfo.ExpiresAt.Equal(expiry) {
+			trInfo.ExpiresAt = nil
+			trInfo.ExpiryWarnedAt = nil
+			released = true
+		}
+		return nil
+	})
+	return released, err
+}
+
+// reap destroys the resources of each enrichment of an expired terrarium and empties out its environment.
+// The lease is released if it succeeds.
+func reap(ctx context.Context, trInfo model.TerrariumInfo) error {
+	trId := trInfo.Id
+	expiry := *trInfo.ExpiresAt
+
+	// Another request is running (e.g., apply), so try again later
+	executing, err := tofu.IsExecuting(trId)
+	if err != nil || executing {
+		return err
+	}
+
+	// Claim the terrarium, which may have been extended since it was read
+	trInfo, claimed, err := claimReap(trId, expiry, time.Now())
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		log.Debug().Msgf("the terrarium (trId: %s) is extended or being reaped, skip reaping", trId)
+		return nil
+	}
+
+	RecordEvent(trId, EventExpired, fmt.Sprintf("the terrarium has expired at %s, destroying the resources", expiry.Format(time.RFC3339)))
+
+	// The request of the reaper is a system request, which is pruned to the latest ones
+	ctx = job.WithSystem(job.WithCredentialHolder(ctx, trInfo.CredentialProfile))
+	reqId := fmt.Sprintf("%d", time.Now().UnixNano())
+	defer func() {
+		if err := job.PruneSystem(trId); err != nil {
+			log.Warn().Err(err).Msgf("failed to prune the requests of the reaper (trId: %s)", trId)
+		}
+	}()
+
+	// Destroy the dependents before their dependencies
+	names, err := DestroyOrder(trId, trInfo.Enrichments.Names())
+	if err != nil {
+		if _, err2 := releaseReap(trId, expiry, false); err2 != nil {
+			log.Warn().Err(err2).Msgf("failed to release the claim to reap (trId: %s)", trId)
+		}
+		return err
+	}
+
+	var failed []string
+	for _, name := range names {
+		if err := reapEnrichment(ctx, trId, name, reqId); err != nil {
+			log.Error().Err(err).Msgf("failed to reap the enrichment (trId: %s, enrichment: %s)", trId, name)
+			failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	if len(failed) > 0 {
+		err := fmt.Errorf("failed to reap the enrichment(s), %s", strings.Join(failed, "; "))
+		RecordEvent(trId, EventReapFailed, fmt.Sprintf("%v, retry in %s (reqId: %s)", err, reapRetryInterval, reqId))
+		if _, err2 := releaseReap(trId, expiry, false); err2 != nil {
+			log.Warn().Err(err2).Msgf("failed to release the claim to reap (trId: %s)", trId)
+		}
+		return err
+	}
+
+	// Release the lease (only if it is unchanged)
+	released, err := releaseReap(trId, expiry, true)
+	if err != nil {
+		return err
+	}
+	if !released {
+		log.Warn().Msgf("the lease of the terrarium (trId: %s) has changed while reaping, it is kept", trId)
+	}
+	RecordEvent(trId, EventReaped, fmt.Sprintf("the resources are destroyed and the environments are emptied out (reqId: %s)", reqId))
+	return nil
+}
+
+// reapEnrichment destroys the resources of an enrichment, if any, and empties out its environment.
+func reapEnrichment(ctx context.Context, trId, name, reqId string) error {
+	workingDir := config.Terrarium.Root + "/.terrarium/" + trId + "/" + name
+	if _, err := os.Stat(workingDir + "/terraform.tfstate"); err == nil {
+		if _, err := Destroy(ctx, trId, name, reqId); err != nil {
+			return err
+		}
+	}
+	return EmptyOutTerrariumEnv(trId, name)
+}
@@ -0,0 +1,101 @@
+package terrarium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu/tfclient"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Outputs of enrichments
+ *
+ * The output values of an enrichment are stored after it is applied or refreshed,
+ * so that the other enrichments in the terrarium can reference them
+ * (e.g., a VPN using the VPC IDs of a testbed) without running a command in the enrichment.
+ * Sensitive values are not stored.
+ */
+
+func outputsKey(trId, enrichment string) string {
+	return "/tr/" + trId + "/enrichments/" + enrichment + "/outputs"
+}
+
+// saveOutputs reads the output values of an enrichment by `tofu output -json` and stores them.
+// It only logs a failure, because the outputs can be read again on the next apply or refresh.
+func saveOutputs(ctx context.Context, trId, enrichment, reqId, workingDir string) {
+
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
+	tfcli.SetChdir(workingDir)
+
+	ret, err := tfcli.Output().Json().Exec()
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to read the outputs (trId: %s, enrichment: %s)", trId, enrichment)
+		return
+	}
+
+	outputs := map[string]struct {
+		Sensitive bool            `json:"sensitive"`
+		Value     json.RawMessage `json:"value"`
+	}{}
+	if err := json.Unmarshal([]byte(ret), &outputs); err != nil {
+		log.Warn().Err(err).Msgf("failed to unmarshal the outputs (trId: %s, enrichment: %s)", trId, enrichment)
+		return
+	}
+
+	values := map[string]json.RawMessage{}
+	for name, output := range outputs {
+		if output.Sensitive {
+			continue
+		}
+		values[name] = output.Value
+	}
+
+	if err := lkvstore.Put(outputsKey(trId, enrichment), values); err != nil {
+		log.Warn().Err(err).Msgf("failed to store the outputs (trId: %s, enrichment: %s)", trId, enrichment)
+	}
+}
+
+// GetOutputs returns the stored output values of an enrichment (key: output name).
+func GetOutputs(trId, enrichment string) (map[string]any, bool, error) {
+
+	outputs := map[string]any{}
+	value, exists := lkvstore.Get(outputsKey(trId, enrichment))
+	if !exists {
+		return outputs, false, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &outputs); err != nil {
+		return outputs, true, fmt.Errorf("failed to unmarshal the outputs (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+	return outputs, true, nil
+}
+
+// ListOutputs returns the stored output values of all the enrichments in a terrarium (key: enrichment).
+func ListOutputs(trId string) (map[string]map[string]any, error) {
+
+	prefix := "/tr/" + trId + "/enrichments/"
+	kvs, err := lkvstore.List(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the outputs (trId: %s): %w", trId, err)
+	}
+
+	ret := map[string]map[string]any{}
+	for _, kv := range kvs {
+		if !strings.HasSuffix(kv.Key, "/outputs") {
+			continue
+		}
+		enrichment := strings.TrimSuffix(strings.TrimPrefix(kv.Key, prefix), "/outputs")
+
+		outputs := map[string]any{}
+		if err := json.Unmarshal([]byte(kv.Value), &outputs); err != nil {
+			log.Warn().Err(err).Msgf("failed to unmarshal the outputs (trId: %s, enrichment: %s)", trId, enrichment)
+			continue
+		}
+		ret[enrichment] = outputs
+	}
+	return ret, nil
+}
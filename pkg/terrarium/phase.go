@@ -0,0 +1,236 @@
+package terrarium
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Phases of enrichments
+ *
+ * An enrichment goes through the phases:
+ * Empty → Initialized → Planned → Provisioning → Ready → Destroying → Destroyed (or Failed).
+ * An operation (e.g., apply, emptyout) is validated against the phase before a tofu command runs,
+ * so that an invalid one (e.g., apply before init, emptyout while resources exist) is rejected clearly.
+ * Provisioning and Destroying last while the command runs; if the command has finished
+ * without updating the phase (e.g., an asynchronous apply, or the server stopped while running),
+ * the phase is settled by the execution status.
+ * The phase of a terrarium is the least progressed phase of its enrichments.
+ */
+
+// Phase of an enrichment (or a terrarium)
+const (
+	PhaseEmpty        = "Empty"
+	PhaseInitialized  = "Initialized"
+	PhasePlanned      = "Planned"
+	PhaseProvisioning = "Provisioning"
+	PhaseReady        = "Ready"
+	PhaseDestroying   = "Destroying"
+	PhaseDestroyed    = "Destroyed"
+	PhaseFailed       = "Failed"
+)
+
+// Operations validated against the phase
+const (
+	OpInit     = "init"
+	OpPlan     = "plan"
+	OpApply    = "apply"
+	OpDestroy  = "destroy"
+	OpRefresh  = "refresh"
+	OpEmptyOut = "emptyout"
+)
+
+// ErrInvalidTransition is returned if an operation is not allowed in the current phase of an enrichment.
+var ErrInvalidTransition = errors.New("invalid phase transition")
+
+// allowedPhases are the phases in which an operation is allowed.
+var allowedPhases = map[string][]string{
+	OpInit:    {PhaseEmpty, PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+	OpPlan:    {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+	OpApply:   {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+	OpDestroy: {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+	OpRefresh: {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+	// Resources may exist in Ready and Failed (e.g., partially applied), so destroy them first
+	OpEmptyOut: {PhaseEmpty, PhaseInitialized, PhasePlanned, PhaseDestroyed},
+}
+
+// terrariumPhaseOrder is the order of the phases from the least progressed one,
+// by which the phase of a terrarium is decided.
+var terrariumPhaseOrder = []string{
+	PhaseFailed, PhaseDestroying, PhaseProvisioning, PhaseEmpty, PhaseInitialized, PhasePlanned, PhaseDestroyed, PhaseReady,
+}
+
+// CheckOperation checks if an operation is allowed in the current phase of an enrichment.
+// If enrichment is empty, it checks all the enrichments of a terrarium.
+func CheckOperation(trId, enrichment, op string) error {
+	trInfo, _, err := GetInfo(trId)
+	if err != nil {
+		return err
+	}
+
+	names := []string{enrichment}
+	if enrichment == "" {
+		names = trInfo.Enrichments.Names()
+	}
+	for _, name := range names {
+		e, exists := trInfo.Enrichments[name]
+		if !exists {
+			return fmt.Errorf("%w (trId: %s, enrichment: %s)", ErrEnrichmentNotFound, trId, name)
+		}
+		if err := checkTransition(trId, e, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartOperation checks if an operation is allowed in the current phase of an enrichment,
+// and moves the enrichment to the phase during the operation (i.e., Provisioning for apply, Destroying for destroy).
+// It returns the phase before the operation, which is passed to FinishOperation.
+func StartOperation(trId, enrichment, op string) (string, error) {
+	previous := ""
+	err := updateEnrichments(trId, func(enrichments model.Enrichments) error {
+		e, exists := enrichments[enrichment]
+		if !exists {
+			return fmt.Errorf("%w (trId: %s, enrichment: %s)", ErrEnrichmentNotFound, trId, enrichment)
+		}
+		if err := checkTransition(trId, e, op); err != nil {
+			return err
+		}
+		previous = e.Phase
+
+		switch op {
+		case OpApply:
+			e.Phase = PhaseProvisioning
+		case OpDestroy:
+			e.Phase = PhaseDestroying
+		}
+		enrichments[enrichment] = e
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+// FinishOperation moves an enrichment to the phase after an operation by its result (opErr).
+// If the operation has not changed anything (e.g., another command is running),
+// the phase before the operation is restored.
+func FinishOperation(trId, enrichment, op, previous string, opErr error) {
+	phase := nextPhase(op, previous, opErr)
+
+	err := updateEnrichments(trId, func(enrichments model.Enrichments) error {
+		e, exists := enrichments[enrichment]
+		if !exists {
+			// e.g., removed while the operation runs
+			return nil
+		}
+		e.Phase = phase
+		enrichments[enrichment] = e
+		return nil
+	})
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to update the phase (trId: %s, enrichment: %s, phase: %s)", trId, enrichment, phase)
+	}
+}
+
+// nextPhase returns the phase after an operation.
+// The phase of an enrichment stored by an older version (i.e., empty) is kept on init and plan,
+// because resources may exist in it.
+func nextPhase(op, previous string, opErr error) string {
+	// The operation has not changed anything (e.g., another command is running, the saved plan is stale)
+	if errors.Is(opErr, tofu.ErrInProgress) || errors.Is(opErr, ErrStalePlan) {
+		return previous
+	}
+
+	switch op {
+	case OpInit, OpPlan:
+		if opErr != nil {
+			return previous
+		}
+		switch previous {
+		case "", PhaseReady, PhaseFailed:
+			return previous
+		}
+		if op == OpInit {
+			return PhaseInitialized
+		}
+		return PhasePlanned
+	case OpApply:
+		if opErr != nil {
+			return PhaseFailed
+		}
+		return PhaseReady
+	case OpDestroy:
+		if opErr != nil {
+			return PhaseFailed
+		}
+		return PhaseDestroyed
+	}
+	return previous
+}
+
+// checkTransition checks if an operation is allowed in the phase of an enrichment.
+// The phase of an enrichment stored by an older version (i.e., empty) is not checked.
+func checkTransition(trId string, e model.EnrichmentInfo, op string) error {
+	if e.Phase == "" {
+		return nil
+	}
+	if slices.Contains(allowedPhases[op], e.Phase) {
+		return nil
+	}
+
+	reason := ""
+	switch {
+	case op == OpEmptyOut && (e.Phase == PhaseReady || e.Phase == PhaseFailed):
+		reason = ", resources may exist, please destroy them first"
+	case e.Phase == PhaseEmpty:
+		reason = ", please init first"
+	case e.Phase == PhaseProvisioning || e.Phase == PhaseDestroying:
+		reason = ", a previous request is still in progress"
+	}
+	return fmt.Errorf("%w, cannot %s the enrichment in %s phase (trId: %s, enrichment: %s)%s",
+		ErrInvalidTransition, op, e.Phase, trId, e.Name, reason)
+}
+
+// settlePhase settles the phase of an enrichment whose command has finished
+// without updating the phase (e.g., an asynchronous apply, or the server stopped while running).
+func settlePhase(phase, status string) string {
+	if phase != PhaseProvisioning && phase != PhaseDestroying {
+		return phase
+	}
+
+	switch status {
+	case "", tofu.StatusRunning:
+		return phase
+	case tofu.StatusSuccess:
+		if phase == PhaseProvisioning {
+			return PhaseReady
+		}
+		return PhaseDestroyed
+	}
+	return PhaseFailed
+}
+
+// terrariumPhase returns the phase of a terrarium, which is the least progressed phase of its enrichments.
+func terrariumPhase(enrichments model.Enrichments) string {
+	if len(enrichments) == 0 {
+		return PhaseEmpty
+	}
+
+	phase := ""
+	rank := len(terrariumPhaseOrder)
+	for _, e := range enrichments {
+		if i := slices.Index(terrariumPhaseOrder, e.Phase); i >= 0 && i < rank {
+			phase = e.Phase
+			rank = i
+		}
+	}
+	return phase
+}
@@ -0,0 +1,236 @@
+package terrarium
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+)
+
+var allPhases = []string{
+	PhaseEmpty, PhaseInitialized, PhasePlanned, PhaseProvisioning, PhaseReady, PhaseDestroying, PhaseDestroyed, PhaseFailed,
+}
+
+func TestCheckTransition(t *testing.T) {
+	// The phases in which an operation is allowed, the others are rejected
+	allowed := map[string][]string{
+		OpInit:     {PhaseEmpty, PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+		OpPlan:     {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+		OpApply:    {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+		OpDestroy:  {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+		OpRefresh:  {PhaseInitialized, PhasePlanned, PhaseReady, PhaseDestroyed, PhaseFailed},
+		OpEmptyOut: {PhaseEmpty, PhaseInitialized, PhasePlanned, PhaseDestroyed},
+	}
+
+	for op, phases := range allowed {
+		for _, phase := range allPhases {
+			t.Run(op+" in "+phase, func(t *testing.T) {
+				err := checkTransition("tr01", model.EnrichmentInfo{Name: "testbed", Phase: phase}, op)
+				if slices.Contains(phases, phase) {
+					if err != nil {
+						t.Errorf("checkTransition() = %v, want nil", err)
+					}
+					return
+				}
+				if !errors.Is(err, ErrInvalidTransition) {
+					t.Errorf("checkTransition() = %v, want %v", err, ErrInvalidTransition)
+				}
+			})
+		}
+
+		// The phase stored by an older version is not checked
+		if err := checkTransition("tr01", model.EnrichmentInfo{Name: "testbed"}, op); err != nil {
+			t.Errorf("checkTransition() of %s without a phase = %v, want nil", op, err)
+		}
+	}
+}
+
+func TestNextPhase(t *testing.T) {
+	errFailed := errors.New("exit status 1")
+
+	tests := []struct {
+		op       string
+		previous string
+		opErr    error
+		want     string
+	}{
+		{op: OpInit, previous: PhaseEmpty, want: PhaseInitialized},
+		{op: OpInit, previous: PhaseEmpty, opErr: errFailed, want: PhaseEmpty},
+		{op: OpInit, previous: PhaseReady, want: PhaseReady},
+		{op: OpInit, previous: PhaseFailed, want: PhaseFailed},
+		{op: OpInit, previous: "", want: ""},
+		{op: OpPlan, previous: PhaseInitialized, want: PhasePlanned},
+		{op: OpPlan, previous: PhaseDestroyed, want: PhasePlanned},
+		{op: OpPlan, previous: PhaseReady, want: PhaseReady},
+		{op: OpPlan, previous: PhaseInitialized, opErr: errFailed, want: PhaseInitialized},
+		{op: OpApply, previous: PhasePlanned, want: PhaseReady},
+		{op: OpApply, previous: PhasePlanned, opErr: errFailed, want: PhaseFailed},
+		{op: OpApply, previous: PhasePlanned, opErr: fmt.Errorf("wrapped: %w", tofu.ErrInProgress), want: PhasePlanned},
+		{op: OpApply, previous: PhasePlanned, opErr: ErrStalePlan, want: PhasePlanned},
+		{op: OpDestroy, previous: PhaseReady, want: PhaseDestroyed},
+		{op: OpDestroy, previous: PhaseReady, opErr: errFailed, want: PhaseFailed},
+		{op: OpDestroy, previous: PhaseReady, opErr: tofu.ErrInProgress, want: PhaseReady},
+		{op: OpRefresh, previous: PhaseReady, want: PhaseReady},
+		{op: OpRefresh, previous: PhaseReady, opErr: errFailed, want: PhaseReady},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s in %s (%v)", tt.op, tt.previous, tt.opErr), func(t *testing.T) {
+			if got := nextPhase(tt.op, tt.previous, tt.opErr); got != tt.want {
+				t.Errorf("nextPhase() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettlePhase(t *testing.T) {
+	tests := []struct {
+		phase  string
+		status string
+		want   string
+	}{
+		{phase: PhaseProvisioning, status: tofu.StatusRunning, want: PhaseProvisioning},
+		{phase: PhaseProvisioning, status: "", want: PhaseProvisioning},
+		{phase: PhaseProvisioning, status: tofu.StatusSuccess, want: PhaseReady},
+		{phase: PhaseProvisioning, status: tofu.StatusFailed, want: PhaseFailed},
+		{phase: PhaseProvisioning, status: tofu.StatusInterrupted, want: PhaseFailed},
+		{phase: PhaseDestroying, status: tofu.StatusSuccess, want: PhaseDestroyed},
+		{phase: PhaseDestroying, status: tofu.StatusCancelled, want: PhaseFailed},
+		{phase: PhaseReady, status: tofu.StatusFailed, want: PhaseReady},
+		{phase: PhasePlanned, status: tofu.StatusSuccess, want: PhasePlanned},
+	}
+
+	for _, tt := range tests {
+		if got := settlePhase(tt.phase, tt.status); got != tt.want {
+			t.Errorf("settlePhase(%q, %q) = %q, want %q", tt.phase, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestTerrariumPhase(t *testing.T) {
+	enrichments := func(phases ...string) model.Enrichments {
+		e := model.Enrichments{}
+		for i, phase := range phases {
+			name := fmt.Sprintf("e%d", i)
+			e[name] = model.EnrichmentInfo{Name: name, Phase: phase}
+		}
+		return e
+	}
+
+	tests := []struct {
+		enrichments model.Enrichments
+		want        string
+	}{
+		{enrichments: enrichments(), want: PhaseEmpty},
+		{enrichments: enrichments(PhaseReady), want: PhaseReady},
+		{enrichments: enrichments(PhaseReady, PhasePlanned), want: PhasePlanned},
+		{enrichments: enrichments(PhaseReady, PhaseDestroyed), want: PhaseDestroyed},
+		{enrichments: enrichments(PhaseReady, PhaseProvisioning, PhaseEmpty), want: PhaseProvisioning},
+		{enrichments: enrichments(PhaseDestroying, PhaseFailed), want: PhaseFailed},
+		{enrichments: enrichments(""), want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := terrariumPhase(tt.enrichments); got != tt.want {
+			t.Errorf("terrariumPhase(%v) = %q, want %q", tt.enrichments, got, tt.want)
+		}
+	}
+}
+
+func TestStartAndFinishOperation(t *testing.T) {
+	if err := lkvstore.Init(lkvstore.Config{DbFilePath: filepath.Join(t.TempDir(), "lkvstore.db")}); err != nil {
+		t.Fatalf("failed to init the store: %v", err)
+	}
+	t.Cleanup(func() { lkvstore.Close() })
+
+	trInfo := model.TerrariumInfo{
+		Id:          "tr01",
+		Name:        "tr01",
+		Enrichments: model.Enrichments{"testbed": {Name: "testbed", Phase: PhaseEmpty}},
+	}
+	if err := IssueID(trInfo); err != nil {
+		t.Fatalf("failed to issue the terrarium: %v", err)
+	}
+
+	phaseOf := func() string {
+		t.Helper()
+		trInfo, _, err := GetInfo("tr01")
+		if err != nil {
+			t.Fatalf("failed to get the terrarium: %v", err)
+		}
+		return trInfo.Enrichments["testbed"].Phase
+	}
+	run := func(op string, opErr error) error {
+		t.Helper()
+		previous, err := StartOperation("tr01", "testbed", op)
+		if err != nil {
+			return err
+		}
+		FinishOperation("tr01", "testbed", op, previous, opErr)
+		return nil
+	}
+
+	// Apply before init is rejected
+	if err := run(OpApply, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("apply in %s = %v, want %v", PhaseEmpty, err, ErrInvalidTransition)
+	}
+	if err := run(OpInit, nil); err != nil {
+		t.Fatalf("init = %v", err)
+	}
+	if err := run(OpPlan, nil); err != nil {
+		t.Fatalf("plan = %v", err)
+	}
+	if phase := phaseOf(); phase != PhasePlanned {
+		t.Fatalf("phase after plan = %s, want %s", phase, PhasePlanned)
+	}
+
+	// Another operation is rejected while applying
+	previous, err := StartOperation("tr01", "testbed", OpApply)
+	if err != nil {
+		t.Fatalf("apply = %v", err)
+	}
+	if phase := phaseOf(); phase != PhaseProvisioning {
+		t.Errorf("phase while applying = %s, want %s", phase, PhaseProvisioning)
+	}
+	for _, op := range []string{OpApply, OpDestroy, OpEmptyOut} {
+		if _, err := StartOperation("tr01", "testbed", op); !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s while applying = %v, want %v", op, err, ErrInvalidTransition)
+		}
+	}
+	if err := CheckOperation("tr01", "", OpRefresh); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("refresh of the terrarium while applying = %v, want %v", err, ErrInvalidTransition)
+	}
+	FinishOperation("tr01", "testbed", OpApply, previous, nil)
+	if phase := phaseOf(); phase != PhaseReady {
+		t.Fatalf("phase after apply = %s, want %s", phase, PhaseReady)
+	}
+
+	// Emptying out is rejected while resources may exist
+	if err := run(OpEmptyOut, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("emptyout in %s = %v, want %v", PhaseReady, err, ErrInvalidTransition)
+	}
+	if err := run(OpDestroy, errors.New("exit status 1")); err != nil {
+		t.Fatalf("destroy = %v", err)
+	}
+	if phase := phaseOf(); phase != PhaseFailed {
+		t.Fatalf("phase after a failed destroy = %s, want %s", phase, PhaseFailed)
+	}
+	if err := run(OpEmptyOut, nil); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("emptyout in %s = %v, want %v", PhaseFailed, err, ErrInvalidTransition)
+	}
+	if err := run(OpDestroy, nil); err != nil {
+		t.Fatalf("destroy = %v", err)
+	}
+	if err := CheckOperation("tr01", "testbed", OpEmptyOut); err != nil {
+		t.Errorf("emptyout in %s = %v, want nil", phaseOf(), err)
+	}
+
+	if err := CheckOperation("tr01", "vpn/site-to-site", OpApply); !errors.Is(err, ErrEnrichmentNotFound) {
+		t.Errorf("apply of a missing enrichment = %v, want %v", err, ErrEnrichmentNotFound)
+	}
+}
@@ -0,0 +1,314 @@
+package terrarium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Saved plans
+ */
+
+// Status of a saved plan
+const (
+	PlanStatusPlanned         = "Planned"
+	PlanStatusPendingApproval = "PendingApproval"
+	PlanStatusApproved        = "Approved"
+	PlanStatusRejected        = "Rejected"
+	PlanStatusExpired         = "Expired"
+	PlanStatusApplied         = "Applied"
+	PlanStatusStale           = "Stale"
+	PlanStatusFailed          = "Failed"
+)
+
+// defaultApprovalExpiry is the time to apply an approved plan
+// if the configuration does not specify one.
+const defaultApprovalExpiry = 60 * time.Minute
+
+var (
+	// ErrPlanNotFound is returned when a saved plan does not exist.
+	ErrPlanNotFound = errors.New("plan not found")
+	// ErrStalePlan is returned when the state has changed since a plan was saved.
+	ErrStalePlan = errors.New("plan is stale")
+	// ErrPlanNotApplicable is returned when a plan cannot be applied (e.g., already applied).
+	ErrPlanNotApplicable = errors.New("plan is not applicable")
+	// ErrPlanNotApproved is returned when a plan requiring approval is applied before it is approved.
+	ErrPlanNotApproved = errors.New("plan is not approved")
+	// ErrPlanExpired is returned when the approval of a plan has expired.
+	ErrPlanExpired = errors.New("approval of the plan has expired")
+	// ErrPlanNotPending is returned when a plan not pending approval is approved or rejected.
+	ErrPlanNotPending = errors.New("plan is not pending approval")
+	// ErrSelfApproval is returned when the creator of a plan approves the plan.
+	ErrSelfApproval = errors.New("plan cannot be approved by its creator")
+)
+
+// ApprovalExpiry returns the time to apply an approved plan.
+func ApprovalExpiry() time.Duration {
+	if config.Terrarium.Approval.ExpiryMin > 0 {
+		return time.Duration(config.Terrarium.Approval.ExpiryMin) * time.Minute
+	}
+	return defaultApprovalExpiry
+}
+
+func planKey(trId, planId string) string {
+	return "/plan/" + trId + "/" + planId
+}
+
+// planFile returns the path of a saved plan relative to the terrarium environment.
+func planFile(planId string) string {
+	return "plans/" + planId + ".tfplan"
+}
+
+// GetPlan reads a saved plan of a terrarium
+func GetPlan(trId, planId string) (model.Plan, bool, error) {
+
+	plan := model.Plan{}
+	value, exists := lkvstore.Get(planKey(trId, planId))
+	if !exists {
+		return plan, false, nil
+	}
+
+	err := json.Unmarshal([]byte(value), &plan)
+	if err != nil {
+		return plan, true, fmt.Errorf("failed to unmarshal plan: %w", err)
+	}
+
+	return plan, true, nil
+}
+
+// ListPlans reads the saved plans of a terrarium in order of their creation time
+func ListPlans(trId string) ([]model.Plan, error) {
+
+	plans := []model.Plan{}
+	values, exists := lkvstore.GetWithPrefix("/plan/" + trId + "/")
+	if !exists {
+		return plans, nil
+	}
+
+	for _, value := range values {
+		plan := model.Plan{}
+		err := json.Unmarshal([]byte(value), &plan)
+		if err != nil {
+			log.Debug().Msgf("failed to unmarshal plan: %v", err)
+			continue
+		}
+		plans = append(plans, plan)
+	}
+
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].CreatedAt.Before(plans[j].CreatedAt)
+	})
+
+	return plans, nil
+}
+
+// DeletePlans deletes the saved plans of a terrarium
+func DeletePlans(trId string) error {
+
+	plans, err := ListPlans(trId)
+	if err != nil {
+		return err
+	}
+	for _, plan := range plans {
+		lkvstore.Delete(planKey(trId, plan.Id))
+	}
+
+	return nil
+}
+
+// ApprovePlan approves a plan pending approval, so that it can be applied until the approval expires.
+// The approver must be different from the creator of the plan.
+// Only one of concurrent reviews (e.g., approve and reject) succeeds, and the others fail with ErrPlanNotPending.
+func ApprovePlan(trId, planId, approver, comment string) (model.Plan, error) {
+
+	plan, err := updatePlan(trId, planId, func(plan *model.Plan) error {
+		if err := checkPending(*plan); err != nil {
+			return err
+		}
+		if approver == plan.CreatedBy {
+			return fmt.Errorf("%w (planId: %s, approver: %s)", ErrSelfApproval, planId, approver)
+		}
+
+		now := time.Now()
+		expiresAt := now.Add(ApprovalExpiry())
+		plan.Status = PlanStatusApproved
+		plan.ApprovedBy = approver
+		plan.ReviewedAt = &now
+		plan.Comment = comment
+		plan.ExpiresAt = &expiresAt
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	log.Info().Msgf("Plan approved (trId: %s, planId: %s, approver: %s)", trId, planId, approver)
+	return plan, nil
+}
+
+// RejectPlan rejects a plan pending approval, so that it cannot be applied.
+func RejectPlan(trId, planId, rejecter, comment string) (model.Plan, error) {
+
+	plan, err := updatePlan(trId, planId, func(plan *model.Plan) error {
+		if err := checkPending(*plan); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		plan.Status = PlanStatusRejected
+		plan.RejectedBy = rejecter
+		plan.ReviewedAt = &now
+		plan.Comment = comment
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+
+	log.Info().Msgf("Plan rejected (trId: %s, planId: %s, rejecter: %s)", trId, planId, rejecter)
+	return plan, nil
+}
+
+// checkPending checks if a plan is pending approval.
+func checkPending(plan model.Plan) error {
+	if plan.Status != PlanStatusPendingApproval {
+		return fmt.Errorf("%w, the plan (planId: %s) is %s", ErrPlanNotPending, plan.Id, strings.ToLower(plan.Status))
+	}
+	return nil
+}
+
+// updatePlan updates a saved plan only if it has not changed since it was read (i.e., compare-and-swap),
+// and retries with the latest one otherwise, whose status is checked again by update.
+func updatePlan(trId, planId string, update func(*model.Plan) error) (model.Plan, error) {
+	for {
+		plan := model.Plan{}
+		value, exists := lkvstore.Get(planKey(trId, planId))
+		if !exists {
+			return plan, fmt.Errorf("%w (trId: %s, planId: %s)", ErrPlanNotFound, trId, planId)
+		}
+		if err := json.Unmarshal([]byte(value), &plan); err != nil {
+			return plan, fmt.Errorf("failed to unmarshal plan: %w", err)
+		}
+
+		if err := update(&plan); err != nil {
+			return plan, err
+		}
+
+		swapped, err := lkvstore.CompareAndSwap(planKey(trId, planId), value, plan)
+		if err != nil {
+			return plan, fmt.Errorf("failed to update the plan (trId: %s, planId: %s): %w", trId, planId, err)
+		}
+		if swapped {
+			return plan, nil
+		}
+	}
+}
+
+// updatePlanStatus changes the status of a saved plan only if it is still the status read before (from),
+// e.g., not to mark a plan applied by another request as stale.
+func updatePlanStatus(trId, planId, from string, update func(*model.Plan)) error {
+	_, err := updatePlan(trId, planId, func(plan *model.Plan) error {
+		if plan.Status != from {
+			return fmt.Errorf("the plan (planId: %s) has become %s in the meantime", planId, strings.ToLower(plan.Status))
+		}
+		update(plan)
+		return nil
+	})
+	return err
+}
+
+func savePlan(plan model.Plan) error {
+	return lkvstore.Put(planKey(plan.TrId, plan.Id), plan)
+}
+
+// stateVersion reads the serial and the lineage of the state in a terrarium environment.
+// A saved plan is stale if either of them has changed since the plan was saved.
+func stateVersion(workingDir string) (int64, string, error) {
+
+	b, err := os.ReadFile(workingDir + "/terraform.tfstate")
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read the state: %w", err)
+	}
+
+	state := struct {
+		Serial  int64  `json:"serial"`
+		Lineage string `json:"lineage"`
+	}{}
+	if err := json.Unmarshal(b, &state); err != nil {
+		return 0, "", fmt.Errorf("failed to unmarshal the state: %w", err)
+	}
+
+	return state.Serial, state.Lineage, nil
+}
+
+// summarizePlan summarizes the resource changes of a plan from the output of `tofu show -json`.
+func summarizePlan(showJson string) (model.PlanSummary, error) {
+
+	summary := model.PlanSummary{}
+	plan := struct {
+		ResourceChanges []struct {
+			Address      string `json:"address"`
+			Mode         string `json:"mode"`
+			Type         string `json:"type"`
+			Name         string `json:"name"`
+			ProviderName string `json:"provider_name"`
+			Change       struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}{}
+	// Decode the JSON document only, because the output may include the messages printed to stderr
+	start := strings.Index(showJson, "{")
+	if start < 0 {
+		return summary, errors.New("failed to find the plan in the output")
+	}
+	if err := json.NewDecoder(strings.NewReader(showJson[start:])).Decode(&plan); err != nil {
+		return summary, fmt.Errorf("failed to unmarshal the plan: %w", err)
+	}
+
+	for _, rc := range plan.ResourceChanges {
+		// Skip data sources, which are read but not changed
+		if rc.Mode == "data" {
+			continue
+		}
+
+		actions := rc.Change.Actions
+		switch {
+		case len(actions) == 2:
+			// ["delete", "create"] or ["create", "delete"]
+			summary.Replace++
+		case len(actions) == 1 && actions[0] == "create":
+			summary.Add++
+		case len(actions) == 1 && actions[0] == "update":
+			summary.Change++
+		case len(actions) == 1 && actions[0] == "delete":
+			summary.Destroy++
+		default:
+			// no-op or read
+			continue
+		}
+
+		summary.ResourceChanges = append(summary.ResourceChanges, model.PlannedResourceChange{
+			Address:      rc.Address,
+			Type:         rc.Type,
+			Name:         rc.Name,
+			ProviderName: rc.ProviderName,
+			Actions:      actions,
+		})
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,255 @@
+package terrarium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu/tfclient"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Recovery at startup
+ *
+ * If the server stops while running a command, the request and the terrarium are left Running,
+ * and the following requests fail with "a previous request is still in progress".
+ * The recovery finds such requests of this host whose process no longer exists,
+ * marks them (and their terrariums) Interrupted, and checks the state locks left by them.
+ */
+
+// Actions of the recovery
+const (
+	RecoveryActionInterrupted = "Interrupted"
+	RecoveryActionSkipped     = "Skipped"
+)
+
+// stateLockFile is the lock file of the local backend in a working directory.
+const stateLockFile = ".terraform.tfstate.lock.info"
+
+var (
+	recoveryMu     sync.Mutex
+	recoveryReport *model.RecoveryReport
+)
+
+// GetRecoveryReport returns the report of the last recovery, if any.
+func GetRecoveryReport() (model.RecoveryReport, bool) {
+	recoveryMu.Lock()
+	defer recoveryMu.Unlock()
+
+	if recoveryReport == nil {
+		return model.RecoveryReport{}, false
+	}
+	return *recoveryReport, true
+}
+
+// FilterRecoveryReport returns the report of the terrariums held by a given credential holder only.
+func FilterRecoveryReport(report model.RecoveryReport, holder string) model.RecoveryReport {
+	held := map[string]bool{}
+	isHeld := func(trId string) bool {
+		if _, checked := held[trId]; !checked {
+			held[trId] = ValidateCredentialProfile(trId, holder) == nil
+		}
+		return held[trId]
+	}
+
+	filtered := report
+	filtered.Requests = []model.RecoveredRequest{}
+	for _, r := range report.Requests {
+		if isHeld(r.TrId) {
+			filtered.Requests = append(filtered.Requests, r)
+		}
+	}
+	filtered.Terrariums = []model.RecoveredTerrarium{}
+	for _, t := range report.Terrariums {
+		if isHeld(t.TrId) {
+			filtered.Terrariums = append(filtered.Terrariums, t)
+		}
+	}
+	return filtered
+}
+
+// Recover reconciles the requests and terrariums left running by a previous server process.
+// The state locks left by the interrupted requests are released if force unlock is configured.
+// It must be called at startup before serving requests.
+func Recover(ctx context.Context) (model.RecoveryReport, error) {
+	recoveryMu.Lock()
+	defer recoveryMu.Unlock()
+
+	host := job.Hostname()
+	report := model.RecoveryReport{
+		Host:        host,
+		StartedAt:   time.Now(),
+		ForceUnlock: config.Terrarium.Recovery.ForceUnlock,
+		Requests:    []model.RecoveredRequest{},
+		Terrariums:  []model.RecoveredTerrarium{},
+	}
+
+	jobs, err := job.ListAll()
+	if err != nil {
+		return report, fmt.Errorf("failed to list the requests: %w", err)
+	}
+
+	// Requests still running (i.e., their process still exists)
+	alive := map[string]bool{}
+	type interruptedJob struct {
+		index int
+		job   model.Job
+	}
+	interrupted := []interruptedJob{}
+	for _, j := range jobs {
+		if j.Status != job.StatusRunning {
+			continue
+		}
+		// Skip the requests of other servers sharing the store
+		if j.Host != "" && j.Host != host {
+			continue
+		}
+
+		rr := model.RecoveredRequest{
+			TrId:       j.TrId,
+			ReqId:      j.ReqId,
+			Enrichment: j.Enrichment,
+			Command:    j.Command,
+			Pid:        j.Pid,
+			StartedAt:  j.StartedAt,
+		}
+
+		if tofu.ProcessExists(j.Pid) {
+			// The process may have outlived the server, but it cannot be tracked anymore
+			log.Warn().Msgf("The process (pid: %d) of the request (trId: %s, reqId: %s) still exists", j.Pid, j.TrId, j.ReqId)
+			alive[j.TrId+"/"+j.ReqId] = true
+			rr.Action = RecoveryActionSkipped
+			report.Requests = append(report.Requests, rr)
+			continue
+		}
+
+		rr.Action = RecoveryActionInterrupted
+		if err := job.Interrupt(j.TrId, j.ReqId, "interrupted by the restart of the server"); err != nil {
+			rr.Error = err.Error()
+		}
+		interrupted = append(interrupted, interruptedJob{index: len(report.Requests), job: j})
+		log.Info().Msgf("Recovered the request (trId: %s, reqId: %s) interrupted by the restart", j.TrId, j.ReqId)
+		report.Requests = append(report.Requests, rr)
+	}
+
+	trInfoList, err := ReadAllInfo()
+	if err != nil {
+		return report, fmt.Errorf("failed to list the terrariums: %w", err)
+	}
+
+	for _, trInfo := range trInfoList {
+		statuses, err := tofu.ListExecutionStatus(trInfo.Id)
+		if err != nil {
+			log.Warn().Err(err).Msgf("failed to get the execution status (trId: %s)", trInfo.Id)
+			continue
+		}
+
+		// Recover each enrichment (or the terrarium itself, if the key is empty)
+		enrichments := make([]string, 0, len(statuses))
+		for enrichment, status := range statuses {
+			if status == tofu.StatusRunning {
+				enrichments = append(enrichments, enrichment)
+			}
+		}
+		sort.Strings(enrichments)
+
+		for _, enrichment := range enrichments {
+			rt := model.RecoveredTerrarium{TrId: trInfo.Id, Enrichment: enrichment}
+			if lock, exists := tofu.GetExecutionLock(trInfo.Id, enrichment); exists {
+				rt.ReqId = lock.ReqId
+				// Skip the terrariums locked by other servers sharing the store
+				if lock.Host != "" && lock.Host != host {
+					continue
+				}
+				if alive[trInfo.Id+"/"+lock.ReqId] {
+					rt.Action = RecoveryActionSkipped
+					report.Terrariums = append(report.Terrariums, rt)
+					continue
+				}
+			}
+
+			rt.Action = RecoveryActionInterrupted
+			released, err := tofu.InterruptExecution(trInfo.Id, enrichment)
+			if err != nil {
+				rt.Error = err.Error()
+			} else if !released {
+				rt.Error = "the execution status has changed during the recovery"
+			}
+			log.Info().Msgf("Recovered the terrarium (trId: %s, enrichment: %s) interrupted by the restart", trInfo.Id, enrichment)
+			report.Terrariums = append(report.Terrariums, rt)
+		}
+	}
+
+	// Check the state locks after the execution locks are released,
+	// because force-unlock is also a command executed in the terrarium
+	for _, ij := range interrupted {
+		report.Requests[ij.index].StateLock = recoverStateLock(ctx, ij.job)
+	}
+
+	report.FinishedAt = time.Now()
+	recoveryReport = &report
+
+	return report, nil
+}
+
+// recoverStateLock checks the state lock left in the working directory of an interrupted request
+// and releases it by `tofu force-unlock` if force unlock is configured.
+// Only the lock of the local backend can be checked.
+func recoverStateLock(ctx context.Context, j model.Job) *model.StateLock {
+	workingDir := job.WorkingDir(j)
+	b, err := os.ReadFile(filepath.Join(workingDir, stateLockFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return &model.StateLock{Error: fmt.Sprintf("failed to read the state lock: %v", err)}
+	}
+
+	info := struct {
+		ID        string `json:"ID"`
+		Operation string `json:"Operation"`
+		Who       string `json:"Who"`
+		Created   string `json:"Created"`
+	}{}
+	if err := json.Unmarshal(b, &info); err != nil {
+		return &model.StateLock{Error: fmt.Sprintf("failed to unmarshal the state lock: %v", err)}
+	}
+
+	stateLock := &model.StateLock{
+		Id:        info.ID,
+		Operation: info.Operation,
+		Who:       info.Who,
+		Created:   info.Created,
+	}
+	log.Warn().Msgf("The state lock (id: %s) is left in the terrarium (trId: %s, dir: %s)", info.ID, j.TrId, workingDir)
+
+	if !config.Terrarium.Recovery.ForceUnlock {
+		return stateLock
+	}
+
+	reqId := fmt.Sprintf("%d", time.Now().UnixNano())
+	_, err = tfclient.NewClient(ctx, j.TrId, reqId).
+		SetChdir(workingDir).
+		ForceUnlock().
+		SetArgs("-force", info.ID).
+		Exec()
+	if err != nil {
+		stateLock.Error = fmt.Sprintf("failed to force-unlock the state: %v", err)
+		return stateLock
+	}
+	stateLock.Unlocked = true
+	log.Info().Msgf("Released the state lock (id: %s) of the terrarium (trId: %s)", info.ID, j.TrId)
+
+	return stateLock
+}
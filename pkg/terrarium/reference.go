@@ -0,0 +1,314 @@
+package terrarium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	tfutil "github.com/cloud-barista/mc-terrarium/pkg/tofu/util"
+	"github.com/rs/zerolog/log"
+	"github.com/tidwall/gjson"
+)
+
+/*
+ * [Note] References to the outputs of enrichments
+ *
+ * A request (or tfvars) can reference an output of another enrichment in the terrarium
+ * instead of copying it by hand, e.g., {"$ref": "testbed.aws_testbed_info.vpc_id"}
+ * ({enrichment}.{output}[.{path}], where the path is a gjson path in the output value).
+ * The references are resolved from the stored outputs (see saveOutputs) when a request is made,
+ * and kept to be resolved again when the enrichment is planned or applied (i.e., updated),
+ * so that it follows the changes of the referenced outputs.
+ */
+
+const refKey = "$ref"
+
+var (
+	// ErrInvalidReference is returned if a reference is malformed.
+	ErrInvalidReference = errors.New("invalid reference")
+	// ErrUnresolvedReference is returned if a referenced output does not exist (e.g., not applied yet).
+	ErrUnresolvedReference = errors.New("unresolved reference")
+)
+
+func referencesKey(trId, enrichment string) string {
+	return "/tr/" + trId + "/enrichments/" + enrichment + "/references"
+}
+
+// ResolveReferences replaces the references to the outputs of the other enrichments in a JSON document
+// with the output values, and returns the resolved document and the references found.
+// An enrichment cannot reference its own outputs.
+func ResolveReferences(trId, enrichment string, data []byte) ([]byte, []model.OutputReference, error) {
+
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal the document: %w", err)
+	}
+
+	r := resolver{trId: trId, self: enrichment, outputs: map[string]string{}}
+	resolved, err := r.resolve(doc, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(r.refs) == 0 {
+		return data, nil, nil
+	}
+
+	b, err := json.Marshal(resolved)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal the resolved document: %w", err)
+	}
+	return b, r.refs, nil
+}
+
+// SaveReferences keeps the references in the tfvars of an enrichment to resolve them again on update.
+func SaveReferences(trId, enrichment string, refs []model.OutputReference) error {
+	if len(refs) == 0 {
+		return lkvstore.Delete(referencesKey(trId, enrichment))
+	}
+	return lkvstore.Put(referencesKey(trId, enrichment), refs)
+}
+
+// GetReferences returns the references in the tfvars of an enrichment.
+func GetReferences(trId, enrichment string) ([]model.OutputReference, error) {
+	refs := []model.OutputReference{}
+	value, exists := lkvstore.Get(referencesKey(trId, enrichment))
+	if !exists {
+		return refs, nil
+	}
+	if err := json.Unmarshal([]byte(value), &refs); err != nil {
+		return refs, fmt.Errorf("failed to unmarshal the references (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+	return refs, nil
+}
+
+// DestroyOrder returns the names of the enrichments of a terrarium in order to destroy them,
+// i.e., the dependents (referencing the outputs of another enrichment) before their dependencies,
+// and in order of their names otherwise. The enrichments in a cycle of references, if any, are in order of their names.
+func DestroyOrder(trId string, names []string) ([]string, error) {
+
+	// dependents counts the remaining enrichments referencing each enrichment
+	dependents := map[string]int{}
+	dependencies := map[string][]string{}
+	for _, name := range names {
+		dependents[name] += 0
+	}
+	for _, name := range names {
+		refs, err := GetReferences(trId, name)
+		if err != nil {
+			return nil, err
+		}
+		seen := map[string]bool{}
+		for _, ref := range refs {
+			dependency, _, _ := strings.Cut(ref.Ref, ".")
+			if _, ok := dependents[dependency]; !ok || dependency == name || seen[dependency] {
+				continue
+			}
+			seen[dependency] = true
+			dependencies[name] = append(dependencies[name], dependency)
+			dependents[dependency]++
+		}
+	}
+
+	remaining := append([]string{}, names...)
+	sort.Strings(remaining)
+	order := make([]string, 0, len(names))
+	for len(remaining) > 0 {
+		next := remaining[:0]
+		progressed := false
+		for _, name := range remaining {
+			if dependents[name] > 0 {
+				next = append(next, name)
+				continue
+			}
+			order = append(order, name)
+			progressed = true
+			for _, dependency := range dependencies[name] {
+				dependents[dependency]--
+			}
+		}
+		remaining = next
+		if !progressed {
+			log.Warn().Msgf("a cycle of references among the enrichments (trId: %s, enrichments: %s)", trId, strings.Join(remaining, ", "))
+			order = append(order, remaining...)
+			break
+		}
+	}
+	return order, nil
+}
+
+// resolveTfVarsReferences resolves the references kept for an enrichment again
+// and updates the tfvars in the working directory with the current output values.
+func resolveTfVarsReferences(trId, enrichment, workingDir string) error {
+
+	refs, err := GetReferences(trId, enrichment)
+	if err != nil || len(refs) == 0 {
+		return err
+	}
+
+	tfVarsPath := workingDir + "/terraform.tfvars.json"
+	b, err := os.ReadFile(tfVarsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the tfvars (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+	var tfVars any
+	if err := json.Unmarshal(b, &tfVars); err != nil {
+		return fmt.Errorf("failed to unmarshal the tfvars (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+
+	r := resolver{trId: trId, self: enrichment, outputs: map[string]string{}}
+	for _, ref := range refs {
+		value, err := r.lookup(ref.Ref)
+		if err != nil {
+			return err
+		}
+		if err := setPath(tfVars, ref.Path, value); err != nil {
+			return fmt.Errorf("%w, failed to set %s in the tfvars: %w", ErrInvalidReference, ref.Path, err)
+		}
+	}
+
+	if err := tfutil.SaveTfVars(tfVars, tfVarsPath); err != nil {
+		return err
+	}
+	log.Debug().Msgf("resolved %d reference(s) in the tfvars (trId: %s, enrichment: %s)", len(refs), trId, enrichment)
+	return nil
+}
+
+// resolver resolves the references in a terrarium, reading the outputs of each enrichment once.
+type resolver struct {
+	trId    string
+	self    string
+	outputs map[string]string // key: enrichment, value: outputs in JSON
+	refs    []model.OutputReference
+}
+
+// resolve walks a JSON value and replaces the references with the output values.
+func (r *resolver) resolve(v any, path string) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		if ref, ok := t[refKey]; ok {
+			s, isString := ref.(string)
+			if !isString || len(t) != 1 {
+				return nil, fmt.Errorf("%w at %s, it must be {\"%s\": \"{enrichment}.{output}[.{path}]\"}", ErrInvalidReference, pathOrRoot(path), refKey)
+			}
+			value, err := r.lookup(s)
+			if err != nil {
+				return nil, err
+			}
+			r.refs = append(r.refs, model.OutputReference{Path: path, Ref: s})
+			return value, nil
+		}
+
+		// Walk in order to list the references in order
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			resolved, err := r.resolve(t[k], joinPath(path, k))
+			if err != nil {
+				return nil, err
+			}
+			t[k] = resolved
+		}
+		return t, nil
+
+	case []any:
+		for i := range t {
+			resolved, err := r.resolve(t[i], joinPath(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			t[i] = resolved
+		}
+		return t, nil
+	}
+	return v, nil
+}
+
+// lookup returns the output value referenced by {enrichment}.{output}[.{path}].
+func (r *resolver) lookup(ref string) (any, error) {
+
+	enrichment, outputPath, found := strings.Cut(ref, ".")
+	if !found || enrichment == "" || outputPath == "" {
+		return nil, fmt.Errorf("%w (%s), it must be {enrichment}.{output}[.{path}]", ErrInvalidReference, ref)
+	}
+	if enrichment == r.self {
+		return nil, fmt.Errorf("%w (%s), an enrichment cannot reference its own outputs", ErrInvalidReference, ref)
+	}
+
+	outputs, ok := r.outputs[enrichment]
+	if !ok {
+		value, exists := lkvstore.Get(outputsKey(r.trId, enrichment))
+		if !exists {
+			return nil, fmt.Errorf("%w (%s), no outputs of the enrichment (trId: %s, enrichment: %s)", ErrUnresolvedReference, ref, r.trId, enrichment)
+		}
+		outputs = value
+		r.outputs[enrichment] = outputs
+	}
+
+	result := gjson.Get(outputs, outputPath)
+	if !result.Exists() {
+		return nil, fmt.Errorf("%w (%s), no such output in the enrichment (trId: %s, enrichment: %s)", ErrUnresolvedReference, ref, r.trId, enrichment)
+	}
+	return result.Value(), nil
+}
+
+// setPath sets a value at a dot-separated path (e.g., aws.vpc_id, subnets.0.id) in a JSON value.
+func setPath(doc any, path string, value any) error {
+	if path == "" {
+		return errors.New("empty path")
+	}
+
+	keys := strings.Split(path, ".")
+	current := doc
+	for i, key := range keys {
+		last := i == len(keys)-1
+		switch t := current.(type) {
+		case map[string]any:
+			if last {
+				t[key] = value
+				return nil
+			}
+			next, ok := t[key]
+			if !ok {
+				next = map[string]any{}
+				t[key] = next
+			}
+			current = next
+		case []any:
+			idx, err := strconv.Atoi(key)
+			if err != nil || idx < 0 || idx >= len(t) {
+				return fmt.Errorf("invalid index (%s)", key)
+			}
+			if last {
+				t[idx] = value
+				return nil
+			}
+			current = t[idx]
+		default:
+			return fmt.Errorf("not an object or an array at %s", strings.Join(keys[:i], "."))
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
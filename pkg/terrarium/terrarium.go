@@ -1,14 +1,18 @@
 package terrarium
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
 	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
 	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
 	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
 	"github.com/cloud-barista/mc-terrarium/pkg/tofu/tfclient"
@@ -20,18 +24,28 @@ import (
  * [Note] Terrarium Management
  */
 
+// ErrTerrariumExists is returned if a terrarium of the same ID already exists.
+var ErrTerrariumExists = errors.New("the terrarium already exists")
+
+// ErrTerrariumNotFound is returned if no terrarium has the given ID.
+var ErrTerrariumNotFound = errors.New("no terrarium with the given ID")
+
 // IssueID issues a terrarium ID
 func IssueID(trInfo model.TerrariumInfo) error {
 
 	log.Debug().Msgf("trInfo: %v", trInfo)
-	// Check if the terrarium already exists
-	if value, exists := lkvstore.Get("/tr/" + trInfo.Id); exists {
-		log.Debug().Msgf("value: %v", value)
-		return fmt.Errorf("the terrarium (trId: %s) already exist", trInfo.Id)
+	if trInfo.CreatedAt.IsZero() {
+		trInfo.CreatedAt = time.Now()
+	}
+	// Save the terrarium info only if the terrarium does not exist
+	// (checked and saved atomically not to issue the same ID to concurrent requests)
+	issued, err := lkvstore.PutIfAbsent("/tr/"+trInfo.Id, trInfo)
+	if err != nil {
+		return fmt.Errorf("failed to issue the terrarium ID (trId: %s): %w", trInfo.Id, err)
+	}
+	if !issued {
+		return fmt.Errorf("%w (trId: %s)", ErrTerrariumExists, trInfo.Id)
 	}
-
-	// Save the terrarium info
-	lkvstore.Put("/tr/"+trInfo.Id, trInfo)
 
 	return nil
 }
@@ -42,34 +56,68 @@ func GetInfo(trId string) (model.TerrariumInfo, bool, error) {
 	ret := model.TerrariumInfo{}
 	value, exists := lkvstore.Get("/tr/" + trId)
 	if !exists {
-		return ret, exists, fmt.Errorf("no terrarium (trId: %s)", trId)
+		return ret, exists, fmt.Errorf("%w (trId: %s)", ErrTerrariumNotFound, trId)
 	}
 
 	err := json.Unmarshal([]byte(value), &ret)
 	if err != nil {
 		return ret, exists, fmt.Errorf("failed to unmarshal terrarium info: %w", err)
 	}
+	fillEnrichments(&ret)
 
 	return ret, exists, nil
 }
 
+// fillEnrichments fills the execution status of the enrichments of a terrarium and the phase of the terrarium.
+// The providers of a terrarium stored by an older version, which allowed a single enrichment,
+// are the providers of the enrichment.
+func fillEnrichments(trInfo *model.TerrariumInfo) {
+	if trInfo.Enrichments == nil {
+		trInfo.Enrichments = model.Enrichments{}
+	}
+	if len(trInfo.Enrichments) == 0 {
+		trInfo.Phase = terrariumPhase(trInfo.Enrichments)
+		return
+	}
+
+	statuses, err := tofu.ListExecutionStatus(trInfo.Id)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to get the execution status of the enrichments (trId: %s)", trInfo.Id)
+	}
+	for name, e := range trInfo.Enrichments {
+		if len(e.Providers) == 0 && len(trInfo.Enrichments) == 1 {
+			e.Providers = trInfo.Providers
+		}
+		e.Status = statuses[name]
+		e.Phase = settlePhase(e.Phase, e.Status)
+		trInfo.Enrichments[name] = e
+	}
+	trInfo.Phase = terrariumPhase(trInfo.Enrichments)
+}
+
 // ReadAllInfo reads all terrarium info
 func ReadAllInfo() ([]model.TerrariumInfo, error) {
 
 	terrariumInfoList := []model.TerrariumInfo{}
-	values, exists := lkvstore.GetWithPrefix("/tr/")
+	kvs, err := lkvstore.List("/tr/")
+	if err != nil {
+		return terrariumInfoList, err
+	}
 
-	if exists {
-		for _, value := range values {
+	for _, kv := range kvs {
+		// Skip the other values of a terrarium (e.g., /tr/{trId}/status)
+		if strings.Contains(strings.TrimPrefix(kv.Key, "/tr/"), "/") {
+			continue
+		}
 
-			trInfo := model.TerrariumInfo{}
-			err := json.Unmarshal([]byte(value), &trInfo)
-			if err != nil {
-				log.Debug().Msgf("failed to unmarshal terrarium info: %v", err)
-				continue
-			}
-			terrariumInfoList = append(terrariumInfoList, trInfo)
+		trInfo := model.TerrariumInfo{}
+		err := json.Unmarshal([]byte(kv.Value), &trInfo)
+		if err != nil {
+			log.Debug().Msgf("failed to unmarshal terrarium info: %v", err)
+			continue
 		}
+		fillEnrichments(&trInfo)
+		terrariumInfoList = append(terrariumInfoList, trInfo)
 	}
 
 	return terrariumInfoList, nil
@@ -82,16 +130,39 @@ func UpdateInfo(trInfo model.TerrariumInfo) error {
 	if !exists {
 		return fmt.Errorf("no terrarium (trId: %s)", trInfo.Id)
 	}
-	lkvstore.Put("/tr/"+trInfo.Id, trInfo)
+	lkvstore.Put("/tr/"+trInfo.Id, withoutStatus(trInfo))
 
 	return nil
 }
 
-// DeleteInfo deletes the terrarium info
+// withoutStatus returns the terrarium info without the execution status of the enrichments,
+// which is kept by the tofu package, and the phase of the terrarium, which is decided by the enrichments.
+func withoutStatus(trInfo model.TerrariumInfo) model.TerrariumInfo {
+	trInfo.Phase = ""
+	enrichments := model.Enrichments{}
+	for name, e := range trInfo.Enrichments {
+		e.Status = ""
+		enrichments[name] = e
+	}
+	trInfo.Enrichments = enrichments
+	return trInfo
+}
+
+// DeleteInfo deletes the terrarium info and all the values under it (i.e., /tr/{trId}/),
+// such as the execution status and lock of the terrarium, the values of its enrichments (e.g., status, outputs),
+// its events and drift, so that a terrarium re-created with the same ID inherits none of them.
 func DeleteInfo(trId string) error {
 
 	lkvstore.Delete("/tr/" + trId)
 
+	kvs, err := lkvstore.List("/tr/" + trId + "/")
+	if err != nil {
+		return fmt.Errorf("failed to list the values of the terrarium (trId: %s): %w", trId, err)
+	}
+	for _, kv := range kvs {
+		lkvstore.Delete(kv.Key)
+	}
+
 	return nil
 }
 
@@ -117,37 +188,133 @@ func ValidateCredentialProfile(trId string, providedProfile string) error {
 	return nil
 }
 
-// GetEnrichments gets the terrarium enrichments from the terrarium info
-func GetEnrichments(trId string) (string, bool, error) {
+/*
+ * [Note] Enrichments of a terrarium
+ *
+ * A terrarium holds several enrichments by name (e.g., testbed, vpn/site-to-site, sql-db).
+ * Each enrichment has its own working directory (i.e., .terrarium/{trId}/{name}),
+ * state, execution status, providers and outputs.
+ */
+
+// ErrEnrichmentNotFound is returned if a terrarium does not have an enrichment.
+var ErrEnrichmentNotFound = errors.New("no enrichment in the terrarium")
+
+// GetEnrichment gets an enrichment of a terrarium
+func GetEnrichment(trId, name string) (model.EnrichmentInfo, bool, error) {
 	trInfo, exist, err := GetInfo(trId)
 	if !exist {
 		log.Error().Msg("no terrarium")
-		return "", false, errors.New("no terrarium")
+		return model.EnrichmentInfo{}, false, errors.New("no terrarium")
 	}
 
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium info")
-		return "", false, err
+		return model.EnrichmentInfo{}, false, err
 	}
 
-	exist = (trInfo.Enrichments != "")
-	if !exist {
-		return "", false, nil
+	e, exist := trInfo.Enrichments[name]
+	return e, exist, nil
+}
+
+// AddEnrichment adds an enrichment to a terrarium.
+// If the enrichment exists, its providers are updated.
+func AddEnrichment(trId, name string, providers []string) error {
+	return updateEnrichments(trId, func(enrichments model.Enrichments) error {
+		e, exists := enrichments[name]
+		if !exists {
+			e = model.EnrichmentInfo{Name: name, Phase: PhaseEmpty, CreatedAt: time.Now()}
+		}
+		e.Providers = providers
+		enrichments[name] = e
+		return nil
+	})
+}
+
+// RemoveEnrichment removes an enrichment from a terrarium and deletes its outputs and references.
+func RemoveEnrichment(trId, name string) error {
+	err := updateEnrichments(trId, func(enrichments model.Enrichments) error {
+		if _, exists := enrichments[name]; !exists {
+			return fmt.Errorf("%w (trId: %s, enrichment: %s)", ErrEnrichmentNotFound, trId, name)
+		}
+		delete(enrichments, name)
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	return trInfo.Enrichments, exist, nil
+	lkvstore.Delete(outputsKey(trId, name))
+	lkvstore.Delete(referencesKey(trId, name))
+	return nil
+}
+
+// updateEnrichments updates the enrichments of a terrarium and the providers engaged in them.
+// The terrarium info is updated only if it has not changed since it was read,
+// so that concurrent requests to the different enrichments do not overwrite each other.
+func updateEnrichments(trId string, update func(model.Enrichments) error) error {
+	return updateInfo(trId, func(trInfo *model.TerrariumInfo) error {
+		if err := update(trInfo.Enrichments); err != nil {
+			return err
+		}
+
+		// The providers of the terrarium are all the providers engaged in the enrichments
+		seen := map[string]bool{}
+		providers := []string{}
+		for _, name := range trInfo.Enrichments.Names() {
+			for _, provider := range trInfo.Enrichments[name].Providers {
+				if !seen[provider] {
+					seen[provider] = true
+					providers = append(providers, provider)
+				}
+			}
+		}
+		trInfo.Providers = providers
+		return nil
+	})
+}
+
+// updateInfo updates the terrarium info only if it has not changed since it was read (i.e., compare-and-swap),
+// and retries with the latest one otherwise.
+func updateInfo(trId string, update func(*model.TerrariumInfo) error) error {
+	for {
+		value, exists := lkvstore.Get("/tr/" + trId)
+		if !exists {
+			return fmt.Errorf("no terrarium (trId: %s)", trId)
+		}
+
+		trInfo := model.TerrariumInfo{}
+		if err := json.Unmarshal([]byte(value), &trInfo); err != nil {
+			return fmt.Errorf("failed to unmarshal terrarium info: %w", err)
+		}
+		fillEnrichments(&trInfo)
+
+		if err := update(&trInfo); err != nil {
+			return err
+		}
+
+		swapped, err := lkvstore.CompareAndSwap("/tr/"+trId, value, withoutStatus(trInfo))
+		if err != nil {
+			return fmt.Errorf("failed to update the terrarium info (trId: %s): %w", trId, err)
+		}
+		if swapped {
+			return nil
+		}
+	}
 }
 
-// GetTerrariumEnvPath gets the terrarium environment path (i.e., a working directory)
-func GetTerrariumEnvPath(trId string) (string, error) {
-	enrichments, _, err := GetEnrichments(trId)
+// GetTerrariumEnvPath gets the environment path of an enrichment (i.e., a working directory)
+func GetTerrariumEnvPath(trId, enrichment string) (string, error) {
+	_, exist, err := GetEnrichment(trId, enrichment)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get enrichments")
+		log.Error().Err(err).Msg("failed to get the enrichment")
 		return "", err
 	}
+	if !exist {
+		return "", fmt.Errorf("%w (trId: %s, enrichment: %s)", ErrEnrichmentNotFound, trId, enrichment)
+	}
 
 	projectRoot := config.Terrarium.Root
-	workingDir := projectRoot + "/.terrarium/" + trId + "/" + enrichments
+	workingDir := projectRoot + "/.terrarium/" + trId + "/" + enrichment
 	if _, err := os.Stat(workingDir); os.IsNotExist(err) {
 		err := os.MkdirAll(workingDir, 0755)
 		if err != nil {
@@ -159,53 +326,39 @@ func GetTerrariumEnvPath(trId string) (string, error) {
 	return workingDir, nil
 }
 
-// SetEnrichments puts the terrarium enrichments to the terrarium info
-func SetEnrichments(trId, enrichments string) error {
-	trInfo, exist, err := GetInfo(trId)
-
-	if !exist {
-		log.Error().Msg("no terrarium")
-		return errors.New("no terrarium")
-	}
-	if err != nil {
-		log.Error().Err(err).Msg("failed to get terrarium info")
-		return err
-	}
-
-	trInfo.Enrichments = enrichments
-	err = UpdateInfo(trInfo)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to update terrarium info")
-		return err
-	}
-	return nil
-}
-
-// CreateEnv sets the terrarium environment
-func CreateEnv(trInfo model.TerrariumInfo) error {
+// CreateEnv sets the environment of an enrichment in a terrarium
+func CreateEnv(trId, enrichments string, providers []string) error {
 
 	/*
-	 * [Note] Validate the terrarium info
+	 * [Note] Validate the arguments
 	 */
-	if trInfo.Id == "" {
+	if trId == "" {
 		err := fmt.Errorf("not specified the terrarium ID")
 		log.Error().Msg(err.Error())
 		return err
 	}
-	if trInfo.Enrichments == "" {
+	if enrichments == "" {
 		err := fmt.Errorf("not specified the terrarium enrichments")
 		log.Error().Msg(err.Error())
 		return err
 	}
-	if len(trInfo.Providers) == 0 {
+	if len(providers) == 0 {
 		err := fmt.Errorf("not specified the desired providers")
 		log.Error().Msg(err.Error())
 		return err
 	}
 
-	trId := trInfo.Id
-	enrichments := trInfo.Enrichments
-	providers := trInfo.Providers
+	// Check the credentials of the providers under the credential profile of the terrarium (fail fast)
+	trInfo, _, err := GetInfo(trId)
+	if err != nil {
+		log.Error().Err(err).Msg(err.Error())
+		return err
+	}
+	err = CheckCredentials(context.Background(), trInfo.CredentialProfile, providers)
+	if err != nil {
+		log.Warn().Msg(err.Error())
+		return err
+	}
 
 	// Check if the terrarium environment exists (i.e., a terrarium environment)
 	projectRoot := config.Terrarium.Root
@@ -223,7 +376,7 @@ func CreateEnv(trInfo model.TerrariumInfo) error {
 	templateTfsPath := projectRoot + "/templates/" + enrichments
 
 	// Copy the template files to the terrarium environment
-	err := tfutil.CopyFiles(templateTfsPath, workingDir)
+	err = tfutil.CopyFiles(templateTfsPath, workingDir)
 	if err != nil {
 		err2 := fmt.Errorf("failed to copy the template files to terrarium environment")
 		log.Error().Err(err).Msg(err2.Error())
@@ -370,12 +523,16 @@ func SaveTfVars(trId, enrichments string, tfVars any) error {
 	return nil
 }
 
-// EmptyOutTerrariumEnv truncates the terrarium environment
-func EmptyOutTerrariumEnv(trId string) error {
+// EmptyOutTerrariumEnv truncates the environment of an enrichment in a terrarium.
+// If enrichment is empty, it truncates the environments of all the enrichments.
+func EmptyOutTerrariumEnv(trId, enrichment string) error {
 
 	// Check if the terrarium environment exists (i.e., a terrarium environment)
 	projectRoot := config.Terrarium.Root
 	workingDir := projectRoot + "/.terrarium/" + trId
+	if enrichment != "" {
+		workingDir += "/" + enrichment
+	}
 	if _, err := os.Stat(workingDir); os.IsNotExist(err) {
 		err := os.MkdirAll(workingDir, 0755)
 		if err != nil {
@@ -386,9 +543,25 @@ func EmptyOutTerrariumEnv(trId string) error {
 	}
 
 	// Check if a previous request is still in progress
-	currentStatus, exists := tofu.GetExecutionStatus(trId)
-	if exists && currentStatus == "Running" {
-		return errors.New("the request is still in progress")
+	running := false
+	if enrichment != "" {
+		currentStatus, exists := tofu.GetExecutionStatus(trId, enrichment)
+		running = exists && currentStatus == tofu.StatusRunning
+	} else {
+		executing, err := tofu.IsExecuting(trId)
+		if err != nil {
+			return err
+		}
+		running = executing
+	}
+	if running {
+		return fmt.Errorf("%w (trId: %s, enrichment: %s)", tofu.ErrInProgress, trId, enrichment)
+	}
+
+	// Check if resources may exist (i.e., not destroyed)
+	if err := CheckOperation(trId, enrichment, OpEmptyOut); err != nil {
+		log.Warn().Err(err).Msg("failed to empty out the terrarium environment")
+		return err
 	}
 
 	// Empty out the terrarium environment
@@ -414,38 +587,22 @@ func EmptyOutTerrariumEnv(trId string) error {
 	return nil
 }
 
-// RecoverImportTf recovers the imports.tf file
-func RecoverImportTf(trId string) error {
+// RecoverImportTf recovers the imports.tf file of an enrichment
+func RecoverImportTf(trId, enrichment string) error {
 
 	// Check if the terrarium environment exists (i.e., a terrarium environment)
-	trInfo, exist, err := GetInfo(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
-		log.Error().Err(err).Msg("failed to get terrarium info")
-		return err
-	}
-
-	if !exist {
-		err := fmt.Errorf("no terrarium (trId: %s)", trId)
-		log.Error().Msg(err.Error())
+		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return err
 	}
-
 	projectRoot := config.Terrarium.Root
-	workingDir := projectRoot + "/.terrarium/" + trId + "/" + trInfo.Enrichments
-	if _, err := os.Stat(workingDir); os.IsNotExist(err) {
-		err := os.MkdirAll(workingDir, 0755)
-		if err != nil {
-			err2 := fmt.Errorf("failed to set the terrarium environment (trId: %s)", trId)
-			log.Error().Err(err).Msg(err2.Error())
-			return err2
-		}
-	}
 
 	// Check if the imports.tf file exists
 	importsTfPath := workingDir + "/imports.tf"
 
 	// Copy template files and modules to the terrarium environment (overwrite)
-	templateTfsPath := projectRoot + "/templates/" + trInfo.Enrichments + "/imports.tf"
+	templateTfsPath := projectRoot + "/templates/" + enrichment + "/imports.tf"
 
 	// Copy the imports.tf to the terrarium environment
 	if err := tfutil.CopyFile(templateTfsPath, importsTfPath); err != nil {
@@ -462,20 +619,28 @@ func RecoverImportTf(trId string) error {
  */
 
 // Init prepares a terrarium environment for other commands (i.e., a terrarium environment)
-func Init(trId, reqId string) (string, error) {
+func Init(ctx context.Context, trId, enrichment, reqId string) (string, error) {
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
+	// Check the phase of the enrichment
+	previous, err := StartOperation(trId, enrichment, OpInit)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to init the terrarium environment")
+		return "", err
+	}
+
 	// Execute tofu command: init
-	tfcli := tfclient.NewClient(trId, reqId)
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
 	ret, err := tfcli.Init().Exec()
+	FinishOperation(trId, enrichment, OpInit, previous, err)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to execute tofu command")
 		return "", err
@@ -484,87 +649,300 @@ func Init(trId, reqId string) (string, error) {
 	return ret, nil
 }
 
-// Plan shows changes required by the current configuration
-func Plan(trId, reqId string) (string, error) {
+// Plan shows changes required by the current configuration.
+// The plan is saved in the terrarium environment (i.e., plans/{planId}.tfplan),
+// and the changes are summarized from `tofu show -json` to be reviewed before it is applied.
+func Plan(ctx context.Context, trId, enrichment, reqId string) (model.Plan, string, error) {
+
+	emptyPlan := model.Plan{}
+
+	trInfo, _, err := GetInfo(trId)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get terrarium info")
+		return emptyPlan, "", err
+	}
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
-		return "", err
+		return emptyPlan, "", err
 	}
 
-	// Execute tofu command: plan
-	tfcli := tfclient.NewClient(trId, reqId)
+	// Check the phase of the enrichment
+	previous, err := StartOperation(trId, enrichment, OpPlan)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to plan the changes")
+		return emptyPlan, "", err
+	}
+
+	// Resolve the references to the outputs of the other enrichments again (i.e., on update)
+	if err := resolveTfVarsReferences(trId, enrichment, workingDir); err != nil {
+		log.Warn().Err(err).Msg("failed to resolve the references")
+		return emptyPlan, "", err
+	}
+
+	if err := os.MkdirAll(workingDir+"/plans", 0755); err != nil {
+		err2 := fmt.Errorf("failed to create the plan directory (trId: %s)", trId)
+		log.Error().Err(err).Msg(err2.Error())
+		return emptyPlan, "", err2
+	}
+
+	// Keep the version of the state on which the plan is based
+	serial, lineage, err := stateVersion(workingDir)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to read the state version")
+		return emptyPlan, "", err
+	}
+
+	planId := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	// Execute tofu command: plan -out=plans/{planId}.tfplan
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
-	ret, err := tfcli.Plan().Exec()
+	ret, err := tfcli.Plan().SetOut(planFile(planId)).Exec()
+	FinishOperation(trId, enrichment, OpPlan, previous, err)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to execute tofu command")
-		return "", err
+		return emptyPlan, "", err
 	}
 
-	return ret, nil
+	// Execute tofu command: show -json plans/{planId}.tfplan
+	tfcli = tfclient.NewClient(ctx, trId, reqId)
+	tfcli.SetChdir(workingDir)
+
+	showJson, err := tfcli.Show().Json().SetArg(planFile(planId)).Exec()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to execute tofu command")
+		return emptyPlan, "", err
+	}
+
+	summary, err := summarizePlan(showJson)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to summarize the plan")
+		return emptyPlan, "", err
+	}
+
+	// A plan waits for approval if the terrarium requires it
+	status := PlanStatusPlanned
+	if trInfo.ApprovalRequired {
+		status = PlanStatusPendingApproval
+	}
+
+	plan := model.Plan{
+		Id:           planId,
+		TrId:         trId,
+		Enrichment:   enrichment,
+		ReqId:        reqId,
+		Status:       status,
+		Summary:      summary,
+		CreatedBy:    job.PrincipalNameFrom(ctx),
+		CreatedAt:    time.Now(),
+		StateSerial:  serial,
+		StateLineage: lineage,
+	}
+	if err := savePlan(plan); err != nil {
+		log.Error().Err(err).Msg("failed to save the plan")
+		return emptyPlan, "", err
+	}
+
+	return plan, ret, nil
 }
 
-// Apply creates or updates infrastructure
-func Apply(trId, reqId string) (string, error) {
+// Apply creates or updates infrastructure.
+// If planId is given, it applies exactly the saved plan, which must not be stale (see Plan).
+// The plan must be approved if the terrarium requires approval (see ApprovePlan).
+// Otherwise, it plans and applies the changes at once.
+func Apply(ctx context.Context, trId, enrichment, reqId, planId string) (string, error) {
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
-	// Execute tofu command: apply
-	tfcli := tfclient.NewClient(trId, reqId)
+	if planId == "" {
+		trInfo, _, err := GetInfo(trId)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get terrarium info")
+			return "", err
+		}
+		if trInfo.ApprovalRequired {
+			return "", fmt.Errorf("%w, the terrarium (trId: %s) requires an approved plan to apply (planId is required)", ErrPlanNotApproved, trId)
+		}
+
+		// Resolve the references to the outputs of the other enrichments again (i.e., on update)
+		if err := resolveTfVarsReferences(trId, enrichment, workingDir); err != nil {
+			log.Warn().Err(err).Msg("failed to resolve the references")
+			return "", err
+		}
+
+		// Check the phase of the enrichment and mark it as provisioning
+		previous, err := StartOperation(trId, enrichment, OpApply)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to apply the changes")
+			return "", err
+		}
+
+		// Execute tofu command: apply
+		tfcli := tfclient.NewClient(ctx, trId, reqId)
+		tfcli.SetChdir(workingDir)
+
+		ret, err := tfcli.Apply().Auto().Exec()
+		FinishOperation(trId, enrichment, OpApply, previous, err)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to execute tofu command")
+			return "", err
+		}
+		saveOutputs(ctx, trId, enrichment, reqId, workingDir)
+
+		return ret, nil
+	}
+
+	plan, err := applicablePlan(trId, enrichment, planId, workingDir)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to apply the plan (planId: %s)", planId)
+		return "", err
+	}
+
+	// Check the phase of the enrichment and mark it as provisioning
+	previous, err := StartOperation(trId, enrichment, OpApply)
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to apply the plan (planId: %s)", planId)
+		return "", err
+	}
+
+	// Record the plan and its approver in the request (job)
+	ctx = job.WithPlan(ctx, plan.Id, plan.ApprovedBy)
+
+	// Execute tofu command: apply plans/{planId}.tfplan
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
-	ret, err := tfcli.Apply().Auto().Exec()
+	ret, err := tfcli.Apply().SetArg(planFile(planId)).Exec()
 	if err != nil {
 		log.Error().Err(err).Msg("failed to execute tofu command")
+
+		status := PlanStatusFailed
+		if strings.Contains(ret, "Saved plan is stale") {
+			status = PlanStatusStale
+			err = fmt.Errorf("%w (planId: %s): %w", ErrStalePlan, planId, err)
+		}
+		FinishOperation(trId, enrichment, OpApply, previous, err)
+		if err2 := updatePlanStatus(trId, planId, plan.Status, func(plan *model.Plan) { plan.Status = status }); err2 != nil {
+			log.Warn().Err(err2).Msgf("failed to update the plan (planId: %s)", planId)
+		}
 		return "", err
 	}
 
+	FinishOperation(trId, enrichment, OpApply, previous, nil)
+
+	now := time.Now()
+	err = updatePlanStatus(trId, planId, plan.Status, func(plan *model.Plan) {
+		plan.Status = PlanStatusApplied
+		plan.AppliedAt = &now
+		plan.AppliedReqId = reqId
+	})
+	if err != nil {
+		log.Warn().Err(err).Msgf("failed to update the plan (planId: %s)", planId)
+	}
+	saveOutputs(ctx, trId, enrichment, reqId, workingDir)
+
 	return ret, nil
 }
 
+// applicablePlan reads a saved plan and checks if it can be applied to the current state.
+// A stale plan is marked as stale.
+func applicablePlan(trId, enrichment, planId, workingDir string) (model.Plan, error) {
+
+	plan, exists, err := GetPlan(trId, planId)
+	if err != nil {
+		return plan, err
+	}
+	if !exists {
+		return plan, fmt.Errorf("%w (trId: %s, planId: %s)", ErrPlanNotFound, trId, planId)
+	}
+
+	switch plan.Status {
+	case PlanStatusPlanned:
+	case PlanStatusApproved:
+		if plan.ExpiresAt != nil && time.Now().After(*plan.ExpiresAt) {
+			err := updatePlanStatus(trId, planId, plan.Status, func(plan *model.Plan) { plan.Status = PlanStatusExpired })
+			if err != nil {
+				log.Warn().Err(err).Msgf("failed to update the plan (planId: %s)", planId)
+			}
+			return plan, fmt.Errorf("%w (planId: %s, expiresAt: %s), please plan and approve again", ErrPlanExpired, planId, plan.ExpiresAt.Format(time.RFC3339))
+		}
+	case PlanStatusPendingApproval:
+		return plan, fmt.Errorf("%w, the plan (planId: %s) is pending approval", ErrPlanNotApproved, planId)
+	default:
+		return plan, fmt.Errorf("%w, the plan (planId: %s) is %s", ErrPlanNotApplicable, planId, strings.ToLower(plan.Status))
+	}
+
+	if _, err := os.Stat(workingDir + "/" + planFile(planId)); plan.Enrichment != enrichment || err != nil {
+		return plan, fmt.Errorf("%w, the plan file does not exist in the terrarium environment (trId: %s, planId: %s)", ErrPlanNotFound, trId, planId)
+	}
+
+	serial, lineage, err := stateVersion(workingDir)
+	if err != nil {
+		return plan, err
+	}
+	if serial != plan.StateSerial || lineage != plan.StateLineage {
+		if err := updatePlanStatus(trId, planId, plan.Status, func(plan *model.Plan) { plan.Status = PlanStatusStale }); err != nil {
+			log.Warn().Err(err).Msgf("failed to update the plan (planId: %s)", planId)
+		}
+		return plan, fmt.Errorf("%w, the state has changed since the plan (planId: %s) was created, please plan again", ErrStalePlan, planId)
+	}
+
+	return plan, nil
+}
+
 // Destroy destroys previously-created infrastructure
-func Destroy(trId, reqId string) (string, error) {
+func Destroy(ctx context.Context, trId, enrichment, reqId string) (string, error) {
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
+	// Check the phase of the enrichment and mark it as destroying
+	previous, err := StartOperation(trId, enrichment, OpDestroy)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to destroy the infrastructure")
+		return "", err
+	}
+
 	// Execute tofu command: destroy
-	tfcli := tfclient.NewClient(trId, reqId)
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
 	ret, err := tfcli.Destroy().Auto().Exec()
+	FinishOperation(trId, enrichment, OpDestroy, previous, err)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to execute tofu command")
 		return "", err
 	}
+	lkvstore.Delete(outputsKey(trId, enrichment))
 
 	return ret, nil
 }
 
 // Output shows output values from your root module
-func Output(trId, reqId, name string, options ...string) (string, error) {
+func Output(ctx context.Context, trId, enrichment, reqId, name string, options ...string) (string, error) {
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
 	// Execute tofu command: output
-	tfcli := tfclient.NewClient(trId, reqId)
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
 	tfcli.Output()
@@ -586,17 +964,17 @@ func Output(trId, reqId, name string, options ...string) (string, error) {
 }
 
 // Show shows the current state or a save plan
-func Show(trId, reqId string, options ...string) (string, error) {
+func Show(ctx context.Context, trId, enrichment, reqId string, options ...string) (string, error) {
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
 	// Execute tofu command: output
-	tfcli := tfclient.NewClient(trId, reqId)
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
 	tfcli.Show()
@@ -618,16 +996,16 @@ func Show(trId, reqId string, options ...string) (string, error) {
 }
 
 // State reads and outputs a OpenTofu state or plan file in a human-readable form
-func State(trId, reqId, subcommand string, args ...string) (string, error) {
+func State(ctx context.Context, trId, enrichment, reqId, subcommand string, args ...string) (string, error) {
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
-	tfcli := tfclient.NewClient(trId, reqId).SetChdir(workingDir).State()
+	tfcli := tfclient.NewClient(ctx, trId, reqId).SetChdir(workingDir).State()
 
 	switch subcommand {
 	case "pull":
@@ -653,17 +1031,23 @@ func State(trId, reqId, subcommand string, args ...string) (string, error) {
 // Uses 'tofu apply -refresh-only -auto-approve' instead of 'tofu refresh'
 // because 'tofu refresh' only updates resource attributes in state
 // but does NOT recompute output values.
-func Refresh(trId, reqId string) (string, error) {
+func Refresh(ctx context.Context, trId, enrichment, reqId string) (string, error) {
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return "", err
 	}
 
+	// Check the phase of the enrichment
+	if err := CheckOperation(trId, enrichment, OpRefresh); err != nil {
+		log.Warn().Err(err).Msg("failed to refresh the state")
+		return "", err
+	}
+
 	// Execute tofu command: apply -refresh-only -auto-approve
 	// This refreshes state from CSPs AND recomputes output values
-	tfcli := tfclient.NewClient(trId, reqId)
+	tfcli := tfclient.NewClient(ctx, trId, reqId)
 	tfcli.SetChdir(workingDir)
 
 	ret, err := tfcli.Apply().RefreshOnly().Auto().Exec()
@@ -671,14 +1055,15 @@ func Refresh(trId, reqId string) (string, error) {
 		log.Error().Err(err).Msg("failed to execute tofu command")
 		return "", err
 	}
+	saveOutputs(ctx, trId, enrichment, reqId, workingDir)
 
 	return ret, nil
 }
 
 // DetachImportedResource detaches an imported resource from the state
-func DetachImportedResource(trId, reqId, resourceId string) error {
+func DetachImportedResource(ctx context.Context, trId, enrichment, reqId, resourceId string) error {
 
-	_, err := State(trId, reqId, "rm", resourceId)
+	_, err := State(ctx, trId, enrichment, reqId, "rm", resourceId)
 	if err != nil {
 		err2 := fmt.Errorf("failed to remove the imported route table")
 		log.Error().Err(err).Msg(err2.Error())
@@ -686,7 +1071,7 @@ func DetachImportedResource(trId, reqId, resourceId string) error {
 	}
 
 	// Get working directory
-	workingDir, err := GetTerrariumEnvPath(trId)
+	workingDir, err := GetTerrariumEnvPath(trId, enrichment)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to get terrarium environment path")
 		return err
@@ -0,0 +1,147 @@
+package terrarium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cloud-barista/mc-terrarium/pkg/api/rest/model"
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
+	tfutil "github.com/cloud-barista/mc-terrarium/pkg/tofu/util"
+	"github.com/rs/zerolog/log"
+)
+
+/*
+ * [Note] Credential Verification
+ * The credential of a provider is verified by a tiny, read-only configuration (templates/verify/{provider}),
+ * which reads a data source identifying the caller (e.g., aws_caller_identity), so that no resource is created.
+ * It runs `tofu init` and `tofu plan -refresh-only` in a scratch directory, which is not a terrarium
+ * (i.e., not locked nor recorded as a request), and is removed after the verification.
+ * An expired or mis-scoped credential fails the plan with the diagnostic of the provider.
+ */
+
+// Stages of the verification of a credential
+const (
+	VerifyStageCredential = "credential"
+	VerifyStageInit       = "init"
+	VerifyStagePlan       = "plan"
+)
+
+// verifyTimeout is the timeout of each stage of the verification (e.g., downloading the providers by init).
+const verifyTimeout = 5 * time.Minute
+
+// maxDiagnosticLength is the maximum length of the diagnostic in the result.
+const maxDiagnosticLength = 4000
+
+// VerifyProviders lists the providers whose credentials can be verified (i.e., templates/verify/{provider}).
+func VerifyProviders() []string {
+	providers := []string{}
+	entries, err := os.ReadDir(config.Terrarium.Root + "/templates/verify")
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read the verification templates")
+		return providers
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			providers = append(providers, entry.Name())
+		}
+	}
+	return providers
+}
+
+// VerifyCredential verifies the credential of a provider in a credential profile by running tofu.
+// A failed verification is not an error but the result (i.e., passed is false with the diagnostic).
+func VerifyCredential(ctx context.Context, profile, provider, reqId string) (model.CredentialVerification, error) {
+	if err := validateProfileName(profile); err != nil {
+		return model.CredentialVerification{}, err
+	}
+	templateDir := config.Terrarium.Root + "/templates/verify/" + provider
+	if info, err := os.Stat(templateDir); !providerPattern.MatchString(provider) || err != nil || !info.IsDir() {
+		return model.CredentialVerification{}, fmt.Errorf("%w, provider (%s) must be one of [%s]",
+			ErrInvalidCredentialProfile, provider, strings.Join(VerifyProviders(), ", "))
+	}
+
+	start := time.Now()
+	result := model.CredentialVerification{
+		Profile:    profile,
+		Provider:   provider,
+		VerifiedAt: start,
+	}
+	finish := func(stage, diagnostic string) model.CredentialVerification {
+		result.Passed = stage == ""
+		result.Stage = stage
+		result.Diagnostic = diagnostic
+		result.ElapsedSec = time.Since(start).Round(100 * time.Millisecond).Seconds()
+		log.Info().Msgf("verified the credential (profile: %s, provider: %s, passed: %t, stage: %s)",
+			profile, provider, result.Passed, stage)
+		return result
+	}
+
+	// Fail fast without tofu if no credential is stored (only if the secrets store is configured)
+	if secrets.Enabled() {
+		if _, err := GetCredentialProfile(ctx, profile); err != nil {
+			return model.CredentialVerification{}, err
+		}
+	}
+	if err := CheckCredentials(ctx, profile, []string{provider}); err != nil {
+		if errors.Is(err, ErrMissingCredential) {
+			return finish(VerifyStageCredential, err.Error()), nil
+		}
+		return model.CredentialVerification{}, err
+	}
+
+	// Run tofu in a scratch directory
+	scratchDir, err := os.MkdirTemp("", "terrarium-verify-")
+	if err != nil {
+		return model.CredentialVerification{}, fmt.Errorf("failed to create a scratch directory: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(scratchDir); err != nil {
+			log.Warn().Err(err).Msgf("failed to remove the scratch directory (%s)", scratchDir)
+		}
+	}()
+	if err := tfutil.CopyFiles(templateDir, scratchDir); err != nil {
+		return model.CredentialVerification{}, fmt.Errorf("failed to copy the verification template (%s): %w", provider, err)
+	}
+
+	name := "verify-" + reqId
+	chdir := "-chdir=" + scratchDir
+
+	initCtx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	output, err := tofu.ExecuteScratchCommand(initCtx, name, chdir, "init", "-input=false", "-no-color")
+	cancel()
+	if err != nil {
+		return finish(VerifyStageInit, diagnosticOf(output, err)), nil
+	}
+
+	planCtx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	output, err = tofu.ExecuteScratchCommand(planCtx, name, chdir, "plan", "-refresh-only", "-input=false", "-lock=false", "-no-color",
+		"-var", "credential_profile="+profile)
+	cancel()
+	if err != nil {
+		return finish(VerifyStagePlan, diagnosticOf(output, err)), nil
+	}
+
+	return finish("", ""), nil
+}
+
+// diagnosticOf returns the diagnostic of tofu (i.e., from the first error) in the output,
+// or the error itself if no diagnostic is printed (e.g., tofu not found, timeout).
+func diagnosticOf(output string, err error) string {
+	diagnostic := ""
+	if i := strings.Index(output, "Error: "); i >= 0 {
+		diagnostic = strings.TrimSpace(output[i:])
+	}
+	if diagnostic == "" {
+		diagnostic = err.Error()
+	}
+	if len(diagnostic) > maxDiagnosticLength {
+		diagnostic = diagnostic[:maxDiagnosticLength] + "..."
+	}
+	return diagnostic
+}
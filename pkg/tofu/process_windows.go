@@ -0,0 +1,34 @@
+//go:build windows
+
+package tofu
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is not supported on Windows.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// interruptProcessGroup kills the process because Windows does not support SIGINT.
+func interruptProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// killProcessGroup kills the process.
+func killProcessGroup(p *os.Process) error {
+	return p.Kill()
+}
+
+// ProcessExists checks if a process of a given pid exists.
+func ProcessExists(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
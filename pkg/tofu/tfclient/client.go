@@ -1,8 +1,10 @@
 package tfclient
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/cloud-barista/mc-terrarium/pkg/tofu"
 )
@@ -19,6 +21,8 @@ type GlobalOptions struct {
 
 // Client is the main struct for executing OpenTofu commands.
 type Client struct {
+	ctx        context.Context
+	timeout    time.Duration
 	trId       string
 	reqId      string
 	cmd        string
@@ -47,8 +51,10 @@ func (opts *GlobalOptions) String() []string {
 }
 
 // NewClient creates a new OpenTofu client.
-func NewClient(trId, reqId string) *Client {
+// The command is interrupted when ctx is done.
+func NewClient(ctx context.Context, trId, reqId string) *Client {
 	return &Client{
+		ctx:   ctx,
 		trId:  trId,
 		reqId: reqId,
 		args:  []string{},
@@ -94,6 +100,13 @@ func (c *Client) Async(async bool) *Client {
 	return c
 }
 
+// SetTimeout sets the maximum duration of the command.
+// If it is not set, the default timeout of the tofu package is applied.
+func (c *Client) SetTimeout(timeout time.Duration) *Client {
+	c.timeout = timeout
+	return c
+}
+
 // buildArgs builds the command and arguments.
 func (c *Client) buildArgs() []string {
 	args := []string{}
@@ -122,11 +135,19 @@ func (c *Client) Exec() (string, error) {
 		return "", errors.New("no command specified")
 	}
 
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.timeout > 0 {
+		ctx = tofu.WithTimeout(ctx, c.timeout)
+	}
+
 	if c.async {
-		return tofu.ExecuteCommandAsync(c.trId, c.reqId, args...)
+		return tofu.ExecuteCommandAsync(ctx, c.trId, c.reqId, args...)
 	}
 
-	return tofu.ExecuteCommand(c.trId, c.reqId, args...)
+	return tofu.ExecuteCommand(ctx, c.trId, c.reqId, args...)
 }
 
 // --- Main Commands ---
@@ -458,6 +479,13 @@ func (c *Client) RefreshOnly() *Client {
 	return c
 }
 
+// DetailedExitcode sets the -detailed-exitcode flag (used with plan command).
+// The plan returns tofu.ErrChangesPresent if it succeeds with changes.
+func (c *Client) DetailedExitcode() *Client {
+	c.args = append(c.args, "-detailed-exitcode")
+	return c
+}
+
 // Compact sets the compressed warning output format.
 func (c *Client) Compact() *Client {
 	c.args = append(c.args, "-compact-warnings")
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/cloud-barista/mc-terrarium/pkg/tofu/tfclient"
 )
@@ -12,45 +14,48 @@ func main() {
 	traceID := "example-trace-001"
 	requestID := "example-req-001"
 
+	// Define a context to stop the commands (e.g., on shutdown)
+	ctx := context.Background()
+
 	fmt.Println("OpenTofu Client Examples")
 	fmt.Println("========================")
 
 	// Example 1: Basic initialization
 	fmt.Println("\n=== Example 1: Initialize a Terraform project ===")
-	initExample(traceID, requestID)
+	initExample(ctx, traceID, requestID)
 
 	// Example 2: Plan with variables
 	fmt.Println("\n=== Example 2: Create a plan with variables ===")
-	planExample(traceID, requestID)
+	planExample(ctx, traceID, requestID)
 
 	// Example 3: Apply with auto-approve
 	fmt.Println("\n=== Example 3: Apply configuration with auto-approve ===")
-	applyExample(traceID, requestID)
+	applyExample(ctx, traceID, requestID)
 
 	// Example 4: Show outputs in JSON format
 	fmt.Println("\n=== Example 4: Get outputs in JSON format ===")
-	outputExample(traceID, requestID)
+	outputExample(ctx, traceID, requestID)
 
 	// Example 5: Destroy with variables
 	fmt.Println("\n=== Example 5: Destroy infrastructure ===")
-	destroyExample(traceID, requestID)
+	destroyExample(ctx, traceID, requestID)
 
 	// Example 6: Workspace management
 	fmt.Println("\n=== Example 6: Workspace management ===")
-	workspaceExample(traceID, requestID)
+	workspaceExample(ctx, traceID, requestID)
 
 	// Example 7: Async command execution
 	fmt.Println("\n=== Example 7: Asynchronous command execution ===")
-	asyncExample(traceID, requestID)
+	asyncExample(ctx, traceID, requestID)
 
 	// Example 8: State management
 	fmt.Println("\n=== Example 8: State management ===")
-	stateExample(traceID, requestID)
+	stateExample(ctx, traceID, requestID)
 }
 
 // Basic initialization example
-func initExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func initExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -67,8 +72,8 @@ func initExample(traceID, requestID string) {
 }
 
 // Plan example with variables
-func planExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func planExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -91,17 +96,18 @@ func planExample(traceID, requestID string) {
 }
 
 // Apply example with auto-approve
-func applyExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func applyExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
 
-	// Apply with auto-approve and parallelism settings
+	// Apply with auto-approve and parallelism settings (stop it if it takes more than 30 minutes)
 	result, err := client.Apply().
 		Auto().
 		Parallelism(10).
 		SetVarFile("env/prod.tfvars").
+		SetTimeout(30 * time.Minute).
 		Exec()
 
 	if err != nil {
@@ -113,8 +119,8 @@ func applyExample(traceID, requestID string) {
 }
 
 // Output example with JSON format
-func outputExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func outputExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -134,8 +140,8 @@ func outputExample(traceID, requestID string) {
 }
 
 // Destroy example
-func destroyExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func destroyExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -155,8 +161,8 @@ func destroyExample(traceID, requestID string) {
 }
 
 // Workspace management example
-func workspaceExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func workspaceExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -193,8 +199,8 @@ func workspaceExample(traceID, requestID string) {
 }
 
 // Async command execution example
-func asyncExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func asyncExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -215,8 +221,8 @@ func asyncExample(traceID, requestID string) {
 }
 
 // State management example
-func stateExample(traceID, requestID string) {
-	client := tfclient.NewClient(traceID, requestID)
+func stateExample(ctx context.Context, traceID, requestID string) {
+	client := tfclient.NewClient(ctx, traceID, requestID)
 
 	// Set working directory
 	client.SetChdir("./terraform-project")
@@ -2,14 +2,22 @@ package tofu
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/cloud-barista/mc-terrarium/pkg/config"
+	"github.com/cloud-barista/mc-terrarium/pkg/job"
 	"github.com/cloud-barista/mc-terrarium/pkg/lkvstore"
+	"github.com/cloud-barista/mc-terrarium/pkg/secrets"
+	"github.com/cloud-barista/mc-terrarium/pkg/tofu/uistream"
 	"github.com/rs/zerolog/log"
 )
 
@@ -17,84 +25,571 @@ import (
 // It can be modified if the binary name is different in the system.
 var cliName = "tofu"
 
-// SetRunningStatus sets the running status for a given trId.
-func SetRunningStatus(trId, status string) {
-	lkvstore.Put("/tr/"+trId+"/status", status)
+const (
+	// defaultTimeout is the maximum duration of a tofu command
+	// if neither the caller nor the configuration specifies one.
+	defaultTimeout = 60 * time.Minute
+	// defaultGracePeriod is the time given to tofu to stop gracefully
+	// (e.g., to release the state lock) after SIGINT before it is killed.
+	defaultGracePeriod = 30 * time.Second
+)
+
+// Execution status of a terrarium (or an enrichment of a terrarium), the same as the status of its request
+const (
+	StatusRunning     = job.StatusRunning
+	StatusSuccess     = job.StatusSuccess
+	StatusFailed      = job.StatusFailed
+	StatusCancelled   = job.StatusCancelled
+	StatusInterrupted = job.StatusInterrupted
+)
+
+// ErrNoRunningCommand is returned when there is no running command to cancel.
+var ErrNoRunningCommand = errors.New("no running command for the request")
+
+// ErrChangesPresent is returned when a plan with -detailed-exitcode succeeds with changes (i.e., exit code 2).
+// The command is recorded as succeeded.
+var ErrChangesPresent = errors.New("succeeded with changes present")
+
+// runningCommands holds the cancel functions of the running commands (key: trId/reqId).
+var runningCommands sync.Map
+
+type runningCommand struct {
+	cancel    context.CancelFunc
+	cancelled bool
+	mu        sync.Mutex
 }
 
-// GetExecutionStatus gets the running status for a given trId.
-func GetExecutionStatus(trId string) (string, bool) {
-	value, exists := lkvstore.Get("/tr/" + trId + "/status")
+// ErrInProgress is returned when a command is requested while another command is running in an enrichment.
+var ErrInProgress = errors.New("a previous request is still in progress")
+
+/*
+ * [Note] Execution status and lock
+ *
+ * Each enrichment of a terrarium (i.e., a working directory with its own state) has its own status and lock,
+ * so that the commands in the different enrichments of a terrarium can run at the same time.
+ * A command not run in an enrichment (i.e., without -chdir) has the status and lock of the terrarium.
+ */
+
+// scopeKey returns the key prefix of the execution status and lock of an enrichment (or a terrarium).
+func scopeKey(trId, enrichment string) string {
+	if enrichment == "" {
+		return "/tr/" + trId
+	}
+	return "/tr/" + trId + "/enrichments/" + enrichment
+}
+
+func statusKey(trId, enrichment string) string {
+	return scopeKey(trId, enrichment) + "/status"
+}
+
+func lockKey(trId, enrichment string) string {
+	return scopeKey(trId, enrichment) + "/lock"
+}
+
+// scopeOf returns the enrichment in which a command runs, from its working directory (-chdir).
+func scopeOf(trId string, args []string) string {
+	return job.EnrichmentOf(trId, args)
+}
+
+// ExecutionLock represents the request holding the execution lock of an enrichment.
+type ExecutionLock struct {
+	ReqId      string    `json:"reqId"`
+	Host       string    `json:"host,omitempty"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+}
+
+// SetRunningStatus sets the running status for a given enrichment of a terrarium.
+func SetRunningStatus(trId, enrichment, status string) {
+	lkvstore.Put(statusKey(trId, enrichment), status)
+}
+
+// GetExecutionStatus gets the running status for a given enrichment of a terrarium.
+// An empty enrichment means the terrarium itself.
+func GetExecutionStatus(trId, enrichment string) (string, bool) {
+	value, exists := lkvstore.Get(statusKey(trId, enrichment))
 	if !exists {
 		return "", false
 	}
-	return value, true
+	return decodeStatus(value), true
+}
+
+// decodeStatus decodes a status value stored in JSON.
+func decodeStatus(value string) string {
+	status := ""
+	if err := json.Unmarshal([]byte(value), &status); err != nil {
+		// The status stored as it is
+		return value
+	}
+	return status
+}
+
+// ListExecutionStatus returns the running status of the enrichments of a terrarium (key: enrichment).
+// The status of the terrarium itself, if any, has an empty key.
+func ListExecutionStatus(trId string) (map[string]string, error) {
+	kvs, err := lkvstore.List("/tr/" + trId + "/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the execution status (trId: %s): %w", trId, err)
+	}
+
+	statuses := map[string]string{}
+	for _, kv := range kvs {
+		switch {
+		case kv.Key == statusKey(trId, ""):
+			statuses[""] = decodeStatus(kv.Value)
+		case strings.HasPrefix(kv.Key, scopeKey(trId, "")+"/enrichments/") && strings.HasSuffix(kv.Key, "/status"):
+			enrichment := strings.TrimSuffix(strings.TrimPrefix(kv.Key, scopeKey(trId, "")+"/enrichments/"), "/status")
+			statuses[enrichment] = decodeStatus(kv.Value)
+		}
+	}
+	return statuses, nil
+}
+
+// IsExecuting checks if a command is running in any enrichment of a terrarium.
+func IsExecuting(trId string) (bool, error) {
+	statuses, err := ListExecutionStatus(trId)
+	if err != nil {
+		return false, err
+	}
+	for _, status := range statuses {
+		if status == StatusRunning {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// acquireExecutionLock marks an enrichment of a terrarium as running by a request
+// only if no command is running in the enrichment.
+// The status and the lock holder are updated in a transaction conditioned on the status read,
+// so that only one of concurrent requests acquires the lock.
+func acquireExecutionLock(trId, enrichment, reqId string) (string, error) {
+	value, exists := lkvstore.Get(statusKey(trId, enrichment))
+	if exists {
+		if decodeStatus(value) == StatusRunning {
+			return "", ErrInProgress
+		}
+	} else {
+		// The status must not exist when the lock is acquired
+		value = ""
+	}
+
+	opStatus, err := lkvstore.OpPut(statusKey(trId, enrichment), StatusRunning)
+	if err != nil {
+		return "", err
+	}
+	opLock, err := lkvstore.OpPut(lockKey(trId, enrichment), ExecutionLock{ReqId: reqId, Host: job.Hostname(), AcquiredAt: time.Now()})
+	if err != nil {
+		return "", err
+	}
+
+	acquired, err := lkvstore.Txn([]lkvstore.Cmp{{Key: statusKey(trId, enrichment), Value: value}}, opStatus, opLock)
+	if err != nil {
+		return "", fmt.Errorf("failed to acquire the execution lock (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+	if !acquired {
+		// Another request has changed the status (i.e., acquired the lock) in the meantime
+		return "", ErrInProgress
+	}
+
+	return opLock.Value, nil
+}
+
+// GetExecutionLock returns the request holding the execution lock of an enrichment, if any.
+func GetExecutionLock(trId, enrichment string) (ExecutionLock, bool) {
+	lock := ExecutionLock{}
+	value, exists := lkvstore.Get(lockKey(trId, enrichment))
+	if !exists {
+		return lock, false
+	}
+	if err := json.Unmarshal([]byte(value), &lock); err != nil {
+		log.Warn().Err(err).Msgf("failed to unmarshal the execution lock (trId: %s, enrichment: %s)", trId, enrichment)
+	}
+	return lock, true
+}
+
+// InterruptExecution marks a running enrichment as interrupted and releases the execution lock,
+// which is left by a request whose process no longer exists (e.g., the server stopped while running a command).
+// It returns false if the enrichment is not running or the lock has changed in the meantime.
+func InterruptExecution(trId, enrichment string) (bool, error) {
+	status, exists := lkvstore.Get(statusKey(trId, enrichment))
+	if !exists {
+		return false, nil
+	}
+	if decodeStatus(status) != StatusRunning {
+		return false, nil
+	}
+	// The lock does not exist if it was set before the lock was introduced
+	lock, _ := lkvstore.Get(lockKey(trId, enrichment))
+
+	opStatus, err := lkvstore.OpPut(statusKey(trId, enrichment), StatusInterrupted)
+	if err != nil {
+		return false, err
+	}
+	cmps := []lkvstore.Cmp{{Key: statusKey(trId, enrichment), Value: status}, {Key: lockKey(trId, enrichment), Value: lock}}
+	interrupted, err := lkvstore.Txn(cmps, opStatus, lkvstore.OpDelete(lockKey(trId, enrichment)))
+	if err != nil {
+		return false, fmt.Errorf("failed to release the execution lock (trId: %s, enrichment: %s): %w", trId, enrichment, err)
+	}
+	return interrupted, nil
+}
+
+// releaseExecutionLock sets the final status of an enrichment and releases the execution lock
+// only if the lock is still held by the request.
+func releaseExecutionLock(trId, enrichment, lock, status string) {
+	opStatus, err := lkvstore.OpPut(statusKey(trId, enrichment), status)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to release the execution lock (trId: %s, enrichment: %s)", trId, enrichment)
+		return
+	}
+
+	released, err := lkvstore.Txn([]lkvstore.Cmp{{Key: lockKey(trId, enrichment), Value: lock}}, opStatus, lkvstore.OpDelete(lockKey(trId, enrichment)))
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to release the execution lock (trId: %s, enrichment: %s)", trId, enrichment)
+		return
+	}
+	if !released {
+		log.Warn().Msgf("the execution lock (trId: %s, enrichment: %s) is not held by the request anymore", trId, enrichment)
+	}
+}
+
+// Timeout returns the default timeout of a tofu command.
+func Timeout() time.Duration {
+	if config.Terrarium.Tofu.TimeoutMin > 0 {
+		return time.Duration(config.Terrarium.Tofu.TimeoutMin) * time.Minute
+	}
+	return defaultTimeout
+}
+
+type timeoutKey struct{}
+
+// WithTimeout returns a copy of ctx that carries the timeout of tofu commands,
+// which overrides the default timeout (see Timeout).
+func WithTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, timeout)
+}
+
+// timeoutOf returns the timeout of tofu commands carried by ctx or the default timeout.
+func timeoutOf(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(timeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+	return Timeout()
+}
+
+// GracePeriod returns the time given to tofu to stop gracefully after an interrupt.
+func GracePeriod() time.Duration {
+	if config.Terrarium.Tofu.GracePeriodSec > 0 {
+		return time.Duration(config.Terrarium.Tofu.GracePeriodSec) * time.Second
+	}
+	return defaultGracePeriod
 }
 
 // ExecuteCommand executes a given tofu CLI command with arguments and returns the result.
 // It also logs the full command being executed.
+// The command is interrupted when ctx is done, the timeout expires (see WithTimeout),
+// or the request is cancelled by CancelCommand.
 // Example usage:
-// - ExecuteCommand("version")
-// - ExecuteCommand("apply", "-var=\"image_id=ami-abc123\"")
-// - ExecuteCommand("import", "aws_vpc.my-imported-vpc", "vpc-a01106c2")
-func ExecuteCommand(trId, reqId string, args ...string) (string, error) {
-	currentStatus, exists := GetExecutionStatus(trId)
-	if exists && currentStatus == "Running" {
-		return "", errors.New("a previous request is still in progress")
+// - ExecuteCommand(ctx, trId, reqId, "version")
+// - ExecuteCommand(ctx, trId, reqId, "apply", "-var=\"image_id=ami-abc123\"")
+// - ExecuteCommand(ctx, trId, reqId, "import", "aws_vpc.my-imported-vpc", "vpc-a01106c2")
+func ExecuteCommand(ctx context.Context, trId, reqId string, args ...string) (string, error) {
+	enrichment := scopeOf(trId, args)
+	lock, err := acquireExecutionLock(trId, enrichment, reqId)
+	if err != nil {
+		return "", err
 	}
-	SetRunningStatus(trId, "Running")
 
 	defer func() {
 		if r := recover(); r != nil {
-			SetRunningStatus(trId, "Failed")
+			releaseExecutionLock(trId, enrichment, lock, StatusFailed)
 		}
 	}()
 
 	// Execute the command and setup
-	output, err := executeCommand(reqId, args)
+	output, err := runCommand(ctx, trId, reqId, args)
 	if err != nil {
-		log.Error().Msgf("Command execution failed: %v", err)
-		SetRunningStatus(trId, "Failed")
+		if !errors.Is(err, ErrChangesPresent) {
+			log.Error().Msgf("Command execution failed: %v", err)
+		}
+		releaseExecutionLock(trId, enrichment, lock, statusOf(err))
 		return output, err
 	}
-	SetRunningStatus(trId, "Success")
+	releaseExecutionLock(trId, enrichment, lock, StatusSuccess)
 
 	// Return the result
 	return output, nil
 }
 
+// ExecuteScratchCommand executes a given tofu CLI command with arguments in a scratch directory (-chdir),
+// which is not a terrarium (e.g., to verify the credentials).
+// The command is neither locked nor recorded as a request, and it is interrupted when ctx is done.
+func ExecuteScratchCommand(ctx context.Context, name string, args ...string) (string, error) {
+	return executeCommand(ctx, "", name, args)
+}
+
 // ExecuteCommandAsync executes a given tofu CLI command with arguments asynchronously.
-func ExecuteCommandAsync(trId string, reqId string, args ...string) (string, error) {
-	currentStatus, exists := GetExecutionStatus(trId)
-	if exists && currentStatus == "Running" {
-		return "", errors.New("a previous request is still in progress")
+// The command keeps the values of ctx but is not stopped when ctx is done
+// because ctx (e.g., a request context) usually ends before the command.
+// Use CancelCommand to stop the command.
+func ExecuteCommandAsync(ctx context.Context, trId string, reqId string, args ...string) (string, error) {
+	enrichment := scopeOf(trId, args)
+	lock, err := acquireExecutionLock(trId, enrichment, reqId)
+	if err != nil {
+		return "", err
 	}
-	SetRunningStatus(trId, "Running")
+
+	ctx = context.WithoutCancel(ctx)
 
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				SetRunningStatus(trId, "Failed")
+				releaseExecutionLock(trId, enrichment, lock, StatusFailed)
 			}
 		}()
 
 		// Execute the command and setup
-		_, err := executeCommand(reqId, args)
+		_, err := runCommand(ctx, trId, reqId, args)
 		if err != nil {
-			log.Error().Msgf("Command execution failed: %v", err)
-			SetRunningStatus(trId, "Failed")
+			if !errors.Is(err, ErrChangesPresent) {
+				log.Error().Msgf("Command execution failed: %v", err)
+			}
+			releaseExecutionLock(trId, enrichment, lock, statusOf(err))
 			return
 		}
-		SetRunningStatus(trId, "Success")
+		releaseExecutionLock(trId, enrichment, lock, StatusSuccess)
 	}()
 
 	res := fmt.Sprintf("Request (reqId: %s) in progress. Please use the status check API with the request ID.", reqId)
 	return res, nil
 }
 
+// CancelCommand cancels the running command of a given request.
+// It returns job.ErrOtherHost if the command is running on another host sharing the store.
+// The tofu process receives SIGINT first so that it can release the state lock,
+// and it is killed if it does not stop within the grace period.
+func CancelCommand(trId, reqId string) error {
+	value, ok := runningCommands.Load(trId + "/" + reqId)
+	if !ok {
+		// The command may be running on another host sharing the store
+		if reqJob, exists, err := job.Get(trId, reqId); err == nil && exists &&
+			reqJob.Status == StatusRunning && reqJob.Host != "" && reqJob.Host != job.Hostname() {
+			return fmt.Errorf("%w (%s), send the cancellation to the host (trId: %s, reqId: %s)", job.ErrOtherHost, reqJob.Host, trId, reqId)
+		}
+		return ErrNoRunningCommand
+	}
+	rc := value.(*runningCommand)
+
+	rc.mu.Lock()
+	rc.cancelled = true
+	rc.mu.Unlock()
+
+	rc.cancel()
+	log.Info().Msgf("Cancellation requested (trId: %s, reqId: %s)", trId, reqId)
+	return nil
+}
+
+// IsRunning checks if a command of a given request is running.
+func IsRunning(trId, reqId string) bool {
+	_, ok := runningCommands.Load(trId + "/" + reqId)
+	return ok
+}
+
+// errCancelled marks an error caused by CancelCommand.
+type errCancelled struct {
+	err error
+}
+
+func (e *errCancelled) Error() string { return e.err.Error() }
+func (e *errCancelled) Unwrap() error { return e.err }
+
+// statusOf returns the execution status for an error returned by runCommand.
+func statusOf(err error) string {
+	if err == nil || errors.Is(err, ErrChangesPresent) {
+		return StatusSuccess
+	}
+	var cancelled *errCancelled
+	if errors.As(err, &cancelled) {
+		return StatusCancelled
+	}
+	return StatusFailed
+}
+
+// exitCodeOf returns the exit code of the command for an error returned by executeCommand.
+// It returns -1 if the command was terminated by a signal or could not be started.
+func exitCodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// runCommand records the command as a step of the request (job) and executes it.
+func runCommand(ctx context.Context, trId, reqId string, args []string) (string, error) {
+	args = withJSONUI(args)
+
+	if err := job.Start(ctx, trId, reqId, cliName, args); err != nil {
+		log.Warn().Err(err).Msgf("failed to record the start of the job (reqId: %s)", reqId)
+	}
+
+	output, err := runCancellableCommand(ctx, trId, reqId, args)
+
+	exitCode := exitCodeOf(err)
+	cmdErr := err
+	if exitCode == 2 && hasArg(args, "-detailed-exitcode") {
+		// Not failed, but succeeded with changes present
+		err, cmdErr = ErrChangesPresent, nil
+	}
+
+	if err := job.Finish(trId, reqId, statusOf(err), exitCode, cmdErr); err != nil {
+		log.Warn().Err(err).Msgf("failed to record the result of the job (reqId: %s)", reqId)
+	}
+	return output, err
+}
+
+// hasArg checks if the command has a given flag.
+func hasArg(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// runCancellableCommand registers the command as running, so that it can be cancelled,
+// and executes it within the timeout.
+func runCancellableCommand(ctx context.Context, trId, reqId string, args []string) (string, error) {
+	timeout := timeoutOf(ctx)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	key := trId + "/" + reqId
+	rc := &runningCommand{cancel: cancel}
+	runningCommands.Store(key, rc)
+	defer runningCommands.Delete(key)
+
+	output, err := executeCommand(ctx, trId, reqId, args)
+	if err != nil {
+		rc.mu.Lock()
+		cancelled := rc.cancelled
+		rc.mu.Unlock()
+
+		switch {
+		case cancelled:
+			return output, &errCancelled{err: fmt.Errorf("cancelled by request: %w", err)}
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return output, fmt.Errorf("timed out after %s: %w", timeout, err)
+		}
+	}
+	return output, err
+}
+
+// uiCommands are the commands that print the machine-readable UI with -json.
+var uiCommands = map[string]bool{
+	"plan":    true,
+	"apply":   true,
+	"destroy": true,
+	"refresh": true,
+}
+
+// withJSONUI returns the args with -json, if the command prints the machine-readable UI,
+// so that the progress of the command can be tracked.
+// The flag is inserted right after the command because flags are not parsed after positional arguments.
+// apply and destroy are left as they are unless they are non-interactive
+// (i.e., -auto-approve or a saved plan), which -json requires.
+func withJSONUI(args []string) []string {
+	cmdIdx := -1
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			cmdIdx = i
+			break
+		}
+	}
+	if cmdIdx < 0 || !uiCommands[args[cmdIdx]] {
+		return args
+	}
+
+	nonInteractive := args[cmdIdx] == "plan" || args[cmdIdx] == "refresh"
+	for _, arg := range args[cmdIdx+1:] {
+		if arg == "-json" {
+			return args
+		}
+		if arg == "-auto-approve" || !strings.HasPrefix(arg, "-") {
+			nonInteractive = true
+		}
+	}
+	if !nonInteractive {
+		return args
+	}
+
+	ret := make([]string, 0, len(args)+1)
+	ret = append(ret, args[:cmdIdx+1]...)
+	ret = append(ret, "-json")
+	ret = append(ret, args[cmdIdx+1:]...)
+	return ret
+}
+
+// credentialCommands are the commands that read the credentials (i.e., configure the providers).
+var credentialCommands = map[string]bool{
+	"plan":    true,
+	"apply":   true,
+	"destroy": true,
+	"refresh": true,
+	"import":  true,
+	"console": true,
+	"test":    true,
+}
+
+// commandOf returns the command of the args (i.e., the first positional argument).
+func commandOf(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+// hasJSONUI checks if the command prints the machine-readable UI.
+func hasJSONUI(args []string) bool {
+	for i, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if !uiCommands[arg] {
+			return false
+		}
+		for _, arg := range args[i+1:] {
+			if arg == "-json" {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// lockedWriter serializes the writes from stdout and stderr of a command.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
 // executeCommand executes the tofu command with the given arguments.
-func executeCommand(reqId string, args []string) (string, error) {
-	var logFile *os.File
+// If the command prints the machine-readable UI (-json), the messages are kept in the event file
+// and tracked as the progress of the request, and their human-readable text is logged and returned.
+func executeCommand(ctx context.Context, trId, reqId string, args []string) (string, error) {
+	var logFile, eventFile *os.File
 	var outputBuffer bytes.Buffer
 	var err error
 
@@ -118,34 +613,134 @@ func executeCommand(reqId string, args []string) (string, error) {
 				return "", fmt.Errorf("failed to open log file: %v", err)
 			}
 			defer logFile.Close()
+
+			if hasJSONUI(args) {
+				eventFile, err = os.OpenFile(fmt.Sprintf("%s/%s.jsonl", logDir, reqId), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+				if err != nil {
+					return "", fmt.Errorf("failed to open event file: %v", err)
+				}
+				defer eventFile.Close()
+			}
 		}
 	}
 
-	cmd := exec.Command(cliName, args...)
+	var stdout, stderr io.Writer
 	if logFile != nil {
-		cmd.Stdout = io.MultiWriter(os.Stdout, logFile, &outputBuffer)
-		cmd.Stderr = io.MultiWriter(os.Stderr, logFile, &outputBuffer)
+		output := &lockedWriter{w: io.MultiWriter(logFile, &outputBuffer)}
+		stdout = io.MultiWriter(os.Stdout, output)
+		stderr = io.MultiWriter(os.Stderr, output)
 	} else {
-		cmd.Stdout = &outputBuffer
-		cmd.Stderr = &outputBuffer
+		output := &lockedWriter{w: &outputBuffer}
+		stdout = output
+		stderr = output
+	}
+
+	var ui *uistream.Writer
+	if hasJSONUI(args) {
+		ui = uistream.NewWriter(stdout, func(event uistream.Event, raw []byte) {
+			if eventFile != nil {
+				if _, err := fmt.Fprintf(eventFile, "%s\n", raw); err != nil {
+					log.Warn().Err(err).Msg("failed to write the event")
+				}
+			}
+			if err := job.Track(trId, reqId, event); err != nil {
+				log.Warn().Err(err).Msgf("failed to track the progress of the job (reqId: %s)", reqId)
+			}
+		})
+		stdout = ui
+	}
+
+	cmd := exec.CommandContext(ctx, cliName, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// Inject a short-lived token into the tofu process only, which expires with the command,
+	// so that the templates read the credentials without a long-lived token in the server environment
+	if secrets.Enabled() && credentialCommands[commandOf(args)] {
+		ttl := Timeout() + GracePeriod()
+		if deadline, ok := ctx.Deadline(); ok {
+			ttl = time.Until(deadline) + GracePeriod()
+		}
+		displayName := fmt.Sprintf("tofu-%s-%s", trId, reqId)
+		if trId == "" {
+			displayName = "tofu-" + reqId
+		}
+		runEnv, err := secrets.IssueRunEnv(ctx, ttl, displayName)
+		if err != nil {
+			return "", fmt.Errorf("failed to issue the credentials of the command: %w", err)
+		}
+		defer runEnv.Revoke()
+		cmd.Env = append(os.Environ(), runEnv.Env...)
+	}
+
+	// Run tofu and its providers in a new process group,
+	// so that they can be interrupted and killed together.
+	// On cancellation, tofu receives SIGINT first to stop gracefully (e.g., release the state lock)
+	// and the whole process group is killed if it does not stop within the grace period.
+	gracePeriod := GracePeriod()
+	var killTimer *time.Timer
+	var timerMu sync.Mutex
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		log.Warn().Msgf("Interrupting command: %s", fullCommand)
+		process := cmd.Process
+		timerMu.Lock()
+		killTimer = time.AfterFunc(gracePeriod, func() {
+			log.Warn().Msgf("Killing command (not stopped within %s): %s", gracePeriod, fullCommand)
+			if err := killProcessGroup(process); err != nil {
+				log.Error().Err(err).Msg("failed to kill the process group")
+			}
+		})
+		timerMu.Unlock()
+		return interruptProcessGroup(process)
 	}
+	// Stop waiting for the output of the orphaned child processes (e.g., providers)
+	cmd.WaitDelay = gracePeriod + 5*time.Second
 
-	if err := cmd.Run(); err != nil {
-		return outputBuffer.String(), fmt.Errorf("failed to execute command: %s. Error: %v", fullCommand, err)
+	err = cmd.Start()
+	if err == nil {
+		// Record the process to check if it still exists when recovering from a restart
+		// (not for a scratch command, which is not recorded as a request)
+		if trId != "" {
+			if err := job.SetPid(trId, reqId, cmd.Process.Pid); err != nil {
+				log.Warn().Err(err).Msgf("failed to record the process of the job (reqId: %s)", reqId)
+			}
+		}
+		err = cmd.Wait()
+	}
+
+	if ui != nil {
+		if err := ui.Flush(); err != nil {
+			log.Warn().Err(err).Msg("failed to flush the output")
+		}
+	}
+
+	timerMu.Lock()
+	if killTimer != nil {
+		killTimer.Stop()
+	}
+	timerMu.Unlock()
+
+	if err != nil {
+		return outputBuffer.String(), fmt.Errorf("failed to execute command: %s. Error: %w", fullCommand, err)
 	}
 
 	return outputBuffer.String(), nil
 }
 
-// GetExcutionHistory gets the running status for a given trId.
-func GetExcutionHistory(trId, statusLogFile string) (string, error) {
-	status, exists := GetExecutionStatus(trId)
+// GetExcutionHistory gets the status and the running log of a given request.
+func GetExcutionHistory(trId, reqId, statusLogFile string) (string, error) {
+	reqJob, exists, err := job.Get(trId, reqId)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the request: %w", err)
+	}
 	if !exists {
 		return "", errors.New("no request found")
 	}
+	status := reqJob.Status
 	log.Debug().Msgf("Request status: %s", status)
 
-	_, err := os.Stat(statusLogFile)
+	_, err = os.Stat(statusLogFile)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return "", errors.New("status log file does not exist")
@@ -0,0 +1,218 @@
+// Package uistream decodes the machine-readable UI of OpenTofu,
+// which is the stream of JSON messages printed by plan, apply, destroy and refresh with the -json flag.
+package uistream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Types of the messages in the machine-readable UI
+const (
+	TypeVersion           = "version"
+	TypeLog               = "log"
+	TypeDiagnostic        = "diagnostic"
+	TypeResourceDrift     = "resource_drift"
+	TypePlannedChange     = "planned_change"
+	TypeChangeSummary     = "change_summary"
+	TypeOutputs           = "outputs"
+	TypeApplyStart        = "apply_start"
+	TypeApplyProgress     = "apply_progress"
+	TypeApplyComplete     = "apply_complete"
+	TypeApplyErrored      = "apply_errored"
+	TypeProvisionStart    = "provision_start"
+	TypeProvisionComplete = "provision_complete"
+	TypeProvisionErrored  = "provision_errored"
+	TypeRefreshStart      = "refresh_start"
+	TypeRefreshComplete   = "refresh_complete"
+)
+
+// Event represents a message in the machine-readable UI.
+// Depending on the type, one of Hook, Change, Changes, Diagnostic and Outputs is set.
+type Event struct {
+	Level     string    `json:"@level" example:"info"`
+	Message   string    `json:"@message" example:"aws_vpc.main: Creating..."`
+	Module    string    `json:"@module" example:"tofu.ui"`
+	Timestamp time.Time `json:"@timestamp"`
+	Type      string    `json:"type" example:"apply_start"`
+
+	// Hook is set for apply_*, provision_* and refresh_* messages.
+	Hook *Hook `json:"hook,omitempty"`
+	// Change is set for planned_change and resource_drift messages.
+	Change *Change `json:"change,omitempty"`
+	// Changes is set for change_summary messages.
+	Changes *ChangeSummary `json:"changes,omitempty"`
+	// Diagnostic is set for diagnostic messages.
+	Diagnostic *Diagnostic `json:"diagnostic,omitempty"`
+	// Outputs is set for outputs messages.
+	Outputs map[string]Output `json:"outputs,omitempty"`
+}
+
+// Resource represents the address of a resource in a message.
+type Resource struct {
+	Addr            string `json:"addr" example:"aws_vpc.main"`
+	Module          string `json:"module,omitempty"`
+	Resource        string `json:"resource" example:"aws_vpc.main"`
+	ResourceType    string `json:"resource_type" example:"aws_vpc"`
+	ResourceName    string `json:"resource_name" example:"main"`
+	ResourceKey     any    `json:"resource_key,omitempty"`
+	ImpliedProvider string `json:"implied_provider,omitempty" example:"aws"`
+}
+
+// Hook represents the progress of an operation on a resource.
+type Hook struct {
+	Resource       Resource `json:"resource"`
+	Action         string   `json:"action,omitempty" example:"create"`
+	IdKey          string   `json:"id_key,omitempty" example:"id"`
+	IdValue        string   `json:"id_value,omitempty" example:"vpc-0123456789abcdef0"`
+	ElapsedSeconds int      `json:"elapsed_seconds,omitempty" example:"10"`
+}
+
+// Change represents a planned change (or a drift) of a resource.
+type Change struct {
+	Resource Resource `json:"resource"`
+	Action   string   `json:"action" example:"create"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+// ChangeSummary represents the number of changes of a plan or an apply.
+type ChangeSummary struct {
+	Add       int    `json:"add" example:"3"`
+	Change    int    `json:"change" example:"0"`
+	Import    int    `json:"import" example:"0"`
+	Remove    int    `json:"remove" example:"0"`
+	Operation string `json:"operation" example:"apply"`
+}
+
+// Diagnostic represents a warning or an error.
+type Diagnostic struct {
+	Severity string `json:"severity" example:"error"`
+	Summary  string `json:"summary"`
+	Detail   string `json:"detail,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Range    *Range `json:"range,omitempty"`
+}
+
+// Range represents the location of a diagnostic in the configuration.
+type Range struct {
+	Filename string `json:"filename" example:"main.tf"`
+	Start    Pos    `json:"start"`
+	End      Pos    `json:"end"`
+}
+
+// Pos represents a position in a configuration file.
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// Output represents an output value of the root module.
+type Output struct {
+	Sensitive bool            `json:"sensitive"`
+	Type      json.RawMessage `json:"type,omitempty" swaggertype:"object"`
+	Value     json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+	Action    string          `json:"action,omitempty"`
+}
+
+// Decode decodes a line of the machine-readable UI.
+// It returns false if the line is not a message (e.g., a line printed by a provider to stderr).
+func Decode(line []byte) (Event, bool) {
+	event := Event{}
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 || line[0] != '{' {
+		return event, false
+	}
+	if err := json.Unmarshal(line, &event); err != nil || event.Type == "" {
+		return event, false
+	}
+	return event, true
+}
+
+// Parse decodes all messages in r and skips the other lines.
+func Parse(r io.Reader) ([]Event, error) {
+	events := []Event{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if event, ok := Decode(scanner.Bytes()); ok {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+// Text returns the human-readable text of a message.
+// The detail of a diagnostic is appended to its message.
+func (e Event) Text() string {
+	if e.Diagnostic != nil && e.Diagnostic.Detail != "" {
+		return e.Message + "\n\n" + e.Diagnostic.Detail
+	}
+	return e.Message
+}
+
+// Writer decodes the machine-readable UI written to it line by line.
+// It calls the handler for each message and writes the human-readable text of the messages to the underlying writer.
+// The lines which are not messages are written as they are.
+type Writer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	handler func(event Event, raw []byte)
+	buf     []byte
+}
+
+// NewWriter returns a Writer that writes the human-readable text to w and calls handler for each message.
+// raw is the line of the message, which is valid only during the call.
+func NewWriter(w io.Writer, handler func(event Event, raw []byte)) *Writer {
+	return &Writer{w: w, handler: handler}
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := w.buf[:i+1]
+		if err := w.writeLine(line); err != nil {
+			return len(p), err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush processes the remaining incomplete line, if any.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	err := w.writeLine(append(w.buf, '\n'))
+	w.buf = nil
+	return err
+}
+
+func (w *Writer) writeLine(line []byte) error {
+	event, ok := Decode(line)
+	if !ok {
+		_, err := w.w.Write(line)
+		return err
+	}
+	if w.handler != nil {
+		w.handler(event, bytes.TrimSpace(line))
+	}
+	_, err := io.WriteString(w.w, event.Text()+"\n")
+	return err
+}
@@ -0,0 +1,137 @@
+package uistream
+
+import (
+	"strings"
+	"testing"
+)
+
+const applyOutput = `{"@level":"info","@message":"OpenTofu 1.8.0","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:00.000000Z","type":"version","tofu":"1.8.0","ui":"1.2"}
+{"@level":"info","@message":"aws_vpc.main: Plan to create","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:01.000000Z","type":"planned_change","change":{"resource":{"addr":"aws_vpc.main","resource":"aws_vpc.main","resource_type":"aws_vpc","resource_name":"main"},"action":"create"}}
+{"@level":"info","@message":"aws_vpc.main: Creating...","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:02.000000Z","type":"apply_start","hook":{"resource":{"addr":"aws_vpc.main","resource":"aws_vpc.main","resource_type":"aws_vpc","resource_name":"main"},"action":"create"}}
+2024/06/10 12:00:03 [WARN] a line printed by a provider
+{"@level":"info","@message":"aws_vpc.main: Still creating... [10s elapsed]","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:12.000000Z","type":"apply_progress","hook":{"resource":{"addr":"aws_vpc.main","resource":"aws_vpc.main","resource_type":"aws_vpc","resource_name":"main"},"action":"create","elapsed_seconds":10}}
+{"@level":"info","@message":"aws_vpc.main: Creation complete after 12s [id=vpc-0123]","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:14.000000Z","type":"apply_complete","hook":{"resource":{"addr":"aws_vpc.main","resource":"aws_vpc.main","resource_type":"aws_vpc","resource_name":"main"},"action":"create","id_key":"id","id_value":"vpc-0123","elapsed_seconds":12}}
+{"@level":"error","@message":"Error: creating EC2 Subnet","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:15.000000Z","type":"diagnostic","diagnostic":{"severity":"error","summary":"creating EC2 Subnet","detail":"InvalidParameterValue","address":"aws_subnet.main"}}
+{"@level":"info","@message":"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.","@module":"tofu.ui","@timestamp":"2024-06-10T12:00:16.000000Z","type":"change_summary","changes":{"add":1,"change":0,"import":0,"remove":0,"operation":"apply"}}
+`
+
+const applyText = `OpenTofu 1.8.0
+aws_vpc.main: Plan to create
+aws_vpc.main: Creating...
+2024/06/10 12:00:03 [WARN] a line printed by a provider
+aws_vpc.main: Still creating... [10s elapsed]
+aws_vpc.main: Creation complete after 12s [id=vpc-0123]
+Error: creating EC2 Subnet
+
+InvalidParameterValue
+Apply complete! Resources: 1 added, 0 changed, 0 destroyed.
+`
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		ok   bool
+		typ  string
+	}{
+		{name: "message", line: `{"@message":"aws_vpc.main: Creating...","type":"apply_start","hook":{"resource":{"addr":"aws_vpc.main"}}}`, ok: true, typ: TypeApplyStart},
+		{name: "surrounded by spaces", line: "  {\"type\":\"log\"}\r\n", ok: true, typ: TypeLog},
+		{name: "empty", line: "", ok: false},
+		{name: "plain text", line: "2024/06/10 12:00:03 [WARN] a line printed by a provider", ok: false},
+		{name: "invalid json", line: `{"type":"apply_start"`, ok: false},
+		{name: "json without type", line: `{"@message":"not a message"}`, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, ok := Decode([]byte(tt.line))
+			if ok != tt.ok || event.Type != tt.typ {
+				t.Errorf("Decode() = (%q, %v), want (%q, %v)", event.Type, ok, tt.typ, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	events, err := Parse(strings.NewReader(applyOutput))
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	types := []string{TypeVersion, TypePlannedChange, TypeApplyStart, TypeApplyProgress, TypeApplyComplete, TypeDiagnostic, TypeChangeSummary}
+	if len(events) != len(types) {
+		t.Fatalf("Parse() returned %d events, want %d", len(events), len(types))
+	}
+	for i, typ := range types {
+		if events[i].Type != typ {
+			t.Errorf("events[%d].Type = %q, want %q", i, events[i].Type, typ)
+		}
+	}
+
+	if c := events[1].Change; c == nil || c.Resource.Addr != "aws_vpc.main" || c.Action != "create" {
+		t.Errorf("planned_change = %+v, want the change of aws_vpc.main", c)
+	}
+	if h := events[4].Hook; h == nil || h.IdValue != "vpc-0123" || h.ElapsedSeconds != 12 {
+		t.Errorf("apply_complete = %+v, want the hook with the id and the elapsed time", h)
+	}
+	if d := events[5].Diagnostic; d == nil || d.Severity != "error" || d.Address != "aws_subnet.main" {
+		t.Errorf("diagnostic = %+v, want the error of aws_subnet.main", d)
+	}
+	if s := events[6].Changes; s == nil || s.Add != 1 || s.Operation != "apply" {
+		t.Errorf("change_summary = %+v, want 1 added by apply", s)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	// The output is written in chunks splitting the lines
+	for _, size := range []int{1, 7, 64, len(applyOutput)} {
+		var text strings.Builder
+		var types []string
+		w := NewWriter(&text, func(event Event, raw []byte) {
+			if _, ok := Decode(raw); !ok {
+				t.Errorf("raw line of %s is not a message: %s", event.Type, raw)
+			}
+			types = append(types, event.Type)
+		})
+
+		for i := 0; i < len(applyOutput); i += size {
+			chunk := applyOutput[i:min(i+size, len(applyOutput))]
+			if n, err := w.Write([]byte(chunk)); err != nil || n != len(chunk) {
+				t.Fatalf("Write() = (%d, %v), want (%d, nil)", n, err, len(chunk))
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush() failed: %v", err)
+		}
+
+		if text.String() != applyText {
+			t.Errorf("text written in chunks of %d bytes:\n%s\nwant:\n%s", size, text.String(), applyText)
+		}
+		if len(types) != 7 {
+			t.Errorf("handler called %d times with chunks of %d bytes, want 7", len(types), size)
+		}
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	var text strings.Builder
+	handled := 0
+	w := NewWriter(&text, func(event Event, raw []byte) { handled++ })
+
+	// The last line without a newline is processed by Flush only
+	w.Write([]byte(`{"@message":"Outputs: 1","type":"outputs","outputs":{"vpc_id":{"sensitive":false,"value":"vpc-0123"}}}`))
+	if handled != 0 || text.Len() != 0 {
+		t.Fatalf("an incomplete line is processed before Flush()")
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	if handled != 1 || text.String() != "Outputs: 1\n" {
+		t.Errorf("Flush() handled %d messages and wrote %q, want 1 and %q", handled, text.String(), "Outputs: 1\n")
+	}
+
+	// Nothing remains after Flush
+	if err := w.Flush(); err != nil || handled != 1 {
+		t.Errorf("second Flush() = %v with %d messages handled, want nil and 1", err, handled)
+	}
+}
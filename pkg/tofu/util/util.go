@@ -1,6 +1,7 @@
 package tfutil
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,8 +15,8 @@ import (
 )
 
 // Version returns the version of the Tofu installation
-func Version() (string, error) {
-	cmd := exec.Command("tofu", "version")
+func Version(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, "tofu", "version")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -0,0 +1,42 @@
+# A read-only configuration to verify the Alibaba Cloud credentials (no resource is created)
+terraform {
+  required_providers {
+    alicloud = {
+      source  = "aliyun/alicloud"
+      version = "~>1.243.0"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read Alibaba Cloud credentials from OpenBao
+data "vault_kv_secret_v2" "alibaba" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/alibaba" : "users/${var.credential_profile}/csp/alibaba"
+}
+
+provider "alicloud" {
+  region     = "ap-northeast-2" # Default: "ap-northeast-2", Seoul region, Korea
+  access_key = data.vault_kv_secret_v2.alibaba.data["ALIBABA_CLOUD_ACCESS_KEY_ID"]
+  secret_key = data.vault_kv_secret_v2.alibaba.data["ALIBABA_CLOUD_ACCESS_KEY_SECRET"]
+}
+
+# Who am I (sts:GetCallerIdentity)
+data "alicloud_caller_identity" "current" {}
+
+output "identity" {
+  value = data.alicloud_caller_identity.current.arn
+}
@@ -0,0 +1,42 @@
+# A read-only configuration to verify the AWS credentials (no resource is created)
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~>5.42"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read AWS credentials from OpenBao
+data "vault_kv_secret_v2" "aws" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/aws" : "users/${var.credential_profile}/csp/aws"
+}
+
+provider "aws" {
+  region     = "ap-northeast-2" # Default: "ap-northeast-2", Seoul region, Korea
+  access_key = data.vault_kv_secret_v2.aws.data["AWS_ACCESS_KEY_ID"]
+  secret_key = data.vault_kv_secret_v2.aws.data["AWS_SECRET_ACCESS_KEY"]
+}
+
+# Who am I (sts:GetCallerIdentity)
+data "aws_caller_identity" "current" {}
+
+output "identity" {
+  value = data.aws_caller_identity.current.arn
+}
@@ -0,0 +1,46 @@
+# A read-only configuration to verify the Azure credentials (no resource is created)
+terraform {
+  required_providers {
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = "~>3.97.0"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read Azure credentials from OpenBao
+data "vault_kv_secret_v2" "azure" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/azure" : "users/${var.credential_profile}/csp/azure"
+}
+
+provider "azurerm" {
+  skip_provider_registration = true
+  features {}
+
+  client_id       = data.vault_kv_secret_v2.azure.data["ARM_CLIENT_ID"]
+  client_secret   = data.vault_kv_secret_v2.azure.data["ARM_CLIENT_SECRET"]
+  tenant_id       = data.vault_kv_secret_v2.azure.data["ARM_TENANT_ID"]
+  subscription_id = data.vault_kv_secret_v2.azure.data["ARM_SUBSCRIPTION_ID"]
+}
+
+# The subscription of the service principal
+data "azurerm_subscription" "current" {}
+
+output "identity" {
+  value = data.azurerm_subscription.current.display_name
+}
@@ -0,0 +1,46 @@
+# A read-only configuration to verify the OpenStack (DCS) credentials (no resource is created)
+terraform {
+  required_providers {
+    openstack = {
+      source  = "registry.opentofu.org/terraform-provider-openstack/openstack"
+      version = "~>3.3"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read OpenStack/DCS credentials from OpenBao
+data "vault_kv_secret_v2" "openstack" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/openstack" : "users/${var.credential_profile}/csp/openstack"
+}
+
+provider "openstack" {
+  auth_url    = data.vault_kv_secret_v2.openstack.data["OS_AUTH_URL"]
+  user_name   = data.vault_kv_secret_v2.openstack.data["OS_USERNAME"]
+  password    = data.vault_kv_secret_v2.openstack.data["OS_PASSWORD"]
+  domain_name = data.vault_kv_secret_v2.openstack.data["OS_DOMAIN_NAME"]
+  tenant_id   = data.vault_kv_secret_v2.openstack.data["OS_PROJECT_ID"]
+}
+
+# The scope of the current token (i.e., authenticated)
+data "openstack_identity_auth_scope_v3" "current" {
+  name = "current"
+}
+
+output "identity" {
+  value = data.openstack_identity_auth_scope_v3.current.user_name
+}
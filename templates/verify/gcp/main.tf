@@ -0,0 +1,59 @@
+# A read-only configuration to verify the GCP credentials (no resource is created)
+terraform {
+  required_providers {
+    google = {
+      source  = "hashicorp/google"
+      version = "~>5.21"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read GCP credentials from OpenBao
+data "vault_kv_secret_v2" "gcp" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/gcp" : "users/${var.credential_profile}/csp/gcp"
+}
+
+# Reconstruct GCP credential JSON from OpenBao KV data
+locals {
+  gcp_credential = jsonencode({
+    type           = "service_account"
+    project_id     = data.vault_kv_secret_v2.gcp.data["project_id"]
+    private_key_id = data.vault_kv_secret_v2.gcp.data["private_key_id"]
+    # Replace literal "\n" with actual newlines (OpenBao may store escaped newlines)
+    private_key                 = replace(data.vault_kv_secret_v2.gcp.data["private_key"], "\\n", "\n")
+    client_email                = data.vault_kv_secret_v2.gcp.data["client_email"]
+    client_id                   = data.vault_kv_secret_v2.gcp.data["client_id"]
+    auth_uri                    = "https://accounts.google.com/o/oauth2/auth"
+    token_uri                   = "https://oauth2.googleapis.com/token"
+    auth_provider_x509_cert_url = "https://www.googleapis.com/oauth2/v1/certs"
+    client_x509_cert_url        = "https://www.googleapis.com/robot/v1/metadata/x509/${urlencode(data.vault_kv_secret_v2.gcp.data["client_email"])}"
+  })
+}
+
+provider "google" {
+  credentials = local.gcp_credential
+  project     = data.vault_kv_secret_v2.gcp.data["project_id"]
+  region      = "asia-northeast3" # Default: "asia-northeast3", Seoul region, Korea
+}
+
+# The project of the service account
+data "google_project" "current" {}
+
+output "identity" {
+  value = data.google_project.current.project_id
+}
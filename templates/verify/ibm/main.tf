@@ -0,0 +1,43 @@
+# A read-only configuration to verify the IBM Cloud credentials (no resource is created)
+terraform {
+  required_providers {
+    ibm = {
+      source  = "ibm-cloud/ibm"
+      version = "~>1.76.0"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read IBM Cloud credentials from OpenBao
+data "vault_kv_secret_v2" "ibm" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/ibm" : "users/${var.credential_profile}/csp/ibm"
+}
+
+provider "ibm" {
+  region           = "au-syd" # Default: "au-syd",  Sydney region, Australia
+  ibmcloud_api_key = data.vault_kv_secret_v2.ibm.data["IC_API_KEY"]
+}
+
+# The default resource group of the account
+data "ibm_resource_group" "default" {
+  is_default = true
+}
+
+output "identity" {
+  value = data.ibm_resource_group.default.account_id
+}
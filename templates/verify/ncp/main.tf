@@ -0,0 +1,43 @@
+# A read-only configuration to verify the NCP credentials (no resource is created)
+terraform {
+  required_providers {
+    ncloud = {
+      source  = "NaverCloudPlatform/ncloud"
+      version = "3.2.1"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read NCP credentials from OpenBao
+data "vault_kv_secret_v2" "ncp" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/ncp" : "users/${var.credential_profile}/csp/ncp"
+}
+
+provider "ncloud" {
+  access_key  = data.vault_kv_secret_v2.ncp.data["NCLOUD_ACCESS_KEY"]
+  secret_key  = data.vault_kv_secret_v2.ncp.data["NCLOUD_SECRET_KEY"]
+  region      = "KR"
+  support_vpc = true
+}
+
+# The regions available to the account
+data "ncloud_regions" "available" {}
+
+output "identity" {
+  value = length(data.ncloud_regions.available.regions)
+}
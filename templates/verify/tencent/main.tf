@@ -0,0 +1,42 @@
+# A read-only configuration to verify the Tencent Cloud credentials (no resource is created)
+terraform {
+  required_providers {
+    tencentcloud = {
+      source  = "tencentcloudstack/tencentcloud"
+      version = "~>1.82.0"
+    }
+    # Vault provider for OpenBao credential management
+    vault = {
+      source  = "hashicorp/vault"
+      version = "~>4.0"
+    }
+  }
+}
+
+variable "credential_profile" {
+  type        = string
+  description = "The name of the credential profile (holder) to use."
+  default     = "admin"
+}
+
+# Vault provider reads VAULT_ADDR and VAULT_TOKEN from environment
+provider "vault" {}
+
+# Read Tencent Cloud credentials from OpenBao
+data "vault_kv_secret_v2" "tencent" {
+  mount = "secret"
+  name  = var.credential_profile == "admin" ? "csp/tencent" : "users/${var.credential_profile}/csp/tencent"
+}
+
+provider "tencentcloud" {
+  region     = "ap-seoul" # Default: "ap-seoul", Seoul region, Korea
+  secret_id  = data.vault_kv_secret_v2.tencent.data["TENCENTCLOUD_SECRET_ID"]
+  secret_key = data.vault_kv_secret_v2.tencent.data["TENCENTCLOUD_SECRET_KEY"]
+}
+
+# The account of the credentials
+data "tencentcloud_user_info" "current" {}
+
+output "identity" {
+  value = data.tencentcloud_user_info.current.uin
+}